@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+	"go.uber.org/zap"
+)
+
+// newLogger builds the service logger: JSON output for production, the
+// human-readable development encoder when format is console
+func newLogger(format, level string) (*zap.Logger, error) {
+	atomicLevel, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	
+	var zapConfig zap.Config
+	switch format {
+	case config.LogFormatConsole:
+		zapConfig = zap.NewDevelopmentConfig()
+	case config.LogFormatJSON:
+		zapConfig = zap.NewProductionConfig()
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+	zapConfig.Level = atomicLevel
+	
+	return zapConfig.Build()
+}
\ No newline at end of file
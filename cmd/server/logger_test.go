@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// loggedLine builds a logger with newLogger, logs one warning and returns
+// what it wrote to stderr
+func loggedLine(t *testing.T, format, level string) string {
+	t.Helper()
+	
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = writer
+	defer func() {
+		os.Stderr = stderr
+	}()
+	
+	logger, err := newLogger(format, level)
+	if err != nil {
+		t.Fatalf("newLogger(%s, %s): %v", format, level, err)
+	}
+	logger.Warn("disk almost full")
+	logger.Sync()
+	writer.Close()
+	
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading stderr: %v", err)
+	}
+	return string(output)
+}
+
+func TestNewLoggerEncoder(t *testing.T) {
+	if line := loggedLine(t, "json", "info"); !strings.HasPrefix(line, "{") || !strings.Contains(line, `"msg":"disk almost full"`) {
+		t.Errorf("json output = %q, want a JSON object", line)
+	}
+	if line := loggedLine(t, "console", "info"); strings.HasPrefix(line, "{") || !strings.Contains(line, "WARN") {
+		t.Errorf("console output = %q, want a plain text line", line)
+	}
+}
+
+func TestNewLoggerLevel(t *testing.T) {
+	for _, format := range []string{"json", "console"} {
+		logger, err := newLogger(format, "warn")
+		if err != nil {
+			t.Fatalf("newLogger(%s, warn): %v", format, err)
+		}
+		core := logger.Core()
+		if core.Enabled(zapcore.InfoLevel) || !core.Enabled(zapcore.WarnLevel) {
+			t.Errorf("%s logger at warn: info enabled %v, warn enabled %v, want warn and up only",
+				format, core.Enabled(zapcore.InfoLevel), core.Enabled(zapcore.WarnLevel))
+		}
+	}
+	
+	if _, err := newLogger("json", "loud"); err == nil {
+		t.Error("newLogger with level loud succeeded, want an error")
+	}
+	if _, err := newLogger("xml", "info"); err == nil {
+		t.Error("newLogger with format xml succeeded, want an error")
+	}
+}
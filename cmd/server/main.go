@@ -7,27 +7,37 @@ import (
 	"syscall"
 	"time"
 
-	"weather-aggregator/internal/api"
-	"weather-aggregator/internal/config"
-	"weather-aggregator/internal/scheduler"
-	"weather-aggregator/internal/services"
+	"github.com/bobby-s-dev/weather-aggregator/internal/api"
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+	"github.com/bobby-s-dev/weather-aggregator/internal/scheduler"
+	"github.com/bobby-s-dev/weather-aggregator/internal/services"
+	"github.com/bobby-s-dev/weather-aggregator/internal/version"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 func main() {
-	// Initialize logger
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
-	
-	zap.ReplaceGlobals(logger)
-	logger.Info("Starting Weather Data Aggregator Service")
+	// Bootstrap logger used until the configured one is available
+	bootstrap, _ := zap.NewProduction()
+	zap.ReplaceGlobals(bootstrap)
 	
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		logger.Fatal("Failed to load configuration", zap.Error(err))
+		bootstrap.Fatal("Failed to load configuration", zap.Error(err))
+	}
+	
+	// Initialize logger
+	logger, err := newLogger(cfg.Server.LogFormat, cfg.Server.LogLevel)
+	if err != nil {
+		bootstrap.Fatal("Failed to initialize logger", zap.Error(err))
 	}
+	defer logger.Sync()
+	
+	zap.ReplaceGlobals(logger)
+	logger.Info("Starting Weather Data Aggregator Service",
+		zap.String("version", version.Version),
+		zap.String("commit", version.Commit))
 	
 	// Initialize aggregator
 	aggregator, err := services.NewAggregator(cfg, logger)
@@ -35,11 +45,22 @@ func main() {
 		logger.Fatal("Failed to initialize aggregator", zap.Error(err))
 	}
 	
+	// Optionally populate the cache before accepting traffic
+	if cfg.Cache.WarmOnStart {
+		aggregator.WarmCache(context.Background(), cfg.Scheduler.DefaultCities, cfg.Cache.WarmTimeout)
+	}
+	
 	// Initialize scheduler
 	weatherScheduler := scheduler.NewScheduler(
 		aggregator,
 		cfg.Scheduler.DefaultCities,
 		cfg.Scheduler.FetchInterval,
+		cfg.Scheduler.FetchTimeout,
+		cfg.Scheduler.RetryAttempts,
+		cfg.Scheduler.RetryDelay,
+		cfg.Scheduler.StartJitter,
+		cfg.Scheduler.SpreadCities,
+		cfg.Scheduler.DailySummaryAt,
 		logger,
 	)
 	
@@ -47,12 +68,11 @@ func main() {
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
-		JSONEncoder:  fiber.DefaultJSONEncoder,
 		ErrorHandler: errorHandler,
 	})
 	
 	// Setup handlers and routes
-	handler := api.NewHandler(aggregator, logger)
+	handler := api.NewHandler(aggregator, cfg, logger)
 	api.SetupRoutes(app, handler, logger)
 	
 	// Start scheduler
@@ -79,7 +99,8 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	
-	// Stop scheduler
+	// Stop scheduler, cancelling its fetches in flight and waiting for them, so
+	// none is left running when the aggregator is closed below
 	weatherScheduler.Stop()
 	
 	// Shutdown Fiber app
@@ -87,6 +108,12 @@ func main() {
 		logger.Error("Server shutdown failed", zap.Error(err))
 	}
 	
+	// Once no request is served anymore, stop background work, save fetch
+	// stats and release storage and connections
+	if err := aggregator.Close(ctx); err != nil {
+		logger.Error("Aggregator shutdown failed", zap.Error(err))
+	}
+	
 	logger.Info("Server stopped")
 }
 
@@ -104,8 +131,5 @@ func errorHandler(c *fiber.Ctx, err error) error {
 		code = e.Code
 	}
 	
-	return c.Status(code).JSON(fiber.Map{
-		"error":   err.Error(),
-		"success": false,
-	})
+	return api.RespondError(c, code, api.CodeForStatus(code), err.Error(), nil)
 }
\ No newline at end of file
@@ -3,24 +3,42 @@ module github.com/bobby-s-dev/weather-aggregator
 go 1.21
 
 require (
-    github.com/gofiber/fiber/v2 v2.52.0
-    github.com/joho/godotenv v1.5.1
-    github.com/robfig/cron/v3 v3.0.1
-    github.com/sony/gobreaker v0.5.0
-    go.uber.org/zap v1.26.0
+	github.com/alicebob/miniredis/v2 v2.31.1
+	github.com/gofiber/fiber/v2 v2.52.0
+	github.com/joho/godotenv v1.5.1
+	github.com/redis/go-redis/v9 v9.5.1
+	github.com/sony/gobreaker v0.5.0
+	go.uber.org/zap v1.26.0
+	modernc.org/sqlite v1.29.5
 )
 
 require (
-    github.com/andybalholm/brotli v1.0.5 // indirect
-    github.com/google/uuid v1.5.0 // indirect
-    github.com/klauspost/compress v1.17.0 // indirect
-    github.com/mattn/go-colorable v0.1.13 // indirect
-    github.com/mattn/go-isatty v0.0.20 // indirect
-    github.com/mattn/go-runewidth v0.0.15 // indirect
-    github.com/rivo/uniseg v0.2.0 // indirect
-    github.com/valyala/bytebufferpool v1.0.0 // indirect
-    github.com/valyala/fasthttp v1.51.0 // indirect
-    github.com/valyala/tcplisten v1.0.0 // indirect
-    go.uber.org/multierr v1.10.0 // indirect
-    golang.org/x/sys v0.15.0 // indirect
-)
\ No newline at end of file
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/andybalholm/brotli v1.0.5 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.5.0 // indirect
+	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
+	github.com/klauspost/compress v1.17.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-runewidth v0.0.15 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/philhofer/fwd v1.1.2 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/tinylib/msgp v1.1.8 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasthttp v1.51.0 // indirect
+	github.com/valyala/tcplisten v1.0.0 // indirect
+	github.com/yuin/gopher-lua v1.1.0 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+	modernc.org/gc/v3 v3.0.0-20240107210532-573471604cb6 // indirect
+	modernc.org/libc v1.41.0 // indirect
+	modernc.org/mathutil v1.6.0 // indirect
+	modernc.org/memory v1.7.2 // indirect
+	modernc.org/strutil v1.2.0 // indirect
+	modernc.org/token v1.1.0 // indirect
+)
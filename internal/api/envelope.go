@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// fetchTrackerLocal holds the request's fetch tracker in the fiber locals
+	fetchTrackerLocal = "fetch_tracker"
+	
+	// expiryTrackerLocal holds the request's cache expiry tracker
+	expiryTrackerLocal = "expiry_tracker"
+)
+
+type envelope struct {
+	Data interface{}  `json:"data"`
+	Meta envelopeMeta `json:"meta"`
+}
+
+type envelopeMeta struct {
+	Sources []string `json:"sources,omitempty"`
+	Cached  *bool    `json:"cached,omitempty"` // only on endpoints that may fetch
+	Age     *int64   `json:"age,omitempty"`    // seconds since the data was last updated
+}
+
+// requestContext is the context weather lookups run under; it records whether
+// the request had to fetch from the providers for the envelope's cached flag,
+// and when the served data expires for the Cache-Control header
+func requestContext(c *fiber.Ctx) context.Context {
+	ctx, fetched := services.TrackFetches(c.Context())
+	c.Locals(fetchTrackerLocal, fetched)
+	ctx, expiry := services.TrackExpiry(ctx)
+	c.Locals(expiryTrackerLocal, expiry)
+	return ctx
+}
+
+// setCacheControl lets intermediaries cache the response until the data it
+// was built from expires. Responses behind API keys are only cached privately.
+func (h *Handler) setCacheControl(c *fiber.Ctx) {
+	tracker, ok := c.Locals(expiryTrackerLocal).(*services.ExpiryTracker)
+	if !ok {
+		return
+	}
+	expiresAt := tracker.Earliest()
+	if expiresAt.IsZero() {
+		return
+	}
+	
+	maxAge := int64(time.Until(expiresAt).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	
+	value := "max-age=" + strconv.FormatInt(maxAge, 10)
+	if len(h.cfg.API.Keys) > 0 {
+		value = "private, " + value
+	}
+	c.Set(fiber.HeaderCacheControl, value)
+}
+
+// send writes payload, wrapped in an envelope when requested. value is the
+// unprojected response the metadata is read from.
+func (h *Handler) send(c *fiber.Ctx, value, payload interface{}) error {
+	wrap := h.cfg.API.Envelope
+	if param := c.Query("envelope"); param != "" {
+		parsed, err := strconv.ParseBool(param)
+		if err != nil {
+			return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, "Envelope parameter must be true or false", nil)
+		}
+		wrap = parsed
+	}
+	
+	h.setCacheControl(c)
+	if !wrap {
+		return c.JSON(payload)
+	}
+	
+	return c.JSON(envelope{
+		Data: payload,
+		Meta: buildMeta(c, value),
+	})
+}
+
+func buildMeta(c *fiber.Ctx, value interface{}) envelopeMeta {
+	var meta envelopeMeta
+	var lastUpdated time.Time
+	
+	switch v := value.(type) {
+	case *models.AggregatedCurrentWeather:
+		meta.Sources, lastUpdated = v.Sources, v.LastUpdated
+	case *models.AggregatedForecast:
+		meta.Sources, lastUpdated = v.Sources, v.LastUpdated
+	case *models.MultiHorizonForecast:
+		meta.Sources, lastUpdated = v.Forecast.Sources, v.Forecast.LastUpdated
+	case *models.PointForecast:
+		meta.Sources = v.Sources
+	case *models.WeatherSummary:
+		lastUpdated = v.LastUpdated
+	}
+	
+	if !lastUpdated.IsZero() {
+		age := int64(time.Since(lastUpdated).Seconds())
+		meta.Age = &age
+	}
+	
+	if fetched, ok := c.Locals(fetchTrackerLocal).(*atomic.Bool); ok {
+		cached := !fetched.Load()
+		meta.Cached = &cached
+	}
+	
+	return meta
+}
\ No newline at end of file
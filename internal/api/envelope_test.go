@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnvelopeParameter(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	_, body := server.get(t, "/api/v1/weather/current?city=Prague")
+	if _, wrapped := body["data"]; wrapped || body["temperature"] != 20.0 {
+		t.Errorf("default body = %v, want the bare weather", body)
+	}
+	
+	_, body = server.get(t, "/api/v1/weather/current?city=Prague&envelope=true")
+	data, _ := body["data"].(map[string]interface{})
+	meta, _ := body["meta"].(map[string]interface{})
+	if data["temperature"] != 20.0 {
+		t.Errorf("data = %v, want the weather", body["data"])
+	}
+	if sources, _ := meta["sources"].([]interface{}); len(sources) != 1 || sources[0] != "fake" {
+		t.Errorf("meta.sources = %v, want [fake]", meta["sources"])
+	}
+	if meta["cached"] != true {
+		t.Errorf("meta.cached = %v on the second request, want true", meta["cached"])
+	}
+	if _, ok := meta["age"].(float64); !ok {
+		t.Errorf("meta.age = %v, want seconds", meta["age"])
+	}
+	
+	_, body = server.get(t, "/api/v1/weather/current?city=London&envelope=true")
+	if meta, _ := body["meta"].(map[string]interface{}); meta["cached"] != false {
+		t.Errorf("meta.cached = %v for a fetched city, want false", meta["cached"])
+	}
+	
+	_, body = server.get(t, "/api/v1/health?envelope=true")
+	if data, _ := body["data"].(map[string]interface{}); data["status"] == nil {
+		t.Errorf("health body = %v, want the status under data", body)
+	}
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&envelope=maybe")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("envelope=maybe: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestEnvelopeConfigDefault(t *testing.T) {
+	t.Setenv("RESPONSE_ENVELOPE", "true")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	_, body := server.get(t, "/api/v1/weather/forecast?city=Prague&days=3")
+	data, _ := body["data"].(map[string]interface{})
+	if days, _ := data["days"].([]interface{}); len(days) != 3 {
+		t.Errorf("body = %v, want the 3-day forecast under data", body)
+	}
+	
+	_, body = server.get(t, "/api/v1/weather/forecast?city=Prague&days=3&envelope=false")
+	if days, _ := body["days"].([]interface{}); len(days) != 3 {
+		t.Errorf("envelope=false body = %v, want the bare forecast", body)
+	}
+}
+
+// maxAge parses the max-age of a Cache-Control header, -1 without one
+func maxAge(t *testing.T, header string) int {
+	t.Helper()
+	
+	index := strings.Index(header, "max-age=")
+	if index < 0 {
+		return -1
+	}
+	seconds, err := strconv.Atoi(header[index+len("max-age="):])
+	if err != nil {
+		t.Fatalf("Cache-Control %q: %v", header, err)
+	}
+	return seconds
+}
+
+func TestCacheControlReflectsRemainingTTL(t *testing.T) {
+	t.Setenv("CACHE_DURATION", "10s")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, _ := server.get(t, "/api/v1/weather/current?city=Prague")
+	if fresh := maxAge(t, resp.Header.Get("Cache-Control")); fresh < 9 || fresh > 10 {
+		t.Fatalf("max-age = %d on a fresh fetch, want the 10s TTL", fresh)
+	}
+	
+	time.Sleep(1100 * time.Millisecond)
+	resp, _ = server.get(t, "/api/v1/weather/current?city=Prague")
+	if hit := maxAge(t, resp.Header.Get("Cache-Control")); hit < 8 || hit > 9 {
+		t.Errorf("max-age = %d on a cache hit a second later, want the remaining TTL", hit)
+	}
+	
+	resp, _ = server.get(t, "/api/v1/health")
+	if header := resp.Header.Get("Cache-Control"); header != "" {
+		t.Errorf("health Cache-Control = %q, want none", header)
+	}
+	resp, _ = server.get(t, "/api/v1/weather/forecast?city=Prague&days=0")
+	if header := resp.Header.Get("Cache-Control"); header != "no-store" {
+		t.Errorf("error Cache-Control = %q, want no-store", header)
+	}
+}
+
+func TestCacheControlIsPrivateBehindAPIKeys(t *testing.T) {
+	t.Setenv("API_KEYS", "alpha")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/current?city=Prague", nil)
+	req.Header.Set(apiKeyHeader, "alpha")
+	resp, _ := server.do(t, req)
+	if header := resp.Header.Get("Cache-Control"); !strings.HasPrefix(header, "private, max-age=") {
+		t.Errorf("Cache-Control = %q, want a private max-age", header)
+	}
+}
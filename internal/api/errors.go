@@ -0,0 +1,84 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Error codes of the error response, stable for clients to branch on
+const (
+	CodeInvalidParameter      = "INVALID_PARAMETER"
+	CodeCityRequired          = "CITY_REQUIRED"
+	CodeInvalidCity           = "INVALID_CITY"
+	CodeInvalidDays           = "INVALID_DAYS"
+	CodeInvalidTime           = "INVALID_TIME"
+	CodeUnknownSource         = "UNKNOWN_SOURCE"
+	CodeConfidenceTooLow      = "CONFIDENCE_TOO_LOW"
+	CodeInsufficientSources   = "INSUFFICIENT_SOURCES"
+	CodeCityNotFound          = "CITY_NOT_FOUND"
+	CodeNotCached             = "NOT_CACHED"
+	CodeProviderNotFound      = "PROVIDER_NOT_FOUND"
+	CodeHistoryDisabled       = "HISTORY_DISABLED"
+	CodeNoHistoricalProvider  = "NO_HISTORICAL_PROVIDER"
+	CodeNoForecastDays        = "NO_FORECAST_DAYS"
+	CodeMaintenance           = "MAINTENANCE"
+	CodeUpstreamUnavailable   = "UPSTREAM_UNAVAILABLE"
+	CodeNoEnabledProvider     = "NO_ENABLED_PROVIDER"
+	CodeUpstreamError         = "UPSTREAM_ERROR"
+	CodeUnauthorized          = "UNAUTHORIZED"
+	CodeAdminDisabled         = "ADMIN_DISABLED"
+	CodeRateLimited           = "RATE_LIMITED"
+	CodeEndpointNotFound      = "ENDPOINT_NOT_FOUND"
+	CodeInternal              = "INTERNAL_ERROR"
+)
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// RespondError writes an error response; details may be nil
+func RespondError(c *fiber.Ctx, status int, code, message string, details interface{}) error {
+	// Errors are never cached, a retry may well succeed
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(status).JSON(ErrorResponse{
+		Error: ErrorBody{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	})
+}
+
+// respondInsufficientSources rejects a result aggregated from fewer than
+// minSources providers
+func respondInsufficientSources(c *fiber.Ctx, sources []string, minSources int) error {
+	return RespondError(c, fiber.StatusUnprocessableEntity, CodeInsufficientSources, "Fewer providers contributed than required", fiber.Map{
+		"sources":     sources,
+		"min_sources": minSources,
+	})
+}
+
+// CodeForStatus is the error code of errors that only carry an HTTP status,
+// such as the ones fiber raises before a handler runs
+func CodeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeInvalidParameter
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusNotFound, fiber.StatusMethodNotAllowed:
+		return CodeEndpointNotFound
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	case fiber.StatusServiceUnavailable:
+		return CodeUpstreamUnavailable
+	default:
+		return CodeInternal
+	}
+}
\ No newline at end of file
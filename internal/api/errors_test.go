@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorResponsesCarryCodes(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	tests := []struct {
+		target string
+		status int
+		code   string
+	}{
+		{"/api/v1/weather/current", http.StatusBadRequest, CodeCityRequired},
+		{"/api/v1/weather/current?city=Prague%26units%3Dimperial", http.StatusBadRequest, CodeInvalidCity},
+		{"/api/v1/weather/current?city=Prague&sources=nope", http.StatusBadRequest, CodeUnknownSource},
+		{"/api/v1/weather/forecast?city=Prague&days=0", http.StatusBadRequest, CodeInvalidDays},
+		{"/api/v1/weather/at?city=Prague&time=tomorrow", http.StatusBadRequest, CodeInvalidTime},
+		{"/api/v1/weather/history?city=Prague&date=yesterday", http.StatusBadRequest, CodeInvalidTime},
+		{"/api/v1/weather/compare?cities=Prague", http.StatusBadRequest, CodeInvalidParameter},
+		{"/api/v1/weather/daily-summary?city=Tokyo", http.StatusNotFound, CodeCityNotFound},
+		{"/api/v1/admin/cache", http.StatusForbidden, CodeAdminDisabled},
+		{"/api/v1/nope", http.StatusNotFound, CodeEndpointNotFound},
+	}
+	for _, tt := range tests {
+		resp, body := server.get(t, tt.target)
+		if resp.StatusCode != tt.status || errorCode(body) != tt.code {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.target, resp.StatusCode, errorCode(body), tt.status, tt.code)
+		}
+		if errorBody, _ := body["error"].(map[string]interface{}); errorBody["message"] == "" {
+			t.Errorf("GET %s has no error message", tt.target)
+		}
+		if cacheControl := resp.Header.Get("Cache-Control"); cacheControl != "no-store" {
+			t.Errorf("GET %s Cache-Control = %q, want no-store", tt.target, cacheControl)
+		}
+	}
+}
+
+func TestUpstreamErrorCarriesDetails(t *testing.T) {
+	server := newTestServer(t, &fakeClient{name: "fake", err: errors.New("provider down")})
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague")
+	if resp.StatusCode != http.StatusInternalServerError || errorCode(body) != CodeUpstreamError {
+		t.Fatalf("GET current = %d %q, want 500 %q", resp.StatusCode, errorCode(body), CodeUpstreamError)
+	}
+	if errorBody, _ := body["error"].(map[string]interface{}); errorBody["details"] == nil {
+		t.Errorf("error = %v, want the underlying error as details", errorBody)
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{fiber.StatusBadRequest, CodeInvalidParameter},
+		{fiber.StatusUnauthorized, CodeUnauthorized},
+		{fiber.StatusNotFound, CodeEndpointNotFound},
+		{fiber.StatusMethodNotAllowed, CodeEndpointNotFound},
+		{fiber.StatusTooManyRequests, CodeRateLimited},
+		{fiber.StatusServiceUnavailable, CodeUpstreamUnavailable},
+		{fiber.StatusInternalServerError, CodeInternal},
+		{fiber.StatusTeapot, CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := CodeForStatus(tt.status); got != tt.want {
+			t.Errorf("CodeForStatus(%d) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseFields splits the comma-separated fields query parameter
+func parseFields(value string) []string {
+	if value == "" {
+		return nil
+	}
+	
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// projectFields returns an object holding only the requested top-level fields,
+// matched against the JSON names of the value. Unknown names are ignored unless
+// strict is set, in which case they are reported as an error.
+func projectFields(value interface{}, fields []string, strict bool) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("response is not an object: %w", err)
+	}
+	
+	projected := make(map[string]json.RawMessage, len(fields))
+	var unknown []string
+	for _, field := range fields {
+		if raw, ok := all[field]; ok {
+			projected[field] = raw
+		} else {
+			unknown = append(unknown, field)
+		}
+	}
+	
+	if strict && len(unknown) > 0 {
+		return nil, fmt.Errorf("Unknown fields: %s", strings.Join(unknown, ","))
+	}
+	
+	return projected, nil
+}
\ No newline at end of file
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	if got := parseFields(""); got != nil {
+		t.Errorf("parseFields(\"\") = %v, want nil", got)
+	}
+	if got, want := parseFields(" temperature, ,humidity "), []string{"temperature", "humidity"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFields = %v, want %v", got, want)
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	value := struct {
+		City        string  `json:"city"`
+		Temperature float64 `json:"temperature"`
+		Humidity    float64 `json:"humidity"`
+	}{"Prague", 20, 50}
+	
+	projected, err := projectFields(value, []string{"temperature", "wind"}, false)
+	if err != nil {
+		t.Fatalf("projectFields: %v", err)
+	}
+	if len(projected) != 1 || string(projected["temperature"]) != "20" {
+		t.Errorf("projected = %v, want temperature only", projected)
+	}
+	
+	if _, err := projectFields(value, []string{"temperature", "wind"}, true); err == nil {
+		t.Error("strict projection accepted the unknown field wind")
+	}
+}
+
+func TestGetCurrentWeatherReturnsRequestedFields(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&fields=city,temperature")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	if len(body) != 2 || body["city"] != "Prague" || body["temperature"] != 20.0 {
+		t.Errorf("body = %v, want city and temperature only", body)
+	}
+}
+
+func TestGetCurrentWeatherRejectsUnknownFieldsWhenStrict(t *testing.T) {
+	t.Setenv("STRICT_FIELDS", "true")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&fields=city,nope")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
@@ -1,112 +1,843 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 
-	"weather-aggregator/internal/services"
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/services"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"github.com/bobby-s-dev/weather-aggregator/internal/version"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
 type Handler struct {
 	aggregator *services.Aggregator
+	cfg        *config.Config
 	logger     *zap.Logger
 }
 
-func NewHandler(aggregator *services.Aggregator, logger *zap.Logger) *Handler {
+func NewHandler(aggregator *services.Aggregator, cfg *config.Config, logger *zap.Logger) *Handler {
 	return &Handler{
 		aggregator: aggregator,
+		cfg:        cfg,
 		logger:     logger,
 	}
 }
 
+// resolveCity trims the requested city and maps configured aliases, matched
+// regardless of case and whitespace like cities are, to their canonical name
+func (h *Handler) resolveCity(city string) string {
+	city = strings.TrimSpace(city)
+	if canonical, ok := h.cfg.API.CityAliases[utils.NormalizeCity(city)]; ok {
+		return canonical
+	}
+	return city
+}
+
+// requestedCity returns the resolved city query parameter, falling back to
+// the configured default city when the parameter is absent. A fallback is
+// flagged with the X-Default-City header.
+func (h *Handler) requestedCity(c *fiber.Ctx) string {
+	if city := h.resolveCity(c.Query("city")); city != "" {
+		return city
+	}
+	if h.cfg.API.DefaultCity == "" {
+		return ""
+	}
+	
+	city := h.resolveCity(h.cfg.API.DefaultCity)
+	c.Set(defaultCityHeader, city)
+	return city
+}
+
+// respond writes the weather payload, projected to the requested fields if any
+func (h *Handler) respond(c *fiber.Ctx, value interface{}) error {
+	value = outputRounder{decimals: h.cfg.API.OutputDecimals}.apply(value)
+	
+	fields := parseFields(c.Query("fields"))
+	if len(fields) == 0 {
+		return h.send(c, value, value)
+	}
+	
+	projected, err := projectFields(value, fields, h.cfg.API.StrictFields)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	return h.send(c, value, projected)
+}
+
 // GetCurrentWeather handles GET /api/v1/weather/current
 func (h *Handler) GetCurrentWeather(c *fiber.Ctx) error {
-	city := c.Query("city")
+	city := h.requestedCity(c)
 	if city == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "City parameter is required",
-		})
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	minConfidence, err := parseMinConfidence(c.Query("min_confidence"))
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	minSources, err := parseMinSources(c.Query("min_sources"), h.cfg.API.MinSources)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
 	}
 	
 	h.logger.Info("Fetching current weather", zap.String("city", city))
 	
-	weather, err := h.aggregator.GetAggregatedCurrentWeather(c.Context(), city)
+	weather, err := h.aggregator.GetAggregatedCurrentWeather(requestContext(c), city, opts)
 	if err != nil {
 		h.logger.Error("Failed to get current weather",
 			zap.String("city", city),
 			zap.Error(err))
 		
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch weather data",
-			"details": err.Error(),
+		var unavailable *services.UnavailableError
+		if errors.As(err, &unavailable) {
+			return respondUnavailable(c, unavailable)
+		}
+		if errors.Is(err, services.ErrMaintenance) {
+			return h.respondMaintenance(c)
+		}
+		if errors.Is(err, services.ErrNoEnabledProvider) {
+			return RespondError(c, fiber.StatusServiceUnavailable, CodeNoEnabledProvider, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoRequestedSource) {
+			return RespondError(c, fiber.StatusBadRequest, CodeUnknownSource, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoData) {
+			return h.respondEmpty(c, CodeCityNotFound, "No weather data available for the city")
+		}
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeUpstreamError, "Failed to fetch weather data", err.Error())
+	}
+	
+	if len(weather.Sources) < minSources {
+		return respondInsufficientSources(c, weather.Sources, minSources)
+	}
+	
+	if weather.Confidence < minConfidence {
+		return RespondError(c, fiber.StatusUnprocessableEntity, CodeConfidenceTooLow, "Aggregated confidence is below the requested threshold", fiber.Map{
+			"confidence": weather.Confidence,
+			"min_confidence": minConfidence,
 		})
 	}
 	
-	return c.JSON(weather)
+	return h.respond(c, weather)
+}
+
+// GetAllCurrentWeather handles GET /api/v1/weather/current/all
+func (h *Handler) GetAllCurrentWeather(c *fiber.Ctx) error {
+	cities := h.aggregator.GetAllCurrentWeather()
+	
+	return h.respond(c, fiber.Map{
+		"cities": cities,
+		"count":  len(cities),
+	})
 }
 
 // GetForecast handles GET /api/v1/weather/forecast
 func (h *Handler) GetForecast(c *fiber.Ctx) error {
-	city := c.Query("city")
+	city := h.requestedCity(c)
 	if city == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "City parameter is required",
-		})
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
 	}
 	
 	daysStr := c.Query("days", "3")
 	days, err := strconv.Atoi(daysStr)
-	if err != nil || days < 1 || days > 7 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Days parameter must be between 1 and 7",
-		})
+	maxDays := h.cfg.WeatherAPI.ForecastDays
+	if err != nil || days < 1 || days > maxDays {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidDays, fmt.Sprintf("Days parameter must be between 1 and %d", maxDays), nil)
 	}
 	
 	h.logger.Info("Fetching forecast",
 		zap.String("city", city),
 		zap.Int("days", days))
 	
-	forecast, err := h.aggregator.GetAggregatedForecast(c.Context(), city, days)
+	include, err := parseInclude(c.Query("include"), days)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	if value := c.Query("smooth"); value != "" {
+		smooth, err := strconv.ParseBool(value)
+		if err != nil {
+			return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, "Smooth parameter must be true or false", nil)
+		}
+		opts.Smooth = smooth
+	}
+	
+	minSources, err := parseMinSources(c.Query("min_sources"), h.cfg.API.MinSources)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	forecast, err := h.aggregator.GetAggregatedForecast(requestContext(c), city, days, opts)
 	if err != nil {
 		h.logger.Error("Failed to get forecast",
 			zap.String("city", city),
 			zap.Int("days", days),
 			zap.Error(err))
 		
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch forecast data",
-			"details": err.Error(),
-		})
+		var unavailable *services.UnavailableError
+		if errors.As(err, &unavailable) {
+			return respondUnavailable(c, unavailable)
+		}
+		if errors.Is(err, services.ErrMaintenance) {
+			return h.respondMaintenance(c)
+		}
+		if errors.Is(err, services.ErrNoEnabledProvider) {
+			return RespondError(c, fiber.StatusServiceUnavailable, CodeNoEnabledProvider, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoRequestedSource) {
+			return RespondError(c, fiber.StatusBadRequest, CodeUnknownSource, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoForecastDays) {
+			return RespondError(c, fiber.StatusBadGateway, CodeNoForecastDays, "No forecast days could be assembled from the providers' data", err.Error())
+		}
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeUpstreamError, "Failed to fetch forecast data", err.Error())
+	}
+	
+	if len(forecast.Sources) < minSources {
+		return respondInsufficientSources(c, forecast.Sources, minSources)
+	}
+	
+	if len(include) == 0 {
+		return h.respond(c, forecast)
+	}
+	
+	// Sub-horizons are sliced from the primary aggregate rather than fetched again
+	response := &models.MultiHorizonForecast{
+		City:     forecast.City,
+		Forecast: forecast,
+		Horizons: make(map[int]*models.AggregatedForecast, len(include)),
+	}
+	for _, horizon := range include {
+		response.Horizons[horizon] = services.SliceForecast(forecast, horizon)
+	}
+	
+	return h.respond(c, response)
+}
+
+// GetWeatherAt handles GET /api/v1/weather/at
+func (h *Handler) GetWeatherAt(c *fiber.Ctx) error {
+	city := h.requestedCity(c)
+	if city == "" {
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
+	}
+	
+	at, err := time.Parse(time.RFC3339, c.Query("time"))
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, "Time parameter must be an RFC 3339 timestamp", nil)
+	}
+	
+	if at.Before(time.Now()) {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, "Time parameter must be in the future", nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	minSources, err := parseMinSources(c.Query("min_sources"), h.cfg.API.MinSources)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	h.logger.Info("Fetching weather at time",
+		zap.String("city", city),
+		zap.Time("time", at))
+	
+	point, err := h.aggregator.GetWeatherAt(requestContext(c), city, at.UTC(), opts)
+	if err != nil {
+		if errors.Is(err, services.ErrTimeOutOfRange) {
+			return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, err.Error(), nil)
+		}
+		
+		var unavailable *services.UnavailableError
+		if errors.As(err, &unavailable) {
+			return respondUnavailable(c, unavailable)
+		}
+		if errors.Is(err, services.ErrMaintenance) {
+			return h.respondMaintenance(c)
+		}
+		if errors.Is(err, services.ErrNoEnabledProvider) {
+			return RespondError(c, fiber.StatusServiceUnavailable, CodeNoEnabledProvider, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoRequestedSource) {
+			return RespondError(c, fiber.StatusBadRequest, CodeUnknownSource, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoData) {
+			return h.respondEmpty(c, CodeCityNotFound, "No forecast data available for the city")
+		}
+		
+		h.logger.Error("Failed to get weather at time",
+			zap.String("city", city),
+			zap.Time("time", at),
+			zap.Error(err))
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeUpstreamError, "Failed to fetch forecast data", err.Error())
+	}
+	
+	if len(point.Sources) < minSources {
+		return respondInsufficientSources(c, point.Sources, minSources)
+	}
+	
+	return h.respond(c, point)
+}
+
+// GetBestDay handles GET /api/v1/weather/best-day
+func (h *Handler) GetBestDay(c *fiber.Ctx) error {
+	city := h.requestedCity(c)
+	if city == "" {
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
+	}
+	
+	maxDays := h.cfg.WeatherAPI.ForecastDays
+	days, err := strconv.Atoi(c.Query("days", strconv.Itoa(maxDays)))
+	if err != nil || days < 1 || days > maxDays {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidDays, fmt.Sprintf("Days parameter must be between 1 and %d", maxDays), nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	h.logger.Info("Finding best day",
+		zap.String("city", city),
+		zap.Int("days", days))
+	
+	bestDay, err := h.aggregator.GetBestDay(requestContext(c), city, days, opts)
+	if err != nil {
+		var unavailable *services.UnavailableError
+		if errors.As(err, &unavailable) {
+			return respondUnavailable(c, unavailable)
+		}
+		if errors.Is(err, services.ErrMaintenance) {
+			return h.respondMaintenance(c)
+		}
+		if errors.Is(err, services.ErrNoEnabledProvider) {
+			return RespondError(c, fiber.StatusServiceUnavailable, CodeNoEnabledProvider, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoRequestedSource) {
+			return RespondError(c, fiber.StatusBadRequest, CodeUnknownSource, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoForecastDays) {
+			return RespondError(c, fiber.StatusBadGateway, CodeNoForecastDays, "No forecast days could be assembled from the providers' data", err.Error())
+		}
+		
+		h.logger.Error("Failed to find best day",
+			zap.String("city", city),
+			zap.Int("days", days),
+			zap.Error(err))
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeUpstreamError, "Failed to fetch forecast data", err.Error())
+	}
+	
+	return h.respond(c, bestDay)
+}
+
+// GetDailySummary handles GET /api/v1/weather/daily-summary
+func (h *Handler) GetDailySummary(c *fiber.Ctx) error {
+	city := h.requestedCity(c)
+	if city == "" {
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	summary, err := h.aggregator.GetDailySummary(city)
+	if err != nil {
+		return h.respondEmpty(c, CodeCityNotFound, "No daily summary computed for the city, only tracked cities are summarized")
+	}
+	
+	return h.respond(c, services.ConvertDailySummary(summary, opts))
+}
+
+// GetSummary handles GET /api/v1/weather/summary
+func (h *Handler) GetSummary(c *fiber.Ctx) error {
+	city := h.requestedCity(c)
+	if city == "" {
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	h.logger.Info("Fetching weather summary", zap.String("city", city))
+	
+	summary, err := h.aggregator.GetSummary(requestContext(c), city, opts)
+	if err != nil {
+		var unavailable *services.UnavailableError
+		if errors.As(err, &unavailable) {
+			return respondUnavailable(c, unavailable)
+		}
+		if errors.Is(err, services.ErrMaintenance) {
+			return h.respondMaintenance(c)
+		}
+		if errors.Is(err, services.ErrNoEnabledProvider) {
+			return RespondError(c, fiber.StatusServiceUnavailable, CodeNoEnabledProvider, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoRequestedSource) {
+			return RespondError(c, fiber.StatusBadRequest, CodeUnknownSource, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoData) {
+			return h.respondEmpty(c, CodeCityNotFound, "No weather data available for the city")
+		}
+		
+		h.logger.Error("Failed to build weather summary",
+			zap.String("city", city),
+			zap.Error(err))
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeUpstreamError, "Failed to fetch weather data", err.Error())
+	}
+	
+	return h.respond(c, summary)
+}
+
+// CompareWeather handles GET /api/v1/weather/compare
+func (h *Handler) CompareWeather(c *fiber.Ctx) error {
+	var cities []string
+	for _, city := range strings.Split(c.Query("cities"), ",") {
+		if city = h.resolveCity(city); city != "" {
+			cities = append(cities, city)
+		}
+	}
+	
+	if len(cities) != 2 {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, "Cities parameter must list exactly two cities", nil)
+	}
+	// The weather is keyed by normalized city, the same city twice would
+	// collapse into a single entry
+	if utils.NormalizeCity(cities[0]) == utils.NormalizeCity(cities[1]) {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, "Cities parameter must list two different cities", nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	h.logger.Info("Comparing weather",
+		zap.String("first", cities[0]),
+		zap.String("second", cities[1]))
+	
+	comparison, err := h.aggregator.CompareCities(requestContext(c), cities[0], cities[1], opts)
+	if err != nil {
+		var unavailable *services.UnavailableError
+		if errors.As(err, &unavailable) {
+			return respondUnavailable(c, unavailable)
+		}
+		if errors.Is(err, services.ErrMaintenance) {
+			return h.respondMaintenance(c)
+		}
+		if errors.Is(err, services.ErrNoEnabledProvider) {
+			return RespondError(c, fiber.StatusServiceUnavailable, CodeNoEnabledProvider, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoRequestedSource) {
+			return RespondError(c, fiber.StatusBadRequest, CodeUnknownSource, err.Error(), nil)
+		}
+		
+		h.logger.Error("Failed to compare weather",
+			zap.Strings("cities", cities),
+			zap.Error(err))
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeUpstreamError, "Failed to fetch weather data", err.Error())
+	}
+	
+	return h.respond(c, comparison)
+}
+
+// GetTrends handles GET /api/v1/weather/trends
+func (h *Handler) GetTrends(c *fiber.Ctx) error {
+	city := h.requestedCity(c)
+	if city == "" {
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
+	}
+	
+	// Default to the last 24 hours
+	to := time.Now().UTC()
+	from := to.Add(-24 * time.Hour)
+	
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, "From parameter must be an RFC 3339 timestamp", nil)
+		}
+		from = parsed
+	}
+	
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, "To parameter must be an RFC 3339 timestamp", nil)
+		}
+		to = parsed
+	}
+	
+	if from.After(to) {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, "From must not be after to", nil)
+	}
+	
+	trend, err := h.aggregator.GetTrends(c.Context(), city, from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrHistoryDisabled) {
+			return RespondError(c, fiber.StatusNotFound, CodeHistoryDisabled, err.Error(), nil)
+		}
+		
+		h.logger.Error("Failed to query trends",
+			zap.String("city", city),
+			zap.Error(err))
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeInternal, "Failed to query historical data", err.Error())
+	}
+	
+	return h.respond(c, trend)
+}
+
+// GetHistory handles GET /api/v1/weather/history
+func (h *Handler) GetHistory(c *fiber.Ctx) error {
+	city := h.requestedCity(c)
+	if city == "" {
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
 	}
 	
-	return c.JSON(forecast)
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, "Date parameter must be a date such as 2024-01-15", nil)
+	}
+	
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !date.Before(today) {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidTime, "Date must be in the past, use the forecast endpoint for today and later", nil)
+	}
+	
+	opts, err := parseQueryOptions(c)
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, err.Error(), nil)
+	}
+	
+	history, err := h.aggregator.GetHistoricalWeather(requestContext(c), city, date, opts)
+	if err != nil {
+		h.logger.Error("Failed to get historical weather",
+			zap.String("city", city),
+			zap.Time("date", date),
+			zap.Error(err))
+		
+		if errors.Is(err, services.ErrNoHistoricalProvider) {
+			return RespondError(c, fiber.StatusNotFound, CodeNoHistoricalProvider, err.Error(), nil)
+		}
+		
+		var unavailable *services.UnavailableError
+		if errors.As(err, &unavailable) {
+			return respondUnavailable(c, unavailable)
+		}
+		if errors.Is(err, services.ErrMaintenance) {
+			return h.respondMaintenance(c)
+		}
+		if errors.Is(err, services.ErrNoEnabledProvider) {
+			return RespondError(c, fiber.StatusServiceUnavailable, CodeNoEnabledProvider, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoRequestedSource) {
+			return RespondError(c, fiber.StatusBadRequest, CodeUnknownSource, err.Error(), nil)
+		}
+		if errors.Is(err, services.ErrNoData) {
+			return h.respondEmpty(c, CodeCityNotFound, "No historical weather available for the city and date")
+		}
+		
+		return RespondError(c, fiber.StatusInternalServerError, CodeUpstreamError, "Failed to fetch historical weather", err.Error())
+	}
+	
+	return h.respond(c, history)
+}
+
+// respondUnavailable answers with a 503 and a Retry-After header in whole seconds
+func respondUnavailable(c *fiber.Ctx, err *services.UnavailableError) error {
+	seconds := int(math.Ceil(err.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return RespondError(c, fiber.StatusServiceUnavailable, CodeUpstreamUnavailable, "All weather providers are temporarily unavailable", fiber.Map{
+		"retry_after": seconds,
+	})
+}
+
+// respondMaintenance answers a cache miss during maintenance mode
+func (h *Handler) respondMaintenance(c *fiber.Ctx) error {
+	return h.respondEmpty(c, CodeNotCached, "No cached data available while the service is in maintenance mode")
+}
+
+// respondEmpty answers a request for a city without data, with a 404 or a
+// null body depending on EMPTY_RESULT_STATUS. Like an error the null body is
+// never cached, the data may well exist on the next request.
+func (h *Handler) respondEmpty(c *fiber.Ctx, code, message string) error {
+	if h.cfg.API.EmptyResultStatus == fiber.StatusOK {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(nil)
+	}
+	return RespondError(c, fiber.StatusNotFound, code, message, nil)
+}
+
+// parseInclude parses the comma-separated include parameter, which lists
+// additional horizons that must not exceed the primary days value
+func parseInclude(value string, days int) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	
+	var horizons []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		horizon, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || horizon < 1 || horizon > days {
+			return nil, fmt.Errorf("Include values must be between 1 and %d", days)
+		}
+		if !seen[horizon] {
+			seen[horizon] = true
+			horizons = append(horizons, horizon)
+		}
+	}
+	
+	return horizons, nil
 }
 
 // GetHealth handles GET /api/v1/health
 func (h *Handler) GetHealth(c *fiber.Ctx) error {
 	lastFetch := h.aggregator.GetLastFetchTime()
+	lastSuccess := h.aggregator.GetLastSuccessTime()
 	stats := h.aggregator.GetStats()
 	
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"status":    "healthy",
 		"timestamp": time.Now(),
 		"last_fetch": lastFetch,
+		"last_successful_fetch": lastSuccess,
+		"stale_threshold": h.cfg.Scheduler.StaleThreshold.String(),
+		"maintenance": h.aggregator.InMaintenance(),
 		"uptime":    time.Since(startTime).String(),
 		"stats":     stats,
-	})
+	}
+	
+	// Before the first success the service only counts as stale once it has
+	// been up longer than the threshold
+	sinceSuccess := time.Since(startTime)
+	if !lastSuccess.IsZero() {
+		sinceSuccess = time.Since(lastSuccess)
+		response["seconds_since_last_fetch"] = int64(sinceSuccess.Seconds())
+	}
+	if sinceSuccess > h.cfg.Scheduler.StaleThreshold {
+		response["status"] = "degraded"
+	}
+	
+	return h.respond(c, response)
+}
+
+// GetProviderHealth handles GET /api/v1/health/providers
+func (h *Handler) GetProviderHealth(c *fiber.Ctx) error {
+	results, checkedAt, cached, err := h.aggregator.CheckProviders(c.Context(), h.cfg.API.ProviderHealthInterval)
+	if errors.Is(err, services.ErrMaintenance) {
+		return RespondError(c, fiber.StatusConflict, CodeMaintenance, "Providers are not contacted while the service is in maintenance mode", nil)
+	}
+	if err != nil {
+		return RespondError(c, fiber.StatusInternalServerError, CodeInternal, err.Error(), nil)
+	}
+	
+	up := 0
+	for _, result := range results {
+		if result.Status == models.ProviderUp {
+			up++
+		}
+	}
+	
+	status := "up"
+	if up < len(results) {
+		status = "degraded"
+	}
+	if up == 0 {
+		status = "down"
+	}
+	
+	payload := fiber.Map{
+		"status":     status,
+		"providers":  results,
+		"checked_at": checkedAt,
+		"cached":     cached,
+	}
+	if up == 0 {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+	return h.respond(c, payload)
+}
+
+// GetVersion handles GET /api/v1/version
+func (h *Handler) GetVersion(c *fiber.Ctx) error {
+	return h.respond(c, version.Get())
 }
 
 // GetMetrics handles GET /api/v1/metrics
 func (h *Handler) GetMetrics(c *fiber.Ctx) error {
 	stats := h.aggregator.GetStats()
 	
-	return c.JSON(fiber.Map{
+	return h.respond(c, fiber.Map{
 		"metrics": stats,
 		"timestamp": time.Now(),
 	})
 }
 
+// GetProviders handles GET /api/v1/providers
+func (h *Handler) GetProviders(c *fiber.Ctx) error {
+	return h.respond(c, fiber.Map{
+		"providers": h.aggregator.GetProviders(),
+	})
+}
+
+// GetProviderUsage handles GET /api/v1/providers/usage
+func (h *Handler) GetProviderUsage(c *fiber.Ctx) error {
+	return h.respond(c, fiber.Map{
+		"providers": h.aggregator.GetProviderUsage(),
+		"timestamp": time.Now(),
+	})
+}
+
+// EnableProvider handles POST /api/v1/providers/:name/enable
+func (h *Handler) EnableProvider(c *fiber.Ctx) error {
+	return h.setProviderEnabled(c, true)
+}
+
+// DisableProvider handles POST /api/v1/providers/:name/disable
+func (h *Handler) DisableProvider(c *fiber.Ctx) error {
+	return h.setProviderEnabled(c, false)
+}
+
+func (h *Handler) setProviderEnabled(c *fiber.Ctx, enabled bool) error {
+	// Fiber reuses the buffer behind params once the handler returns, the
+	// aggregator keeps the name
+	name := strings.Clone(c.Params("name"))
+	
+	if err := h.aggregator.SetProviderEnabled(name, enabled); err != nil {
+		return RespondError(c, fiber.StatusNotFound, CodeProviderNotFound, err.Error(), nil)
+	}
+	
+	return h.respond(c, fiber.Map{
+		"provider": name,
+		"enabled":  enabled,
+	})
+}
+
+// GetRawResponses handles GET /api/v1/admin/raw
+func (h *Handler) GetRawResponses(c *fiber.Ctx) error {
+	city := h.resolveCity(c.Query("city"))
+	if city == "" {
+		return RespondError(c, fiber.StatusBadRequest, CodeCityRequired, "City parameter is required", nil)
+	}
+	
+	responses, err := h.aggregator.GetRawResponses(c.Context(), city)
+	if err != nil {
+		if errors.Is(err, services.ErrMaintenance) {
+			return RespondError(c, fiber.StatusConflict, CodeMaintenance, err.Error(), nil)
+		}
+		return RespondError(c, fiber.StatusServiceUnavailable, CodeUpstreamUnavailable, err.Error(), nil)
+	}
+	
+	return h.respond(c, fiber.Map{
+		"city":    city,
+		"sources": responses,
+	})
+}
+
+// GetCacheDump handles GET /api/v1/admin/cache
+func (h *Handler) GetCacheDump(c *fiber.Ctx) error {
+	entries := h.aggregator.DumpCache()
+	
+	return h.respond(c, fiber.Map{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// SetMaintenance handles POST /api/v1/admin/maintenance
+func (h *Handler) SetMaintenance(c *fiber.Ctx) error {
+	enabled, err := strconv.ParseBool(c.Query("enabled"))
+	if err != nil {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, "Enabled parameter must be true or false", nil)
+	}
+	
+	h.aggregator.SetMaintenance(enabled)
+	
+	return h.respond(c, fiber.Map{
+		"maintenance": enabled,
+	})
+}
+
+// Bounds of the geocode limit parameter
+const (
+	defaultGeocodeLimit = 10
+	maxGeocodeLimit     = 50
+)
+
+// Geocode handles GET /api/v1/geocode
+func (h *Handler) Geocode(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < 2 {
+		return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, "Q parameter must be at least 2 characters", nil)
+	}
+	
+	limit := defaultGeocodeLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxGeocodeLimit {
+			return RespondError(c, fiber.StatusBadRequest, CodeInvalidParameter, fmt.Sprintf("Limit parameter must be between 1 and %d", maxGeocodeLimit), nil)
+		}
+		limit = parsed
+	}
+	
+	matches, err := h.aggregator.SearchPlaces(c.Context(), query, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrMaintenance) {
+			return RespondError(c, fiber.StatusConflict, CodeMaintenance, err.Error(), nil)
+		}
+		
+		h.logger.Error("Failed to search places",
+			zap.String("query", query),
+			zap.Error(err))
+		
+		return RespondError(c, fiber.StatusBadGateway, CodeUpstreamError, "Failed to search places", err.Error())
+	}
+	
+	return h.respond(c, fiber.Map{
+		"query":   query,
+		"results": matches,
+		"count":   len(matches),
+	})
+}
+
 // GetCities handles GET /api/v1/cities
 func (h *Handler) GetCities(c *fiber.Ctx) error {
 	// This would typically come from configuration
@@ -119,7 +850,7 @@ func (h *Handler) GetCities(c *fiber.Ctx) error {
 		"Sydney",
 	}
 	
-	return c.JSON(fiber.Map{
+	return h.respond(c, fiber.Map{
 		"cities": cities,
 	})
 }
@@ -0,0 +1,1041 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/services"
+	"github.com/bobby-s-dev/weather-aggregator/internal/version"
+	"github.com/bobby-s-dev/weather-aggregator/pkg/client"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// fakeClient is a WeatherClient answering with fixed readings for any city
+type fakeClient struct {
+	name     string
+	current  *models.CurrentWeather
+	forecast *models.WeatherForecast
+	err      error // returned by every call when set
+	retryAfter time.Duration // reported by BreakerRetryAfter, an open breaker when set
+	cities   map[string]*models.CurrentWeather // per-city readings in place of current, other cities fail when set
+	calls    atomic.Int32 // current weather and forecast requests received
+}
+
+func (c *fakeClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	c.calls.Add(1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.cities != nil {
+		weather, ok := c.cities[city]
+		if !ok {
+			return nil, errors.New("unknown city")
+		}
+		current := *weather
+		return &current, nil
+	}
+	if c.current == nil {
+		return nil, errors.New("no current weather")
+	}
+	current := *c.current
+	return &current, nil
+}
+
+func (c *fakeClient) GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error) {
+	c.calls.Add(1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.forecast == nil {
+		return nil, errors.New("no forecast")
+	}
+	forecast := *c.forecast
+	if len(forecast.Forecast) > days {
+		forecast.Forecast = forecast.Forecast[:days]
+	}
+	return &forecast, nil
+}
+
+func (c *fakeClient) Name() string                      { return c.name }
+func (c *fakeClient) RequiresAPIKey() bool              { return false }
+func (c *fakeClient) BreakerState() string              { return "closed" }
+func (c *fakeClient) BreakerRetryAfter() time.Duration  { return c.retryAfter }
+func (c *fakeClient) Usage() (hour, day models.UsageWindow) { return }
+
+// newFakeClient returns a client reporting temperature now and a week of
+// forecast days starting today
+func newFakeClient(name string, temperature float64) *fakeClient {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	days := make([]models.ForecastDay, 7)
+	for i := range days {
+		days[i] = models.ForecastDay{
+			Date:        today.AddDate(0, 0, i),
+			MaxTemp:     temperature + 5,
+			MinTemp:     temperature - 5,
+			AvgTemp:     temperature,
+			Humidity:    50,
+			Condition:   models.ConditionClear,
+			Description: "clear sky",
+		}
+	}
+	
+	return &fakeClient{
+		name: name,
+		current: &models.CurrentWeather{
+			Temperature: temperature,
+			Humidity:    50,
+			Pressure:    1013,
+			WindSpeed:   3,
+			Condition:   models.ConditionClear,
+			Description: "clear sky",
+			Timestamp:   time.Now(),
+			Source:      name,
+		},
+		forecast: &models.WeatherForecast{Forecast: days, Source: name},
+	}
+}
+
+type testServer struct {
+	app        *fiber.App
+	aggregator *services.Aggregator
+}
+
+// newTestServer serves the API over clients with the configuration in the
+// environment, set the overrides with t.Setenv before calling it
+func newTestServer(t *testing.T, clients ...services.WeatherClient) *testServer {
+	t.Helper()
+	
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	aggregator, err := services.NewAggregatorWithClients(cfg, clients, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAggregatorWithClients: %v", err)
+	}
+	t.Cleanup(func() {
+		aggregator.Close(context.Background())
+	})
+	
+	app := fiber.New()
+	SetupRoutes(app, NewHandler(aggregator, cfg, zap.NewNop()), zap.NewNop())
+	return &testServer{app: app, aggregator: aggregator}
+}
+
+// do sends the request and decodes the JSON response body into a generic value
+func (s *testServer) do(t *testing.T, req *http.Request) (*http.Response, interface{}) {
+	t.Helper()
+	
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+	
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading the response body: %v", err)
+	}
+	var body interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Fatalf("%s %s: response is not JSON: %s", req.Method, req.URL, data)
+		}
+	}
+	return resp, body
+}
+
+func (s *testServer) get(t *testing.T, target string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	
+	resp, body := s.do(t, httptest.NewRequest(http.MethodGet, target, nil))
+	object, _ := body.(map[string]interface{})
+	return resp, object
+}
+
+// errorCode returns the code of an error response body
+func errorCode(body map[string]interface{}) string {
+	errorBody, _ := body["error"].(map[string]interface{})
+	code, _ := errorBody["code"].(string)
+	return code
+}
+
+func TestGetForecastIncludesHorizons(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/forecast?city=Prague&days=5&include=1,3,3")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	
+	forecast, _ := body["forecast"].(map[string]interface{})
+	if days, _ := forecast["days"].([]interface{}); len(days) != 5 {
+		t.Errorf("primary forecast has %d days, want 5", len(days))
+	}
+	horizons, _ := body["horizons"].(map[string]interface{})
+	if len(horizons) != 2 {
+		t.Fatalf("horizons = %v, want 1 and 3", horizons)
+	}
+	for horizon, want := range map[string]int{"1": 1, "3": 3} {
+		sliced, _ := horizons[horizon].(map[string]interface{})
+		if days, _ := sliced["days"].([]interface{}); len(days) != want {
+			t.Errorf("horizon %s has %d days, want %d", horizon, len(days), want)
+		}
+	}
+}
+
+func TestGetForecastRejectsIncludeBeyondDays(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	for _, include := range []string{"4", "0", "x"} {
+		resp, body := server.get(t, "/api/v1/weather/forecast?city=Prague&days=3&include="+include)
+		if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+			t.Errorf("include=%s: status %d code %q, want 400 %s", include, resp.StatusCode, errorCode(body), CodeInvalidParameter)
+		}
+	}
+}
+
+func TestGetProvidersReportsStatusAndOutcomes(t *testing.T) {
+	failing := newFakeClient("b", 20)
+	failing.err = errors.New("provider down")
+	server := newTestServer(t, newFakeClient("a", 20), failing)
+	server.get(t, "/api/v1/weather/current?city=Prague")
+	
+	resp, body := server.get(t, "/api/v1/providers")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	providers, _ := body["providers"].([]interface{})
+	if len(providers) != 2 {
+		t.Fatalf("providers = %v, want a and b", providers)
+	}
+	
+	want := map[string]float64{"a": 1, "b": 0}
+	for _, entry := range providers {
+		provider := entry.(map[string]interface{})
+		name, _ := provider["name"].(string)
+		if provider["enabled"] != true || provider["breaker_state"] != "closed" {
+			t.Errorf("provider %s = %v, want enabled with a closed breaker", name, provider)
+		}
+		if provider["success_rate"] != want[name] {
+			t.Errorf("provider %s success_rate = %v, want %v", name, provider["success_rate"], want[name])
+		}
+	}
+}
+
+func TestAllBreakersOpenRespondsWithRetryAfter(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.retryAfter = 2500 * time.Millisecond
+	server := newTestServer(t, source)
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague")
+	if resp.StatusCode != http.StatusServiceUnavailable || errorCode(body) != CodeUpstreamUnavailable {
+		t.Fatalf("status %d code %q, want 503 %s", resp.StatusCode, errorCode(body), CodeUpstreamUnavailable)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "3" {
+		t.Errorf("Retry-After = %q, want 2.5s rounded up to 3", retryAfter)
+	}
+}
+
+func TestGetWeatherAtInterpolatesWithinHorizon(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	start := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	source.forecast.Hourly = []models.HourlyPoint{
+		{Time: start, Temperature: 10, Condition: models.ConditionClear},
+		{Time: start.Add(time.Hour), Temperature: 20, Condition: models.ConditionClear},
+	}
+	server := newTestServer(t, source)
+	
+	at := start.Add(30 * time.Minute).Format(time.RFC3339)
+	resp, body := server.get(t, "/api/v1/weather/at?city=Prague&time="+at)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	if body["temperature"] != 15.0 {
+		t.Errorf("temperature = %v, want 15 halfway between the hours", body["temperature"])
+	}
+	
+	for name, at := range map[string]time.Time{
+		"past":           time.Now().Add(-time.Hour),
+		"beyond horizon": start.Add(2 * time.Hour),
+	} {
+		resp, body := server.get(t, "/api/v1/weather/at?city=Prague&time="+at.UTC().Format(time.RFC3339))
+		if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidTime {
+			t.Errorf("%s: status %d code %q, want 400 %s", name, resp.StatusCode, errorCode(body), CodeInvalidTime)
+		}
+	}
+}
+
+func TestMinConfidenceGatesCurrentWeather(t *testing.T) {
+	// a single source scores 0.5
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&min_confidence=0.7")
+	if resp.StatusCode != http.StatusUnprocessableEntity || errorCode(body) != CodeConfidenceTooLow {
+		t.Fatalf("below threshold: status %d code %q, want 422 %s", resp.StatusCode, errorCode(body), CodeConfidenceTooLow)
+	}
+	details, _ := body["error"].(map[string]interface{})["details"].(map[string]interface{})
+	if details["confidence"] != 0.5 || details["min_confidence"] != 0.7 {
+		t.Errorf("details = %v, want confidence 0.5 and min_confidence 0.7", details)
+	}
+	
+	for _, query := range []string{"&min_confidence=0.4", ""} {
+		resp, body := server.get(t, "/api/v1/weather/current?city=Prague"+query)
+		if resp.StatusCode != http.StatusOK || body["confidence"] != 0.5 {
+			t.Errorf("%q: status %d confidence %v, want 200 with 0.5", query, resp.StatusCode, body["confidence"])
+		}
+	}
+	
+	resp, body = server.get(t, "/api/v1/weather/current?city=Prague&min_confidence=1.5")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("out of range: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestMaxAgeRefreshesAgedCacheEntry(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.current.Timestamp = time.Now().Add(-10 * time.Minute)
+	server := newTestServer(t, source)
+	
+	server.get(t, "/api/v1/weather/current?city=Prague")
+	calls := source.calls.Load()
+	
+	if resp, _ := server.get(t, "/api/v1/weather/current?city=Prague&max_age=1h"); resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if source.calls.Load() != calls {
+		t.Errorf("provider called again for an entry updated within max_age")
+	}
+	
+	source.current.Timestamp = time.Now()
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&max_age=5m")
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if source.calls.Load() == calls {
+		t.Errorf("entry last updated 10 minutes ago served for max_age=5m, want a refresh")
+	}
+	if lastUpdated, _ := time.Parse(time.RFC3339, body["last_updated"].(string)); time.Since(lastUpdated) > time.Minute {
+		t.Errorf("last_updated = %v, want the refreshed reading", body["last_updated"])
+	}
+}
+
+func TestGetTrendsReturnsStoredSnapshots(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		server := newTestServer(t, newFakeClient("fake", 20))
+		
+		resp, body := server.get(t, "/api/v1/weather/trends?city=Prague")
+		if resp.StatusCode != http.StatusNotFound || errorCode(body) != CodeHistoryDisabled {
+			t.Errorf("status %d code %q, want 404 %s", resp.StatusCode, errorCode(body), CodeHistoryDisabled)
+		}
+	})
+	
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv("HISTORY_ENABLED", "true")
+		t.Setenv("HISTORY_DB_PATH", filepath.Join(t.TempDir(), "history.db"))
+		server := newTestServer(t, newFakeClient("fake", 20))
+		
+		server.get(t, "/api/v1/weather/current?city=Prague")
+		resp, body := server.get(t, "/api/v1/weather/trends?city=Prague")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+		}
+		points, _ := body["points"].([]interface{})
+		if len(points) != 1 {
+			t.Fatalf("points = %v, want the one snapshot stored by the fetch", body["points"])
+		}
+		if point, _ := points[0].(map[string]interface{}); point["temperature"] != 20.0 {
+			t.Errorf("snapshot = %v, want 20°", point)
+		}
+		
+		resp, body = server.get(t, "/api/v1/weather/trends?city=Prague&from=2024-06-02T00:00:00Z&to=2024-06-01T00:00:00Z")
+		if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidTime {
+			t.Errorf("reversed range: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidTime)
+		}
+	})
+}
+
+func TestCompareWeather(t *testing.T) {
+	source := newFakeClient("fake", 0)
+	source.cities = map[string]*models.CurrentWeather{
+		"Prague": {Temperature: 24.5, Humidity: 40, Condition: models.ConditionClear, Timestamp: time.Now(), Source: "fake"},
+		"London": {Temperature: 16, Humidity: 75, Condition: models.ConditionRain, Timestamp: time.Now(), Source: "fake"},
+	}
+	server := newTestServer(t, source)
+	
+	resp, body := server.get(t, "/api/v1/weather/compare?cities=London,Prague")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	diff, _ := body["diff"].(map[string]interface{})
+	if diff["temperature_delta"] != -8.5 || diff["humidity_delta"] != 35.0 || diff["warmer"] != "Prague" {
+		t.Errorf("diff = %v, want London 8.5° colder and 35%% more humid than Prague", diff)
+	}
+	
+	// Atlantis fails at the provider, Prague is still reported
+	resp, body = server.get(t, "/api/v1/weather/compare?cities=Prague,Atlantis")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("partial failure: status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	weather, _ := body["weather"].(map[string]interface{})
+	errs, _ := body["errors"].(map[string]interface{})
+	if weather["Prague"] == nil || errs["Atlantis"] == nil || body["diff"] != nil {
+		t.Errorf("comparison = %v, want Prague's weather, an error for Atlantis and no diff", body)
+	}
+	
+	resp, body = server.get(t, "/api/v1/weather/compare?cities=Prague")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("one city: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestGetSummary(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/summary?city=Prague&lang=fr")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	want := "Clear sky, 20°C, brise légère; demain clear sky, 15 à 25°C, pas de précipitations prévues."
+	if body["summary"] != want || body["lang"] != "fr" {
+		t.Errorf("summary = %v in %v, want %q in fr", body["summary"], body["lang"], want)
+	}
+}
+
+func TestToggleProvider(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	server := newTestServer(t, newFakeClient("a", 20), newFakeClient("b", 20))
+	
+	post := func(target, token string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if token != "" {
+			req.Header.Set(adminTokenHeader, token)
+		}
+		resp, body := server.do(t, req)
+		object, _ := body.(map[string]interface{})
+		return resp, object
+	}
+	
+	if resp, _ := post("/api/v1/providers/b/disable", "wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", resp.StatusCode)
+	}
+	if resp, body := post("/api/v1/providers/c/disable", "secret"); resp.StatusCode != http.StatusNotFound || errorCode(body) != CodeProviderNotFound {
+		t.Errorf("unknown provider: status %d code %q, want 404 %s", resp.StatusCode, errorCode(body), CodeProviderNotFound)
+	}
+	if resp, body := post("/api/v1/providers/b/disable", "secret"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("disable: status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	
+	enabled := func() map[string]interface{} {
+		_, body := server.get(t, "/api/v1/providers")
+		providers, _ := body["providers"].([]interface{})
+		states := make(map[string]interface{})
+		for _, entry := range providers {
+			provider := entry.(map[string]interface{})
+			states[provider["name"].(string)] = provider["enabled"]
+		}
+		return states
+	}
+	if states := enabled(); states["a"] != true || states["b"] != false {
+		t.Errorf("enabled = %v after disabling b, want a only", states)
+	}
+	
+	_, body := server.get(t, "/api/v1/weather/current?city=Tokyo")
+	if sources, _ := body["sources"].([]interface{}); len(sources) != 1 || sources[0] != "a" {
+		t.Errorf("sources = %v with b disabled, want [a]", body["sources"])
+	}
+	
+	post("/api/v1/providers/b/enable", "secret")
+	if states := enabled(); states["b"] != true {
+		t.Errorf("enabled = %v after enabling b again, want b enabled", states)
+	}
+}
+
+func TestCityAliasesResolveToCanonicalCity(t *testing.T) {
+	t.Setenv("CITY_ALIASES", "NYC=NewYork,Big Apple=NewYork")
+	// only the canonical name is known to the provider
+	source := newFakeClient("fake", 0)
+	source.cities = map[string]*models.CurrentWeather{
+		"NewYork": {Temperature: 12, Humidity: 60, Condition: models.ConditionClear, Timestamp: time.Now(), Source: "fake"},
+	}
+	server := newTestServer(t, source)
+	
+	for _, city := range []string{"NYC", "nyc", "big%20%20apple", "NewYork"} {
+		resp, body := server.get(t, "/api/v1/weather/current?city="+city)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("city=%s: status = %d, want 200: %v", city, resp.StatusCode, body)
+			continue
+		}
+		if body["city"] != "NewYork" || body["temperature"] != 12.0 {
+			t.Errorf("city=%s: %v at %v°, want NewYork at 12°", city, body["city"], body["temperature"])
+		}
+	}
+}
+
+func TestGetAllCurrentWeatherServesTrackedCitiesFromCache(t *testing.T) {
+	t.Setenv("CACHE_DURATION", "100ms")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	server.aggregator.SetTrackedCities([]string{"Prague", "London", "Tokyo"})
+	
+	server.get(t, "/api/v1/weather/current?city=Prague")
+	time.Sleep(150 * time.Millisecond)
+	server.get(t, "/api/v1/weather/current?city=London")
+	
+	resp, body := server.get(t, "/api/v1/weather/current/all")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	cities, _ := body["cities"].([]interface{})
+	if len(cities) != 3 || body["count"] != 3.0 {
+		t.Fatalf("cities = %v, want the 3 tracked ones", body["cities"])
+	}
+	
+	entries := make(map[string]map[string]interface{})
+	for _, entry := range cities {
+		city := entry.(map[string]interface{})
+		entries[city["city"].(string)] = city
+	}
+	if prague := entries["Prague"]; prague["weather"] == nil || prague["stale"] != true {
+		t.Errorf("Prague = %v, want its expired reading marked stale", prague)
+	}
+	if london := entries["London"]; london["weather"] == nil || london["stale"] != false {
+		t.Errorf("London = %v, want its fresh reading", london)
+	}
+	// served from the cache only, Tokyo is not fetched on demand
+	if tokyo := entries["Tokyo"]; tokyo["weather"] != nil || tokyo["error"] == nil {
+		t.Errorf("Tokyo = %v, want an error and no weather", tokyo)
+	}
+}
+
+// upstreamClient is a fakeClient that first fetches its current weather from
+// an HTTP upstream, so the response goes through BaseClient like a provider's
+type upstreamClient struct {
+	*fakeClient
+	base     *client.BaseClient
+	upstream string
+}
+
+func (c *upstreamClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	if _, err := c.base.GetWithRetry(ctx, c.upstream); err != nil {
+		return nil, err
+	}
+	return c.fakeClient.GetCurrentWeather(ctx, city, opts)
+}
+
+func (c *upstreamClient) Usage() (hour, day models.UsageWindow) { return c.base.Usage() }
+
+func TestGetRawResponses(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"main":{"temp":21.5}}`))
+	}))
+	t.Cleanup(upstream.Close)
+	
+	source := &upstreamClient{
+		fakeClient: newFakeClient("upstream", 21.5),
+		base:       client.NewBaseClient("upstream", client.ClientConfig{Timeout: time.Second}, zap.NewNop()),
+		upstream:   upstream.URL + "/weather?appid=key",
+	}
+	server := newTestServer(t, source)
+	
+	get := func(token string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/raw?city=London", nil)
+		if token != "" {
+			req.Header.Set(adminTokenHeader, token)
+		}
+		resp, body := server.do(t, req)
+		object, _ := body.(map[string]interface{})
+		return resp, object
+	}
+	
+	if resp, _ := get(""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want 401", resp.StatusCode)
+	}
+	
+	resp, body := get("secret")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	sources, _ := body["sources"].(map[string]interface{})
+	result, _ := sources["upstream"].(map[string]interface{})
+	responses, _ := result["responses"].([]interface{})
+	if len(responses) != 1 {
+		t.Fatalf("responses = %v, want the one upstream response", result)
+	}
+	
+	response := responses[0].(map[string]interface{})
+	raw, _ := json.Marshal(response["body"])
+	if string(raw) != `{"main":{"temp":21.5}}` {
+		t.Errorf("body = %s, want the upstream body untouched", raw)
+	}
+	if url, _ := response["url"].(string); strings.Contains(url, "appid=key") {
+		t.Errorf("url = %q, want the API key redacted", url)
+	}
+}
+
+func TestMaintenanceServesOnlyCachedData(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	source := newFakeClient("fake", 20)
+	server := newTestServer(t, source)
+	
+	setMaintenance := func(enabled string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/maintenance?enabled="+enabled, nil)
+		req.Header.Set(adminTokenHeader, "secret")
+		if resp, body := server.do(t, req); resp.StatusCode != http.StatusOK {
+			t.Fatalf("maintenance=%s: status = %d, want 200: %v", enabled, resp.StatusCode, body)
+		}
+	}
+	
+	if resp, body := server.get(t, "/api/v1/weather/current?city=London"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("warming the cache: status = %d: %v", resp.StatusCode, body)
+	}
+	setMaintenance("true")
+	source.calls.Store(0)
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=London")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(maintenanceHeader) != "true" {
+		t.Errorf("cached city: status %d %s %q, want 200 and true", resp.StatusCode, maintenanceHeader, resp.Header.Get(maintenanceHeader))
+	}
+	resp, body = server.get(t, "/api/v1/weather/current?city=Tokyo")
+	if resp.StatusCode != http.StatusNotFound || errorCode(body) != CodeNotCached || resp.Header.Get(maintenanceHeader) != "true" {
+		t.Errorf("uncached city: status %d code %q %s %q, want 404 %s and true", resp.StatusCode, errorCode(body), maintenanceHeader, resp.Header.Get(maintenanceHeader), CodeNotCached)
+	}
+	if calls := source.calls.Load(); calls != 0 {
+		t.Errorf("provider called %d times in maintenance mode, want 0", calls)
+	}
+	
+	setMaintenance("false")
+	resp, _ = server.get(t, "/api/v1/weather/current?city=Tokyo")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(maintenanceHeader) != "" {
+		t.Errorf("after maintenance: status %d %s %q, want 200 without the header", resp.StatusCode, maintenanceHeader, resp.Header.Get(maintenanceHeader))
+	}
+	if source.calls.Load() == 0 {
+		t.Error("provider not called after leaving maintenance mode")
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	_, body := server.get(t, "/api/v1/version")
+	want := map[string]interface{}{
+		"version":    "dev",
+		"commit":     "unknown",
+		"build_time": "unknown",
+		"go_version": runtime.Version(),
+	}
+	for field, value := range want {
+		if body[field] != value {
+			t.Errorf("%s = %v, want %v when not injected", field, body[field], value)
+		}
+	}
+	
+	defer func(previous string) { version.Commit = previous }(version.Commit)
+	version.Commit = "abc1234"
+	if _, body := server.get(t, "/api/v1/version"); body["commit"] != "abc1234" {
+		t.Errorf("commit = %v, want the injected abc1234", body["commit"])
+	}
+}
+
+func TestSourceTimestampsParameter(t *testing.T) {
+	observed := map[string]time.Time{
+		"a": time.Date(2026, 10, 15, 11, 50, 0, 0, time.UTC),
+		"b": time.Date(2026, 10, 15, 11, 58, 30, 0, time.UTC),
+	}
+	var clients []services.WeatherClient
+	for name, at := range observed {
+		source := newFakeClient(name, 20)
+		source.current.Timestamp = at
+		clients = append(clients, source)
+	}
+	server := newTestServer(t, clients...)
+	
+	_, body := server.get(t, "/api/v1/weather/current?city=Prague")
+	if _, ok := body["source_timestamps"]; ok {
+		t.Errorf("source_timestamps = %v without timestamps=true, want it omitted", body["source_timestamps"])
+	}
+	
+	_, body = server.get(t, "/api/v1/weather/current?city=Prague&timestamps=true")
+	timestamps, _ := body["source_timestamps"].(map[string]interface{})
+	if len(timestamps) != len(observed) {
+		t.Fatalf("source_timestamps = %v, want one per source", body["source_timestamps"])
+	}
+	for source, want := range observed {
+		value, _ := timestamps[source].(string)
+		if got, err := time.Parse(time.RFC3339, value); err != nil || !got.Equal(want) {
+			t.Errorf("source_timestamps[%s] = %q, want %v", source, value, want)
+		}
+	}
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&timestamps=yes")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("timestamps=yes: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestForecastWithoutDaysIsBadGateway(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Source: "fake"}
+	server := newTestServer(t, source)
+	
+	resp, body := server.get(t, "/api/v1/weather/forecast?city=Prague&days=3")
+	if resp.StatusCode != http.StatusBadGateway || errorCode(body) != CodeNoForecastDays {
+		t.Errorf("forecast: status %d code %q, want 502 %s", resp.StatusCode, errorCode(body), CodeNoForecastDays)
+	}
+	resp, body = server.get(t, "/api/v1/weather/best-day?city=Prague&days=3")
+	if resp.StatusCode != http.StatusBadGateway || errorCode(body) != CodeNoForecastDays {
+		t.Errorf("best day: status %d code %q, want 502 %s", resp.StatusCode, errorCode(body), CodeNoForecastDays)
+	}
+	
+	// Fewer days than requested is a partial forecast, not an error
+	source.forecast.Forecast = newFakeClient("fake", 20).forecast.Forecast[:2]
+	server.aggregator.InvalidateCity("Prague")
+	resp, body = server.get(t, "/api/v1/weather/forecast?city=Prague&days=3")
+	if days, _ := body["days"].([]interface{}); resp.StatusCode != http.StatusOK || len(days) != 2 {
+		t.Errorf("partial forecast: status %d with %d days, want 200 with 2", resp.StatusCode, len(days))
+	}
+}
+
+func TestGetProviderHealth(t *testing.T) {
+	up := newFakeClient("up", 20)
+	down := newFakeClient("down", 20)
+	down.err = errors.New("connection refused")
+	open := newFakeClient("open", 20)
+	open.retryAfter = time.Minute
+	server := newTestServer(t, up, down, open)
+	
+	resp, body := server.get(t, "/api/v1/health/providers")
+	if resp.StatusCode != http.StatusOK || body["status"] != "degraded" || body["cached"] != false {
+		t.Fatalf("status %d body %v, want 200 degraded and freshly checked", resp.StatusCode, body)
+	}
+	
+	statuses := make(map[string]string)
+	providers, _ := body["providers"].([]interface{})
+	for _, entry := range providers {
+		provider := entry.(map[string]interface{})
+		statuses[provider["name"].(string)] = provider["status"].(string)
+		if provider["name"] == "down" && provider["error"] != "connection refused" {
+			t.Errorf("down provider error = %v, want the probe's error", provider["error"])
+		}
+	}
+	want := map[string]string{"up": "up", "down": "down", "open": "down"}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("%s is %q, want %q", name, statuses[name], status)
+		}
+	}
+	if open.calls.Load() != 0 {
+		t.Errorf("provider with an open breaker probed %d times, want 0", open.calls.Load())
+	}
+	
+	// Within the minimum interval the earlier results are served
+	_, body = server.get(t, "/api/v1/health/providers")
+	if body["cached"] != true || up.calls.Load() != 1 {
+		t.Errorf("second check cached=%v after %d probes, want the first check reused", body["cached"], up.calls.Load())
+	}
+}
+
+func TestGetProviderHealthAllDown(t *testing.T) {
+	down := newFakeClient("down", 20)
+	down.err = errors.New("connection refused")
+	server := newTestServer(t, down)
+	
+	resp, body := server.get(t, "/api/v1/health/providers")
+	if resp.StatusCode != http.StatusServiceUnavailable || body["status"] != "down" {
+		t.Errorf("status %d %v, want 503 down", resp.StatusCode, body["status"])
+	}
+	
+	// The 503 goes through the same field projection and envelope as a 200
+	resp, body = server.get(t, "/api/v1/health/providers?fields=status")
+	if resp.StatusCode != http.StatusServiceUnavailable || body["status"] != "down" || body["providers"] != nil {
+		t.Errorf("projected status %d %v, want 503 with status alone", resp.StatusCode, body)
+	}
+	resp, body = server.get(t, "/api/v1/health/providers?envelope=true")
+	if data, _ := body["data"].(map[string]interface{}); resp.StatusCode != http.StatusServiceUnavailable || data["status"] != "down" {
+		t.Errorf("enveloped status %d %v, want 503 with the payload under data", resp.StatusCode, body)
+	}
+}
+
+func TestHealthDegradesPastStaleThreshold(t *testing.T) {
+	t.Setenv("STALE_THRESHOLD", "100ms")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	// The process has been up longer than the threshold without a success
+	time.Sleep(100 * time.Millisecond)
+	_, body := server.get(t, "/api/v1/health")
+	if body["status"] != "degraded" {
+		t.Errorf("status before any fetch = %v, want degraded", body["status"])
+	}
+	if _, ok := body["seconds_since_last_fetch"]; ok {
+		t.Errorf("seconds_since_last_fetch = %v before any fetch, want it omitted", body["seconds_since_last_fetch"])
+	}
+	
+	server.get(t, "/api/v1/weather/current?city=Prague")
+	_, body = server.get(t, "/api/v1/health")
+	if body["status"] != "healthy" || body["seconds_since_last_fetch"] != 0.0 || body["stale_threshold"] != "100ms" {
+		t.Errorf("after a fetch: status %v %v seconds since it, threshold %v, want healthy, 0 and 100ms",
+			body["status"], body["seconds_since_last_fetch"], body["stale_threshold"])
+	}
+	
+	time.Sleep(150 * time.Millisecond)
+	_, body = server.get(t, "/api/v1/health")
+	if body["status"] != "degraded" {
+		t.Errorf("status past the threshold = %v, want degraded", body["status"])
+	}
+}
+
+func TestEmptyResultStatus(t *testing.T) {
+	tests := []struct {
+		setting string
+		status  int
+	}{
+		{"", http.StatusNotFound},
+		{"404", http.StatusNotFound},
+		{"200", http.StatusOK},
+	}
+	for _, tt := range tests {
+		name := "default"
+		if tt.setting != "" {
+			name = tt.setting
+		}
+		t.Run(name, func(t *testing.T) {
+			if tt.setting != "" {
+				t.Setenv("EMPTY_RESULT_STATUS", tt.setting)
+			}
+			// The provider answers, but without a usable temperature
+			server := newTestServer(t, newFakeClient("fake", math.NaN()))
+			
+			resp, raw := server.do(t, httptest.NewRequest(http.MethodGet, "/api/v1/weather/current?city=Prague", nil))
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %v", resp.StatusCode, tt.status, raw)
+			}
+			if tt.status == http.StatusOK {
+				if raw != nil || resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+					t.Errorf("body %v with Cache-Control %q, want null and no-store", raw, resp.Header.Get(fiber.HeaderCacheControl))
+				}
+				return
+			}
+			if body, _ := raw.(map[string]interface{}); errorCode(body) != CodeCityNotFound {
+				t.Errorf("code = %q, want %s", errorCode(body), CodeCityNotFound)
+			}
+		})
+	}
+}
+
+func TestGeocodeValidatesParameters(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	for _, target := range []string{
+		"/api/v1/geocode",
+		"/api/v1/geocode?q=P",
+		"/api/v1/geocode?q=Par&limit=0",
+		"/api/v1/geocode?q=Par&limit=many",
+	} {
+		resp, body := server.get(t, target)
+		if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+			t.Errorf("%s: status %d code %q, want 400 %s", target, resp.StatusCode, errorCode(body), CodeInvalidParameter)
+		}
+	}
+}
+
+func TestDefaultCity(t *testing.T) {
+	t.Run("default set", func(t *testing.T) {
+		t.Setenv("DEFAULT_CITY", "NYC")
+		t.Setenv("CITY_ALIASES", "NYC=NewYork")
+		source := newFakeClient("fake", 0)
+		source.cities = map[string]*models.CurrentWeather{
+			"NewYork": {Temperature: 12, Humidity: 60, Condition: models.ConditionClear, Timestamp: time.Now(), Source: "fake"},
+			"Tokyo":   {Temperature: 25, Humidity: 70, Condition: models.ConditionClear, Timestamp: time.Now(), Source: "fake"},
+		}
+		server := newTestServer(t, source)
+		
+		resp, body := server.get(t, "/api/v1/weather/current")
+		if resp.StatusCode != http.StatusOK || body["city"] != "NewYork" {
+			t.Fatalf("GET current without city = %d %v, want 200 for the aliased default NewYork", resp.StatusCode, body["city"])
+		}
+		if header := resp.Header.Get(defaultCityHeader); header != "NewYork" {
+			t.Errorf("%s = %q, want NewYork", defaultCityHeader, header)
+		}
+		
+		resp, body = server.get(t, "/api/v1/weather/current?city=Tokyo")
+		if resp.StatusCode != http.StatusOK || body["city"] != "Tokyo" {
+			t.Fatalf("GET current for Tokyo = %d %v, want 200 for Tokyo", resp.StatusCode, body["city"])
+		}
+		if header := resp.Header.Get(defaultCityHeader); header != "" {
+			t.Errorf("%s = %q for a named city, want none", defaultCityHeader, header)
+		}
+	})
+	
+	t.Run("no default", func(t *testing.T) {
+		server := newTestServer(t, newFakeClient("fake", 20))
+		
+		for _, endpoint := range []string{"current", "forecast", "summary"} {
+			resp, body := server.get(t, "/api/v1/weather/"+endpoint)
+			if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeCityRequired {
+				t.Errorf("GET %s without city = %d %q, want 400 %s", endpoint, resp.StatusCode, errorCode(body), CodeCityRequired)
+			}
+			if header := resp.Header.Get(defaultCityHeader); header != "" {
+				t.Errorf("GET %s: %s = %q, want none", endpoint, defaultCityHeader, header)
+			}
+		}
+	})
+}
+
+func TestGetProviderUsage(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(upstream.Close)
+	
+	source := &upstreamClient{
+		fakeClient: newFakeClient("upstream", 20),
+		base:       client.NewBaseClient("upstream", client.ClientConfig{Timeout: time.Second}, zap.NewNop()),
+		upstream:   upstream.URL,
+	}
+	server := newTestServer(t, source)
+	
+	for _, city := range []string{"Prague", "London", "Prague"} {
+		if resp, body := server.get(t, "/api/v1/weather/current?city="+city); resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET current for %s = %d: %v", city, resp.StatusCode, body)
+		}
+	}
+	
+	_, body := server.get(t, "/api/v1/providers/usage")
+	providers, _ := body["providers"].([]interface{})
+	usage := make(map[string]map[string]interface{})
+	for _, provider := range providers {
+		entry, _ := provider.(map[string]interface{})
+		name, _ := entry["name"].(string)
+		usage[name] = entry
+	}
+	
+	// The cached Prague is not asked for again
+	for _, window := range []string{"hour", "day"} {
+		counts, _ := usage["upstream"][window].(map[string]interface{})
+		if counts["calls"] != 2.0 {
+			t.Errorf("upstream %s = %v, want 2 calls", window, counts)
+		}
+	}
+	if _, ok := usage["geocoder"]; !ok {
+		t.Errorf("providers = %v, want the geocoder listed", providers)
+	}
+	
+	_, body = server.get(t, "/api/v1/metrics")
+	if metrics, _ := body["metrics"].(map[string]interface{}); metrics["upstream_usage"] == nil {
+		t.Errorf("metrics = %v, want the upstream usage", metrics)
+	}
+}
+
+func TestGetCacheDump(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	get := func(token string) (*http.Response, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/cache", nil)
+		if token != "" {
+			req.Header.Set(adminTokenHeader, token)
+		}
+		resp, body := server.do(t, req)
+		object, _ := body.(map[string]interface{})
+		return resp, object
+	}
+	
+	if resp, _ := get("wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", resp.StatusCode)
+	}
+	
+	if _, body := get("secret"); body["count"] != float64(0) {
+		t.Errorf("empty cache: count = %v, want 0", body["count"])
+	}
+	
+	server.get(t, "/api/v1/weather/current?city=Prague")
+	resp, body := get("secret")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	entries, _ := body["entries"].([]interface{})
+	if body["count"] != float64(len(entries)) {
+		t.Errorf("count = %v, want %d", body["count"], len(entries))
+	}
+	var current map[string]interface{}
+	for _, value := range entries {
+		entry := value.(map[string]interface{})
+		if _, ok := entry["weather"]; ok {
+			t.Errorf("entry = %v, want no payload", entry)
+		}
+		if entry["city"] == "prague" && entry["type"] == "current" {
+			current = entry
+		}
+	}
+	if current == nil || current["expires_at"] == nil {
+		t.Errorf("entries = %v, want the current weather of prague with its expiry", entries)
+	}
+}
+
+func TestGetBestDay(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/best-day?city=Prague&days=3")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	days, _ := body["days"].([]interface{})
+	best, _ := body["best"].(map[string]interface{})
+	if len(days) != 3 || best["score"] == nil || best["date"] == nil {
+		t.Errorf("body = %v, want the best of 3 scored days", body)
+	}
+	
+	for _, query := range []string{"days=0", "days=99", "days=soon"} {
+		resp, body := server.get(t, "/api/v1/weather/best-day?city=Prague&"+query)
+		if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidDays {
+			t.Errorf("%s: status %d code %q, want 400 %s", query, resp.StatusCode, errorCode(body), CodeInvalidDays)
+		}
+	}
+}
+
+func TestGetDailySummary(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/daily-summary?city=Prague")
+	if resp.StatusCode != http.StatusNotFound || errorCode(body) != CodeCityNotFound {
+		t.Errorf("before the scheduled run: status %d code %q, want 404 %s", resp.StatusCode, errorCode(body), CodeCityNotFound)
+	}
+	
+	if err := server.aggregator.ComputeDailySummaries(context.Background(), []string{"Prague"}, time.Second); err != nil {
+		t.Fatalf("ComputeDailySummaries: %v", err)
+	}
+	forecast, err := server.aggregator.GetAggregatedForecast(context.Background(), "Prague", 1, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	today := forecast.Days[0]
+	
+	resp, body = server.get(t, "/api/v1/weather/daily-summary?city=prague")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	if body["high"] != today.MaxTemp || body["low"] != today.MinTemp || body["condition"] != string(today.Condition) {
+		t.Errorf("summary = %v, want today's %v/%v %s", body, today.MaxTemp, today.MinTemp, today.Condition)
+	}
+	
+	_, body = server.get(t, "/api/v1/weather/daily-summary?city=Prague&units=imperial")
+	if body["units"] != "imperial" || body["high"] != today.MaxTemp*9/5+32 {
+		t.Errorf("imperial summary = %v, want %v°F", body, today.MaxTemp*9/5+32)
+	}
+}
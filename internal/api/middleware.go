@@ -0,0 +1,137 @@
+package api
+
+import (
+	"crypto/subtle"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+const (
+	apiKeyHeader      = "X-API-Key"
+	adminTokenHeader  = "X-Admin-Token"
+	maintenanceHeader = "X-Maintenance"
+	defaultCityHeader = "X-Default-City"
+)
+
+// cityPattern accepts names in any script with the punctuation real place
+// names use, e.g. "St. John's", "Saint-Étienne" or "Washington (DC)"
+var cityPattern = regexp.MustCompile(`^[\p{L}\p{M}\p{N} .'’()-]{1,100}$`)
+
+// validateCities rejects city and cities parameters outside cityPattern before
+// they reach a handler or an upstream URL
+func validateCities() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		names := strings.Split(c.Query("cities"), ",")
+		names = append(names, c.Query("city"))
+		
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name != "" && !cityPattern.MatchString(name) {
+				return RespondError(c, fiber.StatusBadRequest, CodeInvalidCity,
+					"City names may only contain letters, digits, spaces and . ' ( ) -, up to 100 characters", nil)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// requireAPIKey rejects requests without one of the configured keys in the
+// X-API-Key header. Paths in public are let through unauthenticated.
+func requireAPIKey(keys []string, public ...string) fiber.Handler {
+	allowed := make([][]byte, len(keys))
+	for i, key := range keys {
+		allowed[i] = []byte(key)
+	}
+	
+	return func(c *fiber.Ctx) error {
+		for _, path := range public {
+			if c.Path() == path {
+				return c.Next()
+			}
+		}
+		
+		provided := []byte(c.Get(apiKeyHeader))
+		if len(provided) > 0 && matchesAnyKey(provided, allowed) {
+			return c.Next()
+		}
+		
+		return RespondError(c, fiber.StatusUnauthorized, CodeUnauthorized, "Missing or invalid API key", nil)
+	}
+}
+
+// requireAdminToken guards admin endpoints. Without a configured token they
+// are switched off entirely.
+func requireAdminToken(token string) fiber.Handler {
+	expected := []byte(token)
+	
+	return func(c *fiber.Ctx) error {
+		if len(expected) == 0 {
+			return RespondError(c, fiber.StatusForbidden, CodeAdminDisabled, "Admin endpoints are disabled, set ADMIN_TOKEN to enable them", nil)
+		}
+		
+		if subtle.ConstantTimeCompare([]byte(c.Get(adminTokenHeader)), expected) != 1 {
+			return RespondError(c, fiber.StatusUnauthorized, CodeUnauthorized, "Missing or invalid admin token", nil)
+		}
+		
+		return c.Next()
+	}
+}
+
+// matchesAnyKey compares against every key so the response time does not
+// reveal which key, if any, matched
+func matchesAnyKey(provided []byte, keys [][]byte) bool {
+	matched := 0
+	for _, key := range keys {
+		matched |= subtle.ConstantTimeCompare(provided, key)
+	}
+	return matched == 1
+}
+
+// rateLimit allows each client perMinute requests per minute, identified by its
+// API key when it is one of keys and by IP otherwise, so made-up keys do not
+// get a bucket of their own. Paths in exempt are not counted.
+func rateLimit(perMinute int, keys []string, exempt ...string) fiber.Handler {
+	allowed := make([][]byte, len(keys))
+	for i, key := range keys {
+		allowed[i] = []byte(key)
+	}
+	
+	return limiter.New(limiter.Config{
+		Max:        perMinute,
+		Expiration: time.Minute,
+		Next: func(c *fiber.Ctx) bool {
+			for _, path := range exempt {
+				if c.Path() == path {
+					return true
+				}
+			}
+			return false
+		},
+		KeyGenerator: func(c *fiber.Ctx) string {
+			if key := c.Get(apiKeyHeader); key != "" && matchesAnyKey([]byte(key), allowed) {
+				return "key:" + key
+			}
+			return "ip:" + c.IP()
+		},
+		// The limiter has already set Retry-After
+		LimitReached: func(c *fiber.Ctx) error {
+			return RespondError(c, fiber.StatusTooManyRequests, CodeRateLimited, "Rate limit exceeded", nil)
+		},
+	})
+}
+
+// markMaintenance flags every response served while maintenance mode is on,
+// checked after the handler so toggling it takes effect on its own response
+func markMaintenance(aggregator *services.Aggregator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if aggregator.InMaintenance() {
+			c.Set(maintenanceHeader, "true")
+		}
+		return err
+	}
+}
\ No newline at end of file
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	t.Setenv("API_KEYS", "alpha,beta")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+	}{
+		{"valid key", "/api/v1/weather/current?city=Prague", "beta", http.StatusOK},
+		{"invalid key", "/api/v1/weather/current?city=Prague", "gamma", http.StatusUnauthorized},
+		{"prefix of a key", "/api/v1/weather/current?city=Prague", "alph", http.StatusUnauthorized},
+		{"missing key", "/api/v1/weather/current?city=Prague", "", http.StatusUnauthorized},
+		{"health is public", "/api/v1/health", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set(apiKeyHeader, tt.key)
+		}
+		
+		resp, body := server.do(t, req)
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+		if tt.status == http.StatusUnauthorized {
+			if object, _ := body.(map[string]interface{}); errorCode(object) != CodeUnauthorized {
+				t.Errorf("%s: code = %q, want %s", tt.name, errorCode(object), CodeUnauthorized)
+			}
+		}
+	}
+}
+
+func TestAuthenticationDisabledWithoutKeys(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	if resp, _ := server.get(t, "/api/v1/weather/current?city=Prague"); resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d without API_KEYS, want 200", resp.StatusCode)
+	}
+}
+
+func TestRateLimitRejectsPastTheLimit(t *testing.T) {
+	t.Setenv("INBOUND_RATE_LIMIT", "2")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	for i := 0; i < 2; i++ {
+		if resp, _ := server.get(t, "/api/v1/weather/current?city=Prague"); resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 within the limit", i+1, resp.StatusCode)
+		}
+	}
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague")
+	if resp.StatusCode != http.StatusTooManyRequests || errorCode(body) != CodeRateLimited {
+		t.Fatalf("status %d code %q, want 429 %s", resp.StatusCode, errorCode(body), CodeRateLimited)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("429 without a Retry-After header")
+	}
+	
+	for _, path := range []string{"/api/v1/health", "/api/v1/metrics"} {
+		if resp, _ := server.get(t, path); resp.StatusCode == http.StatusTooManyRequests {
+			t.Errorf("%s rate limited, want exempt", path)
+		}
+	}
+}
+
+// requestWithKey sends a current weather request carrying key and returns the status
+func requestWithKey(t *testing.T, server *testServer, key string) int {
+	t.Helper()
+	
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/weather/current?city=Prague", nil)
+	req.Header.Set(apiKeyHeader, key)
+	resp, _ := server.do(t, req)
+	return resp.StatusCode
+}
+
+func TestRateLimitKeysOnValidAPIKeys(t *testing.T) {
+	t.Setenv("INBOUND_RATE_LIMIT", "1")
+	
+	t.Run("configured keys", func(t *testing.T) {
+		t.Setenv("API_KEYS", "alpha,beta")
+		server := newTestServer(t, newFakeClient("fake", 20))
+		
+		for _, key := range []string{"alpha", "beta"} {
+			if status := requestWithKey(t, server, key); status != http.StatusOK {
+				t.Errorf("first request with %s: status = %d, want 200 from its own budget", key, status)
+			}
+		}
+		if status := requestWithKey(t, server, "alpha"); status != http.StatusTooManyRequests {
+			t.Errorf("second request with alpha: status = %d, want 429", status)
+		}
+	})
+	
+	t.Run("made-up keys", func(t *testing.T) {
+		server := newTestServer(t, newFakeClient("fake", 20))
+		
+		if status := requestWithKey(t, server, "made-up-1"); status != http.StatusOK {
+			t.Errorf("first request: status = %d, want 200", status)
+		}
+		if status := requestWithKey(t, server, "made-up-2"); status != http.StatusTooManyRequests {
+			t.Errorf("another made-up key: status = %d, want 429 from the shared IP budget", status)
+		}
+	})
+}
+
+func TestCORSAllowlist(t *testing.T) {
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://app.example.com")
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	for origin, want := range map[string]string{
+		"https://app.example.com":  "https://app.example.com",
+		"https://evil.example.com": "",
+	} {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/weather/current?city=Prague", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		
+		resp, _ := server.do(t, req)
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("Origin %s: Access-Control-Allow-Origin = %q, want %q", origin, got, want)
+		}
+	}
+}
+
+func TestValidateCities(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	tests := []struct {
+		query string
+		valid bool
+	}{
+		{"city=New%20York", true},
+		{"city=St.%20John's", true},
+		{"city=Saint-%C3%89tienne", true},
+		{"city=Washington%20(DC)", true},
+		{"city=%E6%9D%B1%E4%BA%AC", true},
+		{"city=Prague%26units%3Dimperial", false},
+		{"city=Prague%23fragment", false},
+		{"city=Prague%3Bdrop", false},
+		{"city=" + strings.Repeat("a", 101), false},
+		{"cities=Prague,London", true},
+		{"cities=Prague,Lon%3Cdon%3E", false},
+	}
+	for _, tt := range tests {
+		resp, body := server.get(t, "/api/v1/weather/compare?"+tt.query)
+		if rejected := errorCode(body) == CodeInvalidCity; rejected == tt.valid {
+			t.Errorf("%s: status = %d %q, want valid %v", tt.query, resp.StatusCode, errorCode(body), tt.valid)
+		}
+		if !tt.valid && resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", tt.query, resp.StatusCode)
+		}
+	}
+}
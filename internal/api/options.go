@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Language codes as accepted by OpenWeatherMap, e.g. "de" or "zh_cn"
+var langPattern = regexp.MustCompile(`^[a-z]{2}(_[a-z]{2})?$`)
+
+// ISO 3166-1 alpha-2 country codes, e.g. "GB" or "CA"
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// parseQueryOptions reads the query parameters that change what is fetched
+func parseQueryOptions(c *fiber.Ctx) (models.QueryOptions, error) {
+	var opts models.QueryOptions
+	
+	lang := strings.ToLower(strings.TrimSpace(c.Query("lang", models.DefaultLang)))
+	if !langPattern.MatchString(lang) {
+		return opts, fmt.Errorf("Invalid lang parameter: %s", lang)
+	}
+	opts.Lang = lang
+	
+	if value := c.Query("country"); value != "" {
+		country := strings.ToUpper(strings.TrimSpace(value))
+		if !countryPattern.MatchString(country) {
+			return opts, fmt.Errorf("country must be a two-letter ISO 3166-1 code such as GB or CA")
+		}
+		opts.Country = country
+	}
+	
+	if value := c.Query("pressure_unit"); value != "" {
+		unit, ok := models.ParsePressureUnit(strings.ToLower(value))
+		if !ok {
+			return opts, fmt.Errorf("pressure_unit must be one of hpa, inhg, mmhg")
+		}
+		opts.PressureUnit = unit
+	}
+	
+	if value := c.Query("units"); value != "" {
+		units, ok := models.ParseUnitSystem(strings.ToLower(value))
+		if !ok {
+			return opts, fmt.Errorf("units must be one of metric, imperial, standard")
+		}
+		opts.Units = units
+	}
+	
+	if value := c.Query("precip_unit"); value != "" {
+		unit, ok := models.ParsePrecipitationUnit(strings.ToLower(value))
+		if !ok {
+			return opts, fmt.Errorf("precip_unit must be one of mm, in")
+		}
+		opts.PrecipUnit = unit
+	}
+	
+	if value := c.Query("sources"); value != "" {
+		seen := make(map[string]bool)
+		for _, source := range strings.Split(strings.ToLower(value), ",") {
+			source = strings.TrimSpace(source)
+			if source != "" && !seen[source] {
+				seen[source] = true
+				opts.Sources = append(opts.Sources, source)
+			}
+		}
+		if len(opts.Sources) == 0 {
+			return opts, fmt.Errorf("sources must list at least one provider")
+		}
+		// Sorted so every order of the same sources shares a cache entry
+		sort.Strings(opts.Sources)
+	}
+	
+	if value := c.Query("timestamps"); value != "" {
+		timestamps, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("Timestamps parameter must be true or false")
+		}
+		opts.Timestamps = timestamps
+	}
+	
+	if value := c.Query("blend"); value != "" {
+		blend, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("Blend parameter must be true or false")
+		}
+		opts.Blend = blend
+	}
+	
+	if value := c.Query("breakdown"); value != "" {
+		breakdown, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("Breakdown parameter must be true or false")
+		}
+		opts.Breakdown = breakdown
+	}
+	
+	if value := c.Query("raw_units"); value != "" {
+		rawUnits, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("raw_units must be true or false")
+		}
+		if rawUnits && !opts.Breakdown {
+			return opts, fmt.Errorf("raw_units requires breakdown=true")
+		}
+		opts.RawUnits = rawUnits
+	}
+	
+	if value := c.Query("max_age"); value != "" {
+		maxAge, err := parseMaxAge(value)
+		if err != nil {
+			return opts, err
+		}
+		opts.MaxAge = maxAge
+	}
+	
+	return opts, nil
+}
+
+// parseMinConfidence reads the optional min_confidence threshold, zero when absent
+func parseMinConfidence(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		return 0, fmt.Errorf("min_confidence must be a number between 0 and 1")
+	}
+	return threshold, nil
+}
+
+// parseMinSources reads the optional min_sources override of the configured minimum
+func parseMinSources(value string, configured int) (int, error) {
+	if value == "" {
+		return configured, nil
+	}
+	
+	minSources, err := strconv.Atoi(value)
+	if err != nil || minSources < 1 {
+		return 0, fmt.Errorf("min_sources must be a positive integer")
+	}
+	return minSources, nil
+}
+
+// parseMaxAge accepts a Go duration ("120s", "2m") or a bare number of seconds
+func parseMaxAge(value string) (time.Duration, error) {
+	maxAge, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, fmt.Errorf("Invalid max_age parameter: %s", value)
+		}
+		maxAge = time.Duration(seconds) * time.Second
+	}
+	
+	if maxAge <= 0 {
+		return 0, fmt.Errorf("max_age must be positive")
+	}
+	return maxAge, nil
+}
\ No newline at end of file
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInvalidLangIsRejected(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&lang=german")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	valid := map[string]time.Duration{
+		"120":  120 * time.Second,
+		"120s": 120 * time.Second,
+		"2m":   2 * time.Minute,
+	}
+	for value, want := range valid {
+		if got, err := parseMaxAge(value); err != nil || got != want {
+			t.Errorf("parseMaxAge(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"0", "-5", "-1m", "soon"} {
+		if _, err := parseMaxAge(value); err == nil {
+			t.Errorf("parseMaxAge(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestPressureUnitParameter(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&pressure_unit=inHg")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	labels, _ := body["unit_labels"].(map[string]interface{})
+	if pressure, _ := body["pressure"].(float64); pressure < 29.9 || pressure > 30 || labels["pressure"] != "inHg" {
+		t.Errorf("pressure = %v %v, want 1013 hPa as about 29.9 inHg", body["pressure"], labels["pressure"])
+	}
+	
+	resp, body = server.get(t, "/api/v1/weather/current?city=Prague&pressure_unit=psi")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("pressure_unit=psi: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestSmoothParameter(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	if resp, body := server.get(t, "/api/v1/weather/forecast?city=Prague&days=3&smooth=true"); resp.StatusCode != http.StatusOK {
+		t.Errorf("smooth=true: status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	resp, body := server.get(t, "/api/v1/weather/forecast?city=Prague&days=3&smooth=maybe")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("smooth=maybe: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestCountryParameter(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	if resp, body := server.get(t, "/api/v1/weather/current?city=London&country=ca"); resp.StatusCode != http.StatusOK {
+		t.Errorf("country=ca: status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	resp, body := server.get(t, "/api/v1/weather/current?city=London&country=CAN")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("country=CAN: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestPrecipUnitParameter(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/forecast?city=Prague&days=1&precip_unit=in")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	if labels, _ := body["unit_labels"].(map[string]interface{}); labels["precipitation"] != "in" {
+		t.Errorf("precipitation label = %v, want in", labels["precipitation"])
+	}
+	
+	resp, body = server.get(t, "/api/v1/weather/forecast?city=Prague&days=1&precip_unit=cm")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("precip_unit=cm: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestStandardUnitsReportKelvin(t *testing.T) {
+	t.Setenv("OUTPUT_DECIMALS", "2")
+	source := newFakeClient("fake", 20)
+	source.current.FeelsLike = 18
+	server := newTestServer(t, source)
+	
+	near := func(value interface{}, want float64) bool {
+		got, ok := value.(float64)
+		return ok && math.Abs(got-want) < 1e-6
+	}
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&units=standard")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	labels, _ := body["unit_labels"].(map[string]interface{})
+	if !near(body["temperature"], 293.15) || !near(body["feels_like"], 291.15) || labels["temperature"] != "K" {
+		t.Errorf("temperature %v feels like %v %v, want 293.15 and 291.15 K", body["temperature"], body["feels_like"], labels["temperature"])
+	}
+	if !near(body["wind_speed"], 3) || labels["wind_speed"] != "m/s" {
+		t.Errorf("wind speed = %v %v, want 3 m/s unchanged", body["wind_speed"], labels["wind_speed"])
+	}
+	
+	_, body = server.get(t, "/api/v1/weather/forecast?city=Prague&days=1&units=standard")
+	days, _ := body["days"].([]interface{})
+	if len(days) != 1 {
+		t.Fatalf("days = %v, want 1", body["days"])
+	}
+	day := days[0].(map[string]interface{})
+	if !near(day["max_temp"], 298.15) || !near(day["min_temp"], 288.15) || !near(day["avg_temp"], 293.15) {
+		t.Errorf("day %v/%v/%v, want 298.15/288.15/293.15 K", day["max_temp"], day["min_temp"], day["avg_temp"])
+	}
+	
+	// The Celsius entry cached by the first request is not served for Kelvin or back
+	_, body = server.get(t, "/api/v1/weather/current?city=Prague")
+	if !near(body["temperature"], 20) {
+		t.Errorf("metric temperature after a Kelvin request = %v, want 20", body["temperature"])
+	}
+}
+
+func TestSourcesParameter(t *testing.T) {
+	a, b := newFakeClient("a", 10), newFakeClient("b", 30)
+	server := newTestServer(t, a, b)
+	
+	_, body := server.get(t, "/api/v1/weather/current?city=Prague&sources=B")
+	if sources, _ := body["sources"].([]interface{}); len(sources) != 1 || sources[0] != "b" || body["temperature"] != 30.0 {
+		t.Errorf("sources=B: %v from %v, want 30 from b only", body["temperature"], body["sources"])
+	}
+	if a.calls.Load() != 0 {
+		t.Errorf("a fetched %d times, want only b fetched", a.calls.Load())
+	}
+	
+	// The aggregate of every source is cached apart from the single source one
+	_, body = server.get(t, "/api/v1/weather/current?city=Prague")
+	if sources, _ := body["sources"].([]interface{}); len(sources) != 2 || body["temperature"] != 20.0 {
+		t.Errorf("without sources: %v from %v, want 20 from both", body["temperature"], body["sources"])
+	}
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&sources=c")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeUnknownSource {
+		t.Errorf("sources=c: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeUnknownSource)
+	}
+	resp, body = server.get(t, "/api/v1/weather/current?city=Prague&sources=,")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+		t.Errorf("sources=,: status %d code %q, want 400 %s", resp.StatusCode, errorCode(body), CodeInvalidParameter)
+	}
+}
+
+func TestResponsesStateUnits(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	tests := []struct {
+		query       string
+		units       string
+		temperature string
+		windSpeed   string
+		pressure    string
+	}{
+		{"", "metric", "°C", "m/s", "hPa"},
+		{"&units=imperial&pressure_unit=inhg", "imperial", "°F", "mph", "inHg"},
+		{"&units=standard", "standard", "K", "m/s", "hPa"},
+	}
+	for _, tt := range tests {
+		_, body := server.get(t, "/api/v1/weather/current?city=Prague"+tt.query)
+		labels, _ := body["unit_labels"].(map[string]interface{})
+		if body["units"] != tt.units || labels["temperature"] != tt.temperature || labels["wind_speed"] != tt.windSpeed || labels["pressure"] != tt.pressure {
+			t.Errorf("current%s: units %v labels %v, want %s in %s, %s and %s", tt.query, body["units"], labels, tt.units, tt.temperature, tt.windSpeed, tt.pressure)
+		}
+		
+		_, body = server.get(t, "/api/v1/weather/forecast?city=Prague&days=2"+tt.query)
+		labels, _ = body["unit_labels"].(map[string]interface{})
+		if body["units"] != tt.units || labels["temperature"] != tt.temperature || labels["precipitation"] == nil {
+			t.Errorf("forecast%s: units %v labels %v, want %s in %s with precipitation", tt.query, body["units"], labels, tt.units, tt.temperature)
+		}
+	}
+}
+
+func TestMinSources(t *testing.T) {
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("MIN_SOURCES", "2")
+		server := newTestServer(t, newFakeClient("fake", 20))
+		
+		for _, path := range []string{
+			"/api/v1/weather/current?city=Prague",
+			"/api/v1/weather/forecast?city=Prague&days=2",
+		} {
+			resp, body := server.get(t, path)
+			if resp.StatusCode != http.StatusUnprocessableEntity || errorCode(body) != CodeInsufficientSources {
+				t.Errorf("%s: status %d code %q, want 422 %s", path, resp.StatusCode, errorCode(body), CodeInsufficientSources)
+			}
+		}
+		
+		resp, body := server.get(t, "/api/v1/weather/current?city=Prague&min_sources=1")
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("min_sources=1: status %d, want 200: %v", resp.StatusCode, body)
+		}
+	})
+	
+	t.Run("override", func(t *testing.T) {
+		server := newTestServer(t, newFakeClient("a", 20), newFakeClient("b", 22))
+		
+		resp, body := server.get(t, "/api/v1/weather/current?city=Prague&min_sources=2")
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("min_sources=2 with two providers: status %d, want 200: %v", resp.StatusCode, body)
+		}
+		
+		resp, body = server.get(t, "/api/v1/weather/current?city=Prague&min_sources=3")
+		if resp.StatusCode != http.StatusUnprocessableEntity || errorCode(body) != CodeInsufficientSources {
+			t.Fatalf("min_sources=3: status %d code %q, want 422 %s", resp.StatusCode, errorCode(body), CodeInsufficientSources)
+		}
+		details, _ := body["error"].(map[string]interface{})["details"].(map[string]interface{})
+		if details["min_sources"] != float64(3) {
+			t.Errorf("details = %v, want min_sources 3", details)
+		}
+		
+		for _, value := range []string{"0", "two"} {
+			resp, body = server.get(t, "/api/v1/weather/current?city=Prague&min_sources="+value)
+			if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+				t.Errorf("min_sources=%s: status %d code %q, want 400 %s", value, resp.StatusCode, errorCode(body), CodeInvalidParameter)
+			}
+		}
+	})
+}
+
+func TestBreakdownParameters(t *testing.T) {
+	server := newTestServer(t, newFakeClient("fake", 20))
+	
+	resp, body := server.get(t, "/api/v1/weather/current?city=Prague&breakdown=true&raw_units=true")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", resp.StatusCode, body)
+	}
+	breakdown, _ := body["breakdown"].(map[string]interface{})
+	if _, ok := breakdown["fake"]; !ok {
+		t.Errorf("breakdown = %v, want the reading of fake", body["breakdown"])
+	}
+	
+	for _, query := range []string{"raw_units=true", "breakdown=maybe", "breakdown=true&raw_units=maybe"} {
+		resp, body := server.get(t, "/api/v1/weather/current?city=Prague&"+query)
+		if resp.StatusCode != http.StatusBadRequest || errorCode(body) != CodeInvalidParameter {
+			t.Errorf("%s: status %d code %q, want 400 %s", query, resp.StatusCode, errorCode(body), CodeInvalidParameter)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// outputRounder rounds the measured values of a response to the configured
+// number of decimals. It works on copies, so cached and stored data keep full
+// precision and nothing but the serialized output is affected.
+type outputRounder struct {
+	decimals int
+}
+
+func (r outputRounder) round(value float64) float64 {
+	return utils.RoundHalfUp(value, r.decimals)
+}
+
+func (r outputRounder) roundPtr(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	rounded := r.round(*value)
+	return &rounded
+}
+
+// apply returns value with its measurements rounded. Types without
+// measurements are returned as they are.
+func (r outputRounder) apply(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *models.AggregatedCurrentWeather:
+		return r.current(v)
+	case *models.AggregatedForecast:
+		return r.forecast(v)
+	case *models.BestDay:
+		return r.bestDay(v)
+	case *models.DailySummary:
+		rounded := *v
+		rounded.High = r.round(v.High)
+		rounded.Low = r.round(v.Low)
+		return &rounded
+	case *models.MultiHorizonForecast:
+		return r.multiHorizon(v)
+	case *models.PointForecast:
+		return r.point(v)
+	case *models.WeatherComparison:
+		return r.comparison(v)
+	case *models.WeatherSummary:
+		// Its figures are part of the sentence, rounded when it is phrased
+		return v
+	case *models.WeatherTrend:
+		return r.trend(v)
+	case []models.CityCurrentWeather:
+		return r.cities(v)
+	case fiber.Map:
+		rounded := make(fiber.Map, len(v))
+		for key, item := range v {
+			rounded[key] = r.apply(item)
+		}
+		return rounded
+	}
+	return value
+}
+
+func (r outputRounder) current(weather *models.AggregatedCurrentWeather) *models.AggregatedCurrentWeather {
+	if weather == nil {
+		return nil
+	}
+	
+	rounded := *weather
+	rounded.Temperature = r.round(weather.Temperature)
+	rounded.FeelsLike = r.round(weather.FeelsLike)
+	rounded.HeatIndex = r.roundPtr(weather.HeatIndex)
+	rounded.WindChill = r.roundPtr(weather.WindChill)
+	rounded.Humidity = r.round(weather.Humidity)
+	rounded.Pressure = r.round(weather.Pressure)
+	rounded.WindSpeed = r.round(weather.WindSpeed)
+	rounded.WindGust = r.round(weather.WindGust)
+	rounded.PrecipitationProbability = r.roundPtr(weather.PrecipitationProbability)
+	if weather.Breakdown != nil {
+		rounded.Breakdown = make(map[string]models.SourceReading, len(weather.Breakdown))
+		for source, reading := range weather.Breakdown {
+			reading.Temperature = r.round(reading.Temperature)
+			reading.FeelsLike = r.round(reading.FeelsLike)
+			reading.Humidity = r.round(reading.Humidity)
+			reading.Pressure = r.round(reading.Pressure)
+			reading.WindSpeed = r.round(reading.WindSpeed)
+			reading.WindGust = r.round(reading.WindGust)
+			reading.Visibility = r.roundPtr(reading.Visibility)
+			rounded.Breakdown[source] = reading
+		}
+	}
+	return &rounded
+}
+
+func (r outputRounder) forecast(forecast *models.AggregatedForecast) *models.AggregatedForecast {
+	if forecast == nil {
+		return nil
+	}
+	
+	rounded := *forecast
+	rounded.Days = make([]models.ForecastDay, len(forecast.Days))
+	for i, day := range forecast.Days {
+		rounded.Days[i] = r.day(day)
+	}
+	return &rounded
+}
+
+func (r outputRounder) day(day models.ForecastDay) models.ForecastDay {
+	day.MaxTemp = r.round(day.MaxTemp)
+	day.MinTemp = r.round(day.MinTemp)
+	day.AvgTemp = r.round(day.AvgTemp)
+	day.Humidity = r.round(day.Humidity)
+	day.Precipitation = r.round(day.Precipitation)
+	day.RainSum = r.round(day.RainSum)
+	day.SnowfallSum = r.round(day.SnowfallSum)
+	day.WindGust = r.round(day.WindGust)
+	return day
+}
+
+func (r outputRounder) bestDay(bestDay *models.BestDay) *models.BestDay {
+	rounded := *bestDay
+	rounded.Best = r.scoredDay(bestDay.Best)
+	rounded.Days = make([]models.ScoredDay, len(bestDay.Days))
+	for i, day := range bestDay.Days {
+		rounded.Days[i] = r.scoredDay(day)
+	}
+	return &rounded
+}
+
+func (r outputRounder) scoredDay(day models.ScoredDay) models.ScoredDay {
+	day.ForecastDay = r.day(day.ForecastDay)
+	day.Score = r.round(day.Score)
+	return day
+}
+
+func (r outputRounder) multiHorizon(response *models.MultiHorizonForecast) *models.MultiHorizonForecast {
+	rounded := *response
+	rounded.Forecast = r.forecast(response.Forecast)
+	rounded.Horizons = make(map[int]*models.AggregatedForecast, len(response.Horizons))
+	for horizon, forecast := range response.Horizons {
+		rounded.Horizons[horizon] = r.forecast(forecast)
+	}
+	return &rounded
+}
+
+func (r outputRounder) point(point *models.PointForecast) *models.PointForecast {
+	if point == nil {
+		return nil
+	}
+	
+	rounded := *point
+	rounded.Temperature = r.round(point.Temperature)
+	rounded.Humidity = r.round(point.Humidity)
+	rounded.WindSpeed = r.round(point.WindSpeed)
+	return &rounded
+}
+
+func (r outputRounder) comparison(comparison *models.WeatherComparison) *models.WeatherComparison {
+	rounded := *comparison
+	rounded.Weather = make(map[string]*models.AggregatedCurrentWeather, len(comparison.Weather))
+	for city, weather := range comparison.Weather {
+		rounded.Weather[city] = r.current(weather)
+	}
+	if comparison.Diff != nil {
+		diff := *comparison.Diff
+		diff.TemperatureDelta = r.round(diff.TemperatureDelta)
+		diff.HumidityDelta = r.round(diff.HumidityDelta)
+		rounded.Diff = &diff
+	}
+	return &rounded
+}
+
+func (r outputRounder) trend(trend *models.WeatherTrend) *models.WeatherTrend {
+	rounded := *trend
+	rounded.Points = make([]models.TrendPoint, len(trend.Points))
+	for i, point := range trend.Points {
+		point.Temperature = r.round(point.Temperature)
+		point.FeelsLike = r.round(point.FeelsLike)
+		point.Humidity = r.round(point.Humidity)
+		point.Pressure = r.round(point.Pressure)
+		point.WindSpeed = r.round(point.WindSpeed)
+		rounded.Points[i] = point
+	}
+	return &rounded
+}
+
+func (r outputRounder) cities(cities []models.CityCurrentWeather) []models.CityCurrentWeather {
+	rounded := make([]models.CityCurrentWeather, len(cities))
+	for i, city := range cities {
+		city.Weather = r.current(city.Weather)
+		rounded[i] = city
+	}
+	return rounded
+}
\ No newline at end of file
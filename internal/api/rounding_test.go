@@ -0,0 +1,61 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestOutputRounderWorksOnCopies(t *testing.T) {
+	visibility := 9.87654
+	weather := &models.AggregatedCurrentWeather{
+		Temperature: 18.333333,
+		Humidity:    64.25,
+		Pressure:    1013.04,
+		WindSpeed:   3.15,
+		Breakdown: map[string]models.SourceReading{
+			"fake": {Temperature: 18.333333, Visibility: &visibility},
+		},
+	}
+	
+	rounded := outputRounder{decimals: 1}.apply(weather).(*models.AggregatedCurrentWeather)
+	if rounded.Temperature != 18.3 || rounded.Humidity != 64.3 || rounded.Pressure != 1013 || rounded.WindSpeed != 3.2 {
+		t.Errorf("rounded = %v %v %v %v, want 18.3 64.3 1013 3.2", rounded.Temperature, rounded.Humidity, rounded.Pressure, rounded.WindSpeed)
+	}
+	if reading := rounded.Breakdown["fake"]; reading.Temperature != 18.3 || *reading.Visibility != 9.9 {
+		t.Errorf("rounded breakdown = %v %v, want 18.3 9.9", reading.Temperature, *reading.Visibility)
+	}
+	
+	if weather.Temperature != 18.333333 || weather.Breakdown["fake"].Temperature != 18.333333 || visibility != 9.87654 {
+		t.Error("rounding changed the full-precision value it was given")
+	}
+}
+
+func TestOutputRounderRoundsForecastDays(t *testing.T) {
+	forecast := &models.AggregatedForecast{Days: []models.ForecastDay{{MaxTemp: 21.45, MinTemp: 9.04, AvgTemp: 15.25, Precipitation: 1.26}}}
+	
+	rounded := outputRounder{decimals: 1}.apply(forecast).(*models.AggregatedForecast)
+	day := rounded.Days[0]
+	if day.MaxTemp != 21.5 || day.MinTemp != 9 || day.AvgTemp != 15.3 || day.Precipitation != 1.3 {
+		t.Errorf("rounded day = %v %v %v %v, want 21.5 9 15.3 1.3", day.MaxTemp, day.MinTemp, day.AvgTemp, day.Precipitation)
+	}
+	if forecast.Days[0].MaxTemp != 21.45 {
+		t.Error("rounding changed the full-precision forecast it was given")
+	}
+}
+
+func TestResponsesRoundedToOutputDecimals(t *testing.T) {
+	for decimals, want := range map[string]float64{"": 18.3, "0": 18, "2": 18.33} {
+		t.Run("OUTPUT_DECIMALS="+decimals, func(t *testing.T) {
+			if decimals != "" {
+				t.Setenv("OUTPUT_DECIMALS", decimals)
+			}
+			server := newTestServer(t, newFakeClient("fake", 18.333333))
+			
+			_, body := server.get(t, "/api/v1/weather/current?city=Prague")
+			if temperature := body["temperature"]; temperature != want {
+				t.Errorf("temperature = %v, want %v", temperature, want)
+			}
+		})
+	}
+}
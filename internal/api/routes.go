@@ -1,6 +1,7 @@
 package api
 
 import (
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,8 +17,9 @@ func SetupRoutes(app *fiber.App, handler *Handler, log *zap.Logger) {
 	app.Use(recover.New())
 	app.Use(requestid.New())
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH",
+		AllowOrigins: strings.Join(handler.cfg.CORS.AllowOrigins, ","),
+		AllowMethods: strings.Join(handler.cfg.CORS.AllowMethods, ","),
+		AllowHeaders: strings.Join(handler.cfg.CORS.AllowHeaders, ","),
 	}))
 	
 	// Custom logger middleware
@@ -29,25 +31,64 @@ func SetupRoutes(app *fiber.App, handler *Handler, log *zap.Logger) {
 	// API v1 routes
 	api := app.Group("/api/v1")
 	
+	// Authentication is enabled only when keys are configured
+	if len(handler.cfg.API.Keys) > 0 {
+		api.Use(requireAPIKey(handler.cfg.API.Keys, "/api/v1/health"))
+		log.Info("API key authentication enabled", zap.Int("keys", len(handler.cfg.API.Keys)))
+	}
+	
+	// Runs after authentication so only valid keys get their own budget
+	if handler.cfg.API.RateLimit > 0 {
+		api.Use(rateLimit(handler.cfg.API.RateLimit, handler.cfg.API.Keys, "/api/v1/health", "/api/v1/metrics"))
+		log.Info("Inbound rate limiting enabled", zap.Int("requests_per_minute", handler.cfg.API.RateLimit))
+	}
+	
+	api.Use(markMaintenance(handler.aggregator))
+	api.Use(validateCities())
+	
 	// Health check
 	api.Get("/health", handler.GetHealth)
+	api.Get("/health/providers", handler.GetProviderHealth)
 	
 	// Metrics
 	api.Get("/metrics", handler.GetMetrics)
 	
+	// Build information
+	api.Get("/version", handler.GetVersion)
+	
 	// Cities
 	api.Get("/cities", handler.GetCities)
+	api.Get("/geocode", handler.Geocode)
+	
+	// Providers
+	api.Get("/providers", handler.GetProviders)
+	api.Get("/providers/usage", handler.GetProviderUsage)
+	
+	// Admin routes
+	admin := requireAdminToken(handler.cfg.API.AdminToken)
+	api.Post("/providers/:name/enable", admin, handler.EnableProvider)
+	api.Post("/providers/:name/disable", admin, handler.DisableProvider)
+	api.Get("/admin/raw", admin, handler.GetRawResponses)
+	api.Get("/admin/cache", admin, handler.GetCacheDump)
+	api.Post("/admin/maintenance", admin, handler.SetMaintenance)
 	
 	// Weather routes
 	weather := api.Group("/weather")
 	weather.Get("/current", handler.GetCurrentWeather)
+	weather.Get("/current/all", handler.GetAllCurrentWeather)
 	weather.Get("/forecast", handler.GetForecast)
+	weather.Get("/at", handler.GetWeatherAt)
+	weather.Get("/compare", handler.CompareWeather)
+	weather.Get("/summary", handler.GetSummary)
+	weather.Get("/best-day", handler.GetBestDay)
+	weather.Get("/daily-summary", handler.GetDailySummary)
+	weather.Get("/trends", handler.GetTrends)
+	weather.Get("/history", handler.GetHistory)
 	
 	// 404 handler
 	app.Use(func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Endpoint not found",
-			"path":  c.Path(),
+		return RespondError(c, fiber.StatusNotFound, CodeEndpointNotFound, "Endpoint not found", fiber.Map{
+			"path": c.Path(),
 		})
 	})
 }
\ No newline at end of file
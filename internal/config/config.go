@@ -1,48 +1,182 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 )
 
+// Log output formats
+const (
+	LogFormatJSON    = "json"
+	LogFormatConsole = "console"
+)
+
+// Cache backends
+const (
+	CacheBackendMemory = "memory"
+	CacheBackendTiered = "tiered" // in-memory backed by Redis
+)
+
+// Source weighting modes
+const (
+	WeightingEqual    = "equal"
+	WeightingAdaptive = "adaptive"
+)
+
+// How a client's separate current and forecast requests are issued
+const (
+	FetchModeSequential = "sequential"
+	FetchModeParallel   = "parallel"
+)
+
+// How the providers of a fetch are ordered, the first ones are started first
+const (
+	ProviderOrderPriority = "priority" // PROVIDER_PRIORITY, then registration order
+	ProviderOrderLatency  = "latency"  // lowest observed latency first
+)
+
+// Forecast day alignment between providers
+const (
+	ForecastAlignmentDate  = "date"  // days with the same calendar date
+	ForecastAlignmentIndex = "index" // days at the same position
+)
+
+// Aggregated condition strategies
+const (
+	ConditionAggregationFrequency = "frequency" // condition most sources report
+	ConditionAggregationSeverity  = "severity"  // most severe condition any source reports
+)
+
 type Config struct {
 	Server struct {
 		Port         string
 		ReadTimeout  time.Duration
 		WriteTimeout time.Duration
 		LogLevel     string
+		LogFormat    string
+		LogHTTPBodies bool
+	}
+	
+	API struct {
+		StrictFields bool
+		Envelope     bool // wrap responses in {"data", "meta"} unless ?envelope=false
+		Keys         []string
+		RateLimit    int // requests per minute per client, 0 disables
+		AdminToken   string
+		CityAliases  map[string]string // normalized alias -> canonical city
+		DefaultCity  string // used when a request names no city, empty requires one
+		ProviderHealthInterval time.Duration // minimum time between active provider probes
+		EmptyResultStatus int // 404, or 200 with a null body, when a city has no data
+		OutputDecimals    int // decimals measurements are rounded to in responses
+		MinSources        int // providers a result must be aggregated from, overridable per request
+	}
+	
+	CORS struct {
+		AllowOrigins []string
+		AllowMethods []string
+		AllowHeaders []string // empty reflects the headers the browser asks for
 	}
 	
 	WeatherAPI struct {
-		OpenWeatherAPIKey string
+		OpenWeatherAPIKeys []string // rotated per request
+		OpenWeatherOneCall bool
 		WeatherAPIKey     string
+		TomorrowIOAPIKey  string
+		VisualCrossingAPIKey string
 		OpenMeteoURL      string
+		FetchTimeout      time.Duration
+		PartialTimeout    time.Duration // aggregate what arrived after this, 0 waits for every provider
+		ForecastDays      int // horizon requested from providers and cached
+		FetchMode         string
+		ProviderPriority  []string // provider names started first, in this order
+		ProviderOrder     string
 	}
 	
 	Scheduler struct {
 		FetchInterval time.Duration
+		FetchTimeout  time.Duration
 		DefaultCities []string
+		StaleThreshold time.Duration // health is degraded when the last successful fetch is older
+		RetryAttempts int           // retries of cities that failed a run, 0 disables
+		RetryDelay    time.Duration // before the first retry, doubled for each further one
+		StartJitter   time.Duration // random delay of the first fetch up to this, 0 disables
+		SpreadCities  bool          // stagger each run's cities across the interval
+		DailySummaryAt time.Duration // time of day in UTC, as the offset from midnight, daily summaries are computed at
 	}
 	
 	Cache struct {
 		Duration     time.Duration
 		MaxSize      int
+		WarmOnStart  bool
+		WarmTimeout  time.Duration
+		Backend      string
+		RedisURL     string
+		Prefix       string // namespace of every cache key, e.g. the environment
+		PrefetchWindow float64 // fraction of the TTL before expiry to refresh tracked cities, 0 disables
+		PrefetchMax  int       // cities refreshed per cleanup tick at most
+		ForecastHorizons []int // shorter forecast day counts cached next to the full horizon
+		LastKnownMaxAge time.Duration // how long current weather is kept to serve stale, 0 disables
+	}
+	
+	Aggregation struct {
+		Weighting       string
+		SmoothingWindow int // odd number of days
+		ConditionAggregation string
+		ForecastAlignment string
+		PrimarySource   string // provider preferred when sources tie
+		CoordinatesSource string // provider whose resolved coordinates are reported, empty for the nearest
+		NowBlendWeight  float64 // weight of the observation when blending it with the hourly forecast, see blend=true
+	}
+	
+	Trend struct {
+		Window          time.Duration // readings the temperature trend is fitted over
+		SteadyThreshold float64       // °C per hour below which the trend is steady
+	}
+	
+	BestDay struct {
+		TargetTemp          float64 // °C the temperature comfort peaks at
+		TemperatureWeight   float64
+		PrecipitationWeight float64
+		WindWeight          float64
+	}
+	
+	History struct {
+		Enabled bool
+		DBPath  string
+	}
+	
+	Stats struct {
+		File         string // empty keeps the fetch stats in memory only
+		SaveInterval time.Duration
+	}
+	
+	HTTP struct {
+		MaxIdleConns        int
+		MaxIdleConnsPerHost int
+		IdleConnTimeout     time.Duration
 	}
 	
 	CircuitBreaker struct {
-		Threshold int
-		Timeout   time.Duration
+		Threshold    int     // consecutive failures that trip the breaker, 0 disables
+		MinRequests  int     // requests before the failure ratio is considered
+		FailureRatio float64 // failed share of requests that trips the breaker, 0 disables
+		SuccessThreshold int // consecutive half-open probe successes that close the breaker
+		Timeout      time.Duration
 	}
 	
 	Retry struct {
 		MaxRetries int
 		Delay      time.Duration
 		Multiplier float64
+		MaxDelay   time.Duration // cap on each backoff delay
 	}
 }
 
@@ -58,34 +192,263 @@ func LoadConfig() (*Config, error) {
 	cfg.Server.Port = getEnv("FIBER_PORT", "8080")
 	cfg.Server.ReadTimeout = parseDuration(getEnv("FIBER_READ_TIMEOUT", "10s"))
 	cfg.Server.WriteTimeout = parseDuration(getEnv("FIBER_WRITE_TIMEOUT", "10s"))
-	cfg.Server.LogLevel = getEnv("LOG_LEVEL", "info")
+	cfg.Server.LogLevel = strings.ToLower(getEnv("LOG_LEVEL", "info"))
+	cfg.Server.LogFormat = strings.ToLower(getEnv("LOG_FORMAT", LogFormatJSON))
+	cfg.Server.LogHTTPBodies = parseBool(getEnv("LOG_HTTP_BODIES", "false"))
+	
+	// API configuration
+	cfg.API.StrictFields = parseBool(getEnv("STRICT_FIELDS", "false"))
+	cfg.API.Envelope = parseBool(getEnv("RESPONSE_ENVELOPE", "false"))
+	cfg.API.Keys = parseList(getEnv("API_KEYS", ""))
+	cfg.API.RateLimit = parseInt(getEnv("INBOUND_RATE_LIMIT", "0"))
+	cfg.API.AdminToken = getEnv("ADMIN_TOKEN", "")
+	cfg.API.CityAliases = parseAliases(getEnv("CITY_ALIASES", "NYC=NewYork,New York=NewYork"))
+	cfg.API.DefaultCity = strings.TrimSpace(getEnv("DEFAULT_CITY", ""))
+	cfg.API.ProviderHealthInterval = parseDuration(getEnv("PROVIDER_HEALTH_MIN_INTERVAL", "30s"))
+	cfg.API.EmptyResultStatus = parseInt(getEnv("EMPTY_RESULT_STATUS", "404"))
+	cfg.API.OutputDecimals = parseInt(getEnv("OUTPUT_DECIMALS", "1"))
+	cfg.API.MinSources = parseInt(getEnv("MIN_SOURCES", "1"))
+	
+	// CORS configuration
+	cfg.CORS.AllowOrigins = parseList(getEnv("CORS_ALLOW_ORIGINS", "*"))
+	cfg.CORS.AllowMethods = parseList(getEnv("CORS_ALLOW_METHODS", "GET,POST,HEAD,PUT,DELETE,PATCH"))
+	cfg.CORS.AllowHeaders = parseList(getEnv("CORS_ALLOW_HEADERS", ""))
 	
 	// Weather API configuration
-	cfg.WeatherAPI.OpenWeatherAPIKey = getEnv("OPENWEATHER_API_KEY", "")
+	cfg.WeatherAPI.OpenWeatherAPIKeys = parseList(getEnv("OPENWEATHER_API_KEY", ""))
+	cfg.WeatherAPI.OpenWeatherOneCall = parseBool(getEnv("OPENWEATHER_ONE_CALL", "false"))
 	cfg.WeatherAPI.WeatherAPIKey = getEnv("WEATHERAPI_API_KEY", "")
+	cfg.WeatherAPI.TomorrowIOAPIKey = getEnv("TOMORROWIO_API_KEY", "")
+	cfg.WeatherAPI.VisualCrossingAPIKey = getEnv("VISUALCROSSING_API_KEY", "")
 	cfg.WeatherAPI.OpenMeteoURL = getEnv("OPENMETEO_URL", "https://api.open-meteo.com/v1")
+	cfg.WeatherAPI.FetchTimeout = parseDuration(getEnv("REQUEST_FETCH_TIMEOUT", "30s"))
+	cfg.WeatherAPI.PartialTimeout = parseDuration(getEnv("PARTIAL_RESPONSE_TIMEOUT", "0s"))
+	cfg.WeatherAPI.ForecastDays = parseInt(getEnv("FORECAST_DAYS", "7"))
+	cfg.WeatherAPI.FetchMode = strings.ToLower(getEnv("CLIENT_FETCH_MODE", FetchModeSequential))
+	cfg.WeatherAPI.ProviderPriority = parseList(strings.ToLower(getEnv("PROVIDER_PRIORITY", "")))
+	cfg.WeatherAPI.ProviderOrder = strings.ToLower(getEnv("PROVIDER_ORDER", ProviderOrderPriority))
 	
 	// Scheduler configuration
 	cfg.Scheduler.FetchInterval = parseDuration(getEnv("FETCH_INTERVAL", "15m"))
+	cfg.Scheduler.FetchTimeout = parseDuration(getEnv("SCHEDULER_FETCH_TIMEOUT", "60s"))
+	cfg.Scheduler.StaleThreshold = parseDuration(getEnv("STALE_THRESHOLD", (2 * cfg.Scheduler.FetchInterval).String()))
+	cfg.Scheduler.RetryAttempts = parseInt(getEnv("SCHEDULER_RETRY_ATTEMPTS", "3"))
+	cfg.Scheduler.RetryDelay = parseDuration(getEnv("SCHEDULER_RETRY_DELAY", "30s"))
+	cfg.Scheduler.StartJitter = parseDuration(getEnv("SCHEDULER_START_JITTER", "0s"))
+	cfg.Scheduler.SpreadCities = parseBool(getEnv("SCHEDULER_SPREAD_CITIES", "false"))
+	cfg.Scheduler.DailySummaryAt = parseClock(getEnv("DAILY_SUMMARY_TIME", "06:00"))
 	cities := getEnv("DEFAULT_CITIES", "Prague,London,NewYork")
 	cfg.Scheduler.DefaultCities = strings.Split(cities, ",")
 	
 	// Cache configuration
 	cfg.Cache.Duration = parseDuration(getEnv("CACHE_DURATION", "10m"))
 	cfg.Cache.MaxSize = parseInt(getEnv("MAX_CACHE_SIZE", "1000"))
+	cfg.Cache.WarmOnStart = parseBool(getEnv("WARM_CACHE_ON_START", "false"))
+	cfg.Cache.WarmTimeout = parseDuration(getEnv("WARM_CACHE_TIMEOUT", "20s"))
+	cfg.Cache.Backend = strings.ToLower(getEnv("CACHE_BACKEND", CacheBackendMemory))
+	cfg.Cache.RedisURL = getEnv("REDIS_URL", "redis://localhost:6379/0")
+	cfg.Cache.Prefix = strings.TrimSpace(getEnv("CACHE_PREFIX", ""))
+	cfg.Cache.PrefetchWindow = parseFloat(getEnv("CACHE_PREFETCH_WINDOW", "0"))
+	cfg.Cache.PrefetchMax = parseInt(getEnv("CACHE_PREFETCH_MAX_CITIES", "5"))
+	cfg.Cache.ForecastHorizons = parseIntList(getEnv("FORECAST_CACHE_HORIZONS", ""))
+	cfg.Cache.LastKnownMaxAge = parseDuration(getEnv("LAST_KNOWN_MAX_AGE", "24h"))
+	
+	// Aggregation configuration
+	cfg.Aggregation.Weighting = strings.ToLower(getEnv("AGGREGATION_WEIGHTING", WeightingEqual))
+	cfg.Aggregation.SmoothingWindow = parseInt(getEnv("FORECAST_SMOOTHING_WINDOW", "3"))
+	cfg.Aggregation.ConditionAggregation = strings.ToLower(getEnv("CONDITION_AGGREGATION", ConditionAggregationFrequency))
+	cfg.Aggregation.ForecastAlignment = strings.ToLower(getEnv("FORECAST_ALIGNMENT", ForecastAlignmentDate))
+	cfg.Aggregation.PrimarySource = strings.ToLower(strings.TrimSpace(getEnv("PRIMARY_SOURCE", "")))
+	cfg.Aggregation.CoordinatesSource = strings.ToLower(strings.TrimSpace(getEnv("COORDINATES_SOURCE", "")))
+	cfg.Aggregation.NowBlendWeight = parseFloat(getEnv("NOW_BLEND_WEIGHT", "0.7"))
+	
+	// Temperature trend configuration
+	cfg.Trend.Window = parseDuration(getEnv("TREND_WINDOW", "3h"))
+	cfg.Trend.SteadyThreshold = parseFloat(getEnv("TREND_STEADY_THRESHOLD", "0.5"))
+	
+	// Best day scoring configuration
+	cfg.BestDay.TargetTemp = parseFloat(getEnv("BEST_DAY_TARGET_TEMP", "22"))
+	cfg.BestDay.TemperatureWeight = parseFloat(getEnv("BEST_DAY_TEMPERATURE_WEIGHT", "0.5"))
+	cfg.BestDay.PrecipitationWeight = parseFloat(getEnv("BEST_DAY_PRECIPITATION_WEIGHT", "0.3"))
+	cfg.BestDay.WindWeight = parseFloat(getEnv("BEST_DAY_WIND_WEIGHT", "0.2"))
+	
+	// History configuration
+	cfg.History.Enabled = parseBool(getEnv("HISTORY_ENABLED", "false"))
+	cfg.History.DBPath = getEnv("HISTORY_DB_PATH", "weather_history.db")
+	
+	// Stats persistence configuration
+	cfg.Stats.File = getEnv("STATS_FILE", "")
+	cfg.Stats.SaveInterval = parseDuration(getEnv("STATS_SAVE_INTERVAL", "5m"))
+	
+	// Outbound HTTP configuration
+	cfg.HTTP.MaxIdleConns = parseInt(getEnv("HTTP_MAX_IDLE_CONNS", "100"))
+	cfg.HTTP.MaxIdleConnsPerHost = parseInt(getEnv("HTTP_MAX_IDLE_CONNS_PER_HOST", "10"))
+	cfg.HTTP.IdleConnTimeout = parseDuration(getEnv("HTTP_IDLE_CONN_TIMEOUT", "90s"))
 	
 	// Circuit breaker configuration
 	cfg.CircuitBreaker.Threshold = parseInt(getEnv("CIRCUIT_BREAKER_THRESHOLD", "3"))
+	cfg.CircuitBreaker.MinRequests = parseInt(getEnv("CIRCUIT_BREAKER_MIN_REQUESTS", "3"))
+	cfg.CircuitBreaker.FailureRatio = parseFloat(getEnv("CIRCUIT_BREAKER_FAILURE_RATIO", "0.6"))
+	cfg.CircuitBreaker.SuccessThreshold = parseInt(getEnv("BREAKER_SUCCESS_THRESHOLD", "1"))
 	cfg.CircuitBreaker.Timeout = parseDuration(getEnv("CIRCUIT_BREAKER_TIMEOUT", "30s"))
 	
 	// Retry configuration
 	cfg.Retry.MaxRetries = parseInt(getEnv("MAX_RETRIES", "3"))
 	cfg.Retry.Delay = parseDuration(getEnv("RETRY_DELAY", "1s"))
 	cfg.Retry.Multiplier = parseFloat(getEnv("RETRY_MULTIPLIER", "2"))
+	cfg.Retry.MaxDelay = parseDuration(getEnv("RETRY_MAX_DELAY", "30s"))
+	
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	
 	return cfg, nil
 }
 
+func (c *Config) validate() error {
+	if c.API.RateLimit < 0 {
+		return fmt.Errorf("INBOUND_RATE_LIMIT must not be negative")
+	}
+	if c.API.ProviderHealthInterval <= 0 {
+		return fmt.Errorf("PROVIDER_HEALTH_MIN_INTERVAL must be positive")
+	}
+	if c.API.EmptyResultStatus != 404 && c.API.EmptyResultStatus != 200 {
+		return fmt.Errorf("EMPTY_RESULT_STATUS must be 404 or 200")
+	}
+	if c.API.MinSources < 1 {
+		return fmt.Errorf("MIN_SOURCES must be positive")
+	}
+	if c.API.OutputDecimals < 0 || c.API.OutputDecimals > 6 {
+		return fmt.Errorf("OUTPUT_DECIMALS must be between 0 and 6")
+	}
+	for _, origin := range c.CORS.AllowOrigins {
+		if err := validateOrigin(origin); err != nil {
+			return fmt.Errorf("CORS_ALLOW_ORIGINS: %w", err)
+		}
+	}
+	if c.Aggregation.Weighting != WeightingEqual && c.Aggregation.Weighting != WeightingAdaptive {
+		return fmt.Errorf("AGGREGATION_WEIGHTING must be %s or %s", WeightingEqual, WeightingAdaptive)
+	}
+	if c.Aggregation.ConditionAggregation != ConditionAggregationFrequency && c.Aggregation.ConditionAggregation != ConditionAggregationSeverity {
+		return fmt.Errorf("CONDITION_AGGREGATION must be %s or %s", ConditionAggregationFrequency, ConditionAggregationSeverity)
+	}
+	if c.Aggregation.ForecastAlignment != ForecastAlignmentDate && c.Aggregation.ForecastAlignment != ForecastAlignmentIndex {
+		return fmt.Errorf("FORECAST_ALIGNMENT must be %s or %s", ForecastAlignmentDate, ForecastAlignmentIndex)
+	}
+	if c.Aggregation.NowBlendWeight < 0 || c.Aggregation.NowBlendWeight > 1 {
+		return fmt.Errorf("NOW_BLEND_WEIGHT must be between 0 and 1")
+	}
+	if c.Aggregation.SmoothingWindow < 1 || c.Aggregation.SmoothingWindow%2 == 0 {
+		return fmt.Errorf("FORECAST_SMOOTHING_WINDOW must be a positive odd number")
+	}
+	if _, err := zap.ParseAtomicLevel(c.Server.LogLevel); err != nil {
+		return fmt.Errorf("LOG_LEVEL must be one of debug, info, warn, error")
+	}
+	if c.Server.LogFormat != LogFormatJSON && c.Server.LogFormat != LogFormatConsole {
+		return fmt.Errorf("LOG_FORMAT must be %s or %s", LogFormatJSON, LogFormatConsole)
+	}
+	if c.Cache.Backend != CacheBackendMemory && c.Cache.Backend != CacheBackendTiered {
+		return fmt.Errorf("CACHE_BACKEND must be %s or %s", CacheBackendMemory, CacheBackendTiered)
+	}
+	if c.Cache.PrefetchWindow < 0 || c.Cache.PrefetchWindow >= 1 {
+		return fmt.Errorf("CACHE_PREFETCH_WINDOW must be at least 0 and below 1")
+	}
+	if c.Cache.PrefetchMax < 1 {
+		return fmt.Errorf("CACHE_PREFETCH_MAX_CITIES must be positive")
+	}
+	if c.Cache.LastKnownMaxAge < 0 {
+		return fmt.Errorf("LAST_KNOWN_MAX_AGE must not be negative")
+	}
+	if c.WeatherAPI.ForecastDays < 1 || c.WeatherAPI.ForecastDays > 7 {
+		return fmt.Errorf("FORECAST_DAYS must be between 1 and 7")
+	}
+	for _, horizon := range c.Cache.ForecastHorizons {
+		if horizon < 1 || horizon > c.WeatherAPI.ForecastDays {
+			return fmt.Errorf("FORECAST_CACHE_HORIZONS must be between 1 and FORECAST_DAYS")
+		}
+	}
+	if c.WeatherAPI.FetchMode != FetchModeSequential && c.WeatherAPI.FetchMode != FetchModeParallel {
+		return fmt.Errorf("CLIENT_FETCH_MODE must be %s or %s", FetchModeSequential, FetchModeParallel)
+	}
+	if c.WeatherAPI.ProviderOrder != ProviderOrderPriority && c.WeatherAPI.ProviderOrder != ProviderOrderLatency {
+		return fmt.Errorf("PROVIDER_ORDER must be %s or %s", ProviderOrderPriority, ProviderOrderLatency)
+	}
+	if c.WeatherAPI.FetchTimeout <= 0 {
+		return fmt.Errorf("REQUEST_FETCH_TIMEOUT must be positive")
+	}
+	if c.WeatherAPI.PartialTimeout < 0 {
+		return fmt.Errorf("PARTIAL_RESPONSE_TIMEOUT must not be negative")
+	}
+	if c.Trend.Window <= 0 {
+		return fmt.Errorf("TREND_WINDOW must be positive")
+	}
+	if c.Trend.SteadyThreshold < 0 {
+		return fmt.Errorf("TREND_STEADY_THRESHOLD must not be negative")
+	}
+	if c.BestDay.TemperatureWeight < 0 || c.BestDay.PrecipitationWeight < 0 || c.BestDay.WindWeight < 0 {
+		return fmt.Errorf("BEST_DAY weights must not be negative")
+	}
+	if c.BestDay.TemperatureWeight+c.BestDay.PrecipitationWeight+c.BestDay.WindWeight == 0 {
+		return fmt.Errorf("BEST_DAY weights cannot all be 0")
+	}
+	if c.Stats.File != "" && c.Stats.SaveInterval <= 0 {
+		return fmt.Errorf("STATS_SAVE_INTERVAL must be positive")
+	}
+	if c.Scheduler.StaleThreshold <= 0 {
+		return fmt.Errorf("STALE_THRESHOLD must be positive")
+	}
+	if c.Scheduler.RetryAttempts < 0 {
+		return fmt.Errorf("SCHEDULER_RETRY_ATTEMPTS must not be negative")
+	}
+	if c.Scheduler.RetryAttempts > 0 && c.Scheduler.RetryDelay <= 0 {
+		return fmt.Errorf("SCHEDULER_RETRY_DELAY must be positive")
+	}
+	if c.Scheduler.StartJitter < 0 {
+		return fmt.Errorf("SCHEDULER_START_JITTER must not be negative")
+	}
+	if c.Scheduler.FetchTimeout <= 0 {
+		return fmt.Errorf("SCHEDULER_FETCH_TIMEOUT must be positive")
+	}
+	if c.Scheduler.DailySummaryAt < 0 {
+		return fmt.Errorf("DAILY_SUMMARY_TIME must be a time of day as HH:MM")
+	}
+	if c.CircuitBreaker.Threshold < 0 {
+		return fmt.Errorf("CIRCUIT_BREAKER_THRESHOLD must not be negative")
+	}
+	if c.CircuitBreaker.MinRequests < 1 {
+		return fmt.Errorf("CIRCUIT_BREAKER_MIN_REQUESTS must be positive")
+	}
+	if c.CircuitBreaker.FailureRatio < 0 || c.CircuitBreaker.FailureRatio > 1 {
+		return fmt.Errorf("CIRCUIT_BREAKER_FAILURE_RATIO must be between 0 and 1")
+	}
+	if c.CircuitBreaker.Threshold == 0 && c.CircuitBreaker.FailureRatio == 0 {
+		return fmt.Errorf("CIRCUIT_BREAKER_THRESHOLD and CIRCUIT_BREAKER_FAILURE_RATIO cannot both be 0")
+	}
+	if c.CircuitBreaker.SuccessThreshold < 1 {
+		return fmt.Errorf("BREAKER_SUCCESS_THRESHOLD must be positive")
+	}
+	if c.Retry.MaxDelay < c.Retry.Delay {
+		return fmt.Errorf("RETRY_MAX_DELAY must be at least RETRY_DELAY")
+	}
+	return nil
+}
+
+// validateOrigin accepts "*" or a bare scheme://host[:port] origin
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	
+	parsed, err := url.Parse(origin)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid origin %q, expected scheme://host[:port]", origin)
+	}
+	if parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.User != nil {
+		return fmt.Errorf("invalid origin %q, origins have no path, query or credentials", origin)
+	}
+	return nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -118,4 +481,60 @@ func parseFloat(value string) float64 {
 		return 0
 	}
 	return floatValue
+}
+
+// parseClock reads an HH:MM time of day as the offset from midnight, or -1
+// when value is not one so validate can reject it
+func parseClock(value string) time.Duration {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return -1
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+}
+
+// parseList splits a comma-separated value, dropping empty entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseIntList splits a comma-separated list of integers; entries that fail to
+// parse become 0 and are rejected by validation
+func parseIntList(value string) []int {
+	var items []int
+	for _, item := range parseList(value) {
+		items = append(items, parseInt(item))
+	}
+	return items
+}
+
+// parseAliases reads comma-separated alias=canonical pairs, keyed by the
+// alias normalized like every city name, see utils.NormalizeCity
+func parseAliases(value string) map[string]string {
+	aliases := make(map[string]string)
+	for _, pair := range parseList(value) {
+		alias, canonical, ok := strings.Cut(pair, "=")
+		alias, canonical = strings.TrimSpace(alias), strings.TrimSpace(canonical)
+		if !ok || alias == "" || canonical == "" {
+			zap.L().Warn("Ignoring malformed city alias", zap.String("value", pair))
+			continue
+		}
+		aliases[utils.NormalizeCity(alias)] = canonical
+	}
+	return aliases
+}
+
+func parseBool(value string) bool {
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		zap.L().Warn("Failed to parse bool", zap.String("value", value), zap.Error(err))
+		return false
+	}
+	return boolValue
 }
\ No newline at end of file
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadConfig loads the configuration with env set on top of the environment
+func loadConfig(t *testing.T, env map[string]string) (*Config, error) {
+	t.Helper()
+	
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return LoadConfig()
+}
+
+// assertRejected checks that loading with env fails naming setting
+func assertRejected(t *testing.T, env map[string]string, setting string) {
+	t.Helper()
+	
+	_, err := loadConfig(t, env)
+	if err == nil || !strings.Contains(err.Error(), setting) {
+		t.Errorf("LoadConfig with %v = %v, want an error about %s", env, err, setting)
+	}
+}
+
+func TestFetchTimeouts(t *testing.T) {
+	cfg, err := loadConfig(t, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.WeatherAPI.FetchTimeout != 30*time.Second || cfg.Scheduler.FetchTimeout != 60*time.Second {
+		t.Errorf("default timeouts = %v and %v, want 30s and 60s", cfg.WeatherAPI.FetchTimeout, cfg.Scheduler.FetchTimeout)
+	}
+	
+	cfg, err = loadConfig(t, map[string]string{"REQUEST_FETCH_TIMEOUT": "5s", "SCHEDULER_FETCH_TIMEOUT": "2m"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.WeatherAPI.FetchTimeout != 5*time.Second || cfg.Scheduler.FetchTimeout != 2*time.Minute {
+		t.Errorf("timeouts = %v and %v, want 5s and 2m", cfg.WeatherAPI.FetchTimeout, cfg.Scheduler.FetchTimeout)
+	}
+}
+
+func TestFetchTimeoutsMustBePositive(t *testing.T) {
+	for _, setting := range []string{"REQUEST_FETCH_TIMEOUT", "SCHEDULER_FETCH_TIMEOUT"} {
+		for _, value := range []string{"0s", "-1s", "soon"} {
+			t.Run(setting+"="+value, func(t *testing.T) {
+				assertRejected(t, map[string]string{setting: value}, setting)
+			})
+		}
+	}
+}
+
+func TestCORSOriginsMustBeWellFormed(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"CORS_ALLOW_ORIGINS": "https://app.example.com, http://localhost:3000"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.CORS.AllowOrigins) != 2 || cfg.CORS.AllowOrigins[1] != "http://localhost:3000" {
+		t.Errorf("AllowOrigins = %v, want both origins", cfg.CORS.AllowOrigins)
+	}
+	
+	for _, origin := range []string{"app.example.com", "ftp://example.com", "https://example.com/path", "https://user@example.com"} {
+		t.Run(origin, func(t *testing.T) {
+			assertRejected(t, map[string]string{"CORS_ALLOW_ORIGINS": origin}, "CORS_ALLOW_ORIGINS")
+		})
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	aliases := parseAliases("NYC=NewYork, New  York = NewYork,broken,=Prague,Praha=")
+	
+	want := map[string]string{"nyc": "NewYork", "new york": "NewYork"}
+	if len(aliases) != len(want) {
+		t.Fatalf("parseAliases = %v, want %v without the malformed pairs", aliases, want)
+	}
+	for alias, canonical := range want {
+		if aliases[alias] != canonical {
+			t.Errorf("alias %q = %q, want %q", alias, aliases[alias], canonical)
+		}
+	}
+}
+
+func TestTrendSettings(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"TREND_WINDOW": "90m", "TREND_STEADY_THRESHOLD": "0.25"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Trend.Window != 90*time.Minute || cfg.Trend.SteadyThreshold != 0.25 {
+		t.Errorf("trend window %v threshold %v, want 90m and 0.25", cfg.Trend.Window, cfg.Trend.SteadyThreshold)
+	}
+	
+	t.Run("TREND_WINDOW=0s", func(t *testing.T) {
+		assertRejected(t, map[string]string{"TREND_WINDOW": "0s"}, "TREND_WINDOW")
+	})
+	t.Run("TREND_STEADY_THRESHOLD=-1", func(t *testing.T) {
+		assertRejected(t, map[string]string{"TREND_STEADY_THRESHOLD": "-1"}, "TREND_STEADY_THRESHOLD")
+	})
+}
+
+func TestStaleThresholdDefaultsToTwoFetchIntervals(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"FETCH_INTERVAL": "10m"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Scheduler.StaleThreshold != 20*time.Minute {
+		t.Errorf("StaleThreshold = %v, want 20m", cfg.Scheduler.StaleThreshold)
+	}
+	
+	t.Run("STALE_THRESHOLD=0s", func(t *testing.T) {
+		assertRejected(t, map[string]string{"STALE_THRESHOLD": "0s"}, "STALE_THRESHOLD")
+	})
+}
+
+func TestForecastCacheHorizonsWithinForecastDays(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"FORECAST_CACHE_HORIZONS": "1, 3"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if len(cfg.Cache.ForecastHorizons) != 2 || cfg.Cache.ForecastHorizons[1] != 3 {
+		t.Errorf("ForecastHorizons = %v, want [1 3]", cfg.Cache.ForecastHorizons)
+	}
+	
+	for _, value := range []string{"0", "8", "3,9"} {
+		t.Run(value, func(t *testing.T) {
+			assertRejected(t, map[string]string{"FORECAST_CACHE_HORIZONS": value}, "FORECAST_CACHE_HORIZONS")
+		})
+	}
+}
+
+func TestOutputDecimalsRange(t *testing.T) {
+	cfg, err := loadConfig(t, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.API.OutputDecimals != 1 {
+		t.Errorf("default OutputDecimals = %d, want 1", cfg.API.OutputDecimals)
+	}
+	
+	for _, value := range []string{"-1", "7"} {
+		t.Run(value, func(t *testing.T) {
+			assertRejected(t, map[string]string{"OUTPUT_DECIMALS": value}, "OUTPUT_DECIMALS")
+		})
+	}
+}
+
+func TestConditionAggregation(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"CONDITION_AGGREGATION": "Severity"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Aggregation.ConditionAggregation != ConditionAggregationSeverity {
+		t.Errorf("ConditionAggregation = %q, want %q", cfg.Aggregation.ConditionAggregation, ConditionAggregationSeverity)
+	}
+	
+	assertRejected(t, map[string]string{"CONDITION_AGGREGATION": "worst"}, "CONDITION_AGGREGATION")
+}
+
+func TestRetryMaxDelayAtLeastBaseDelay(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"RETRY_DELAY": "2s", "RETRY_MAX_DELAY": "2s"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Retry.MaxDelay != 2*time.Second {
+		t.Errorf("MaxDelay = %v, want 2s", cfg.Retry.MaxDelay)
+	}
+	
+	assertRejected(t, map[string]string{"RETRY_DELAY": "2s", "RETRY_MAX_DELAY": "1s"}, "RETRY_MAX_DELAY")
+}
+
+func TestForecastAlignmentSetting(t *testing.T) {
+	cfg, err := loadConfig(t, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Aggregation.ForecastAlignment != ForecastAlignmentDate {
+		t.Errorf("default ForecastAlignment = %q, want %q", cfg.Aggregation.ForecastAlignment, ForecastAlignmentDate)
+	}
+	
+	assertRejected(t, map[string]string{"FORECAST_ALIGNMENT": "position"}, "FORECAST_ALIGNMENT")
+}
+
+func TestMinSourcesSetting(t *testing.T) {
+	cfg, err := loadConfig(t, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.API.MinSources != 1 {
+		t.Errorf("default MinSources = %d, want 1", cfg.API.MinSources)
+	}
+	
+	assertRejected(t, map[string]string{"MIN_SOURCES": "0"}, "MIN_SOURCES")
+}
+
+func TestCircuitBreakerSettings(t *testing.T) {
+	cfg, err := loadConfig(t, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	breaker := cfg.CircuitBreaker
+	if breaker.Threshold != 3 || breaker.MinRequests != 3 || breaker.FailureRatio != 0.6 {
+		t.Errorf("defaults = %d, %d, %v, want 3, 3, 0.6", breaker.Threshold, breaker.MinRequests, breaker.FailureRatio)
+	}
+	
+	tests := []struct {
+		name    string
+		env     map[string]string
+		setting string
+	}{
+		{"negative threshold", map[string]string{"CIRCUIT_BREAKER_THRESHOLD": "-1"}, "CIRCUIT_BREAKER_THRESHOLD"},
+		{"no min requests", map[string]string{"CIRCUIT_BREAKER_MIN_REQUESTS": "0"}, "CIRCUIT_BREAKER_MIN_REQUESTS"},
+		{"ratio above 1", map[string]string{"CIRCUIT_BREAKER_FAILURE_RATIO": "1.5"}, "CIRCUIT_BREAKER_FAILURE_RATIO"},
+		{"both disabled", map[string]string{"CIRCUIT_BREAKER_THRESHOLD": "0", "CIRCUIT_BREAKER_FAILURE_RATIO": "0"}, "cannot both be 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRejected(t, tt.env, tt.setting)
+		})
+	}
+}
+
+func TestBreakerSuccessThreshold(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"BREAKER_SUCCESS_THRESHOLD": "3"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.CircuitBreaker.SuccessThreshold != 3 {
+		t.Errorf("SuccessThreshold = %d, want 3", cfg.CircuitBreaker.SuccessThreshold)
+	}
+	
+	assertRejected(t, map[string]string{"BREAKER_SUCCESS_THRESHOLD": "0"}, "BREAKER_SUCCESS_THRESHOLD")
+}
+
+func TestNowBlendWeightRange(t *testing.T) {
+	cfg, err := loadConfig(t, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Aggregation.NowBlendWeight != 0.7 {
+		t.Errorf("default NowBlendWeight = %v, want 0.7", cfg.Aggregation.NowBlendWeight)
+	}
+	
+	for _, value := range []string{"-0.1", "1.1"} {
+		t.Run(value, func(t *testing.T) {
+			assertRejected(t, map[string]string{"NOW_BLEND_WEIGHT": value}, "NOW_BLEND_WEIGHT")
+		})
+	}
+}
+
+func TestBestDayWeights(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"BEST_DAY_TARGET_TEMP": "18", "BEST_DAY_WIND_WEIGHT": "0"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.BestDay.TargetTemp != 18 || cfg.BestDay.WindWeight != 0 || cfg.BestDay.TemperatureWeight != 0.5 {
+		t.Errorf("BestDay = %+v, want target 18 and wind weight 0 over the defaults", cfg.BestDay)
+	}
+	
+	t.Run("negative", func(t *testing.T) {
+		assertRejected(t, map[string]string{"BEST_DAY_PRECIPITATION_WEIGHT": "-1"}, "BEST_DAY")
+	})
+	t.Run("all zero", func(t *testing.T) {
+		assertRejected(t, map[string]string{"BEST_DAY_TEMPERATURE_WEIGHT": "0", "BEST_DAY_PRECIPITATION_WEIGHT": "0", "BEST_DAY_WIND_WEIGHT": "0"}, "BEST_DAY")
+	})
+}
+
+func TestCachePrefix(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"CACHE_PREFIX": " staging "})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Cache.Prefix != "staging" {
+		t.Errorf("Prefix = %q, want staging trimmed", cfg.Cache.Prefix)
+	}
+}
+
+func TestDailySummaryTime(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"DAILY_SUMMARY_TIME": "07:30"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Scheduler.DailySummaryAt != 7*time.Hour+30*time.Minute {
+		t.Errorf("DailySummaryAt = %v, want 7h30m", cfg.Scheduler.DailySummaryAt)
+	}
+	
+	for _, value := range []string{"25:00", "6am"} {
+		t.Run(value, func(t *testing.T) {
+			assertRejected(t, map[string]string{"DAILY_SUMMARY_TIME": value}, "DAILY_SUMMARY_TIME")
+		})
+	}
+}
+
+func TestProviderOrder(t *testing.T) {
+	cfg, err := loadConfig(t, map[string]string{"PROVIDER_PRIORITY": "OpenMeteo, ,openweather", "PROVIDER_ORDER": "Latency"})
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got := cfg.WeatherAPI.ProviderPriority; len(got) != 2 || got[0] != "openmeteo" || got[1] != "openweather" {
+		t.Errorf("ProviderPriority = %v, want [openmeteo openweather]", got)
+	}
+	if cfg.WeatherAPI.ProviderOrder != ProviderOrderLatency {
+		t.Errorf("ProviderOrder = %q, want %q", cfg.WeatherAPI.ProviderOrder, ProviderOrderLatency)
+	}
+	
+	assertRejected(t, map[string]string{"PROVIDER_ORDER": "fastest"}, "PROVIDER_ORDER")
+}
+
+func TestPartialResponseTimeout(t *testing.T) {
+	cfg, err := loadConfig(t, nil)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.WeatherAPI.PartialTimeout != 0 {
+		t.Errorf("default PartialTimeout = %v, want 0 to wait for every provider", cfg.WeatherAPI.PartialTimeout)
+	}
+	
+	assertRejected(t, map[string]string{"PARTIAL_RESPONSE_TIMEOUT": "-1s"}, "PARTIAL_RESPONSE_TIMEOUT")
+}
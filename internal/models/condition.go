@@ -0,0 +1,43 @@
+package models
+
+// ConditionCode is a provider-independent weather condition that each client
+// maps its native condition IDs or WMO codes into
+type ConditionCode string
+
+const (
+	ConditionUnknown      ConditionCode = "unknown"
+	ConditionClear        ConditionCode = "clear"
+	ConditionClouds       ConditionCode = "clouds"
+	ConditionFog          ConditionCode = "fog"
+	ConditionDrizzle      ConditionCode = "drizzle"
+	ConditionRain         ConditionCode = "rain"
+	ConditionSnow         ConditionCode = "snow"
+	ConditionThunderstorm ConditionCode = "thunderstorm"
+)
+
+// conditionSeverity orders the conditions from mildest to most severe
+var conditionSeverity = map[ConditionCode]int{
+	ConditionClear:        1,
+	ConditionClouds:       2,
+	ConditionFog:          3,
+	ConditionDrizzle:      4,
+	ConditionRain:         5,
+	ConditionSnow:         6,
+	ConditionThunderstorm: 7,
+}
+
+// Severity ranks the condition against the others, higher is more severe.
+// Unknown conditions rank below all of them.
+func (c ConditionCode) Severity() int {
+	return conditionSeverity[c]
+}
+
+// PrecipitationType is the kind of precipitation currently falling
+type PrecipitationType string
+
+const (
+	PrecipitationNone  PrecipitationType = "none"
+	PrecipitationRain  PrecipitationType = "rain"
+	PrecipitationSleet PrecipitationType = "sleet"
+	PrecipitationSnow  PrecipitationType = "snow"
+)
\ No newline at end of file
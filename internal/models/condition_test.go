@@ -0,0 +1,23 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestConditionSeverity(t *testing.T) {
+	ordered := []ConditionCode{
+		ConditionUnknown,
+		ConditionClear,
+		ConditionClouds,
+		ConditionFog,
+		ConditionDrizzle,
+		ConditionRain,
+		ConditionSnow,
+		ConditionThunderstorm,
+	}
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i].Severity() <= ordered[i-1].Severity() {
+			t.Errorf("%s ranks %d, want above %s at %d", ordered[i], ordered[i].Severity(), ordered[i-1], ordered[i-1].Severity())
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+const DefaultLang = "en"
+
+// QueryOptions carries per-request settings that change what is fetched from
+// the providers and therefore where the result is cached
+type QueryOptions struct {
+	Lang string
+	Country string // ISO 3166-1 alpha-2 code disambiguating the city, e.g. "CA"
+	PressureUnit PressureUnit
+	Units UnitSystem
+	PrecipUnit PrecipitationUnit // overrides the precipitation unit of Units
+	Smooth bool // moving-average the forecast days
+	Sources []string // restricts fetching and aggregation to these providers, sorted; empty uses all
+	
+	// MaxAge forces a fresh fetch when the cached entry is older; it does not
+	// change the cache key
+	MaxAge time.Duration
+	
+	// Timestamps keeps the per-source observation times in current weather,
+	// they are cached either way
+	Timestamps bool
+	
+	// Blend moves current weather toward the hourly forecast for the
+	// observation time; applied after the cache lookup
+	Blend bool
+	
+	// Breakdown adds each provider's reading to current weather, in the
+	// provider's native units with RawUnits; applied after the cache lookup
+	Breakdown bool
+	RawUnits  bool
+}
+
+func (o QueryOptions) LangOrDefault() string {
+	if o.Lang == "" {
+		return DefaultLang
+	}
+	return o.Lang
+}
+
+func (o QueryOptions) PressureUnitOrDefault() PressureUnit {
+	if o.PressureUnit == "" {
+		return PressureHPa
+	}
+	return o.PressureUnit
+}
+
+func (o QueryOptions) UnitsOrDefault() UnitSystem {
+	if o.Units == "" {
+		return UnitsMetric
+	}
+	return o.Units
+}
+
+// PrecipitationUnitOrDefault is the explicit override, otherwise the unit of the unit system
+func (o QueryOptions) PrecipitationUnitOrDefault() PrecipitationUnit {
+	if o.PrecipUnit != "" {
+		return o.PrecipUnit
+	}
+	if o.UnitsOrDefault() == UnitsImperial {
+		return PrecipitationInches
+	}
+	return PrecipitationMM
+}
\ No newline at end of file
@@ -0,0 +1,99 @@
+package models
+
+type PressureUnit string
+
+const (
+	PressureHPa  PressureUnit = "hpa"
+	PressureInHg PressureUnit = "inhg"
+	PressureMmHg PressureUnit = "mmhg"
+)
+
+// Label is the unit's symbol, as shown in unit_labels
+func (u PressureUnit) Label() string {
+	switch u {
+	case PressureInHg:
+		return "inHg"
+	case PressureMmHg:
+		return "mmHg"
+	default:
+		return "hPa"
+	}
+}
+
+func ParsePressureUnit(value string) (PressureUnit, bool) {
+	switch unit := PressureUnit(value); unit {
+	case PressureHPa, PressureInHg, PressureMmHg:
+		return unit, true
+	default:
+		return "", false
+	}
+}
+
+// NativeUnit is the unit a provider reports a measurement in when it differs
+// from the normalized one
+type NativeUnit struct {
+	Label  string
+	Factor float64 // native value per normalized unit, e.g. 3.6 for m/s to km/h
+}
+
+// UnitSystem selects the units of temperatures, wind speeds and precipitation
+type UnitSystem string
+
+const (
+	UnitsMetric   UnitSystem = "metric"   // °C, m/s, mm
+	UnitsImperial UnitSystem = "imperial" // °F, mph, inches
+	UnitsStandard UnitSystem = "standard" // K, m/s, mm
+)
+
+// TemperatureLabel is the symbol of the system's temperature unit
+func (u UnitSystem) TemperatureLabel() string {
+	switch u {
+	case UnitsImperial:
+		return "°F"
+	case UnitsStandard:
+		return "K"
+	default:
+		return "°C"
+	}
+}
+
+// WindSpeedLabel is the symbol of the system's wind speed unit
+func (u UnitSystem) WindSpeedLabel() string {
+	if u == UnitsImperial {
+		return "mph"
+	}
+	return "m/s"
+}
+
+func ParseUnitSystem(value string) (UnitSystem, bool) {
+	switch units := UnitSystem(value); units {
+	case UnitsMetric, UnitsImperial, UnitsStandard:
+		return units, true
+	default:
+		return "", false
+	}
+}
+
+type PrecipitationUnit string
+
+const (
+	PrecipitationMM     PrecipitationUnit = "mm"
+	PrecipitationInches PrecipitationUnit = "in"
+)
+
+// Label is the unit's symbol, as shown in unit_labels
+func (u PrecipitationUnit) Label() string {
+	if u == PrecipitationInches {
+		return "in"
+	}
+	return "mm"
+}
+
+func ParsePrecipitationUnit(value string) (PrecipitationUnit, bool) {
+	switch unit := PrecipitationUnit(value); unit {
+	case PrecipitationMM, PrecipitationInches:
+		return unit, true
+	default:
+		return "", false
+	}
+}
\ No newline at end of file
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -12,10 +13,17 @@ type CurrentWeather struct {
 	Pressure    float64   `json:"pressure"`
 	WindSpeed   float64   `json:"wind_speed"`
 	WindDegree  float64   `json:"wind_degree"`
+	WindGust    float64   `json:"wind_gust"` // zero when the provider reports no gusts
+	CloudCover  float64   `json:"cloud_cover"` // percent, 0-100
+	Visibility  *float64  `json:"visibility,omitempty"` // meters, nil when the provider omits it
+	Condition   ConditionCode `json:"condition"`
 	Description string    `json:"description"`
 	Icon        string    `json:"icon"`
 	Timestamp   time.Time `json:"timestamp"`
 	Source      string    `json:"source"`
+	ResolvedLatitude  float64 `json:"resolved_latitude"`
+	ResolvedLongitude float64 `json:"resolved_longitude"`
+	DistanceKm  float64   `json:"distance_km"`
 }
 
 type ForecastDay struct {
@@ -24,29 +32,118 @@ type ForecastDay struct {
 	MinTemp     float64   `json:"min_temp"`
 	AvgTemp     float64   `json:"avg_temp"`
 	Humidity    float64   `json:"humidity"`
+	Condition   ConditionCode `json:"condition"`
 	Description string    `json:"description"`
 	Icon        string    `json:"icon"`
-	Precipitation float64 `json:"precipitation"`
+	Precipitation float64 `json:"precipitation"` // mm, liquid water equivalent
+	RainSum     float64   `json:"rain_sum"`     // mm
+	SnowfallSum float64   `json:"snowfall_sum"` // mm, liquid water equivalent
+	HasPrecipitationSums bool `json:"-"` // the source reports RainSum and SnowfallSum
+	WindGust    float64   `json:"wind_gust"` // strongest gust of the day
+	MoonPhase   MoonPhase `json:"moon_phase"`
+	Confidence  float64   `json:"confidence,omitempty"` // set on aggregated days only
+}
+
+type MoonPhase struct {
+	Value float64 `json:"value"` // fraction of the lunar cycle, 0 new moon, 0.5 full moon
+	Name  string  `json:"name"`
+}
+
+type HourlyPoint struct {
+	Time        time.Time `json:"time"`
+	Temperature float64   `json:"temperature"`
+	Humidity    float64   `json:"humidity"`
+	WindSpeed   float64   `json:"wind_speed"`
+	PrecipitationProbability float64 `json:"precipitation_probability"` // percent, 0-100
+	Condition   ConditionCode `json:"condition"`
+	Description string    `json:"description"`
 }
 
 type WeatherForecast struct {
 	City     string       `json:"city"`
 	Forecast []ForecastDay `json:"forecast"`
+	Hourly   []HourlyPoint `json:"hourly"` // ordered by time, spacing depends on the provider
 	Source   string       `json:"source"`
 }
 
+// PointForecast is the forecast interpolated to a single moment
+type PointForecast struct {
+	City        string    `json:"city"`
+	Time        time.Time `json:"time"`
+	Temperature float64   `json:"temperature"`
+	Humidity    float64   `json:"humidity"`
+	WindSpeed   float64   `json:"wind_speed"`
+	PrecipitationProbability float64 `json:"precipitation_probability"`
+	Condition   ConditionCode `json:"condition"`
+	Description string    `json:"description"`
+	Sources     []string  `json:"sources"`
+	Units       UnitSystem `json:"units"`
+	UnitLabels  map[string]string `json:"unit_labels"` // measurement -> unit symbol
+}
+
 type AggregatedCurrentWeather struct {
 	City        string    `json:"city"`
 	Temperature float64   `json:"temperature"`
 	FeelsLike   float64   `json:"feels_like"`
+	HeatIndex   *float64  `json:"heat_index,omitempty"` // only from 26.7°C up
+	WindChill   *float64  `json:"wind_chill,omitempty"` // only at or below 10°C with wind
 	Humidity    float64   `json:"humidity"`
 	Pressure    float64   `json:"pressure"`
 	WindSpeed   float64   `json:"wind_speed"`
+	WindGust    float64   `json:"wind_gust"`
+	CloudCover  float64   `json:"cloud_cover"`
+	Visibility  *float64  `json:"visibility,omitempty"` // meters
+	Condition   ConditionCode `json:"condition"`
+	PrecipitationType PrecipitationType `json:"precipitation_type"`
+	PrecipitationProbability *float64 `json:"precipitation_probability,omitempty"` // only with blend=true
+	TemperatureTrend *TemperatureTrend `json:"temperature_trend,omitempty"` // omitted until enough readings are recorded
 	Description string    `json:"description"`
 	Icon        string    `json:"icon"`
 	LastUpdated time.Time `json:"last_updated"`
 	Sources     []string  `json:"sources"`
+	SourceTimestamps map[string]time.Time `json:"source_timestamps,omitempty"` // source -> observation time, only with timestamps=true
 	Confidence  float64   `json:"confidence"`
+	SourceCount int       `json:"source_count"`
+	Degraded    bool      `json:"degraded"` // only a single source contributed
+	Stale       bool      `json:"stale,omitempty"` // last known data served because every provider failed
+	Blended     bool      `json:"blended,omitempty"` // moved toward the hourly forecast, see blend=true
+	Partial     bool      `json:"partial,omitempty"` // aggregated before every provider answered
+	Breakdown   map[string]SourceReading `json:"breakdown,omitempty"` // source -> its reading, only with breakdown=true
+	ResolvedLatitude  float64 `json:"resolved_latitude"`
+	ResolvedLongitude float64 `json:"resolved_longitude"`
+	DistanceKm  float64   `json:"distance_km"`
+	Units       UnitSystem `json:"units"`
+	UnitLabels  map[string]string `json:"unit_labels"` // measurement -> unit symbol
+}
+
+// SourceReading is one provider's current weather as it entered the aggregate
+type SourceReading struct {
+	Temperature float64   `json:"temperature"`
+	FeelsLike   float64   `json:"feels_like"`
+	Humidity    float64   `json:"humidity"`
+	Pressure    float64   `json:"pressure"`
+	WindSpeed   float64   `json:"wind_speed"`
+	WindGust    float64   `json:"wind_gust"`
+	CloudCover  float64   `json:"cloud_cover"`
+	Visibility  *float64  `json:"visibility,omitempty"`
+	Condition   ConditionCode `json:"condition"`
+	Description string    `json:"description"`
+	Timestamp   time.Time `json:"timestamp"`
+	UnitLabels  map[string]string `json:"unit_labels"` // measurement -> unit symbol
+}
+
+type TrendDirection string
+
+const (
+	TrendRising  TrendDirection = "rising"
+	TrendFalling TrendDirection = "falling"
+	TrendSteady  TrendDirection = "steady"
+)
+
+// TemperatureTrend is the short-term change of the aggregated temperature
+type TemperatureTrend struct {
+	Direction   TrendDirection `json:"direction"`
+	RatePerHour float64        `json:"rate_per_hour"` // negative when falling
 }
 
 type AggregatedForecast struct {
@@ -54,6 +151,182 @@ type AggregatedForecast struct {
 	Days     []ForecastDay `json:"days"`
 	LastUpdated time.Time  `json:"last_updated"`
 	Sources  []string      `json:"sources"`
+	Confidence float64     `json:"confidence"` // mean of the daily confidences
+	Partial  bool          `json:"partial,omitempty"` // aggregated before every provider answered
+	Units    UnitSystem    `json:"units"`
+	UnitLabels map[string]string `json:"unit_labels"` // measurement -> unit symbol
+}
+
+type MultiHorizonForecast struct {
+	City     string                      `json:"city"`
+	Forecast *AggregatedForecast         `json:"forecast"`
+	Horizons map[int]*AggregatedForecast `json:"horizons"`
+}
+
+// CityCurrentWeather is one entry of the all-cities listing
+type CityCurrentWeather struct {
+	City    string                    `json:"city"`
+	Weather *AggregatedCurrentWeather `json:"weather,omitempty"`
+	Stale   bool                      `json:"stale"`           // cache entry expired, data is from the last fetch
+	Error   string                    `json:"error,omitempty"` // nothing fetched yet
+}
+
+type WeatherSummary struct {
+	City        string    `json:"city"`
+	Lang        string    `json:"lang"` // language actually used, English when unsupported
+	Summary     string    `json:"summary"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// ScoredDay is an aggregated forecast day with its comfort score
+type ScoredDay struct {
+	ForecastDay
+	Score float64 `json:"score"` // 0-100, higher is nicer
+}
+
+type BestDay struct {
+	City        string      `json:"city"`
+	Best        ScoredDay   `json:"best"`
+	Days        []ScoredDay `json:"days"` // in date order
+	Sources     []string    `json:"sources"`
+	LastUpdated time.Time   `json:"last_updated"`
+	Units       UnitSystem  `json:"units"`
+	UnitLabels  map[string]string `json:"unit_labels"`
+}
+
+// DailySummary is the precomputed outlook of a city's current day, in metric
+type DailySummary struct {
+	City        string        `json:"city"`
+	Date        time.Time     `json:"date"`
+	High        float64       `json:"high"`
+	Low         float64       `json:"low"`
+	Condition   ConditionCode `json:"condition"` // dominant condition of the day
+	Description string        `json:"description"`
+	Sources     []string      `json:"sources"`
+	ComputedAt  time.Time     `json:"computed_at"`
+	Units       UnitSystem    `json:"units"`
+	UnitLabels  map[string]string `json:"unit_labels"` // measurement -> unit symbol
+}
+
+type WeatherComparison struct {
+	Cities  []string                             `json:"cities"`
+	Weather map[string]*AggregatedCurrentWeather `json:"weather"`
+	Diff    *WeatherDiff                         `json:"diff,omitempty"`   // only when both cities succeeded
+	Errors  map[string]string                    `json:"errors,omitempty"` // city -> failure reason
+}
+
+// WeatherDiff compares the first city against the second
+type WeatherDiff struct {
+	TemperatureDelta float64 `json:"temperature_delta"`
+	HumidityDelta    float64 `json:"humidity_delta"`
+	Warmer           string  `json:"warmer"` // empty when equal
+}
+
+// TrendPoint is one stored aggregated reading
+type TrendPoint struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Temperature float64   `json:"temperature"`
+	FeelsLike   float64   `json:"feels_like"`
+	Humidity    float64   `json:"humidity"`
+	Pressure    float64   `json:"pressure"`
+	WindSpeed   float64   `json:"wind_speed"`
+	Confidence  float64   `json:"confidence"`
+	SourceCount int       `json:"source_count"`
+}
+
+type WeatherTrend struct {
+	City   string       `json:"city"`
+	From   time.Time    `json:"from"`
+	To     time.Time    `json:"to"`
+	Points []TrendPoint `json:"points"`
+}
+
+type ProviderStatus struct {
+	Name           string  `json:"name"`
+	RequiresAPIKey bool    `json:"requires_api_key"`
+	Enabled        bool    `json:"enabled"`
+	BreakerState   string  `json:"breaker_state"`
+	SuccessRate    float64 `json:"success_rate"`
+	Successes      int     `json:"successes"`
+	Failures       int     `json:"failures"`
+	LatencyMs      float64 `json:"latency_ms"` // decayed average of successful fetches, 0 until the first
+}
+
+// ProviderUsage counts the upstream calls made to a provider, for operators
+// on metered plans
+type ProviderUsage struct {
+	Name string      `json:"name"`
+	Hour UsageWindow `json:"hour"` // current UTC hour
+	Day  UsageWindow `json:"day"`  // current UTC day
+	Keys []APIKeyUsage `json:"keys,omitempty"` // per configured API key, for providers rotating several
+}
+
+// APIKeyUsage counts the requests made with one API key since startup. Keys
+// are identified by their position in the configured list, never by value.
+type APIKeyUsage struct {
+	Key          int        `json:"key"` // 1-based
+	Requests     int64      `json:"requests"`
+	Rejections   int64      `json:"rejections"` // answered with 401 or 429
+	SkippedUntil *time.Time `json:"skipped_until,omitempty"` // set while the key cools down after a rejection
+}
+
+// UsageWindow is the number of calls made since Start
+type UsageWindow struct {
+	Start time.Time `json:"start"`
+	Calls int64     `json:"calls"`
+}
+
+type ProviderHealthStatus string
+
+const (
+	ProviderUp   ProviderHealthStatus = "up"
+	ProviderDown ProviderHealthStatus = "down"
+)
+
+// ProviderHealth is the outcome of actively probing one provider
+type ProviderHealth struct {
+	Name         string               `json:"name"`
+	Status       ProviderHealthStatus `json:"status"`
+	LatencyMs    int64                `json:"latency_ms"` // zero when no request was made
+	BreakerState string               `json:"breaker_state"`
+	Error        string               `json:"error,omitempty"`
+}
+
+// GeocodeMatch is one candidate city of a free-text search
+type GeocodeMatch struct {
+	Name        string  `json:"name"`
+	Region      string  `json:"region,omitempty"`
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code"`
+	Latitude    float64 `json:"lat"`
+	Longitude   float64 `json:"lon"`
+	Population  int     `json:"population,omitempty"`
+}
+
+// CacheEntryInfo describes a cache entry without its payload
+type CacheEntryInfo struct {
+	Key         string    `json:"key"`
+	City        string    `json:"city"` // normalized
+	Type        string    `json:"type"` // current or forecast
+	Derived     bool      `json:"derived"` // a presentation variant, e.g. other units
+	StoredAt    time.Time `json:"stored_at"`
+	Age         int64     `json:"age"` // seconds since stored
+	ExpiresAt   time.Time `json:"expires_at"`
+	ExpiresIn   int64     `json:"expires_in"` // seconds
+	LastUpdated time.Time `json:"last_updated"` // of the data itself
+}
+
+// RawProviderResponse collects the untouched responses of one provider
+type RawProviderResponse struct {
+	Source    string        `json:"source"`
+	Responses []RawResponse `json:"responses"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type RawResponse struct {
+	URL    string          `json:"url"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"` // a JSON string when the provider did not return JSON
 }
 
 type APIResponse struct {
@@ -61,11 +334,15 @@ type APIResponse struct {
 	Forecast *WeatherForecast
 	Error    error
 	Source   string
+	Latency  time.Duration // time the source took to answer
 }
 
 type WeatherData struct {
-	City      string
+	City      string // display name, see displayCityName
+	Query     string // city as requested, which the geocoder results are keyed by
+	Country   string // country the request was restricted to, if any
 	Current   map[string]*CurrentWeather  // source -> current weather
 	Forecasts map[string]*WeatherForecast // source -> forecast
 	Timestamp time.Time
+	Partial   bool // some providers had not answered by the soft deadline
 }
\ No newline at end of file
@@ -2,10 +2,12 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"math/rand"
 	"sync"
 	"time"
 
-	"weather-aggregator/internal/services"
+	"github.com/bobby-s-dev/weather-aggregator/internal/services"
 	"go.uber.org/zap"
 )
 
@@ -14,24 +16,65 @@ type Scheduler struct {
 	logger         *zap.Logger
 	cities         []string
 	interval       time.Duration
+	fetchTimeout   time.Duration
 	ticker         *time.Ticker
-	stop           chan bool
+	stop           chan struct{} // closed by Stop to end the run loop, pending retries and the daily summaries
+	ctx            context.Context    // parent of every fetch, cancelled by Stop
+	cancel         context.CancelFunc
+	inFlight       sync.WaitGroup     // goroutines of the scheduler, Stop waits for them
 	running        bool
 	mu             sync.Mutex
 	lastRun        time.Time
 	nextRun        time.Time
 	skipIfRunning  bool
+	retryAttempts  int           // retries of the failed cities per run, 0 disables
+	retryDelay     time.Duration // before the first retry, doubled for each further one
+	retries        retryStats
+	startJitter    time.Duration // first fetch is delayed by up to this, 0 disables
+	spreadCities   bool          // stagger the cities of a run across the interval
+	randomDelay    func(max time.Duration) time.Duration
+	dailySummaryAt time.Duration // offset from midnight UTC the daily summaries are computed at
+	nextSummary    time.Time
 }
 
-func NewScheduler(aggregator *services.Aggregator, cities []string, interval time.Duration, logger *zap.Logger) *Scheduler {
+// retryStats tracks the retries of cities that failed a scheduled fetch
+type retryStats struct {
+	pending   []string // cities still waiting for a retry
+	attempts  int
+	recovered int // cities fetched by a retry
+	exhausted int // cities still failing when their run gave up
+}
+
+func NewScheduler(aggregator *services.Aggregator, cities []string, interval, fetchTimeout time.Duration, retryAttempts int, retryDelay, startJitter time.Duration, spreadCities bool, dailySummaryAt time.Duration, logger *zap.Logger) *Scheduler {
+	aggregator.SetTrackedCities(cities)
+	
 	return &Scheduler{
 		aggregator:    aggregator,
 		logger:        logger,
+		ctx:           context.Background(),
 		cities:        cities,
 		interval:      interval,
-		stop:          make(chan bool),
+		fetchTimeout:  fetchTimeout,
 		skipIfRunning: true,
+		retryAttempts: retryAttempts,
+		retryDelay:    retryDelay,
+		startJitter:   startJitter,
+		spreadCities:  spreadCities,
+		randomDelay:   randomDelay,
+		dailySummaryAt: dailySummaryAt,
+	}
+}
+
+// spreadShare is the part of the interval a spread run's cities are staggered
+// across, the rest is left to retries before the next run
+const spreadShare = 2
+
+// randomDelay returns a uniformly random duration between 0 and max
+func randomDelay(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
 	}
+	return time.Duration(rand.Int63n(int64(max) + 1))
 }
 
 func (s *Scheduler) Start() {
@@ -41,31 +84,76 @@ func (s *Scheduler) Start() {
 		return
 	}
 	s.running = true
-	s.mu.Unlock()
+	s.stop = make(chan struct{})
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	stop := s.stop
 	
-	s.ticker = time.NewTicker(s.interval)
-	s.nextRun = time.Now().Add(s.interval)
+	// Instances started together would otherwise all hit the providers at once
+	delay := s.randomDelay(s.startJitter)
+	s.nextRun = time.Now().Add(delay)
+	s.mu.Unlock()
 	
 	s.logger.Info("Scheduler started",
 		zap.Duration("interval", s.interval),
+		zap.Duration("start_delay", delay),
 		zap.Time("next_run", s.nextRun))
 	
-	// Run immediately on start
-	go s.runFetch()
-	
 	// Start the scheduler loop
-	go s.run()
+	s.spawn(func() { s.run(delay, stop) })
+	s.spawn(func() { s.runDailySummaries(stop) })
+}
+
+// spawn runs f in a goroutine Stop waits for
+func (s *Scheduler) spawn(f func()) {
+	s.inFlight.Add(1)
+	go func() {
+		defer s.inFlight.Done()
+		f()
+	}()
 }
 
-func (s *Scheduler) run() {
+// fetchContext returns the context of one fetch, bounded by the fetch timeout
+// and cancelled by Stop so that no fetch outlives the scheduler
+func (s *Scheduler) fetchContext() (context.Context, context.CancelFunc) {
+	s.mu.Lock()
+	parent := s.ctx
+	s.mu.Unlock()
+	return context.WithTimeout(parent, s.fetchTimeout)
+}
+
+// run fetches after delay and then on every tick until stop is closed
+func (s *Scheduler) run(delay time.Duration, stop <-chan struct{}) {
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-stop:
+			return
+		}
+	}
+	
+	ticker := time.NewTicker(s.interval)
+	s.mu.Lock()
+	s.ticker = ticker
+	s.nextRun = time.Now().Add(s.interval)
+	s.mu.Unlock()
+	
+	// Run immediately on start, summarizing the fresh data so the daily
+	// summaries do not wait for their first scheduled time
+	s.spawn(func() {
+		s.runFetch()
+		s.computeDailySummaries()
+	})
+	
 	for {
 		select {
-		case <-s.ticker.C:
+		case <-ticker.C:
+			s.mu.Lock()
 			s.nextRun = time.Now().Add(s.interval)
+			s.mu.Unlock()
 			s.logger.Debug("Scheduler tick", zap.Time("next_run", s.nextRun))
-			go s.runFetch()
-		case <-s.stop:
-			s.ticker.Stop()
+			s.spawn(s.runFetch)
+		case <-stop:
+			ticker.Stop()
 			return
 		}
 	}
@@ -89,35 +177,190 @@ func (s *Scheduler) runFetch() {
 		zap.Time("start_time", startTime),
 		zap.Strings("cities", s.cities))
 	
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	var err error
+	if s.spreadCities && len(s.cities) > 1 {
+		err = s.fetchSpread(s.cities)
+	} else {
+		ctx, cancel := s.fetchContext()
+		err = s.aggregator.FetchWeatherData(ctx, s.cities)
+		cancel()
+	}
 	
-	if err := s.aggregator.FetchWeatherData(ctx, s.cities); err != nil {
+	if errors.Is(err, services.ErrMaintenance) {
+		s.logger.Info("Scheduled weather fetch skipped during maintenance")
+	} else if err != nil {
 		s.logger.Error("Scheduled weather fetch failed",
 			zap.Error(err),
 			zap.Duration("duration", time.Since(startTime)))
+		
+		var fetchErr *services.FetchError
+		if errors.As(err, &fetchErr) {
+			s.retryFailed(fetchErr.Cities)
+		}
 	} else {
 		s.logger.Info("Scheduled weather fetch completed",
 			zap.Duration("duration", time.Since(startTime)))
 	}
 }
 
-func (s *Scheduler) Stop() {
+// fetchSpread fetches the cities one at a time, evenly spaced across the first
+// part of the interval, so each city keeps its own phase instead of all of
+// them hitting the providers on the tick, and retries still have time before
+// the next run. Failed cities are reported in one FetchError.
+func (s *Scheduler) fetchSpread(cities []string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	stop := s.stop
+	s.mu.Unlock()
 	
-	if !s.running {
+	step := s.interval / spreadShare / time.Duration(len(cities))
+	var failed []string
+	for i, city := range cities {
+		if i > 0 {
+			select {
+			case <-time.After(step):
+			case <-stop:
+				return nil
+			}
+		}
+		
+		ctx, cancel := s.fetchContext()
+		err := s.aggregator.FetchWeatherData(ctx, []string{city})
+		cancel()
+		
+		if errors.Is(err, services.ErrMaintenance) {
+			return err
+		}
+		if err != nil {
+			failed = append(failed, city)
+		}
+	}
+	
+	if len(failed) > 0 {
+		return &services.FetchError{Cities: failed}
+	}
+	return nil
+}
+
+// retryFailed re-fetches the cities that failed a run with a doubling delay,
+// until they succeed, the attempts run out or the next regular run is due
+func (s *Scheduler) retryFailed(cities []string) {
+	s.mu.Lock()
+	stop := s.stop
+	s.retries.pending = cities
+	s.mu.Unlock()
+	
+	delay := s.retryDelay
+	for attempt := 1; attempt <= s.retryAttempts && len(cities) > 0; attempt++ {
+		s.mu.Lock()
+		nextRun := s.nextRun
+		s.mu.Unlock()
+		if time.Now().Add(delay).After(nextRun) {
+			break
+		}
+		
+		select {
+		case <-time.After(delay):
+		case <-stop:
+			return
+		}
+		delay *= 2
+		
+		s.logger.Info("Retrying cities that failed the scheduled fetch",
+			zap.Strings("cities", cities),
+			zap.Int("attempt", attempt))
+		
+		ctx, cancel := s.fetchContext()
+		err := s.aggregator.FetchWeatherData(ctx, cities)
+		cancel()
+		
+		remaining := cities
+		var fetchErr *services.FetchError
+		switch {
+		case err == nil:
+			remaining = nil
+		case errors.As(err, &fetchErr):
+			remaining = fetchErr.Cities
+		}
+		
+		s.mu.Lock()
+		s.retries.attempts++
+		s.retries.recovered += len(cities) - len(remaining)
+		s.retries.pending = remaining
+		s.mu.Unlock()
+		
+		cities = remaining
+	}
+	
+	if len(cities) > 0 {
+		s.logger.Warn("Cities still failing, waiting for the next scheduled fetch",
+			zap.Strings("cities", cities))
+	}
+	
+	s.mu.Lock()
+	s.retries.exhausted += len(cities)
+	s.retries.pending = nil
+	s.mu.Unlock()
+}
+
+// runDailySummaries computes the daily summaries every day at dailySummaryAt
+// until stop is closed
+func (s *Scheduler) runDailySummaries(stop <-chan struct{}) {
+	for {
+		next := nextDailyRun(time.Now(), s.dailySummaryAt)
+		s.mu.Lock()
+		s.nextSummary = next
+		s.mu.Unlock()
+		
+		select {
+		case <-time.After(time.Until(next)):
+		case <-stop:
+			return
+		}
+		s.computeDailySummaries()
+	}
+}
+
+func (s *Scheduler) computeDailySummaries() {
+	s.mu.Lock()
+	cities := s.cities
+	ctx := s.ctx
+	s.mu.Unlock()
+	
+	if err := s.aggregator.ComputeDailySummaries(ctx, cities, s.fetchTimeout); err != nil {
+		s.logger.Warn("Daily summaries incomplete", zap.Error(err))
 		return
 	}
+	s.logger.Info("Daily summaries computed", zap.Int("cities", len(cities)))
+}
+
+// nextDailyRun returns the first moment after now at offset from midnight UTC
+func nextDailyRun(now time.Time, offset time.Duration) time.Time {
+	next := now.UTC().Truncate(24 * time.Hour).Add(offset)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
+
+// Stop ends the run loop and the daily summaries, cancels the fetches in
+// flight and waits for them to return, so the aggregator can be closed after
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.running {
+		// Closed rather than sent on, run may be waiting for s.mu right now
+		s.logger.Info("Stopping scheduler")
+		close(s.stop)
+		s.cancel()
+		s.running = false
+	}
+	s.mu.Unlock()
 	
-	s.logger.Info("Stopping scheduler")
-	s.stop <- true
-	s.running = false
+	s.inFlight.Wait()
 }
 
 func (s *Scheduler) ForceRun() {
 	s.logger.Info("Manually triggering weather fetch")
-	go s.runFetch()
+	s.spawn(s.runFetch)
 }
 
 func (s *Scheduler) GetStatus() map[string]interface{} {
@@ -131,13 +374,66 @@ func (s *Scheduler) GetStatus() map[string]interface{} {
 		"next_run":       s.nextRun,
 		"cities":         s.cities,
 		"skip_if_running": s.skipIfRunning,
+		"next_daily_summary": s.nextSummary,
+		"retries": map[string]interface{}{
+			"pending":   s.retries.pending,
+			"attempts":  s.retries.attempts,
+			"recovered": s.retries.recovered,
+			"exhausted": s.retries.exhausted,
+		},
 	}
 }
 
 func (s *Scheduler) UpdateCities(cities []string) {
 	s.mu.Lock()
+	previous := s.cities
 	s.cities = cities
 	s.mu.Unlock()
 	
-	s.logger.Info("Scheduler cities updated", zap.Strings("cities", cities))
+	s.aggregator.SetTrackedCities(cities)
+	
+	removed := difference(previous, cities)
+	added := difference(cities, previous)
+	
+	s.logger.Info("Scheduler cities updated",
+		zap.Strings("cities", cities),
+		zap.Strings("removed", removed),
+		zap.Strings("added", added))
+	
+	// Purge removed cities right away instead of waiting for TTL expiry
+	for _, city := range removed {
+		s.aggregator.InvalidateCity(city)
+	}
+	
+	// Warm newly added cities so they don't wait for the next tick
+	if len(added) > 0 {
+		s.spawn(func() { s.warmCities(added) })
+	}
+}
+
+func (s *Scheduler) warmCities(cities []string) {
+	ctx, cancel := s.fetchContext()
+	defer cancel()
+	
+	if err := s.aggregator.FetchWeatherData(ctx, cities); err != nil {
+		s.logger.Warn("Failed to warm newly added cities",
+			zap.Strings("cities", cities),
+			zap.Error(err))
+	}
+}
+
+// difference returns the cities in a that are not in b
+func difference(a, b []string) []string {
+	seen := make(map[string]bool, len(b))
+	for _, city := range b {
+		seen[city] = true
+	}
+	
+	var result []string
+	for _, city := range a {
+		if !seen[city] {
+			result = append(result, city)
+		}
+	}
+	return result
 }
\ No newline at end of file
@@ -0,0 +1,367 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/services"
+	"go.uber.org/zap"
+)
+
+// fakeClient is a WeatherClient reporting the same reading for any city
+type fakeClient struct {
+	fail      atomic.Bool  // every request fails while set
+	failFirst atomic.Int32 // requests failing before the provider recovers
+	hang      atomic.Bool  // requests wait for their context to end while set
+	calls     atomic.Int32 // current weather requests received
+	active    atomic.Int32 // current weather requests in flight
+}
+
+func (c *fakeClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	c.calls.Add(1)
+	c.active.Add(1)
+	defer c.active.Add(-1)
+	if c.hang.Load() {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if c.fail.Load() || c.failFirst.Add(-1) >= 0 {
+		return nil, errors.New("provider down")
+	}
+	return &models.CurrentWeather{City: city, Temperature: 20, Timestamp: time.Now(), Source: c.Name()}, nil
+}
+
+func (c *fakeClient) GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error) {
+	return nil, errors.New("no forecast")
+}
+
+func (c *fakeClient) Name() string                      { return "fake" }
+func (c *fakeClient) RequiresAPIKey() bool              { return false }
+func (c *fakeClient) BreakerState() string              { return "closed" }
+func (c *fakeClient) BreakerRetryAfter() time.Duration  { return 0 }
+func (c *fakeClient) Usage() (hour, day models.UsageWindow) { return }
+
+func newTestAggregator(t *testing.T, c services.WeatherClient) *services.Aggregator {
+	t.Helper()
+	
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	aggregator, err := services.NewAggregatorWithClients(cfg, []services.WeatherClient{c}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAggregatorWithClients: %v", err)
+	}
+	t.Cleanup(func() {
+		aggregator.Close(context.Background())
+	})
+	return aggregator
+}
+
+func newTestScheduler(aggregator *services.Aggregator, cities []string) *Scheduler {
+	return NewScheduler(aggregator, cities, time.Hour, 5*time.Second, 0, time.Second, 0, false, 6*time.Hour, zap.NewNop())
+}
+
+// eventually polls condition until it holds or a second has passed
+func eventually(t *testing.T, condition func() bool, msg string) {
+	t.Helper()
+	
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func cachedCities(aggregator *services.Aggregator) map[string]bool {
+	cities := make(map[string]bool)
+	for _, entry := range aggregator.DumpCache() {
+		cities[entry.City] = true
+	}
+	return cities
+}
+
+func TestUpdateCitiesInvalidatesRemovedAndWarmsAdded(t *testing.T) {
+	aggregator := newTestAggregator(t, &fakeClient{})
+	s := newTestScheduler(aggregator, []string{"Prague", "London"})
+	if err := aggregator.FetchWeatherData(context.Background(), []string{"Prague", "London"}); err != nil {
+		t.Fatalf("FetchWeatherData: %v", err)
+	}
+	
+	s.UpdateCities([]string{"London", "Tokyo"})
+	
+	cached := cachedCities(aggregator)
+	if cached["prague"] {
+		t.Error("removed city Prague is still cached")
+	}
+	if !cached["london"] {
+		t.Error("kept city London was invalidated")
+	}
+	eventually(t, func() bool { return cachedCities(aggregator)["tokyo"] }, "added city Tokyo was not warmed")
+	
+	all := aggregator.GetAllCurrentWeather()
+	if len(all) != 2 || all[0].City != "London" || all[1].City != "Tokyo" {
+		t.Errorf("tracked cities = %+v, want London and Tokyo", all)
+	}
+}
+
+// retryStatus returns the retries section of the scheduler's status
+func retryStatus(s *Scheduler) map[string]interface{} {
+	return s.GetStatus()["retries"].(map[string]interface{})
+}
+
+func TestFailedCitiesAreRetried(t *testing.T) {
+	client := &fakeClient{}
+	client.failFirst.Store(2) // the scheduled fetch and the first retry
+	aggregator := newTestAggregator(t, client)
+	s := NewScheduler(aggregator, []string{"Prague"}, time.Hour, 5*time.Second, 3, 10*time.Millisecond, 0, false, 6*time.Hour, zap.NewNop())
+	
+	s.Start()
+	defer s.Stop()
+	
+	eventually(t, func() bool { return retryStatus(s)["recovered"] == 1 }, "failed city was not recovered by a retry")
+	retries := retryStatus(s)
+	if retries["attempts"] != 2 || retries["exhausted"] != 0 {
+		t.Errorf("retries = %v, want 2 attempts and nothing exhausted", retries)
+	}
+	eventually(t, func() bool { return len(retryStatus(s)["pending"].([]string)) == 0 }, "recovered city still pending")
+	if !cachedCities(aggregator)["prague"] {
+		t.Error("Prague is not cached after the successful retry")
+	}
+}
+
+func TestRetriesGiveUpAfterAttempts(t *testing.T) {
+	client := &fakeClient{}
+	client.fail.Store(true)
+	aggregator := newTestAggregator(t, client)
+	s := NewScheduler(aggregator, []string{"Prague"}, time.Hour, 5*time.Second, 2, 10*time.Millisecond, 0, false, 6*time.Hour, zap.NewNop())
+	
+	s.Start()
+	defer s.Stop()
+	
+	eventually(t, func() bool { return retryStatus(s)["exhausted"] == 1 }, "failing city was not given up on")
+	if retries := retryStatus(s); retries["attempts"] != 2 || retries["recovered"] != 0 {
+		t.Errorf("retries = %v, want 2 attempts and nothing recovered", retries)
+	}
+}
+
+// stopsPromptly fails the test unless Stop returns within a second
+func stopsPromptly(t *testing.T, s *Scheduler) {
+	t.Helper()
+	
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestStopEndsPendingRetries(t *testing.T) {
+	client := &fakeClient{}
+	client.fail.Store(true)
+	aggregator := newTestAggregator(t, client)
+	s := NewScheduler(aggregator, []string{"Prague"}, time.Hour, 5*time.Second, 3, 10*time.Minute, 0, false, 6*time.Hour, zap.NewNop())
+	
+	s.Start()
+	eventually(t, func() bool { return len(retryStatus(s)["pending"].([]string)) == 1 }, "failed city is not waiting for a retry")
+	
+	stopsPromptly(t, s)
+	if s.GetStatus()["running"] != false {
+		t.Error("scheduler still running after Stop")
+	}
+	
+	time.Sleep(50 * time.Millisecond)
+	if retries := retryStatus(s); retries["attempts"] != 0 || retries["exhausted"] != 0 {
+		t.Errorf("retries = %v after Stop during the retry wait, want no attempt", retries)
+	}
+}
+
+func TestStopWaitsForFetchInFlight(t *testing.T) {
+	client := &fakeClient{}
+	client.hang.Store(true)
+	aggregator := newTestAggregator(t, client)
+	s := newTestScheduler(aggregator, []string{"Prague"})
+	
+	s.Start()
+	eventually(t, func() bool { return client.active.Load() == 1 }, "scheduled fetch is not in flight")
+	
+	// The shutdown order of main: the scheduler first, then the aggregator
+	stopsPromptly(t, s)
+	if active := client.active.Load(); active != 0 {
+		t.Errorf("%d fetches still in flight after Stop, want them cancelled and returned", active)
+	}
+	calls := client.calls.Load()
+	if err := aggregator.Close(context.Background()); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	
+	time.Sleep(50 * time.Millisecond)
+	if client.calls.Load() != calls {
+		t.Error("scheduler fetched after the aggregator was closed")
+	}
+}
+
+func TestStopIsIdempotent(t *testing.T) {
+	aggregator := newTestAggregator(t, &fakeClient{})
+	s := newTestScheduler(aggregator, []string{"Prague"})
+	
+	// Before Start there is nothing to stop
+	stopsPromptly(t, s)
+	
+	s.Start()
+	stopsPromptly(t, s)
+	stopsPromptly(t, s)
+	
+	// Concurrent calls close the stop channel once
+	s.Start()
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			s.Stop()
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("concurrent Stop did not return")
+		}
+	}
+	if s.GetStatus()["running"] != false {
+		t.Error("scheduler still running after Stop")
+	}
+}
+
+func TestRandomDelayWithinBound(t *testing.T) {
+	for _, max := range []time.Duration{-time.Second, 0} {
+		if delay := randomDelay(max); delay != 0 {
+			t.Errorf("randomDelay(%v) = %v, want 0", max, delay)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if delay := randomDelay(10 * time.Millisecond); delay < 0 || delay > 10*time.Millisecond {
+			t.Fatalf("randomDelay(10ms) = %v, want within [0, 10ms]", delay)
+		}
+	}
+}
+
+func TestStartJitterDelaysFirstFetch(t *testing.T) {
+	client := &fakeClient{}
+	aggregator := newTestAggregator(t, client)
+	s := NewScheduler(aggregator, []string{"Prague"}, time.Hour, 5*time.Second, 0, time.Second, 200*time.Millisecond, false, 6*time.Hour, zap.NewNop())
+	var bound time.Duration
+	s.randomDelay = func(max time.Duration) time.Duration {
+		bound = max
+		return max / 2
+	}
+	
+	started := time.Now()
+	s.Start()
+	defer s.Stop()
+	
+	if bound != 200*time.Millisecond {
+		t.Errorf("jitter drawn up to %v, want the configured 200ms", bound)
+	}
+	nextRun := s.GetStatus()["next_run"].(time.Time)
+	if nextRun.Before(started.Add(100*time.Millisecond)) || nextRun.After(time.Now().Add(100*time.Millisecond)) {
+		t.Errorf("next_run %v after start, want the 100ms jitter", nextRun.Sub(started))
+	}
+	
+	time.Sleep(50 * time.Millisecond)
+	if calls := client.calls.Load(); calls != 0 {
+		t.Fatalf("%d requests during the start jitter, want none", calls)
+	}
+	eventually(t, func() bool { return client.calls.Load() > 0 }, "first fetch did not run after the jitter")
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Errorf("first fetch after %v, want at least the 100ms jitter", elapsed)
+	}
+}
+
+func TestStopDuringStartJitter(t *testing.T) {
+	client := &fakeClient{}
+	aggregator := newTestAggregator(t, client)
+	s := NewScheduler(aggregator, []string{"Prague"}, time.Hour, 5*time.Second, 0, time.Second, time.Hour, false, 6*time.Hour, zap.NewNop())
+	s.randomDelay = func(max time.Duration) time.Duration { return max }
+	
+	s.Start()
+	stopsPromptly(t, s)
+	
+	time.Sleep(50 * time.Millisecond)
+	if calls := client.calls.Load(); calls != 0 {
+		t.Errorf("%d requests after Stop during the start jitter, want none", calls)
+	}
+}
+
+func TestSpreadCitiesStaggersFetches(t *testing.T) {
+	aggregator := newTestAggregator(t, &fakeClient{})
+	// Two cities over half of 400ms are fetched 100ms apart
+	s := NewScheduler(aggregator, []string{"Prague", "London"}, 400*time.Millisecond, 5*time.Second, 0, time.Second, 0, true, 6*time.Hour, zap.NewNop())
+	
+	started := time.Now()
+	s.Start()
+	defer s.Stop()
+	
+	eventually(t, func() bool { return cachedCities(aggregator)["prague"] }, "first city was not fetched")
+	if cachedCities(aggregator)["london"] {
+		t.Fatal("second city fetched together with the first")
+	}
+	eventually(t, func() bool { return cachedCities(aggregator)["london"] }, "second city was not fetched")
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Errorf("second city fetched after %v, want at least the 100ms step", elapsed)
+	}
+}
+
+func TestStopDuringSpreadRun(t *testing.T) {
+	aggregator := newTestAggregator(t, &fakeClient{})
+	s := NewScheduler(aggregator, []string{"Prague", "London"}, time.Hour, 5*time.Second, 0, time.Second, 0, true, 6*time.Hour, zap.NewNop())
+	
+	s.Start()
+	eventually(t, func() bool { return cachedCities(aggregator)["prague"] }, "first city was not fetched")
+	stopsPromptly(t, s)
+	if s.GetStatus()["running"] != false {
+		t.Error("scheduler still running after Stop")
+	}
+}
+
+func TestNextDailyRun(t *testing.T) {
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{day.Add(5 * time.Hour), day.Add(6 * time.Hour)},
+		{day.Add(6 * time.Hour), day.Add(30 * time.Hour)},
+		{day.Add(23 * time.Hour), day.Add(30 * time.Hour)},
+		{day.Add(5 * time.Hour).In(time.FixedZone("UTC+2", 2*60*60)), day.Add(6 * time.Hour)},
+	}
+	for _, tt := range tests {
+		if got := nextDailyRun(tt.now, 6*time.Hour); !got.Equal(tt.want) {
+			t.Errorf("nextDailyRun(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestStartSchedulesDailySummaries(t *testing.T) {
+	s := newTestScheduler(newTestAggregator(t, &fakeClient{}), []string{"Prague"})
+	s.Start()
+	defer s.Stop()
+	
+	want := nextDailyRun(time.Now(), 6*time.Hour)
+	eventually(t, func() bool {
+		next, _ := s.GetStatus()["next_daily_summary"].(time.Time)
+		return next.Equal(want)
+	}, "next_daily_summary never reported the next 06:00 UTC")
+}
@@ -2,12 +2,21 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"weather-aggregator/internal/models"
-	"weather-aggregator/pkg/client"
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/storage"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"github.com/bobby-s-dev/weather-aggregator/pkg/client"
 	"go.uber.org/zap"
 )
 
@@ -17,67 +26,315 @@ type Aggregator struct {
 	logger         *zap.Logger
 	mu             sync.RWMutex
 	lastFetchTime  time.Time
+	lastSuccessTime time.Time                     // last fetch that aggregated data for a city
 	successCount   int
 	failureCount   int
 	weatherData    map[string]*models.WeatherData // city -> weather data
-}
-
-type WeatherClient interface {
-	GetCurrentWeather(ctx context.Context, city string) (*models.CurrentWeather, error)
-	GetForecast(ctx context.Context, city string, days int) (*models.WeatherForecast, error)
+	sourceStats    map[string]*sourceStats        // source -> fetch outcomes
+	fetchTimeout   time.Duration                  // bound for on-demand fetches on cache miss
+	dataTTL        time.Duration                  // how long raw weather data is reused
+	history        *storage.HistoryStore          // nil unless history storage is enabled
+	disabled       map[string]bool                // sources switched off at runtime
+	adaptive       bool                           // weight sources by recent reliability and agreement
+	smoothingWindow int                           // days in the moving average of smoothed forecasts
+	trackedCities  []string                       // cities the scheduler keeps fresh
+	forecastDays   int                            // horizon fetched from every provider
+	forecastHorizons []int                        // ascending day counts below forecastDays cached next to it
+	maintenance    atomic.Bool                    // serve cached data only, never call providers
+	prefetchWindow time.Duration                  // refresh tracked cities this close to expiry, 0 disables
+	prefetchMax    int                            // cities refreshed per cleanup tick at most
+	prefetching    atomic.Bool                    // a prefetch is running
+	conditionStrategy string                      // how the aggregated condition is chosen, see aggregateCondition
+	nowBlendWeight float64                        // observation weight in blendWithForecast
+	partialTimeout time.Duration                  // soft deadline of fetchCityWeather, 0 waits for every provider
+	comfort        comfortScorer                  // scores forecast days for GetBestDay
+	forecastAlignment string                      // whether forecast days are matched by date or position
+	primarySource  string                         // wins ties between sources, empty for none
+	coordinatesSource string                      // reports the resolved coordinates, empty for the nearest source
+	statsPath      string                         // file the fetch stats persist to, empty disables
+	stopStats      chan struct{}                  // stops the periodic stats save
+	statsDone      chan struct{}                  // closed when the periodic stats save has returned
+	background     sync.WaitGroup                 // prefetches and late provider responses in flight
+	shutdown       context.Context                // cancelled when Close gives up waiting for background
+	cancelShutdown context.CancelFunc
+	transport      *http.Transport                // shared by the clients
+	closeOnce      sync.Once
+	closeErr       error                          // result of the first Close
+	trends         *trendTracker                  // recent aggregated temperatures per city
+	fetchMode      string                         // whether a client's current and forecast requests run in parallel
+	providerPriority []string                     // providers started first, see orderClients
+	providerOrder  string
+	providerHealth providerHealthCache            // last active provider probe
+	geocoder       *client.Geocoder               // shared by the clients, also serves place search
+	lastKnown      *lastKnownStore                // served stale when every provider fails
+	dailySummaries *dailySummaryStore             // filled by the scheduler, see ComputeDailySummaries
 }
 
 func NewAggregator(cfg *config.Config, logger *zap.Logger) (*Aggregator, error) {
-	clientConfig := client.ClientConfig{
-		Timeout:       10 * time.Second,
-		MaxRetries:    cfg.Retry.MaxRetries,
-		RetryDelay:    cfg.Retry.Delay,
-		Multiplier:    cfg.Retry.Multiplier,
-		Threshold:     cfg.CircuitBreaker.Threshold,
-		BreakerTimeout: cfg.CircuitBreaker.Timeout,
-	}
+	transport := newTransport(cfg)
+	clientConfig := newClientConfig(cfg, transport)
 	
 	var clients []WeatherClient
 	
-	// Initialize OpenWeatherMap client if API key is provided
-	if cfg.WeatherAPI.OpenWeatherAPIKey != "" {
+	// Shared by the clients that need coordinates rather than city names
+	geocoder := client.NewGeocoder(clientConfig, logger)
+	
+	// Initialize OpenWeatherMap client if API keys are provided
+	if len(cfg.WeatherAPI.OpenWeatherAPIKeys) > 0 {
 		openWeatherClient := client.NewOpenWeatherClient(
-			cfg.WeatherAPI.OpenWeatherAPIKey,
+			cfg.WeatherAPI.OpenWeatherAPIKeys,
+			cfg.WeatherAPI.OpenWeatherOneCall,
+			geocoder,
 			clientConfig,
 			logger,
 		)
 		clients = append(clients, openWeatherClient)
-		logger.Info("OpenWeatherMap client initialized")
+		logger.Info("OpenWeatherMap client initialized",
+			zap.Int("api_keys", len(cfg.WeatherAPI.OpenWeatherAPIKeys)))
 	}
 	
 	// Initialize Open-Meteo client (no API key required)
-	openMeteoClient := client.NewOpenMeteoClient(clientConfig, logger)
+	openMeteoClient := client.NewOpenMeteoClient(geocoder, clientConfig, logger)
 	clients = append(clients, openMeteoClient)
 	logger.Info("Open-Meteo client initialized")
 	
+	// Initialize Tomorrow.io client if API key is provided
+	if cfg.WeatherAPI.TomorrowIOAPIKey != "" {
+		tomorrowIOClient := client.NewTomorrowIOClient(cfg.WeatherAPI.TomorrowIOAPIKey, geocoder, clientConfig, logger)
+		clients = append(clients, tomorrowIOClient)
+		logger.Info("Tomorrow.io client initialized")
+	}
+	
+	// Initialize Visual Crossing client if API key is provided
+	if cfg.WeatherAPI.VisualCrossingAPIKey != "" {
+		visualCrossingClient := client.NewVisualCrossingClient(cfg.WeatherAPI.VisualCrossingAPIKey, geocoder, clientConfig, logger)
+		clients = append(clients, visualCrossingClient)
+		logger.Info("Visual Crossing client initialized")
+	}
+	
 	// Note: You can add WeatherAPI.com client similarly
 	
+	return newAggregator(cfg, clients, geocoder, transport, logger)
+}
+
+// NewAggregatorWithClients creates an aggregator over the given clients in
+// place of the providers cfg configures
+func NewAggregatorWithClients(cfg *config.Config, clients []WeatherClient, logger *zap.Logger) (*Aggregator, error) {
+	transport := newTransport(cfg)
+	geocoder := client.NewGeocoder(newClientConfig(cfg, transport), logger)
+	return newAggregator(cfg, clients, geocoder, transport, logger)
+}
+
+func newTransport(cfg *config.Config) *http.Transport {
+	return client.NewTransport(client.TransportConfig{
+		MaxIdleConns:        cfg.HTTP.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.HTTP.IdleConnTimeout,
+	})
+}
+
+func newClientConfig(cfg *config.Config, transport *http.Transport) client.ClientConfig {
+	return client.ClientConfig{
+		Timeout:       10 * time.Second,
+		MaxRetries:    cfg.Retry.MaxRetries,
+		RetryDelay:    cfg.Retry.Delay,
+		Multiplier:    cfg.Retry.Multiplier,
+		MaxDelay:      cfg.Retry.MaxDelay,
+		Threshold:     cfg.CircuitBreaker.Threshold,
+		MinRequests:   cfg.CircuitBreaker.MinRequests,
+		FailureRatio:  cfg.CircuitBreaker.FailureRatio,
+		SuccessThreshold: cfg.CircuitBreaker.SuccessThreshold,
+		BreakerTimeout: cfg.CircuitBreaker.Timeout,
+		LogBodies:     cfg.Server.LogHTTPBodies,
+		Transport:     transport,
+	}
+}
+
+func newAggregator(cfg *config.Config, clients []WeatherClient, geocoder *client.Geocoder, transport *http.Transport, logger *zap.Logger) (*Aggregator, error) {
 	if len(clients) == 0 {
 		return nil, fmt.Errorf("no weather clients initialized")
 	}
 	
-	cache := NewWeatherCache(cfg.Cache.Duration, cfg.Cache.MaxSize, logger)
+	var cache *WeatherCache
+	switch cfg.Cache.Backend {
+	case config.CacheBackendTiered:
+		remote, err := newRedisCache(cfg.Cache.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		
+		// An unreachable Redis only costs remote hits, keep starting up
+		pingCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if err := remote.Ping(pingCtx); err != nil {
+			logger.Warn("Redis cache tier unreachable, continuing with misses until it recovers", zap.Error(err))
+		}
+		cancel()
+		
+		cache = NewTieredWeatherCache(cfg.Cache.Duration, cfg.Cache.MaxSize, cfg.Cache.Prefix, remote, logger)
+		logger.Info("Tiered cache initialized")
+	default:
+		cache = NewWeatherCache(cfg.Cache.Duration, cfg.Cache.MaxSize, cfg.Cache.Prefix, logger)
+	}
+	
+	// History is optional, the service keeps running without it
+	var history *storage.HistoryStore
+	if cfg.History.Enabled {
+		store, err := storage.NewHistoryStore(cfg.History.DBPath, logger)
+		if err != nil {
+			logger.Warn("History storage disabled", zap.Error(err))
+		} else {
+			history = store
+		}
+	}
 	
-	return &Aggregator{
+	aggregator := &Aggregator{
 		clients:      clients,
 		cache:        cache,
 		logger:       logger,
 		weatherData:  make(map[string]*models.WeatherData),
-	}, nil
+		sourceStats:  make(map[string]*sourceStats),
+		fetchTimeout: cfg.WeatherAPI.FetchTimeout,
+		dataTTL:      cfg.Cache.Duration,
+		history:      history,
+		disabled:     make(map[string]bool),
+		adaptive:     cfg.Aggregation.Weighting == config.WeightingAdaptive,
+		smoothingWindow: cfg.Aggregation.SmoothingWindow,
+		forecastDays: cfg.WeatherAPI.ForecastDays,
+		forecastHorizons: cachedHorizons(cfg.Cache.ForecastHorizons, cfg.WeatherAPI.ForecastDays),
+		prefetchWindow: time.Duration(cfg.Cache.PrefetchWindow * float64(cfg.Cache.Duration)),
+		prefetchMax:  cfg.Cache.PrefetchMax,
+		conditionStrategy: cfg.Aggregation.ConditionAggregation,
+		nowBlendWeight: cfg.Aggregation.NowBlendWeight,
+		partialTimeout: cfg.WeatherAPI.PartialTimeout,
+		comfort: comfortScorer{
+			targetTemp:          cfg.BestDay.TargetTemp,
+			temperatureWeight:   cfg.BestDay.TemperatureWeight,
+			precipitationWeight: cfg.BestDay.PrecipitationWeight,
+			windWeight:          cfg.BestDay.WindWeight,
+		},
+		forecastAlignment: cfg.Aggregation.ForecastAlignment,
+		statsPath:    cfg.Stats.File,
+		primarySource: cfg.Aggregation.PrimarySource,
+		coordinatesSource: cfg.Aggregation.CoordinatesSource,
+		trends:       newTrendTracker(cfg.Trend.Window, cfg.Trend.SteadyThreshold),
+		fetchMode:    cfg.WeatherAPI.FetchMode,
+		providerPriority: cfg.WeatherAPI.ProviderPriority,
+		providerOrder: cfg.WeatherAPI.ProviderOrder,
+		geocoder:     geocoder,
+		transport:    transport,
+		lastKnown:    newLastKnownStore(cfg.Cache.LastKnownMaxAge, cfg.Cache.MaxSize),
+		dailySummaries: newDailySummaryStore(),
+	}
+	aggregator.shutdown, aggregator.cancelShutdown = context.WithCancel(context.Background())
+	
+	if primary := aggregator.primarySource; primary != "" && !aggregator.hasClient(primary) {
+		logger.Warn("PRIMARY_SOURCE is not an initialized provider, ties fall back to source order",
+			zap.String("primary_source", primary))
+	}
+	for _, source := range aggregator.providerPriority {
+		if !aggregator.hasClient(source) {
+			logger.Warn("PROVIDER_PRIORITY lists a provider that is not initialized",
+				zap.String("provider", source))
+		}
+	}
+	if source := aggregator.coordinatesSource; source != "" && !aggregator.hasClient(source) {
+		logger.Warn("COORDINATES_SOURCE is not an initialized provider, resolved coordinates come from the geocoder",
+			zap.String("coordinates_source", source))
+	}
+	
+	// Persisted stats are optional, a broken file only costs the history
+	if aggregator.statsPath != "" {
+		if err := aggregator.loadStats(); err != nil {
+			logger.Warn("Fetch stats not restored", zap.Error(err))
+		}
+		aggregator.stopStats = make(chan struct{})
+		aggregator.statsDone = make(chan struct{})
+		go aggregator.persistStats(cfg.Stats.SaveInterval)
+	}
+	
+	if cfg.Cache.LastKnownMaxAge > 0 {
+		cache.OnCleanup(aggregator.lastKnown.sweep)
+	}
+	if aggregator.prefetchWindow > 0 {
+		cache.OnCleanup(aggregator.prefetchExpiring)
+		logger.Info("Cache prefetch enabled",
+			zap.Duration("window", aggregator.prefetchWindow),
+			zap.Int("max_cities", aggregator.prefetchMax))
+	}
+	
+	return aggregator, nil
 }
 
 func (a *Aggregator) FetchWeatherData(ctx context.Context, cities []string) error {
+	return a.fetchWeatherData(ctx, cities, models.QueryOptions{})
+}
+
+// fetchTrackerKey carries the flag set by TrackFetches
+type fetchTrackerKey struct{}
+
+// TrackFetches returns a context whose flag is set once any provider fetch
+// runs under it, so callers can tell cached answers from fresh ones
+func TrackFetches(ctx context.Context) (context.Context, *atomic.Bool) {
+	fetched := new(atomic.Bool)
+	return context.WithValue(ctx, fetchTrackerKey{}, fetched), fetched
+}
+
+func markFetched(ctx context.Context) {
+	if fetched, ok := ctx.Value(fetchTrackerKey{}).(*atomic.Bool); ok {
+		fetched.Store(true)
+	}
+}
+
+// expiryTrackerKey carries the tracker set by TrackExpiry
+type expiryTrackerKey struct{}
+
+// ExpiryTracker holds the earliest expiry of the cached data served under a
+// context, which bounds how long the response built from it stays valid
+type ExpiryTracker struct {
+	mu       sync.Mutex
+	earliest time.Time
+}
+
+// Earliest returns the earliest expiry recorded, zero when no cached data
+// was served
+func (t *ExpiryTracker) Earliest() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.earliest
+}
+
+// TrackExpiry returns a context under which lookups record when the cached
+// data they return expires
+func TrackExpiry(ctx context.Context) (context.Context, *ExpiryTracker) {
+	tracker := &ExpiryTracker{}
+	return context.WithValue(ctx, expiryTrackerKey{}, tracker), tracker
+}
+
+func recordExpiry(ctx context.Context, expiresAt time.Time) {
+	tracker, ok := ctx.Value(expiryTrackerKey{}).(*ExpiryTracker)
+	if !ok {
+		return
+	}
+	
+	tracker.mu.Lock()
+	if tracker.earliest.IsZero() || expiresAt.Before(tracker.earliest) {
+		tracker.earliest = expiresAt
+	}
+	tracker.mu.Unlock()
+}
+
+func (a *Aggregator) fetchWeatherData(ctx context.Context, cities []string, opts models.QueryOptions) error {
+	if a.maintenance.Load() {
+		return ErrMaintenance
+	}
+	markFetched(ctx)
+	
 	a.mu.Lock()
 	a.lastFetchTime = time.Now()
 	a.mu.Unlock()
 	
 	var wg sync.WaitGroup
-	errors := make(chan error, len(cities))
+	failed := make(chan string, len(cities))
 	
 	startTime := time.Now()
 	
@@ -86,11 +343,11 @@ func (a *Aggregator) FetchWeatherData(ctx context.Context, cities []string) erro
 		go func(city string) {
 			defer wg.Done()
 			
-			if err := a.fetchCityWeather(ctx, city); err != nil {
+			if err := a.fetchCityWeather(ctx, city, opts); err != nil {
 				a.logger.Error("Failed to fetch weather for city",
 					zap.String("city", city),
 					zap.Error(err))
-				errors <- err
+				failed <- city
 				a.mu.Lock()
 				a.failureCount++
 				a.mu.Unlock()
@@ -103,86 +360,131 @@ func (a *Aggregator) FetchWeatherData(ctx context.Context, cities []string) erro
 	}
 	
 	wg.Wait()
-	close(errors)
+	close(failed)
 	
 	duration := time.Since(startTime)
+	a.mu.Lock()
+	successCount, failureCount := a.successCount, a.failureCount
+	a.mu.Unlock()
 	a.logger.Info("Weather fetch completed",
 		zap.Int("cities", len(cities)),
 		zap.Duration("duration", duration),
-		zap.Int("success", a.successCount),
-		zap.Int("failure", a.failureCount))
+		zap.Int("success", successCount),
+		zap.Int("failure", failureCount))
 	
-	// Check if we got any errors
-	hasErrors := false
-	for err := range errors {
-		if err != nil {
-			hasErrors = true
-			break
-		}
+	var failedCities []string
+	for city := range failed {
+		failedCities = append(failedCities, city)
 	}
 	
-	if hasErrors {
-		return fmt.Errorf("some cities failed to fetch weather data")
+	if len(failedCities) > 0 {
+		return &FetchError{Cities: failedCities}
 	}
 	
 	return nil
 }
 
-func (a *Aggregator) fetchCityWeather(ctx context.Context, city string) error {
+// WarmCache synchronously fetches the given cities so the first requests after
+// startup are served from cache. The timeout bounds how long a slow provider can
+// hold up startup.
+func (a *Aggregator) WarmCache(ctx context.Context, cities []string, timeout time.Duration) {
+	warmCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	
+	a.logger.Info("Warming cache", zap.Strings("cities", cities), zap.Duration("timeout", timeout))
+	
 	var wg sync.WaitGroup
-	responses := make(chan models.APIResponse, len(a.clients))
+	startTime := time.Now()
 	
-	// Fetch from all clients concurrently
-	for _, client := range a.clients {
+	for _, city := range cities {
 		wg.Add(1)
-		go func(c WeatherClient, source string) {
+		go func(city string) {
 			defer wg.Done()
 			
-			response := models.APIResponse{Source: source}
-			
-			// Fetch current weather
-			current, err := c.GetCurrentWeather(ctx, city)
-			if err != nil {
-				a.logger.Warn("Failed to fetch current weather from source",
-					zap.String("source", source),
+			cityStart := time.Now()
+			if err := a.fetchCityWeather(warmCtx, city, models.QueryOptions{}); err != nil {
+				a.logger.Warn("Cache warm-up failed for city",
 					zap.String("city", city),
+					zap.Duration("duration", time.Since(cityStart)),
 					zap.Error(err))
-				response.Error = err
-			} else {
-				response.Current = current
+				return
 			}
 			
-			// Fetch forecast (3 days)
-			forecast, err := c.GetForecast(ctx, city, 3)
-			if err != nil {
-				a.logger.Warn("Failed to fetch forecast from source",
-					zap.String("source", source),
-					zap.String("city", city),
-					zap.Error(err))
-				if response.Error == nil {
-					response.Error = err
+			a.logger.Info("Cache warmed for city",
+				zap.String("city", city),
+				zap.Duration("duration", time.Since(cityStart)))
+		}(city)
+	}
+	
+	wg.Wait()
+	
+	a.logger.Info("Cache warm-up completed", zap.Duration("duration", time.Since(startTime)))
+}
+
+func (a *Aggregator) fetchCityWeather(ctx context.Context, city string, opts models.QueryOptions) error {
+	clients := a.enabledClients()
+	if len(clients) == 0 {
+		return ErrNoEnabledProvider
+	}
+	if clients = selectSources(clients, opts.Sources); len(clients) == 0 {
+		return ErrNoRequestedSource
+	}
+	
+	// Cancelled once every response is collected, by collectStragglers when
+	// some arrive late
+	fetchCtx, cancel := a.fetchContext(ctx)
+	
+	// Every fetch sends exactly one response, buffered so none blocks
+	responses := make(chan models.APIResponse, len(clients))
+	
+	// Fetch from all enabled clients concurrently, the preferred ones started first
+	ordered := a.orderClients(clients)
+	for _, client := range ordered {
+		go func(c WeatherClient, source string) {
+			// A malformed provider payload must not take down the whole fetch
+			defer func() {
+				if r := recover(); r != nil {
+					a.logger.Error("Recovered from panic while fetching from source",
+						zap.String("source", source),
+						zap.String("city", city),
+						zap.Any("panic", r))
+					responses <- models.APIResponse{Source: source, Error: fmt.Errorf("client panic: %v", r)}
 				}
-			} else {
-				response.Forecast = forecast
-			}
+			}()
 			
-			responses <- response
-		}(client, getSourceName(client))
+			// Fetch current weather and the forecast over the configured horizon
+			started := time.Now()
+			current, forecast, err := a.getWeather(fetchCtx, c, city, a.forecastDays, opts)
+			responses <- models.APIResponse{
+				Source:   source,
+				Current:  current,
+				Forecast: forecast,
+				Error:    err,
+				Latency:  time.Since(started),
+			}
+		}(client, client.Name())
 	}
 	
-	wg.Wait()
-	close(responses)
+	received := a.collectResponses(responses, len(clients), a.preferredSource(ordered))
+	pending := len(clients) - len(received)
+	if pending == 0 {
+		cancel()
+	}
 	
 	// Process responses
 	weatherData := &models.WeatherData{
-		City:      city,
+		Query:     city,
+		Country:   opts.Country,
 		Current:   make(map[string]*models.CurrentWeather),
 		Forecasts: make(map[string]*models.WeatherForecast),
 		Timestamp: time.Now(),
+		Partial:   pending > 0,
 	}
 	
 	successCount := 0
-	for response := range responses {
+	for _, response := range received {
+		a.recordSourceOutcome(response.Source, response.Error == nil, response.Latency)
+		
 		if response.Current != nil {
 			weatherData.Current[response.Source] = response.Current
 			successCount++
@@ -193,93 +495,470 @@ func (a *Aggregator) fetchCityWeather(ctx context.Context, city string) error {
 	}
 	
 	if successCount == 0 {
+		cancel()
 		return fmt.Errorf("all API calls failed for city %s", city)
 	}
 	
+	weatherData.City = displayCityName(weatherData.Query, weatherData.Current)
+	
+	// Late responses still change the set of sources, agreement is recorded
+	// once it is final
+	if pending == 0 {
+		a.recordAgreement(weatherData.Current)
+	}
+	
+	key := dataKey(city, opts)
+	
 	a.mu.Lock()
-	a.weatherData[city] = weatherData
+	a.weatherData[key] = weatherData
+	a.lastSuccessTime = time.Now()
 	a.mu.Unlock()
 	
 	// Aggregate and cache the results
-	a.aggregateAndCache(city)
+	a.aggregateAndCache(key)
+	
+	if pending > 0 {
+		a.logger.Info("Soft deadline passed, aggregated the sources that answered",
+			zap.String("city", city),
+			zap.Int("answered", len(received)),
+			zap.Int("pending", pending))
+		
+		a.background.Add(1)
+		go a.collectStragglers(key, weatherData, responses, pending, cancel)
+	}
 	
 	return nil
 }
 
-func (a *Aggregator) aggregateAndCache(city string) {
+// fetchContext returns the context providers are fetched with. With a soft
+// deadline it outlives the caller's cancellation, for stragglers to finish
+// after the caller got its answer, but keeps the caller's deadline.
+func (a *Aggregator) fetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.partialTimeout <= 0 {
+		return ctx, func() {}
+	}
+	
+	var detached context.Context
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		detached, cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	} else {
+		detached, cancel = context.WithCancel(context.WithoutCancel(ctx))
+	}
+	
+	// Stragglers are cancelled when Close gives up waiting for them
+	stop := context.AfterFunc(a.shutdown, cancel)
+	return detached, func() {
+		stop()
+		cancel()
+	}
+}
+
+// collectResponses waits for the responses of count fetches. Once the soft
+// deadline has passed it returns early with what arrived, as long as one
+// source delivered current weather and the preferred source, if any, has
+// answered.
+func (a *Aggregator) collectResponses(responses <-chan models.APIResponse, count int, preferred string) []models.APIResponse {
+	var softDeadline <-chan time.Time
+	if a.partialTimeout > 0 {
+		timer := time.NewTimer(a.partialTimeout)
+		defer timer.Stop()
+		softDeadline = timer.C
+	}
+	
+	received := make([]models.APIResponse, 0, count)
+	succeeded := false
+	preferredAnswered := preferred == ""
+	deadlinePassed := false
+	for len(received) < count {
+		if deadlinePassed && succeeded && preferredAnswered {
+			break
+		}
+		
+		select {
+		case response := <-responses:
+			received = append(received, response)
+			succeeded = succeeded || response.Current != nil
+			preferredAnswered = preferredAnswered || response.Source == preferred
+		case <-softDeadline:
+			deadlinePassed = true
+			softDeadline = nil
+		}
+	}
+	return received
+}
+
+// collectStragglers merges the responses that missed the soft deadline into
+// the data stored under key as they land, refreshing the cached aggregates
+// after each one, and records the source agreement of the final set. It gives
+// up once a newer fetch replaced the data or Close cancelled it.
+func (a *Aggregator) collectStragglers(key string, data *models.WeatherData, responses <-chan models.APIResponse, pending int, cancel context.CancelFunc) {
+	defer a.background.Done()
+	defer cancel()
+	
+	for ; pending > 0; pending-- {
+		var response models.APIResponse
+		select {
+		case response = <-responses:
+		case <-a.shutdown.Done():
+			return
+		}
+		if a.shutdown.Err() != nil {
+			return
+		}
+		a.recordSourceOutcome(response.Source, response.Error == nil, response.Latency)
+		
+		merged := *data
+		merged.Current = make(map[string]*models.CurrentWeather, len(data.Current)+1)
+		for source, current := range data.Current {
+			merged.Current[source] = current
+		}
+		merged.Forecasts = make(map[string]*models.WeatherForecast, len(data.Forecasts)+1)
+		for source, forecast := range data.Forecasts {
+			merged.Forecasts[source] = forecast
+		}
+		if response.Current != nil {
+			merged.Current[response.Source] = response.Current
+		}
+		if response.Forecast != nil {
+			merged.Forecasts[response.Source] = response.Forecast
+		}
+		merged.Partial = pending > 1
+		
+		a.mu.Lock()
+		if a.weatherData[key] != data {
+			a.mu.Unlock()
+			a.logger.Debug("Dropping late response, the data was refetched",
+				zap.String("source", response.Source),
+				zap.String("key", key))
+			a.recordAgreement(data.Current)
+			return
+		}
+		a.weatherData[key] = &merged
+		a.mu.Unlock()
+		
+		a.logger.Debug("Late response merged",
+			zap.String("source", response.Source),
+			zap.String("key", key),
+			zap.Bool("success", response.Error == nil))
+		
+		data = &merged
+		a.cacheAggregates(key, false)
+	}
+	
+	a.recordAgreement(data.Current)
+}
+
+// displayCityName picks the name shown in responses: the coordinate table's
+// spelling for known cities, otherwise the name a provider resolved the city to
+func displayCityName(city string, current map[string]*models.CurrentWeather) string {
+	if name, ok := client.CanonicalCityName(city); ok {
+		return name
+	}
+	for _, weather := range current {
+		if weather.City != "" {
+			return weather.City
+		}
+	}
+	return strings.TrimSpace(city)
+}
+
+// getWeather fetches current weather and forecast from one client, in a single
+// request when the client supports it. Otherwise the two requests run one
+// after the other, or concurrently in the parallel fetch mode. Either result
+// may be nil alongside the error of the part that failed.
+func (a *Aggregator) getWeather(ctx context.Context, c WeatherClient, city string, days int, opts models.QueryOptions) (*models.CurrentWeather, *models.WeatherForecast, error) {
+	if combined, ok := c.(CombinedWeatherClient); ok {
+		current, forecast, err := combined.GetWeather(ctx, city, days, opts)
+		if err != nil {
+			a.logger.Warn("Failed to fetch weather from source",
+				zap.String("source", c.Name()),
+				zap.String("city", city),
+				zap.Error(err))
+		}
+		return current, forecast, err
+	}
+	
+	var current *models.CurrentWeather
+	var forecast *models.WeatherForecast
+	var currentErr, forecastErr error
+	
+	fetchCurrent := func() {
+		current, currentErr = c.GetCurrentWeather(ctx, city, opts)
+	}
+	fetchForecast := func() {
+		forecast, forecastErr = c.GetForecast(ctx, city, days, opts)
+	}
+	
+	if a.fetchMode == config.FetchModeParallel {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fetchForecast()
+		}()
+		fetchCurrent()
+		wg.Wait()
+	} else {
+		fetchCurrent()
+		fetchForecast()
+	}
+	
+	if currentErr != nil {
+		a.logger.Warn("Failed to fetch current weather from source",
+			zap.String("source", c.Name()),
+			zap.String("city", city),
+			zap.Error(currentErr))
+	}
+	if forecastErr != nil {
+		a.logger.Warn("Failed to fetch forecast from source",
+			zap.String("source", c.Name()),
+			zap.String("city", city),
+			zap.Error(forecastErr))
+	}
+	
+	if currentErr != nil {
+		return current, forecast, currentErr
+	}
+	return current, forecast, forecastErr
+}
+
+func (a *Aggregator) aggregateAndCache(key string) {
+	a.cacheAggregates(key, true)
+}
+
+// cacheAggregates aggregates the data stored under key into the cache and the
+// last known entries. A first pass also records the temperature trend reading
+// and the history snapshot, which happen once per fetch; passes after late
+// responses only report the trend recorded so far.
+func (a *Aggregator) cacheAggregates(key string, firstPass bool) {
 	a.mu.RLock()
-	weatherData, exists := a.weatherData[city]
+	weatherData, exists := a.weatherData[key]
 	a.mu.RUnlock()
 	
 	if !exists || len(weatherData.Current) == 0 {
 		return
 	}
 	
+	// Variants in other units are rebuilt from the fresh aggregate on demand
+	a.cache.DeleteDerived(key)
+	
 	// Aggregate current weather
 	aggregatedCurrent := a.aggregateCurrentWeather(weatherData)
-	a.cache.SetCurrentWeather(city, aggregatedCurrent)
+	if aggregatedCurrent != nil {
+		aggregatedCurrent.Partial = weatherData.Partial
+		if firstPass {
+			aggregatedCurrent.TemperatureTrend = a.trends.record(key, aggregatedCurrent.LastUpdated, aggregatedCurrent.Temperature)
+		} else {
+			aggregatedCurrent.TemperatureTrend = a.trends.trend(key)
+		}
+		a.cache.SetCurrentWeather(key, aggregatedCurrent)
+		a.lastKnown.set(key, aggregatedCurrent)
+		if firstPass {
+			a.recordHistory(key, aggregatedCurrent)
+		}
+	}
 	
-	// Aggregate forecast
-	for days := 1; days <= 7; days++ {
-		aggregatedForecast := a.aggregateForecast(weatherData, days)
-		if aggregatedForecast != nil {
-			a.cache.SetForecast(city, days, aggregatedForecast)
+	// The full horizon and the configured shorter ones are cached, others are
+	// sliced from the full one on request
+	aggregatedForecast := a.aggregateForecast(weatherData, a.forecastDays)
+	if aggregatedForecast != nil {
+		aggregatedForecast.Partial = weatherData.Partial
+		a.cache.SetForecast(key, aggregatedForecast)
+		for _, days := range a.forecastHorizons {
+			a.cache.SetForecast(horizonKey(key, days), SliceForecast(aggregatedForecast, days))
 		}
 	}
 }
 
 func (a *Aggregator) aggregateCurrentWeather(data *models.WeatherData) *models.AggregatedCurrentWeather {
-	if len(data.Current) == 0 {
+	// A reading without a usable temperature cannot be averaged or scored
+	current := make(map[string]*models.CurrentWeather, len(data.Current))
+	for source, weather := range data.Current {
+		if weather != nil && utils.IsFinite(weather.Temperature) {
+			current[source] = weather
+		}
+	}
+	if len(current) == 0 {
 		return nil
 	}
 	
-	var totalTemp, totalFeelsLike, totalHumidity, totalPressure, totalWindSpeed float64
-	var descriptions []string
+	var temps, feelsLikes, humidities, pressures, windSpeeds, cloudCovers, weights []float64
+	var gusts gustAverage
+	var totalVisibility float64
+	var visibilityCount int
+	var descriptions, icons []string
+	var conditions []models.ConditionCode
 	var sources []string
 	var latestTimestamp time.Time
+	timestamps := make(map[string]time.Time, len(current))
 	
-	for source, weather := range data.Current {
-		totalTemp += weather.Temperature
-		totalFeelsLike += weather.FeelsLike
-		totalHumidity += weather.Humidity
-		totalPressure += weather.Pressure
-		totalWindSpeed += weather.WindSpeed
+	sourceWeights := a.sourceWeights(current)
+	
+	// Ordered so ties between conditions and icons resolve the same way every
+	// time, in favour of the primary source
+	for _, source := range orderedSources(current, a.primarySource) {
+		weather := current[source]
+		temps = append(temps, weather.Temperature)
+		feelsLikes = append(feelsLikes, weather.FeelsLike)
+		humidities = append(humidities, weather.Humidity)
+		pressures = append(pressures, weather.Pressure)
+		windSpeeds = append(windSpeeds, weather.WindSpeed)
+		cloudCovers = append(cloudCovers, weather.CloudCover)
+		weights = append(weights, sourceWeights[source])
+		gusts.add(weather.WindGust)
+		if weather.Visibility != nil && utils.IsFinite(*weather.Visibility) {
+			totalVisibility += *weather.Visibility
+			visibilityCount++
+		}
 		descriptions = append(descriptions, weather.Description)
+		icons = append(icons, weather.Icon)
+		conditions = append(conditions, weather.Condition)
 		sources = append(sources, source)
+		timestamps[source] = weather.Timestamp
 		
 		if weather.Timestamp.After(latestTimestamp) {
 			latestTimestamp = weather.Timestamp
 		}
 	}
 	
-	count := float64(len(data.Current))
-	
 	// Calculate confidence based on number of sources and variance
-	confidence := calculateConfidence(data.Current)
+	confidence := calculateConfidence(current)
 	
-	// Find most common description
-	description := mostCommonString(descriptions)
+	// Agree on the normalized condition, free-text descriptions rarely match
+	condition, description := aggregateCondition(a.conditionStrategy, conditions, descriptions)
+	icon := pickIcon(condition, description, conditions, descriptions, icons)
 	
-	// Use icon from first source
-	var icon string
-	for _, weather := range data.Current {
-		icon = weather.Icon
-		break
+	// Average visibility only over the sources that reported it
+	var visibility *float64
+	if visibilityCount > 0 {
+		mean := totalVisibility / float64(visibilityCount)
+		visibility = &mean
 	}
 	
-	return &models.AggregatedCurrentWeather{
+	latitude, longitude, distanceKm := a.resolvedLocation(data, current)
+	
+	aggregated := &models.AggregatedCurrentWeather{
 		City:        data.City,
-		Temperature: totalTemp / count,
-		FeelsLike:   totalFeelsLike / count,
-		Humidity:    totalHumidity / count,
-		Pressure:    totalPressure / count,
-		WindSpeed:   totalWindSpeed / count,
+		Temperature: utils.WeightedMean(temps, weights),
+		FeelsLike:   utils.WeightedMean(feelsLikes, weights),
+		Humidity:    utils.WeightedMean(humidities, weights),
+		Pressure:    utils.WeightedMean(pressures, weights),
+		WindSpeed:   utils.WeightedMean(windSpeeds, weights),
+		WindGust:    gusts.mean(),
+		CloudCover:  utils.Clamp(utils.WeightedMean(cloudCovers, weights), 0, 100),
+		Visibility:  visibility,
+		Condition:   condition,
 		Description: description,
 		Icon:        icon,
 		LastUpdated: latestTimestamp,
 		Sources:     sources,
+		SourceTimestamps: timestamps,
 		Confidence:  confidence,
+		SourceCount: len(sources),
+		Degraded:    len(sources) < 2,
+		ResolvedLatitude:  latitude,
+		ResolvedLongitude: longitude,
+		DistanceKm:  distanceKm,
+		Units:       models.UnitsMetric,
+		UnitLabels:  currentUnitLabels(models.UnitsMetric, models.PressureHPa),
+	}
+	
+	// Derived from the aggregated readings, left out outside their valid ranges
+	if heatIndex, ok := utils.HeatIndex(aggregated.Temperature, aggregated.Humidity); ok {
+		aggregated.HeatIndex = &heatIndex
+	}
+	if windChill, ok := utils.WindChill(aggregated.Temperature, aggregated.WindSpeed); ok {
+		aggregated.WindChill = &windChill
+	}
+	aggregated.PrecipitationType = precipitationType(aggregated.Condition, aggregated.Temperature)
+	
+	return aggregated
+}
+
+// alignDaysByIndex groups the sources' days by position, the first day of
+// every source together and so on, up to days
+func alignDaysByIndex(forecasts [][]models.ForecastDay, days int) [][]models.ForecastDay {
+	var groups [][]models.ForecastDay
+	for day := 0; day < days; day++ {
+		var group []models.ForecastDay
+		for _, forecast := range forecasts {
+			if day < len(forecast) {
+				group = append(group, forecast[day])
+			}
+		}
+		if len(group) == 0 {
+			break
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// alignDaysByDate groups the sources' days by calendar date, so a source
+// whose forecast starts tomorrow is not averaged into today. It returns the
+// earliest days dates, each group in source order.
+func alignDaysByDate(forecasts [][]models.ForecastDay, days int) [][]models.ForecastDay {
+	byDate := make(map[string][]models.ForecastDay)
+	var dates []string
+	for _, forecast := range forecasts {
+		for _, day := range forecast {
+			date := day.Date.Format("2006-01-02")
+			if _, seen := byDate[date]; !seen {
+				dates = append(dates, date)
+			}
+			byDate[date] = append(byDate[date], day)
+		}
+	}
+	
+	sort.Strings(dates)
+	if len(dates) > days {
+		dates = dates[:days]
+	}
+	
+	groups := make([][]models.ForecastDay, 0, len(dates))
+	for _, date := range dates {
+		groups = append(groups, byDate[date])
+	}
+	return groups
+}
+
+// resolvedLocation picks the coordinates reported for the aggregate. With a
+// coordinates source they are that source's, or the geocoder's result for the
+// city when the source did not contribute, so the location stays the same
+// across requests. Otherwise they are the resolved point closest to the
+// geocoded city center, measured here since not every provider knows it, or
+// the first source's point when the city is not geocoded.
+func (a *Aggregator) resolvedLocation(data *models.WeatherData, current map[string]*models.CurrentWeather) (latitude, longitude, distanceKm float64) {
+	center, geocoded := a.geocoder.Cached(data.Query, data.Country)
+	
+	if a.coordinatesSource != "" {
+		if weather, ok := current[a.coordinatesSource]; ok {
+			if geocoded {
+				return weather.ResolvedLatitude, weather.ResolvedLongitude,
+					utils.HaversineKm(center.Latitude, center.Longitude, weather.ResolvedLatitude, weather.ResolvedLongitude)
+			}
+			return weather.ResolvedLatitude, weather.ResolvedLongitude, weather.DistanceKm
+		}
+		if geocoded {
+			return center.Latitude, center.Longitude, 0
+		}
+	}
+	
+	sources := orderedSources(current, a.primarySource)
+	if !geocoded {
+		first := current[sources[0]]
+		return first.ResolvedLatitude, first.ResolvedLongitude, first.DistanceKm
+	}
+	
+	nearest, nearestKm := current[sources[0]], math.Inf(1)
+	for _, source := range sources {
+		weather := current[source]
+		if km := utils.HaversineKm(center.Latitude, center.Longitude, weather.ResolvedLatitude, weather.ResolvedLongitude); km < nearestKm {
+			nearest, nearestKm = weather, km
+		}
 	}
+	return nearest.ResolvedLatitude, nearest.ResolvedLongitude, nearestKm
 }
 
 func (a *Aggregator) aggregateForecast(data *models.WeatherData, days int) *models.AggregatedForecast {
@@ -287,39 +966,63 @@ func (a *Aggregator) aggregateForecast(data *models.WeatherData, days int) *mode
 		return nil
 	}
 	
-	// Collect forecasts from all sources
+	// Collect forecasts from all sources; shorter horizons still contribute
+	// to the days they cover
 	allForecasts := make([][]models.ForecastDay, 0, len(data.Forecasts))
 	var sources []string
 	
-	for source, forecast := range data.Forecasts {
-		if len(forecast.Forecast) >= days {
-			allForecasts = append(allForecasts, forecast.Forecast[:days])
-			sources = append(sources, source)
+	for _, source := range orderedSources(data.Forecasts, a.primarySource) {
+		forecast := data.Forecasts[source]
+		if forecast == nil || len(forecast.Forecast) == 0 {
+			continue
 		}
+		allForecasts = append(allForecasts, forecast.Forecast)
+		sources = append(sources, source)
 	}
 	
 	if len(allForecasts) == 0 {
 		return nil
 	}
 	
-	// Aggregate daily forecasts
-	aggregatedDays := make([]models.ForecastDay, days)
+	var dayGroups [][]models.ForecastDay
+	if a.forecastAlignment == config.ForecastAlignmentIndex {
+		dayGroups = alignDaysByIndex(allForecasts, days)
+	} else {
+		dayGroups = alignDaysByDate(allForecasts, days)
+	}
 	
-	for day := 0; day < days; day++ {
-		var totalMaxTemp, totalMinTemp, totalAvgTemp, totalHumidity, totalPrecipitation float64
-		var dayDescriptions []string
+	// Aggregate daily forecasts, up to the longest horizon any source offers
+	aggregatedDays := make([]models.ForecastDay, 0, len(dayGroups))
+	var totalConfidence float64
+	
+	for _, group := range dayGroups {
+		var totalMaxTemp, totalMinTemp, totalAvgTemp, totalHumidity, totalPrecipitation, totalRain, totalSnowfall, sumsPrecipitation float64
+		var sumsCount int // days whose source splits rain and snowfall
+		var dayGusts gustAverage
+		var dayDescriptions, dayIcons []string
+		var dayConditions []models.ConditionCode
+		var dayTemps []float64
 		var date time.Time
 		
 		dayCount := 0
-		for _, forecast := range allForecasts {
-			if day < len(forecast) {
-				dayForecast := forecast[day]
+		for _, dayForecast := range group {
+			if finiteDay(dayForecast) {
 				totalMaxTemp += dayForecast.MaxTemp
 				totalMinTemp += dayForecast.MinTemp
 				totalAvgTemp += dayForecast.AvgTemp
+				dayTemps = append(dayTemps, dayForecast.AvgTemp)
 				totalHumidity += dayForecast.Humidity
 				totalPrecipitation += dayForecast.Precipitation
+				if dayForecast.HasPrecipitationSums {
+					totalRain += dayForecast.RainSum
+					totalSnowfall += dayForecast.SnowfallSum
+					sumsPrecipitation += dayForecast.Precipitation
+					sumsCount++
+				}
+				dayGusts.add(dayForecast.WindGust)
 				dayDescriptions = append(dayDescriptions, dayForecast.Description)
+				dayConditions = append(dayConditions, dayForecast.Condition)
+				dayIcons = append(dayIcons, dayForecast.Icon)
 				date = dayForecast.Date
 				dayCount++
 			}
@@ -330,17 +1033,44 @@ func (a *Aggregator) aggregateForecast(data *models.WeatherData, days int) *mode
 		}
 		
 		dayCountFloat := float64(dayCount)
+		// When some sources split rain and snowfall, the total comes from those same
+		// sources so it never falls below the sums it is made of
+		precipitation := totalPrecipitation / dayCountFloat
+		var rainSum, snowfallSum float64
+		if sumsCount > 0 {
+			precipitation = sumsPrecipitation / float64(sumsCount)
+			rainSum = totalRain / float64(sumsCount)
+			snowfallSum = totalSnowfall / float64(sumsCount)
+		}
+		dayCondition, dayDescription := aggregateCondition(a.conditionStrategy, dayConditions, dayDescriptions)
+		dayIcon := pickIcon(dayCondition, dayDescription, dayConditions, dayDescriptions, dayIcons)
 		
-		aggregatedDays[day] = models.ForecastDay{
+		// Source independent, taken at midday of the forecast date
+		moonValue, moonName := utils.MoonPhase(date.Add(12 * time.Hour))
+		
+		aggregatedDays = append(aggregatedDays, models.ForecastDay{
 			Date:          date,
 			MaxTemp:       totalMaxTemp / dayCountFloat,
 			MinTemp:       totalMinTemp / dayCountFloat,
 			AvgTemp:       totalAvgTemp / dayCountFloat,
 			Humidity:      totalHumidity / dayCountFloat,
-			Description:   mostCommonString(dayDescriptions),
-			Icon:          allForecasts[0][day].Icon, // Use icon from first source
-			Precipitation: totalPrecipitation / dayCountFloat,
-		}
+			Condition:     dayCondition,
+			Description:   dayDescription,
+			Icon:          dayIcon,
+			Precipitation: precipitation,
+			RainSum:       rainSum,
+			SnowfallSum:   snowfallSum,
+			HasPrecipitationSums: sumsCount > 0,
+			WindGust:      dayGusts.mean(),
+			MoonPhase:     models.MoonPhase{Value: moonValue, Name: moonName},
+			Confidence:    temperatureConfidence(dayTemps),
+		})
+		totalConfidence += aggregatedDays[len(aggregatedDays)-1].Confidence
+	}
+	
+	// Every source's days lacked usable temperatures
+	if len(aggregatedDays) == 0 {
+		return nil
 	}
 	
 	return &models.AggregatedForecast{
@@ -348,72 +1078,499 @@ func (a *Aggregator) aggregateForecast(data *models.WeatherData, days int) *mode
 		Days:        aggregatedDays,
 		LastUpdated: time.Now(),
 		Sources:     sources,
+		Confidence:  totalConfidence / float64(len(aggregatedDays)),
+		Units:       models.UnitsMetric,
+		UnitLabels:  forecastUnitLabels(models.UnitsMetric, models.PrecipitationMM),
 	}
 }
 
-func (a *Aggregator) GetAggregatedCurrentWeather(ctx context.Context, city string) (*models.AggregatedCurrentWeather, error) {
-	// Check cache first
-	if cached, ok := a.cache.GetCurrentWeather(city); ok {
-		a.logger.Debug("Cache hit for current weather", zap.String("city", city))
-		return cached, nil
+// cachedCurrentWeather looks up the response for opts, deriving and caching it
+// from the canonical data entry when only that one is present. The lookups are
+// not counted, the caller records one hit or miss per request.
+func (a *Aggregator) cachedCurrentWeather(city string, opts models.QueryOptions) (*models.AggregatedCurrentWeather, time.Time, bool) {
+	key := cacheKey(city, opts)
+	if cached, expiresAt, ok := a.cache.peekCurrentWeather(key); ok {
+		return withTimestamps(cached, opts), expiresAt, true
 	}
 	
-	// Fetch fresh data if not in cache
-	a.logger.Debug("Cache miss for current weather, fetching fresh data", zap.String("city", city))
-	
-	// Use a shorter context timeout for this request
-	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-	
-	// Fetch from single city
-	cities := []string{city}
-	if err := a.FetchWeatherData(fetchCtx, cities); err != nil {
-		return nil, fmt.Errorf("failed to fetch weather for %s: %w", city, err)
+	baseKey := dataKey(city, opts)
+	if baseKey == key {
+		return nil, time.Time{}, false
 	}
 	
-	// Get from cache after fetch
-	if cached, ok := a.cache.GetCurrentWeather(city); ok {
-		return cached, nil
+	canonical, expiresAt, ok := a.cache.peekCurrentWeather(baseKey)
+	if !ok {
+		return nil, time.Time{}, false
 	}
 	
-	return nil, fmt.Errorf("weather data not available for %s", city)
+	converted := convertCurrentWeather(canonical, opts)
+	a.cache.setCurrentWeatherUntil(key, converted, expiresAt)
+	return withTimestamps(converted, opts), expiresAt, true
 }
 
-func (a *Aggregator) GetAggregatedForecast(ctx context.Context, city string, days int) (*models.AggregatedForecast, error) {
-	// Validate days parameter
-	if days < 1 || days > 7 {
-		return nil, fmt.Errorf("days must be between 1 and 7")
+// withTimestamps drops the per-source timestamps unless opts asks for them.
+// Cached aggregates are shared, so the timestamps are removed from a copy.
+func withTimestamps(weather *models.AggregatedCurrentWeather, opts models.QueryOptions) *models.AggregatedCurrentWeather {
+	if weather == nil || opts.Timestamps || weather.SourceTimestamps == nil {
+		return weather
+	}
+	stripped := *weather
+	stripped.SourceTimestamps = nil
+	return &stripped
+}
+
+// cachedForecast looks up days of the forecast for opts without counting the
+// lookups, see cachedCurrentWeather
+func (a *Aggregator) cachedForecast(city string, days int, opts models.QueryOptions) (*models.AggregatedForecast, time.Time, bool) {
+	if key := forecastCacheKey(city, opts); key == dataKey(city, opts) && a.cachesHorizon(days) {
+		if cached, expiresAt, ok := a.cache.peekForecast(horizonKey(key, days)); ok {
+			return cached, expiresAt, true
+		}
+	}
+	
+	full, expiresAt, ok := a.cachedFullForecast(city, opts)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return SliceForecast(full, days), expiresAt, true
+}
+
+// cachedFullForecast looks up the full-horizon forecast for opts, deriving and
+// caching it from the canonical data entry when only that one is present
+func (a *Aggregator) cachedFullForecast(city string, opts models.QueryOptions) (*models.AggregatedForecast, time.Time, bool) {
+	key := forecastCacheKey(city, opts)
+	if cached, expiresAt, ok := a.cache.peekForecast(key); ok {
+		return cached, expiresAt, true
+	}
+	
+	baseKey := dataKey(city, opts)
+	if baseKey == key {
+		return nil, time.Time{}, false
+	}
+	
+	canonical, expiresAt, ok := a.cache.peekForecast(baseKey)
+	if !ok {
+		return nil, time.Time{}, false
 	}
 	
-	// Check cache first
-	if cached, ok := a.cache.GetForecast(city, days); ok {
-		a.logger.Debug("Cache hit for forecast",
+	converted := convertForecast(canonical, opts)
+	if opts.Smooth {
+		converted.Days = smoothForecastDays(converted.Days, a.smoothingWindow)
+	}
+	a.cache.setForecastUntil(key, converted, expiresAt)
+	return converted, expiresAt, true
+}
+
+// cachesHorizon reports whether days is one of the configured horizons cached
+// next to the full one
+func (a *Aggregator) cachesHorizon(days int) bool {
+	for _, horizon := range a.forecastHorizons {
+		if horizon == days {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedHorizons sorts and deduplicates the configured horizons, leaving out
+// the full one which is always cached
+func cachedHorizons(configured []int, forecastDays int) []int {
+	seen := map[int]bool{forecastDays: true}
+	var horizons []int
+	for _, days := range configured {
+		if !seen[days] {
+			seen[days] = true
+			horizons = append(horizons, days)
+		}
+	}
+	sort.Ints(horizons)
+	return horizons
+}
+
+// SliceForecast returns the first days of forecast
+func SliceForecast(forecast *models.AggregatedForecast, days int) *models.AggregatedForecast {
+	sliced := *forecast
+	if days < len(forecast.Days) {
+		sliced.Days = forecast.Days[:days]
+		
+		// The overall confidence only covers the days that are kept
+		var total float64
+		for _, day := range sliced.Days {
+			total += day.Confidence
+		}
+		sliced.Confidence = total / float64(len(sliced.Days))
+	}
+	return &sliced
+}
+
+func (a *Aggregator) GetAggregatedCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.AggregatedCurrentWeather, error) {
+	weather, err := a.currentWeather(ctx, city, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Blend {
+		weather = a.blendWithForecast(city, weather, opts)
+	}
+	if opts.Breakdown {
+		weather = a.withBreakdown(city, weather, opts)
+	}
+	return weather, nil
+}
+
+func (a *Aggregator) currentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.AggregatedCurrentWeather, error) {
+	// Check cache first, the only lookup of the request counted in the stats
+	if cached, expiresAt, ok := a.cachedCurrentWeather(city, opts); ok {
+		if isFreshEnough(cached.LastUpdated, opts.MaxAge) {
+			a.logger.Debug("Cache hit for current weather", zap.String("city", city))
+			a.cache.recordLookup(true)
+			recordExpiry(ctx, expiresAt)
+			return cached, nil
+		}
+		a.logger.Debug("Cached current weather older than max age, refreshing",
 			zap.String("city", city),
-			zap.Int("days", days))
+			zap.Duration("max_age", opts.MaxAge))
+	}
+	
+	// Fetch fresh data if not in cache
+	a.logger.Debug("Cache miss for current weather, fetching fresh data", zap.String("city", city))
+	a.cache.recordLookup(false)
+	
+	if err := a.checkAvailability(opts); err != nil {
+		// Every breaker open means every provider kept failing; maintenance or
+		// disabled providers are not failures to paper over
+		var unavailable *UnavailableError
+		if errors.As(err, &unavailable) {
+			if stale, ok := a.staleCurrentWeather(ctx, city, opts, err); ok {
+				return stale, nil
+			}
+		}
+		return nil, err
+	}
+	
+	// Use a shorter context timeout for this request
+	fetchCtx, cancel := context.WithTimeout(ctx, a.fetchTimeout)
+	defer cancel()
+	
+	// Fetch from single city
+	cities := []string{city}
+	if err := a.fetchWeatherData(fetchCtx, cities, opts); err != nil {
+		if stale, ok := a.staleCurrentWeather(ctx, city, opts, err); ok {
+			return stale, nil
+		}
+		return nil, fmt.Errorf("failed to fetch weather for %s: %w", city, err)
+	}
+	
+	// Get from cache after fetch
+	if cached, expiresAt, ok := a.cachedCurrentWeather(city, opts); ok {
+		recordExpiry(ctx, expiresAt)
 		return cached, nil
 	}
 	
+	return nil, fmt.Errorf("weather for %s: %w", city, ErrNoData)
+}
+
+// staleCurrentWeather returns the last known good current weather, flagged
+// stale, in place of failing the request with err. An entry older than the
+// request's max age is not served either.
+func (a *Aggregator) staleCurrentWeather(ctx context.Context, city string, opts models.QueryOptions, err error) (*models.AggregatedCurrentWeather, bool) {
+	lastKnown, ok := a.lastKnown.get(dataKey(city, opts))
+	if !ok || !isFreshEnough(lastKnown.LastUpdated, opts.MaxAge) {
+		return nil, false
+	}
+	
+	a.logger.Warn("Live fetch failed, serving last known current weather",
+		zap.String("city", city),
+		zap.Time("last_updated", lastKnown.LastUpdated),
+		zap.Error(err))
+	
+	var stale models.AggregatedCurrentWeather
+	if dataKey(city, opts) != cacheKey(city, opts) {
+		stale = *convertCurrentWeather(lastKnown, opts)
+	} else {
+		stale = *lastKnown
+	}
+	stale.Stale = true
+	
+	// Already past its expiry, intermediaries must not keep it
+	recordExpiry(ctx, time.Now())
+	return withTimestamps(&stale, opts), true
+}
+
+func (a *Aggregator) GetAggregatedForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.AggregatedForecast, error) {
+	// Validate days parameter
+	if days < 1 || days > a.forecastDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", a.forecastDays)
+	}
+	
+	// Check cache first, the only lookup of the request counted in the stats
+	if cached, expiresAt, ok := a.cachedForecast(city, days, opts); ok {
+		if isFreshEnough(cached.LastUpdated, opts.MaxAge) {
+			a.logger.Debug("Cache hit for forecast",
+				zap.String("city", city),
+				zap.Int("days", days))
+			a.cache.recordLookup(true)
+			recordExpiry(ctx, expiresAt)
+			return cached, nil
+		}
+		a.logger.Debug("Cached forecast older than max age, refreshing",
+			zap.String("city", city),
+			zap.Int("days", days),
+			zap.Duration("max_age", opts.MaxAge))
+	}
+	
 	// Fetch fresh data if not in cache
 	a.logger.Debug("Cache miss for forecast, fetching fresh data",
 		zap.String("city", city),
 		zap.Int("days", days))
+	a.cache.recordLookup(false)
+	
+	if err := a.checkAvailability(opts); err != nil {
+		return nil, err
+	}
 	
 	// Use a shorter context timeout for this request
-	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	fetchCtx, cancel := context.WithTimeout(ctx, a.fetchTimeout)
 	defer cancel()
 	
 	// Fetch from single city
 	cities := []string{city}
-	if err := a.FetchWeatherData(fetchCtx, cities); err != nil {
+	if err := a.fetchWeatherData(fetchCtx, cities, opts); err != nil {
 		return nil, fmt.Errorf("failed to fetch forecast for %s: %w", city, err)
 	}
 	
-	// Get from cache after fetch
-	if cached, ok := a.cache.GetForecast(city, days); ok {
+	// Get from cache after fetch, nothing is cached when no provider returned
+	// usable forecast days
+	if cached, expiresAt, ok := a.cachedForecast(city, days, opts); ok && len(cached.Days) > 0 {
+		recordExpiry(ctx, expiresAt)
 		return cached, nil
 	}
 	
-	return nil, fmt.Errorf("forecast data not available for %s", city)
+	return nil, fmt.Errorf("forecast for %s: %w", city, ErrNoForecastDays)
+}
+
+// GetWeatherAt returns conditions at a future moment, interpolated from the
+// hourly forecasts of every source that covers it
+func (a *Aggregator) GetWeatherAt(ctx context.Context, city string, at time.Time, opts models.QueryOptions) (*models.PointForecast, error) {
+	key := dataKey(city, opts)
+	
+	a.mu.RLock()
+	weatherData, exists := a.weatherData[key]
+	a.mu.RUnlock()
+	
+	if !exists || time.Since(weatherData.Timestamp) > a.dataTTL {
+		if err := a.checkAvailability(opts); err != nil {
+			return nil, err
+		}
+		
+		fetchCtx, cancel := context.WithTimeout(ctx, a.fetchTimeout)
+		defer cancel()
+		
+		if err := a.fetchWeatherData(fetchCtx, []string{city}, opts); err != nil {
+			return nil, fmt.Errorf("failed to fetch forecast for %s: %w", city, err)
+		}
+		
+		a.mu.RLock()
+		weatherData, exists = a.weatherData[key]
+		a.mu.RUnlock()
+		if !exists {
+			return nil, fmt.Errorf("forecast for %s: %w", city, ErrNoData)
+		}
+	}
+	
+	recordExpiry(ctx, weatherData.Timestamp.Add(a.dataTTL))
+	point, err := aggregatePointForecast(weatherData, at, a.conditionStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return convertPointForecast(point, opts), nil
+}
+
+// GetTrends returns the stored snapshots of a city between from and to
+func (a *Aggregator) GetTrends(ctx context.Context, city string, from, to time.Time) (*models.WeatherTrend, error) {
+	if a.history == nil {
+		return nil, ErrHistoryDisabled
+	}
+	
+	points, err := a.history.QueryRange(ctx, city, from, to)
+	if err != nil {
+		return nil, err
+	}
+	
+	return &models.WeatherTrend{
+		City:   city,
+		From:   from,
+		To:     to,
+		Points: points,
+	}, nil
+}
+
+// recordHistory stores the snapshot when history is enabled. Only the default
+// language variant is stored since the numbers do not depend on it.
+func (a *Aggregator) recordHistory(key string, weather *models.AggregatedCurrentWeather) {
+	if a.history == nil || weather == nil {
+		return
+	}
+	if key != dataKey(cityFromKey(key), models.QueryOptions{}) {
+		return
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	
+	if err := a.history.SaveSnapshot(ctx, weather); err != nil {
+		a.logger.Warn("Failed to store weather snapshot",
+			zap.String("city", weather.City),
+			zap.Error(err))
+	}
+}
+
+// Close stops the aggregator's background goroutines, waiting for running
+// prefetches and late provider responses until ctx is done and cancelling
+// them after, saves the fetch stats and releases history storage, the remote
+// cache tier and idle HTTP connections. Later calls return the result of the
+// first one.
+func (a *Aggregator) Close(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		a.closeErr = a.close(ctx)
+	})
+	return a.closeErr
+}
+
+func (a *Aggregator) close(ctx context.Context) error {
+	var errs []error
+	
+	// Prefetches are started by the cleanup tick, none start once it stopped
+	a.cache.Stop()
+	
+	finished := make(chan struct{})
+	go func() {
+		a.background.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		// Cancelled fetches return promptly, and nothing may write to the
+		// cache or history once they are closed below
+		errs = append(errs, fmt.Errorf("background fetches still running: %w", ctx.Err()))
+		a.cancelShutdown()
+		<-finished
+	}
+	a.cancelShutdown()
+	
+	if a.stopStats != nil {
+		close(a.stopStats)
+		<-a.statsDone
+		if err := a.SaveStats(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	
+	if a.history != nil {
+		if err := a.history.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close history storage: %w", err))
+		}
+	}
+	
+	if err := a.cache.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close remote cache: %w", err))
+	}
+	
+	a.transport.CloseIdleConnections()
+	
+	return errors.Join(errs...)
+}
+
+// SetTrackedCities records the cities that are fetched on schedule
+func (a *Aggregator) SetTrackedCities(cities []string) {
+	a.mu.Lock()
+	a.trackedCities = append([]string(nil), cities...)
+	a.mu.Unlock()
+}
+
+// GetAllCurrentWeather returns the current weather of every tracked city from
+// the cache only. Expired entries are rebuilt from the last fetched data and
+// marked stale; nothing is fetched.
+func (a *Aggregator) GetAllCurrentWeather() []models.CityCurrentWeather {
+	a.mu.RLock()
+	cities := a.trackedCities
+	a.mu.RUnlock()
+	
+	results := make([]models.CityCurrentWeather, 0, len(cities))
+	for _, city := range cities {
+		entry := models.CityCurrentWeather{City: city}
+		
+		if cached, _, ok := a.cache.GetCurrentWeather(dataKey(city, models.QueryOptions{})); ok {
+			entry.Weather = withTimestamps(cached, models.QueryOptions{})
+		} else {
+			a.mu.RLock()
+			weatherData, exists := a.weatherData[dataKey(city, models.QueryOptions{})]
+			a.mu.RUnlock()
+			
+			if exists && len(weatherData.Current) > 0 {
+				entry.Weather = withTimestamps(a.aggregateCurrentWeather(weatherData), models.QueryOptions{})
+				entry.Stale = true
+			} else {
+				entry.Error = "no data cached yet"
+			}
+		}
+		
+		results = append(results, entry)
+	}
+	
+	return results
+}
+
+// InvalidateCity drops everything held for a city that is no longer tracked
+func (a *Aggregator) InvalidateCity(city string) {
+	a.mu.Lock()
+	for key := range a.weatherData {
+		if matchesCity(key, city) {
+			delete(a.weatherData, key)
+		}
+	}
+	a.mu.Unlock()
+	
+	a.cache.Delete(city)
+	a.trends.forget(city)
+	a.lastKnown.deleteCity(city)
+	
+	a.logger.Info("Invalidated cached weather data", zap.String("city", city))
+}
+
+// checkAvailability fails fast with an UnavailableError when every client's
+// breaker is open, carrying the earliest time one of them accepts a probe
+func (a *Aggregator) checkAvailability(opts models.QueryOptions) error {
+	if a.maintenance.Load() {
+		return ErrMaintenance
+	}
+	
+	clients := a.enabledClients()
+	if len(clients) == 0 {
+		return ErrNoEnabledProvider
+	}
+	if clients = selectSources(clients, opts.Sources); len(clients) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoRequestedSource, strings.Join(opts.Sources, ", "))
+	}
+	
+	var retryAfter time.Duration
+	for i, c := range clients {
+		remaining := c.BreakerRetryAfter()
+		if remaining == 0 {
+			return nil
+		}
+		if i == 0 || remaining < retryAfter {
+			retryAfter = remaining
+		}
+	}
+	
+	return &UnavailableError{RetryAfter: retryAfter}
+}
+
+// isFreshEnough reports whether data last updated at the given time satisfies
+// the requested max age; a zero max age accepts any cached entry
+func isFreshEnough(lastUpdated time.Time, maxAge time.Duration) bool {
+	return maxAge <= 0 || time.Since(lastUpdated) <= maxAge
 }
 
 func (a *Aggregator) GetLastFetchTime() time.Time {
@@ -422,44 +1579,270 @@ func (a *Aggregator) GetLastFetchTime() time.Time {
 	return a.lastFetchTime
 }
 
+// GetLastSuccessTime returns when data was last fetched successfully, zero before the first success
+func (a *Aggregator) GetLastSuccessTime() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastSuccessTime
+}
+
 func (a *Aggregator) GetStats() map[string]interface{} {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	
 	cacheStats := a.cache.GetStats()
 	
+	perSource := make(map[string]interface{}, len(a.sourceStats))
+	for source, stats := range a.sourceStats {
+		perSource[source] = stats.toMap()
+	}
+	
 	return map[string]interface{}{
+		"source_stats":     perSource,
 		"last_fetch_time":  a.lastFetchTime,
 		"success_count":    a.successCount,
 		"failure_count":    a.failureCount,
 		"cities_stored":    len(a.weatherData),
 		"active_clients":   len(a.clients),
 		"cache_stats":      cacheStats,
+		"upstream_usage":   a.providerUsage(),
 	}
 }
 
-func getSourceName(client interface{}) string {
-	switch client.(type) {
-	case *client.OpenWeatherClient:
-		return "openweathermap"
-	case *client.OpenMeteoClient:
-		return "open-meteo"
-	default:
-		return "unknown"
+// DumpCache describes the entries currently in the cache, for troubleshooting
+func (a *Aggregator) DumpCache() []models.CacheEntryInfo {
+	return a.cache.Dump()
+}
+
+// GetProviderUsage returns the upstream calls made to each provider, and to
+// the geocoder they share, in the current hour and day
+func (a *Aggregator) GetProviderUsage() []models.ProviderUsage {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	
+	return a.providerUsage()
+}
+
+func (a *Aggregator) providerUsage() []models.ProviderUsage {
+	usage := make([]models.ProviderUsage, 0, len(a.clients)+1)
+	for _, c := range a.clients {
+		hour, day := c.Usage()
+		entry := models.ProviderUsage{Name: c.Name(), Hour: hour, Day: day}
+		if rotating, ok := c.(KeyRotatingClient); ok {
+			entry.Keys = rotating.KeyUsage()
+		}
+		usage = append(usage, entry)
 	}
+	
+	hour, day := a.geocoder.Usage()
+	usage = append(usage, models.ProviderUsage{Name: "geocoder", Hour: hour, Day: day})
+	return usage
 }
 
-func calculateConfidence(currentWeather map[string]*models.CurrentWeather) float64 {
-	if len(currentWeather) <= 1 {
-		return 0.5
+func (a *Aggregator) recordSourceOutcome(source string, success bool, latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	stats, exists := a.sourceStats[source]
+	if !exists {
+		stats = newSourceStats()
+		a.sourceStats[source] = stats
+	}
+	stats.record(success)
+	if success {
+		stats.recordLatency(latency)
+	}
+}
+
+// recordAgreement tracks how far each source's temperature is from the mean of
+// the other sources. A lone source has nothing to agree with and is skipped.
+func (a *Aggregator) recordAgreement(current map[string]*models.CurrentWeather) {
+	if len(current) < 2 {
+		return
 	}
 	
-	// Calculate variance in temperatures
+	var total float64
+	for _, weather := range current {
+		total += weather.Temperature
+	}
+	others := float64(len(current) - 1)
+	
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	for source, weather := range current {
+		othersMean := (total - weather.Temperature) / others
+		
+		stats, exists := a.sourceStats[source]
+		if !exists {
+			stats = newSourceStats()
+			a.sourceStats[source] = stats
+		}
+		stats.recordDeviation(math.Abs(weather.Temperature - othersMean))
+	}
+}
+
+// sourceWeights returns the aggregation weight of each source, all equal unless
+// adaptive weighting is enabled. Only current weather is weighted: agreement is
+// learned from current temperatures and says nothing about forecast skill, so
+// aggregateForecast keeps an equal mean.
+func (a *Aggregator) sourceWeights(current map[string]*models.CurrentWeather) map[string]float64 {
+	weights := make(map[string]float64, len(current))
+	
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	
+	for source := range current {
+		weights[source] = 1
+		if !a.adaptive {
+			continue
+		}
+		if stats, exists := a.sourceStats[source]; exists {
+			weights[source] = stats.adaptiveWeight()
+		}
+	}
+	return weights
+}
+
+// SetMaintenance switches maintenance mode, in which requests are answered
+// from the cache only and neither misses nor the scheduler call providers
+func (a *Aggregator) SetMaintenance(enabled bool) {
+	a.maintenance.Store(enabled)
+	a.logger.Info("Maintenance mode changed", zap.Bool("enabled", enabled))
+}
+
+func (a *Aggregator) InMaintenance() bool {
+	return a.maintenance.Load()
+}
+
+// SetProviderEnabled switches a source on or off for subsequent fetches
+func (a *Aggregator) SetProviderEnabled(name string, enabled bool) error {
+	for _, c := range a.clients {
+		if c.Name() != name {
+			continue
+		}
+		
+		a.mu.Lock()
+		if enabled {
+			delete(a.disabled, name)
+		} else {
+			a.disabled[name] = true
+		}
+		a.mu.Unlock()
+		
+		a.logger.Info("Provider state changed",
+			zap.String("provider", name),
+			zap.Bool("enabled", enabled))
+		return nil
+	}
+	
+	return fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+}
+
+// hasClient reports whether a client with the given source name is registered
+func (a *Aggregator) hasClient(name string) bool {
+	for _, c := range a.clients {
+		if c.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Aggregator) enabledClients() []WeatherClient {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	
+	clients := make([]WeatherClient, 0, len(a.clients))
+	for _, c := range a.clients {
+		if !a.disabled[c.Name()] {
+			clients = append(clients, c)
+		}
+	}
+	return clients
+}
+
+// selectSources keeps the clients named in sources, all of them when sources is empty
+func selectSources(clients []WeatherClient, sources []string) []WeatherClient {
+	if len(sources) == 0 {
+		return clients
+	}
+	
+	selected := make([]WeatherClient, 0, len(sources))
+	for _, c := range clients {
+		for _, source := range sources {
+			if c.Name() == source {
+				selected = append(selected, c)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// GetProviders reports every registered client with its breaker state and recent reliability
+func (a *Aggregator) GetProviders() []models.ProviderStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	
+	providers := make([]models.ProviderStatus, 0, len(a.clients))
+	for _, c := range a.clients {
+		status := models.ProviderStatus{
+			Name:           c.Name(),
+			RequiresAPIKey: c.RequiresAPIKey(),
+			Enabled:        !a.disabled[c.Name()],
+			BreakerState:   c.BreakerState(),
+			SuccessRate:    1,
+		}
+		
+		if stats, exists := a.sourceStats[c.Name()]; exists {
+			status.SuccessRate = stats.successRate()
+			status.Successes = stats.successes
+			status.Failures = stats.failures
+			status.LatencyMs = float64(stats.latency) / float64(time.Millisecond)
+		}
+		
+		providers = append(providers, status)
+	}
+	
+	return providers
+}
+
+func calculateConfidence(currentWeather map[string]*models.CurrentWeather) float64 {
 	var temps []float64
 	for _, weather := range currentWeather {
-		temps = append(temps, weather.Temperature)
+		if weather != nil {
+			temps = append(temps, weather.Temperature)
+		}
 	}
 	
+	return temperatureConfidence(temps)
+}
+
+// finiteDay reports whether a forecast day carries temperatures that can be averaged
+func finiteDay(day models.ForecastDay) bool {
+	return utils.IsFinite(day.MaxTemp) && utils.IsFinite(day.MinTemp) && utils.IsFinite(day.AvgTemp)
+}
+
+// temperatureConfidence scores agreement between the temperatures reported by
+// several sources for the same time. Without any usable reading there is
+// nothing to be confident about.
+func temperatureConfidence(readings []float64) float64 {
+	var temps []float64
+	for _, temp := range readings {
+		if utils.IsFinite(temp) {
+			temps = append(temps, temp)
+		}
+	}
+	
+	if len(temps) == 0 {
+		return 0
+	}
+	if len(temps) == 1 {
+		return 0.5
+	}
+	
+	// Calculate variance in temperatures
 	mean := 0.0
 	for _, temp := range temps {
 		mean += temp
@@ -483,7 +1866,7 @@ func calculateConfidence(currentWeather map[string]*models.CurrentWeather) float
 	confidence := 1 - normalizedVariance
 	
 	// Boost confidence with more sources
-	sourceBoost := float64(len(currentWeather)-1) * 0.1
+	sourceBoost := float64(len(temps)-1) * 0.1
 	confidence += sourceBoost
 	
 	if confidence > 1 {
@@ -496,6 +1879,67 @@ func calculateConfidence(currentWeather map[string]*models.CurrentWeather) float
 	return confidence
 }
 
+// gustAverage averages wind gusts over the sources that report them. Providers
+// without gust data leave the field at zero, which would otherwise drag the
+// mean down.
+type gustAverage struct {
+	total float64
+	count int
+}
+
+func (g *gustAverage) add(gust float64) {
+	if gust > 0 {
+		g.total += gust
+		g.count++
+	}
+}
+
+func (g *gustAverage) mean() float64 {
+	if g.count == 0 {
+		return 0
+	}
+	return g.total / float64(g.count)
+}
+
+// aggregateCondition picks the normalized condition by strategy, the most
+// common one or with severity the most severe one, and, as the
+// display text, the most common description among the readings reporting it.
+// conditions and descriptions are parallel slices.
+func aggregateCondition(strategy string, conditions []models.ConditionCode, descriptions []string) (models.ConditionCode, string) {
+	var condition models.ConditionCode
+	if strategy == config.ConditionAggregationSeverity {
+		condition = mostSevereCondition(conditions)
+	} else {
+		codes := make([]string, len(conditions))
+		for i, c := range conditions {
+			codes[i] = string(c)
+		}
+		condition = models.ConditionCode(mostCommonString(codes))
+	}
+	
+	var matching []string
+	for i, c := range conditions {
+		if c == condition {
+			matching = append(matching, descriptions[i])
+		}
+	}
+	
+	return condition, mostCommonString(matching)
+}
+
+// mostSevereCondition returns the most severe condition, the earliest one on
+// ties
+func mostSevereCondition(conditions []models.ConditionCode) models.ConditionCode {
+	var worst models.ConditionCode
+	for i, condition := range conditions {
+		if i == 0 || condition.Severity() > worst.Severity() {
+			worst = condition
+		}
+	}
+	return worst
+}
+
+// mostCommonString returns the most frequent value, the earliest one on ties
 func mostCommonString(strs []string) string {
 	counts := make(map[string]int)
 	for _, s := range strs {
@@ -504,12 +1948,70 @@ func mostCommonString(strs []string) string {
 	
 	var mostCommon string
 	maxCount := 0
-	for s, count := range counts {
-		if count > maxCount {
+	for _, s := range strs {
+		if counts[s] > maxCount {
 			mostCommon = s
-			maxCount = count
+			maxCount = counts[s]
 		}
 	}
 	
 	return mostCommon
+}
+
+// Icons for each normalized condition, in the OpenWeatherMap naming all
+// clients map to
+var conditionIcons = map[models.ConditionCode]string{
+	models.ConditionClear:        "01d",
+	models.ConditionClouds:       "03d",
+	models.ConditionFog:          "50d",
+	models.ConditionDrizzle:      "09d",
+	models.ConditionRain:         "10d",
+	models.ConditionSnow:         "13d",
+	models.ConditionThunderstorm: "11d",
+}
+
+// pickIcon chooses the aggregated icon so it matches the chosen condition and
+// description: the icon of the first reading with the chosen description, then
+// of one with the chosen condition, keeping provider details such as night
+// icons, and a fixed icon per condition when no reading has one. icons is
+// parallel to conditions and descriptions.
+func pickIcon(condition models.ConditionCode, description string, conditions []models.ConditionCode, descriptions, icons []string) string {
+	for i := range icons {
+		if conditions[i] == condition && descriptions[i] == description && icons[i] != "" {
+			return icons[i]
+		}
+	}
+	for i := range icons {
+		if conditions[i] == condition && icons[i] != "" {
+			return icons[i]
+		}
+	}
+	
+	if icon, ok := conditionIcons[condition]; ok {
+		return icon
+	}
+	
+	// Unknown conditions keep whatever a provider sent
+	for _, icon := range icons {
+		if icon != "" {
+			return icon
+		}
+	}
+	return ""
+}
+
+// orderedSources returns the source names of a per-source map in a stable
+// order: primary first when it is present, then alphabetically
+func orderedSources[T any](bySource map[string]T, primary string) []string {
+	sources := make([]string, 0, len(bySource))
+	for source := range bySource {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if (sources[i] == primary) != (sources[j] == primary) {
+			return sources[i] == primary
+		}
+		return sources[i] < sources[j]
+	})
+	return sources
 }
\ No newline at end of file
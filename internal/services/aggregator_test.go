@@ -0,0 +1,1199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"go.uber.org/zap"
+)
+
+// fakeClient is a WeatherClient answering with fixed readings
+type fakeClient struct {
+	name     string
+	current  *models.CurrentWeather
+	forecast *models.WeatherForecast
+	err      error         // returned by every call when set
+	delay    time.Duration // before answering, unless the context ends first
+	retryAfter time.Duration // reported by BreakerRetryAfter, an open breaker when set
+	calls    atomic.Int32  // current weather requests received
+	days     atomic.Int32  // days asked for by the last forecast request
+	forecasts atomic.Int32 // forecast requests received
+	active   atomic.Int32  // requests being answered
+	peak     atomic.Int32  // most requests answered at once
+}
+
+func (c *fakeClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	c.calls.Add(1)
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	if c.current == nil {
+		return nil, errors.New("no current weather")
+	}
+	current := *c.current
+	return &current, nil
+}
+
+func (c *fakeClient) GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error) {
+	c.days.Store(int32(days))
+	c.forecasts.Add(1)
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	if c.forecast == nil {
+		return nil, errors.New("no forecast")
+	}
+	forecast := *c.forecast
+	if len(forecast.Forecast) > days {
+		forecast.Forecast = forecast.Forecast[:days]
+	}
+	return &forecast, nil
+}
+
+func (c *fakeClient) wait(ctx context.Context) error {
+	active := c.active.Add(1)
+	defer c.active.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if active <= peak || c.peak.CompareAndSwap(peak, active) {
+			break
+		}
+	}
+	
+	if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return c.err
+}
+
+func (c *fakeClient) Name() string                      { return c.name }
+func (c *fakeClient) RequiresAPIKey() bool              { return false }
+func (c *fakeClient) BreakerState() string              { return "closed" }
+func (c *fakeClient) BreakerRetryAfter() time.Duration  { return c.retryAfter }
+func (c *fakeClient) Usage() (hour, day models.UsageWindow) { return }
+
+// newFakeClient returns a client reporting temperature for any city
+func newFakeClient(name string, temperature float64) *fakeClient {
+	return &fakeClient{
+		name: name,
+		current: &models.CurrentWeather{
+			Temperature: temperature,
+			Humidity:    50,
+			Pressure:    1013,
+			WindSpeed:   3,
+			Condition:   models.ConditionClear,
+			Description: "clear sky",
+			Timestamp:   time.Now(),
+			Source:      name,
+		},
+	}
+}
+
+// testDays returns n forecast days starting today around temperature
+func testDays(n int, temperature float64) []models.ForecastDay {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	days := make([]models.ForecastDay, n)
+	for i := range days {
+		days[i] = models.ForecastDay{
+			Date:        today.AddDate(0, 0, i),
+			MaxTemp:     temperature + 5,
+			MinTemp:     temperature - 5,
+			AvgTemp:     temperature,
+			Humidity:    50,
+			Condition:   models.ConditionClear,
+			Description: "clear sky",
+		}
+	}
+	return days
+}
+
+// newTestAggregator builds an aggregator over clients from the configuration
+// in the environment, set the overrides with t.Setenv before calling it
+func newTestAggregator(t *testing.T, clients ...WeatherClient) *Aggregator {
+	t.Helper()
+	
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	aggregator, err := NewAggregatorWithClients(cfg, clients, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewAggregatorWithClients: %v", err)
+	}
+	t.Cleanup(func() {
+		aggregator.Close(context.Background())
+	})
+	return aggregator
+}
+
+func TestResolvedLocationPicksPointNearestCityCenter(t *testing.T) {
+	// London's center is 51.5074, -0.1278
+	far := newFakeClient("far", 10)
+	far.current.ResolvedLatitude, far.current.ResolvedLongitude = 51.75, -1.25
+	near := newFakeClient("near", 12)
+	near.current.ResolvedLatitude, near.current.ResolvedLongitude = 51.51, -0.13
+	near.current.DistanceKm = 99 // what the provider reported is not trusted
+	
+	aggregator := newTestAggregator(t, far, near)
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "London", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	
+	if weather.ResolvedLatitude != 51.51 || weather.ResolvedLongitude != -0.13 {
+		t.Errorf("resolved point = %v, %v, want the nearest source's 51.51, -0.13",
+			weather.ResolvedLatitude, weather.ResolvedLongitude)
+	}
+	want := utils.HaversineKm(51.5074, -0.1278, 51.51, -0.13)
+	if math.Abs(weather.DistanceKm-want) > 1e-9 {
+		t.Errorf("DistanceKm = %v, want %v measured from the city center", weather.DistanceKm, want)
+	}
+}
+
+func TestResolvedLocationFallsBackToFirstSourceWhenNotGeocoded(t *testing.T) {
+	b := newFakeClient("b", 10)
+	b.current.ResolvedLatitude, b.current.ResolvedLongitude, b.current.DistanceKm = 1, 1, 7
+	a := newFakeClient("a", 12)
+	a.current.ResolvedLatitude, a.current.ResolvedLongitude, a.current.DistanceKm = 2, 2, 3
+	
+	aggregator := newTestAggregator(t, b, a)
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Atlantis", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	
+	if weather.ResolvedLatitude != 2 || weather.DistanceKm != 3 {
+		t.Errorf("resolved = %v at %v km, want source a's point 2 at 3 km",
+			weather.ResolvedLatitude, weather.DistanceKm)
+	}
+}
+
+func TestCoordinatesSource(t *testing.T) {
+	// London's center is 51.5074, -0.1278
+	newClients := func() []WeatherClient {
+		far := newFakeClient("far", 10)
+		far.current.ResolvedLatitude, far.current.ResolvedLongitude, far.current.DistanceKm = 51.75, -1.25, 5
+		near := newFakeClient("near", 12)
+		near.current.ResolvedLatitude, near.current.ResolvedLongitude = 51.51, -0.13
+		// The display name differs, the requested city is still what is geocoded
+		near.current.City, far.current.City = "City of London", "City of London"
+		return []WeatherClient{far, near}
+	}
+	
+	tests := []struct {
+		name      string
+		source    string
+		city      string
+		latitude  float64
+		longitude float64
+		distance  float64
+	}{
+		{"configured source", "Far", "London", 51.75, -1.25, utils.HaversineKm(51.5074, -0.1278, 51.75, -1.25)},
+		{"source not contributing", "openweather", "London", 51.5074, -0.1278, 0},
+		{"city not geocoded", "far", "Atlantis", 51.75, -1.25, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COORDINATES_SOURCE", tt.source)
+			aggregator := newTestAggregator(t, newClients()...)
+			
+			// Every request reports the same point
+			for i := 0; i < 3; i++ {
+				weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), tt.city, models.QueryOptions{})
+				if err != nil {
+					t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+				}
+				if weather.ResolvedLatitude != tt.latitude || weather.ResolvedLongitude != tt.longitude || math.Abs(weather.DistanceKm-tt.distance) > 1e-9 {
+					t.Fatalf("resolved = %v, %v at %v km, want %v, %v at %v km",
+						weather.ResolvedLatitude, weather.ResolvedLongitude, weather.DistanceKm, tt.latitude, tt.longitude, tt.distance)
+				}
+				aggregator.InvalidateCity(tt.city)
+			}
+		})
+	}
+}
+
+func TestWarmCacheFetchesEveryCity(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	aggregator := newTestAggregator(t, source)
+	
+	aggregator.WarmCache(context.Background(), []string{"Prague", "London"}, time.Second)
+	if calls := source.calls.Load(); calls != 2 {
+		t.Fatalf("provider called %d times while warming, want 2", calls)
+	}
+	
+	for _, city := range []string{"prague", "LONDON"} {
+		if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), city, models.QueryOptions{}); err != nil {
+			t.Errorf("GetAggregatedCurrentWeather(%s): %v", city, err)
+		}
+	}
+	if calls := source.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times in total, want the warmed entries served from the cache", calls)
+	}
+}
+
+func TestWarmCacheGivesUpAtTimeout(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.delay = time.Minute
+	aggregator := newTestAggregator(t, source)
+	
+	started := time.Now()
+	aggregator.WarmCache(context.Background(), []string{"Prague"}, 20*time.Millisecond)
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("WarmCache took %v, want it bounded by the timeout", elapsed)
+	}
+	if entries := aggregator.DumpCache(); len(entries) != 0 {
+		t.Errorf("cache holds %d entries after a failed warm-up, want none", len(entries))
+	}
+}
+
+func TestAggregateFlagsSingleSourceAsDegraded(t *testing.T) {
+	tests := []struct {
+		name     string
+		clients  []WeatherClient
+		degraded bool
+	}{
+		{"one source", []WeatherClient{newFakeClient("a", 20)}, true},
+		{"two sources", []WeatherClient{newFakeClient("a", 20), newFakeClient("b", 21)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator := newTestAggregator(t, tt.clients...)
+			weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+			if err != nil {
+				t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+			}
+			if weather.Degraded != tt.degraded || weather.SourceCount != len(tt.clients) {
+				t.Errorf("degraded %v with %d sources, want %v with %d",
+					weather.Degraded, weather.SourceCount, tt.degraded, len(tt.clients))
+			}
+		})
+	}
+}
+
+func TestAggregateIgnoresFailedSourceForDegraded(t *testing.T) {
+	failing := newFakeClient("b", 21)
+	failing.err = errors.New("provider down")
+	aggregator := newTestAggregator(t, newFakeClient("a", 20), failing)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if !weather.Degraded || len(weather.Sources) != 1 {
+		t.Errorf("degraded %v with sources %v, want degraded with source a only", weather.Degraded, weather.Sources)
+	}
+}
+
+// panickingClient fails every request with a panic, like a client tripping
+// over a malformed payload
+type panickingClient struct {
+	*fakeClient
+}
+
+func (c panickingClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	panic("index out of range")
+}
+
+func TestFetchRecoversFromClientPanic(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("a", 20), panickingClient{newFakeClient("b", 30)})
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if len(weather.Sources) != 1 || weather.Sources[0] != "a" || weather.Temperature != 20 {
+		t.Errorf("sources %v at %v°C, want source a alone at 20°C", weather.Sources, weather.Temperature)
+	}
+}
+
+func TestAggregateConditionByFrequency(t *testing.T) {
+	conditions := []models.ConditionCode{models.ConditionRain, models.ConditionClouds, models.ConditionRain}
+	descriptions := []string{"light rain", "overcast clouds", "Slight rain"}
+	
+	condition, description := aggregateCondition(config.ConditionAggregationFrequency, conditions, descriptions)
+	if condition != models.ConditionRain {
+		t.Errorf("condition = %q, want rain reported by two sources with different wording", condition)
+	}
+	if description != "light rain" {
+		t.Errorf("description = %q, want one of the rain descriptions", description)
+	}
+}
+
+func TestAggregatedCurrentWeatherCarriesNormalizedCondition(t *testing.T) {
+	a := newFakeClient("a", 20)
+	a.current.Condition, a.current.Description = models.ConditionRain, "light rain"
+	b := newFakeClient("b", 20)
+	b.current.Condition, b.current.Description = models.ConditionRain, "Slight rain"
+	c := newFakeClient("c", 20)
+	c.current.Condition, c.current.Description = models.ConditionClouds, "overcast"
+	aggregator := newTestAggregator(t, a, b, c)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.Condition != models.ConditionRain {
+		t.Errorf("condition = %q, want rain", weather.Condition)
+	}
+}
+
+func TestAggregateConditionBySeverity(t *testing.T) {
+	tests := []struct {
+		conditions   []models.ConditionCode
+		descriptions []string
+		want         models.ConditionCode
+		description  string
+	}{
+		{
+			[]models.ConditionCode{models.ConditionClouds, models.ConditionThunderstorm, models.ConditionClouds},
+			[]string{"overcast", "thunderstorm with rain", "broken clouds"},
+			models.ConditionThunderstorm, "thunderstorm with rain",
+		},
+		{
+			[]models.ConditionCode{models.ConditionFog, models.ConditionDrizzle},
+			[]string{"mist", "light drizzle"},
+			models.ConditionDrizzle, "light drizzle",
+		},
+		{
+			[]models.ConditionCode{models.ConditionUnknown, models.ConditionClear},
+			[]string{"", "clear sky"},
+			models.ConditionClear, "clear sky",
+		},
+		{
+			[]models.ConditionCode{models.ConditionSnow, models.ConditionSnow, models.ConditionRain},
+			[]string{"light snow", "Snow", "rain"},
+			models.ConditionSnow, "light snow",
+		},
+	}
+	for _, tt := range tests {
+		condition, description := aggregateCondition(config.ConditionAggregationSeverity, tt.conditions, tt.descriptions)
+		if condition != tt.want || description != tt.description {
+			t.Errorf("aggregateCondition(%v) = %q %q, want %q %q", tt.conditions, condition, description, tt.want, tt.description)
+		}
+	}
+}
+
+func TestSeverityAggregationSurfacesWorstCondition(t *testing.T) {
+	clients := make([]WeatherClient, 3)
+	for i, condition := range []models.ConditionCode{models.ConditionClouds, models.ConditionClouds, models.ConditionThunderstorm} {
+		client := newFakeClient(string(rune('a'+i)), 20)
+		client.current.Condition, client.current.Description = condition, string(condition)
+		days := testDays(3, 20)
+		for d := range days {
+			days[d].Condition, days[d].Description = condition, string(condition)
+		}
+		client.forecast = &models.WeatherForecast{Forecast: days, Source: client.name}
+		clients[i] = client
+	}
+	
+	for strategy, want := range map[string]models.ConditionCode{
+		config.ConditionAggregationFrequency: models.ConditionClouds,
+		config.ConditionAggregationSeverity:  models.ConditionThunderstorm,
+	} {
+		t.Run(strategy, func(t *testing.T) {
+			t.Setenv("CONDITION_AGGREGATION", strategy)
+			aggregator := newTestAggregator(t, clients...)
+			
+			weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+			if err != nil {
+				t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+			}
+			if weather.Condition != want || weather.Description != string(want) {
+				t.Errorf("current condition = %q %q, want %q", weather.Condition, weather.Description, want)
+			}
+			
+			forecast, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 3, models.QueryOptions{})
+			if err != nil {
+				t.Fatalf("GetAggregatedForecast: %v", err)
+			}
+			for _, day := range forecast.Days {
+				if day.Condition != want {
+					t.Errorf("%s condition = %q, want %q", day.Date.Format("2006-01-02"), day.Condition, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOnDemandFetchIsBoundedByFetchTimeout(t *testing.T) {
+	t.Setenv("REQUEST_FETCH_TIMEOUT", "50ms")
+	source := newFakeClient("fake", 20)
+	source.delay = time.Minute
+	aggregator := newTestAggregator(t, source)
+	
+	started := time.Now()
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err == nil {
+		t.Fatal("fetch from a provider that never answers succeeded")
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("fetch took %v, want it cut off at the 50ms timeout", elapsed)
+	}
+}
+
+func TestMaxAgeRefetchesOlderCacheEntries(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	aggregator := newTestAggregator(t, source)
+	ctx := context.Background()
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(ctx, "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(ctx, "Prague", models.QueryOptions{MaxAge: time.Hour}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if calls := source.calls.Load(); calls != 1 {
+		t.Errorf("provider called %d times, want the entry younger than max_age served from the cache", calls)
+	}
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(ctx, "Prague", models.QueryOptions{MaxAge: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if calls := source.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times, want the entry older than max_age refetched", calls)
+	}
+}
+
+func TestMaxAgeIsMeasuredFromLastUpdated(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.current.Timestamp = time.Now().Add(-10 * time.Minute)
+	aggregator := newTestAggregator(t, source)
+	ctx := context.Background()
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(ctx, "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if _, err := aggregator.GetAggregatedCurrentWeather(ctx, "Prague", models.QueryOptions{MaxAge: 5 * time.Minute}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if calls := source.calls.Load(); calls != 2 {
+		t.Errorf("provider called %d times, want the just cached entry last updated 10 minutes ago refetched", calls)
+	}
+}
+
+func TestDerivedVariantInheritsExpiryOfItsData(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	if err := aggregator.FetchWeatherData(context.Background(), []string{"Prague"}); err != nil {
+		t.Fatalf("FetchWeatherData: %v", err)
+	}
+	_, dataExpiry, _ := aggregator.cache.GetCurrentWeather(dataKey("Prague", models.QueryOptions{}))
+	time.Sleep(10 * time.Millisecond)
+	
+	imperial := models.QueryOptions{Units: models.UnitsImperial}
+	if _, expiresAt, ok := aggregator.cachedCurrentWeather("Prague", imperial); !ok || !expiresAt.Equal(dataExpiry) {
+		t.Errorf("imperial variant expires at %v, want its data's %v", expiresAt, dataExpiry)
+	}
+	if _, expiresAt, ok := aggregator.cache.GetCurrentWeather(cacheKey("Prague", imperial)); !ok || !expiresAt.Equal(dataExpiry) {
+		t.Errorf("cached imperial variant expires at %v, want its data's %v", expiresAt, dataExpiry)
+	}
+}
+
+func TestWindGustAveragesReportingSourcesOnly(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	current := map[string]*models.CurrentWeather{}
+	for source, gust := range map[string]float64{"a": 10, "b": 0, "c": 14} {
+		current[source] = &models.CurrentWeather{Temperature: 20, WindGust: gust}
+	}
+	weather := aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague", Current: current})
+	if weather.WindGust != 12 {
+		t.Errorf("current WindGust = %v, want 12 from the two sources reporting gusts", weather.WindGust)
+	}
+	
+	a, b := testDays(1, 20), testDays(1, 20)
+	a[0].WindGust = 20
+	forecast := aggregator.aggregateForecast(&models.WeatherData{Query: "Prague", Forecasts: map[string]*models.WeatherForecast{
+		"a": {Forecast: a},
+		"b": {Forecast: b},
+	}}, 1)
+	if gust := forecast.Days[0].WindGust; gust != 20 {
+		t.Errorf("forecast WindGust = %v, want 20 from the source reporting gusts", gust)
+	}
+}
+
+func TestCloudCoverIsAveragedAcrossSources(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	weather := aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague", Current: map[string]*models.CurrentWeather{
+		"a": {Temperature: 20, CloudCover: 20},
+		"b": {Temperature: 20, CloudCover: 60},
+	}})
+	if weather.CloudCover != 40 {
+		t.Errorf("CloudCover = %v, want 40", weather.CloudCover)
+	}
+}
+
+func TestVisibilityAveragesReportingSourcesOnly(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	ten, twenty := 10000.0, 20000.0
+	
+	weather := aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague", Current: map[string]*models.CurrentWeather{
+		"a": {Temperature: 20, Visibility: &ten},
+		"b": {Temperature: 20},
+		"c": {Temperature: 20, Visibility: &twenty},
+	}})
+	if weather.Visibility == nil || *weather.Visibility != 15000 {
+		t.Errorf("Visibility = %v, want 15000 from the two sources reporting it", weather.Visibility)
+	}
+	
+	weather = aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague", Current: map[string]*models.CurrentWeather{
+		"a": {Temperature: 20},
+	}})
+	if weather.Visibility != nil {
+		t.Errorf("Visibility = %v, want nil when no source reports it", *weather.Visibility)
+	}
+}
+
+func TestPrecipitationCoversRainAndSnowfallSums(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	split, total, silent := testDays(1, 20), testDays(1, 20), testDays(1, 20)
+	split[0].Precipitation, split[0].RainSum, split[0].SnowfallSum, split[0].HasPrecipitationSums = 10, 6, 4, true
+	total[0].Precipitation = 2
+	forecast := aggregator.aggregateForecast(&models.WeatherData{Query: "Prague", Forecasts: map[string]*models.WeatherForecast{
+		"split":  {Forecast: split},
+		"total":  {Forecast: total},
+		"silent": {Forecast: silent},
+	}}, 1)
+	day := forecast.Days[0]
+	if day.Precipitation < day.RainSum+day.SnowfallSum {
+		t.Errorf("precipitation = %v, want at least rain %v + snowfall %v", day.Precipitation, day.RainSum, day.SnowfallSum)
+	}
+	if day.Precipitation != 10 {
+		t.Errorf("precipitation = %v, want 10 from the source splitting rain and snowfall", day.Precipitation)
+	}
+	
+	forecast = aggregator.aggregateForecast(&models.WeatherData{Query: "Prague", Forecasts: map[string]*models.WeatherForecast{
+		"total":  {Forecast: total},
+		"silent": {Forecast: silent},
+	}}, 1)
+	if day := forecast.Days[0]; day.Precipitation != 1 || day.HasPrecipitationSums {
+		t.Errorf("precipitation = %v with sums %v, want 1 averaged over every source and no sums", day.Precipitation, day.HasPrecipitationSums)
+	}
+}
+
+func TestAllBreakersOpenFailsFastWithEarliestRetry(t *testing.T) {
+	a := newFakeClient("a", 20)
+	a.retryAfter = 30 * time.Second
+	b := newFakeClient("b", 20)
+	b.retryAfter = 10 * time.Second
+	aggregator := newTestAggregator(t, a, b)
+	
+	_, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) || unavailable.RetryAfter != 10*time.Second {
+		t.Fatalf("err = %v, want an UnavailableError retrying after 10s", err)
+	}
+	if calls := a.calls.Load() + b.calls.Load(); calls != 0 {
+		t.Errorf("providers called %d times behind open breakers", calls)
+	}
+	
+	b.retryAfter = 0
+	if err := aggregator.checkAvailability(models.QueryOptions{}); err != nil {
+		t.Errorf("checkAvailability with one closed breaker = %v, want nil", err)
+	}
+}
+
+// combinedClient answers current weather and forecast together
+type combinedClient struct {
+	*fakeClient
+	combined atomic.Int32 // combined requests received
+}
+
+func (c *combinedClient) GetWeather(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.CurrentWeather, *models.WeatherForecast, error) {
+	c.combined.Add(1)
+	current := *c.current
+	return &current, &models.WeatherForecast{Forecast: testDays(days, current.Temperature), Source: c.name}, nil
+}
+
+func TestFetchUsesCombinedRequestWhenSupported(t *testing.T) {
+	combined := &combinedClient{fakeClient: newFakeClient("combined", 20)}
+	separate := newFakeClient("separate", 22)
+	separate.forecast = &models.WeatherForecast{Forecast: testDays(7, 22), Source: "separate"}
+	aggregator := newTestAggregator(t, combined, separate)
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if combined.combined.Load() != 1 || combined.calls.Load() != 0 {
+		t.Errorf("combined client got %d combined and %d current requests, want 1 and 0",
+			combined.combined.Load(), combined.calls.Load())
+	}
+	if separate.calls.Load() != 1 {
+		t.Errorf("separate client got %d current requests, want 1", separate.calls.Load())
+	}
+	
+	forecast, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 3, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	if len(forecast.Sources) != 2 || combined.combined.Load() != 1 {
+		t.Errorf("forecast from %v after %d combined requests, want both sources from the first fetch", forecast.Sources, combined.combined.Load())
+	}
+}
+
+func TestDisabledProviderIsSkipped(t *testing.T) {
+	a := newFakeClient("a", 20)
+	b := newFakeClient("b", 30)
+	aggregator := newTestAggregator(t, a, b)
+	
+	if err := aggregator.SetProviderEnabled("b", false); err != nil {
+		t.Fatalf("SetProviderEnabled: %v", err)
+	}
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if b.calls.Load() != 0 || len(weather.Sources) != 1 || weather.Temperature != 20 {
+		t.Errorf("disabled b got %d requests, weather %.0f° from %v, want a alone", b.calls.Load(), weather.Temperature, weather.Sources)
+	}
+	
+	if err := aggregator.SetProviderEnabled("a", false); err != nil {
+		t.Fatalf("SetProviderEnabled: %v", err)
+	}
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "London", models.QueryOptions{}); !errors.Is(err, ErrNoEnabledProvider) {
+		t.Errorf("err = %v with every provider disabled, want ErrNoEnabledProvider", err)
+	}
+	
+	if err := aggregator.SetProviderEnabled("c", false); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("SetProviderEnabled(c) = %v, want ErrUnknownProvider", err)
+	}
+}
+
+func TestAdaptiveWeightingDownweightsDisagreeingSource(t *testing.T) {
+	t.Setenv("AGGREGATION_WEIGHTING", "adaptive")
+	var clients []WeatherClient
+	for name, temperature := range map[string]float64{"a": 20, "b": 20, "outlier": 32} {
+		// a missing forecast counts as a failed fetch
+		client := newFakeClient(name, temperature)
+		client.forecast = &models.WeatherForecast{Forecast: testDays(7, temperature), Source: name}
+		clients = append(clients, client)
+	}
+	aggregator := newTestAggregator(t, clients...)
+	
+	var temperatures []float64
+	for _, city := range []string{"Prague", "London", "Tokyo", "NewYork", "Sydney"} {
+		weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), city, models.QueryOptions{})
+		if err != nil {
+			t.Fatalf("GetAggregatedCurrentWeather(%s): %v", city, err)
+		}
+		temperatures = append(temperatures, weather.Temperature)
+	}
+	
+	// the equal mean is 24°, the outlier pulls less with every fetch
+	for i := 1; i < len(temperatures); i++ {
+		if temperatures[i] >= temperatures[i-1] {
+			t.Fatalf("temperatures = %v, want the outlier's influence to keep shrinking", temperatures)
+		}
+	}
+	if last := temperatures[len(temperatures)-1]; last > 23 {
+		t.Errorf("temperature after five fetches = %.2f°, want well below the equal mean", last)
+	}
+}
+
+func TestSmoothedForecastIsCachedApartFromRaw(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: testDays(3, 20), Source: "fake"}
+	source.forecast.Forecast[1].AvgTemp = 26
+	aggregator := newTestAggregator(t, source)
+	
+	raw, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 3, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	smoothed, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 3, models.QueryOptions{Smooth: true})
+	if err != nil {
+		t.Fatalf("GetAggregatedForecast smoothed: %v", err)
+	}
+	
+	if raw.Days[1].AvgTemp != 26 || smoothed.Days[1].AvgTemp != 22 {
+		t.Errorf("middle day = %.2f raw and %.2f smoothed, want 26 and 22", raw.Days[1].AvgTemp, smoothed.Days[1].AvgTemp)
+	}
+	for i := range raw.Days {
+		if !smoothed.Days[i].Date.Equal(raw.Days[i].Date) {
+			t.Errorf("smoothed day %d is %s, want the raw date %s", i, smoothed.Days[i].Date, raw.Days[i].Date)
+		}
+	}
+}
+
+func TestComfortIndicesOnlyInTheirRange(t *testing.T) {
+	hot := newFakeClient("hot", 32)
+	hot.current.Humidity = 70
+	aggregator := newTestAggregator(t, hot)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.HeatIndex == nil || *weather.HeatIndex < 40 || weather.WindChill != nil {
+		t.Errorf("heat index %v and wind chill %v at 32°C and 70%%, want a heat index above 40°C alone", weather.HeatIndex, weather.WindChill)
+	}
+	
+	mild := newTestAggregator(t, newFakeClient("mild", 18))
+	weather, err = mild.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.HeatIndex != nil || weather.WindChill != nil {
+		t.Errorf("heat index %v and wind chill %v at 18°C, want neither", weather.HeatIndex, weather.WindChill)
+	}
+}
+
+func TestCityVariantsShareCacheEntry(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	aggregator := newTestAggregator(t, source)
+	
+	for _, city := range []string{"London", "london", " LONDON ", "London  "} {
+		weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), city, models.QueryOptions{})
+		if err != nil {
+			t.Fatalf("GetAggregatedCurrentWeather(%q): %v", city, err)
+		}
+		if weather.City != "London" {
+			t.Errorf("GetAggregatedCurrentWeather(%q) city = %q, want London", city, weather.City)
+		}
+	}
+	if calls := source.calls.Load(); calls != 1 {
+		t.Errorf("provider called %d times, want every variant served by the first fetch", calls)
+	}
+}
+
+func TestForecastFetchesConfiguredHorizon(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		source := newFakeClient("fake", 20)
+		source.forecast = &models.WeatherForecast{Forecast: testDays(7, 20), Source: "fake"}
+		aggregator := newTestAggregator(t, source)
+		
+		forecast, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 7, models.QueryOptions{})
+		if err != nil {
+			t.Fatalf("GetAggregatedForecast: %v", err)
+		}
+		if source.days.Load() != 7 || len(forecast.Days) != 7 {
+			t.Fatalf("asked for %d days, got %d, want 7 of each", source.days.Load(), len(forecast.Days))
+		}
+		for i, day := range forecast.Days {
+			if day.Date.IsZero() || day.AvgTemp != 20 {
+				t.Errorf("day %d = %.0f° on %v, want populated from the provider", i, day.AvgTemp, day.Date)
+			}
+		}
+	})
+	
+	t.Run("FORECAST_DAYS", func(t *testing.T) {
+		t.Setenv("FORECAST_DAYS", "5")
+		source := newFakeClient("fake", 20)
+		source.forecast = &models.WeatherForecast{Forecast: testDays(7, 20), Source: "fake"}
+		aggregator := newTestAggregator(t, source)
+		
+		if _, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 3, models.QueryOptions{}); err != nil {
+			t.Fatalf("GetAggregatedForecast: %v", err)
+		}
+		if days := source.days.Load(); days != 5 {
+			t.Errorf("asked for %d days, want the configured 5", days)
+		}
+		if _, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 6, models.QueryOptions{}); err == nil {
+			t.Error("6-day forecast with FORECAST_DAYS=5 succeeded, want an error")
+		}
+	})
+}
+
+func TestForecastDaysAverageTheSourcesCoveringThem(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	forecast := aggregator.aggregateForecast(&models.WeatherData{Query: "Prague", Forecasts: map[string]*models.WeatherForecast{
+		"long":  {Forecast: testDays(7, 10)},
+		"short": {Forecast: testDays(5, 20)},
+	}}, 7)
+	if forecast == nil || len(forecast.Days) != 7 {
+		t.Fatalf("forecast = %+v, want 7 days", forecast)
+	}
+	for i, day := range forecast.Days {
+		want := 15.0
+		if i >= 5 {
+			want = 10 // only the longer horizon covers it
+		}
+		if day.AvgTemp != want {
+			t.Errorf("day %d AvgTemp = %v, want %v", i, day.AvgTemp, want)
+		}
+	}
+}
+
+// shiftDays moves every day of forecast by n calendar days
+func shiftDays(forecast []models.ForecastDay, n int) []models.ForecastDay {
+	for i := range forecast {
+		forecast[i].Date = forecast[i].Date.AddDate(0, 0, n)
+	}
+	return forecast
+}
+
+func TestForecastAlignment(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	tests := []struct {
+		alignment string
+		dates     []time.Time
+		temps     []float64
+	}{
+		// The late source's first day is tomorrow, averaged with today's
+		// forecast it would skew it by a day
+		{config.ForecastAlignmentDate, []time.Time{today, today.AddDate(0, 0, 1), today.AddDate(0, 0, 2)}, []float64{10, 15, 15}},
+		// Positions are averaged whatever their dates
+		{config.ForecastAlignmentIndex, nil, []float64{15, 15, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alignment, func(t *testing.T) {
+			t.Setenv("FORECAST_ALIGNMENT", tt.alignment)
+			aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+			
+			forecast := aggregator.aggregateForecast(&models.WeatherData{Query: "Prague", Forecasts: map[string]*models.WeatherForecast{
+				"early": {Forecast: testDays(3, 10)},
+				"late":  {Forecast: shiftDays(testDays(3, 20), 1)},
+			}}, 3)
+			if forecast == nil || len(forecast.Days) != 3 {
+				t.Fatalf("forecast = %+v, want 3 days", forecast)
+			}
+			for i, day := range forecast.Days {
+				if day.AvgTemp != tt.temps[i] {
+					t.Errorf("day %d at %v°, want %v°", i, day.AvgTemp, tt.temps[i])
+				}
+				if tt.dates != nil && !day.Date.Equal(tt.dates[i]) {
+					t.Errorf("day %d dated %s, want %s", i, day.Date.Format("2006-01-02"), tt.dates[i].Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestAlignDaysByDate(t *testing.T) {
+	early := testDays(3, 10)
+	late := shiftDays(testDays(3, 20), 1)
+	
+	groups := alignDaysByDate([][]models.ForecastDay{late, early}, 3)
+	want := [][]float64{{10}, {20, 10}, {20, 10}}
+	if len(groups) != len(want) {
+		t.Fatalf("%d groups, want %d", len(groups), len(want))
+	}
+	for i, group := range groups {
+		var temps []float64
+		for _, day := range group {
+			temps = append(temps, day.AvgTemp)
+			if !day.Date.Equal(group[0].Date) {
+				t.Errorf("group %d mixes %s and %s", i, group[0].Date.Format("2006-01-02"), day.Date.Format("2006-01-02"))
+			}
+		}
+		if !reflect.DeepEqual(temps, want[i]) {
+			t.Errorf("group %d = %v, want %v in source order", i, temps, want[i])
+		}
+	}
+	
+	// The late source's extra day is beyond the horizon asked for
+	if groups := alignDaysByDate([][]models.ForecastDay{early, late}, 4); len(groups) != 4 || len(groups[3]) != 1 {
+		t.Errorf("%d groups for 4 days, want the last date from the late source alone", len(groups))
+	}
+}
+
+func TestForecastConfidenceDropsWithVariance(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	agreeing, disagreeing := testDays(2, 20), testDays(2, 20)
+	agreeing[0].AvgTemp = 21
+	disagreeing[0].AvgTemp = 30
+	forecast := aggregator.aggregateForecast(&models.WeatherData{Query: "Prague", Forecasts: map[string]*models.WeatherForecast{
+		"a": {Forecast: testDays(2, 20)},
+		"b": {Forecast: agreeing},
+		"c": {Forecast: disagreeing},
+	}}, 2)
+	if forecast == nil || len(forecast.Days) != 2 {
+		t.Fatalf("forecast = %+v, want 2 days", forecast)
+	}
+	
+	spread, agreed := forecast.Days[0].Confidence, forecast.Days[1].Confidence
+	if spread >= agreed {
+		t.Errorf("day confidence = %v with a 10° spread, want below %v when sources agree", spread, agreed)
+	}
+	if want := (spread + agreed) / 2; math.Abs(forecast.Confidence-want) > 1e-9 {
+		t.Errorf("Confidence = %v, want the day average %v", forecast.Confidence, want)
+	}
+	
+	single := aggregator.aggregateForecast(&models.WeatherData{Query: "Prague", Forecasts: map[string]*models.WeatherForecast{
+		"a": {Forecast: testDays(1, 20)},
+	}}, 1)
+	if got := single.Days[0].Confidence; got != 0.5 {
+		t.Errorf("single source day confidence = %v, want 0.5", got)
+	}
+}
+
+func TestConfidenceOfEmptyAndSingleInputs(t *testing.T) {
+	if got := calculateConfidence(nil); got != 0 {
+		t.Errorf("confidence of no readings = %v, want 0", got)
+	}
+	if got := calculateConfidence(map[string]*models.CurrentWeather{"a": nil}); got != 0 {
+		t.Errorf("confidence of a nil reading = %v, want 0", got)
+	}
+	if got := temperatureConfidence([]float64{math.NaN(), math.Inf(1)}); got != 0 {
+		t.Errorf("confidence of non-finite readings = %v, want 0", got)
+	}
+	if got := calculateConfidence(map[string]*models.CurrentWeather{"a": {Temperature: 20}}); got != 0.5 {
+		t.Errorf("confidence of a single reading = %v, want 0.5", got)
+	}
+}
+
+func TestAggregationNeverEmitsNaN(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	if weather := aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague"}); weather != nil {
+		t.Errorf("aggregate of no readings = %+v, want nil", weather)
+	}
+	if weather := aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague", Current: map[string]*models.CurrentWeather{
+		"a": {Temperature: math.NaN()},
+	}}); weather != nil {
+		t.Errorf("aggregate of a NaN reading = %+v, want nil", weather)
+	}
+	if forecast := aggregator.aggregateForecast(&models.WeatherData{Query: "Prague"}, 3); forecast != nil {
+		t.Errorf("aggregate of no forecasts = %+v, want nil", forecast)
+	}
+	
+	weather := aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague", Current: map[string]*models.CurrentWeather{
+		"a": {Temperature: 20, Humidity: 50},
+		"b": {Temperature: math.Inf(-1)},
+	}})
+	if weather == nil {
+		t.Fatal("aggregate with one finite reading = nil, want it aggregated")
+	}
+	for name, value := range map[string]float64{
+		"Temperature": weather.Temperature,
+		"FeelsLike":   weather.FeelsLike,
+		"Humidity":    weather.Humidity,
+		"Pressure":    weather.Pressure,
+		"WindSpeed":   weather.WindSpeed,
+		"CloudCover":  weather.CloudCover,
+		"Confidence":  weather.Confidence,
+	} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			t.Errorf("%s = %v, want a finite value", name, value)
+		}
+	}
+	if weather.Temperature != 20 || weather.SourceCount != 1 || weather.Confidence != 0.5 {
+		t.Errorf("aggregate = %.0f° from %d sources at %v, want 20° from the one finite source at 0.5", weather.Temperature, weather.SourceCount, weather.Confidence)
+	}
+}
+
+func TestPickIcon(t *testing.T) {
+	conditions := []models.ConditionCode{models.ConditionClear, models.ConditionRain, models.ConditionRain}
+	descriptions := []string{"clear sky", "light rain", "moderate rain"}
+	
+	tests := []struct {
+		name        string
+		condition   models.ConditionCode
+		description string
+		icons       []string
+		want        string
+	}{
+		{"matching description", models.ConditionRain, "moderate rain", []string{"01n", "09d", "10n"}, "10n"},
+		{"matching condition", models.ConditionRain, "rain", []string{"01n", "09d", "10n"}, "09d"},
+		{"no icon for the description", models.ConditionRain, "moderate rain", []string{"01n", "09d", ""}, "09d"},
+		{"no icon for the condition", models.ConditionRain, "light rain", []string{"01n", "", ""}, "10d"},
+		{"unknown condition", models.ConditionUnknown, "", []string{"", "x1", ""}, "x1"},
+	}
+	for _, tt := range tests {
+		if got := pickIcon(tt.condition, tt.description, conditions, descriptions, tt.icons); got != tt.want {
+			t.Errorf("%s: pickIcon = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAggregatedIconMatchesDescription(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	// The map is aggregated in a different order on every run
+	for i := 0; i < 20; i++ {
+		weather := aggregator.aggregateCurrentWeather(&models.WeatherData{Query: "Prague", Current: map[string]*models.CurrentWeather{
+			"a": {Temperature: 20, Condition: models.ConditionClear, Description: "clear sky", Icon: "01n"},
+			"b": {Temperature: 20, Condition: models.ConditionRain, Description: "light rain", Icon: "10d"},
+			"c": {Temperature: 20, Condition: models.ConditionRain, Description: "light rain", Icon: "10d"},
+		}})
+		if weather.Description != "light rain" || weather.Icon != "10d" {
+			t.Fatalf("aggregate %q with icon %q, want light rain with its 10d", weather.Description, weather.Icon)
+		}
+	}
+}
+
+func TestOrderedSources(t *testing.T) {
+	bySource := map[string]int{"openmeteo": 1, "openweather": 2, "tomorrow.io": 3}
+	
+	tests := []struct {
+		primary string
+		want    []string
+	}{
+		{"", []string{"openmeteo", "openweather", "tomorrow.io"}},
+		{"tomorrow.io", []string{"tomorrow.io", "openmeteo", "openweather"}},
+		{"visualcrossing", []string{"openmeteo", "openweather", "tomorrow.io"}},
+	}
+	for _, tt := range tests {
+		if got := orderedSources(bySource, tt.primary); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("orderedSources with primary %q = %v, want %v", tt.primary, got, tt.want)
+		}
+	}
+}
+
+func TestPrimarySourceWinsTies(t *testing.T) {
+	tied := &models.WeatherData{Query: "Prague", Current: map[string]*models.CurrentWeather{
+		"a": {Temperature: 20, Condition: models.ConditionRain, Description: "light rain", Icon: "10d"},
+		"b": {Temperature: 20, Condition: models.ConditionRain, Description: "moderate rain", Icon: "10n"},
+	}}
+	
+	tests := []struct {
+		primary     string
+		description string
+		icon        string
+	}{
+		{" B ", "moderate rain", "10n"},
+		{"", "light rain", "10d"},
+		{"c", "light rain", "10d"}, // did not contribute
+	}
+	for _, tt := range tests {
+		t.Run(tt.primary, func(t *testing.T) {
+			t.Setenv("PRIMARY_SOURCE", tt.primary)
+			aggregator := newTestAggregator(t, newFakeClient("a", 20), newFakeClient("b", 20))
+			
+			// Map order differs between runs, the result must not
+			for i := 0; i < 10; i++ {
+				weather := aggregator.aggregateCurrentWeather(tied)
+				if weather.Description != tt.description || weather.Icon != tt.icon {
+					t.Fatalf("aggregate %q with icon %q, want %q with %q", weather.Description, weather.Icon, tt.description, tt.icon)
+				}
+			}
+		})
+	}
+}
+
+func TestClientFetchMode(t *testing.T) {
+	tests := []struct {
+		mode string
+		peak int32 // requests of the client answered at once
+	}{
+		{"sequential", 1},
+		{"parallel", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			t.Setenv("CLIENT_FETCH_MODE", tt.mode)
+			source := newFakeClient("fake", 20)
+			source.forecast = &models.WeatherForecast{Forecast: testDays(7, 20), Source: "fake"}
+			source.delay = 50 * time.Millisecond
+			combined := &combinedClient{fakeClient: newFakeClient("combined", 20)}
+			aggregator := newTestAggregator(t, source, combined)
+			
+			if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+				t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+			}
+			if source.calls.Load() != 1 || source.forecasts.Load() != 1 {
+				t.Errorf("%d current and %d forecast requests, want 1 of each", source.calls.Load(), source.forecasts.Load())
+			}
+			if peak := source.peak.Load(); peak != tt.peak {
+				t.Errorf("%d requests at once, want %d", peak, tt.peak)
+			}
+			if combined.combined.Load() != 1 || combined.calls.Load() != 0 || combined.forecasts.Load() != 0 {
+				t.Errorf("combined client got %d combined, %d current and %d forecast requests, want just 1 combined",
+					combined.combined.Load(), combined.calls.Load(), combined.forecasts.Load())
+			}
+		})
+	}
+}
+
+func TestCachedHorizons(t *testing.T) {
+	if got := cachedHorizons([]int{7, 3, 1, 3}, 7); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("cachedHorizons = %v, want [1 3] sorted without duplicates or the full horizon", got)
+	}
+	if got := cachedHorizons(nil, 7); len(got) != 0 {
+		t.Errorf("cachedHorizons of none = %v, want none", got)
+	}
+}
+
+func TestOnlyConfiguredHorizonsArePrecached(t *testing.T) {
+	t.Setenv("FORECAST_CACHE_HORIZONS", "1,3")
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: testDays(7, 20), Source: "fake"}
+	aggregator := newTestAggregator(t, source)
+	
+	if _, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 7, models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	
+	key := dataKey("Prague", models.QueryOptions{})
+	for days := 1; days < 7; days++ {
+		_, _, cached := aggregator.cache.GetForecast(horizonKey(key, days))
+		if want := days == 1 || days == 3; cached != want {
+			t.Errorf("%d-day horizon cached = %v, want %v", days, cached, want)
+		}
+	}
+	
+	// Horizons that are not cached are sliced from the full one
+	forecast, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 5, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	if len(forecast.Days) != 5 || source.forecasts.Load() != 1 {
+		t.Errorf("%d days after %d provider requests, want 5 days from the cache", len(forecast.Days), source.forecasts.Load())
+	}
+}
+
+func TestSliceForecast(t *testing.T) {
+	days := testDays(7, 20)
+	for i := range days {
+		days[i].Confidence = float64(i+1) / 10
+	}
+	full := &models.AggregatedForecast{Days: days, Confidence: 0.4, Sources: []string{"fake"}}
+	
+	sliced := SliceForecast(full, 3)
+	if !reflect.DeepEqual(sliced.Days, days[:3]) {
+		t.Errorf("sliced days = %v, want the first three", sliced.Days)
+	}
+	if !approxEqual(sliced.Confidence, 0.2, 1e-9) {
+		t.Errorf("sliced Confidence = %v, want 0.2 over the kept days", sliced.Confidence)
+	}
+	if len(full.Days) != 7 || full.Confidence != 0.4 {
+		t.Errorf("full forecast changed to %d days at %v", len(full.Days), full.Confidence)
+	}
+	if whole := SliceForecast(full, 10); len(whole.Days) != 7 || whole.Confidence != 0.4 {
+		t.Errorf("slicing past the horizon = %d days at %v, want the full forecast", len(whole.Days), whole.Confidence)
+	}
+}
+
+func TestShortForecastIsSlicedFromCachedHorizon(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: testDays(7, 20), Source: "fake"}
+	for i := range source.forecast.Forecast {
+		source.forecast.Forecast[i].AvgTemp = float64(10 + i)
+	}
+	aggregator := newTestAggregator(t, source)
+	
+	full, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 7, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	short, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 3, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	if !reflect.DeepEqual(short.Days, full.Days[:3]) {
+		t.Errorf("3-day forecast = %v, want the first three days of the 7-day one", short.Days)
+	}
+	if source.forecasts.Load() != 1 {
+		t.Errorf("%d provider forecast requests, want the 3 days from the cached horizon", source.forecasts.Load())
+	}
+	if _, err := aggregator.GetAggregatedForecast(context.Background(), "Prague", 8, models.QueryOptions{}); err == nil {
+		t.Error("8-day forecast beyond the cached horizon succeeded, want an error")
+	}
+}
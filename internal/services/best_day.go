@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// Each comfort component falls linearly from 1 to 0 over these ranges
+const (
+	comfortTempRange = 15.0 // °C away from the target
+	comfortPrecipMax = 10.0 // mm of precipitation
+	comfortCalmGust  = 5.0  // m/s of gust still fully comfortable
+	comfortGaleGust  = 20.0 // m/s of gust with no comfort left
+)
+
+// comfortScorer rates a forecast day by the weighted mean of its temperature,
+// precipitation and wind comfort, each between 0 and 1
+type comfortScorer struct {
+	targetTemp          float64
+	temperatureWeight   float64
+	precipitationWeight float64
+	windWeight          float64
+}
+
+// score returns the comfort of a metric day from 0 to 100. A day without
+// gusts has no wind data, a WindGust of 0 means no source reported any, so
+// it is scored on temperature and precipitation alone.
+func (s comfortScorer) score(day models.ForecastDay) float64 {
+	temperature := 1 - math.Min(math.Abs(day.AvgTemp-s.targetTemp)/comfortTempRange, 1)
+	precipitation := 1 - math.Min(day.Precipitation/comfortPrecipMax, 1)
+	
+	weighted := s.temperatureWeight*temperature + s.precipitationWeight*precipitation
+	total := s.temperatureWeight + s.precipitationWeight
+	if day.WindGust > 0 {
+		wind := 1 - math.Min(math.Max(day.WindGust-comfortCalmGust, 0)/(comfortGaleGust-comfortCalmGust), 1)
+		weighted += s.windWeight * wind
+		total += s.windWeight
+	}
+	
+	if total == 0 {
+		return 0
+	}
+	return 100 * weighted / total
+}
+
+// GetBestDay scores the next days of the aggregated forecast by comfort and
+// picks the highest, the earliest one on ties
+func (a *Aggregator) GetBestDay(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.BestDay, error) {
+	// Scored in metric, the ranges above are in °C, mm and m/s
+	metricOpts := opts
+	metricOpts.Units = ""
+	metricOpts.PrecipUnit = ""
+	
+	forecast, err := a.GetAggregatedForecast(ctx, city, days, metricOpts)
+	if err != nil {
+		return nil, err
+	}
+	if len(forecast.Days) == 0 {
+		return nil, fmt.Errorf("best day for %s: %w", city, ErrNoForecastDays)
+	}
+	
+	converted := convertForecast(forecast, opts)
+	
+	scored := make([]models.ScoredDay, len(forecast.Days))
+	best := 0
+	for i, day := range forecast.Days {
+		scored[i] = models.ScoredDay{
+			ForecastDay: converted.Days[i],
+			Score:       a.comfort.score(day),
+		}
+		if scored[i].Score > scored[best].Score {
+			best = i
+		}
+	}
+	
+	return &models.BestDay{
+		City:        forecast.City,
+		Best:        scored[best],
+		Days:        scored,
+		Sources:     forecast.Sources,
+		LastUpdated: forecast.LastUpdated,
+		Units:       converted.Units,
+		UnitLabels:  converted.UnitLabels,
+	}, nil
+}
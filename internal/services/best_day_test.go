@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestComfortScore(t *testing.T) {
+	scorer := comfortScorer{targetTemp: 22, temperatureWeight: 0.5, precipitationWeight: 0.3, windWeight: 0.2}
+	
+	tests := []struct {
+		name string
+		day  models.ForecastDay
+		want float64
+	}{
+		{"ideal without gusts", models.ForecastDay{AvgTemp: 22}, 100},
+		{"ideal with calm gusts", models.ForecastDay{AvgTemp: 22, WindGust: 5}, 100},
+		{"far from target", models.ForecastDay{AvgTemp: 7}, 37.5},
+		{"halfway to target range", models.ForecastDay{AvgTemp: 29.5, Precipitation: 5}, 50},
+		{"gale", models.ForecastDay{AvgTemp: 22, WindGust: 20}, 80},
+		{"strong gusts", models.ForecastDay{AvgTemp: 22, WindGust: 12.5}, 90},
+		{"downpour", models.ForecastDay{AvgTemp: 22, Precipitation: 30}, 62.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scorer.score(tt.day); !approxEqual(got, tt.want, 1e-9) {
+				t.Errorf("score(%+v) = %.2f, want %.2f", tt.day, got, tt.want)
+			}
+		})
+	}
+	
+	if got := (comfortScorer{}).score(models.ForecastDay{AvgTemp: 22}); got != 0 {
+		t.Errorf("score without weights = %.2f, want 0", got)
+	}
+}
+
+func TestGetBestDayPicksMostComfortableDay(t *testing.T) {
+	days := testDays(4, 10)
+	days[1].Precipitation = 8
+	days[2].AvgTemp = 21
+	days[3].AvgTemp = 21
+	days[3].WindGust = 18
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: days}
+	aggregator := newTestAggregator(t, source)
+	
+	bestDay, err := aggregator.GetBestDay(context.Background(), "Prague", 4, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetBestDay: %v", err)
+	}
+	if len(bestDay.Days) != 4 {
+		t.Fatalf("Days = %d, want all 4 scored", len(bestDay.Days))
+	}
+	if !bestDay.Best.Date.Equal(days[2].Date) {
+		t.Errorf("Best = %s, want %s, the mild calm dry day", bestDay.Best.Date.Format("2006-01-02"), days[2].Date.Format("2006-01-02"))
+	}
+	for _, day := range bestDay.Days {
+		if day.Score > bestDay.Best.Score {
+			t.Errorf("%s scored %.2f above the best %.2f", day.Date.Format("2006-01-02"), day.Score, bestDay.Best.Score)
+		}
+	}
+	if bestDay.Days[1].Score >= bestDay.Days[0].Score {
+		t.Errorf("rainy day scored %.2f, want below the dry day's %.2f", bestDay.Days[1].Score, bestDay.Days[0].Score)
+	}
+}
+
+func TestGetBestDayPrefersEarliestOnTies(t *testing.T) {
+	days := testDays(3, 22)
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: days}
+	aggregator := newTestAggregator(t, source)
+	
+	bestDay, err := aggregator.GetBestDay(context.Background(), "Prague", 3, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetBestDay: %v", err)
+	}
+	if !bestDay.Best.Date.Equal(days[0].Date) {
+		t.Errorf("Best = %s, want the first of equally scored days", bestDay.Best.Date.Format("2006-01-02"))
+	}
+}
+
+func TestGetBestDayScoresInMetric(t *testing.T) {
+	days := testDays(2, 10)
+	days[1].AvgTemp = 22
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: days}
+	aggregator := newTestAggregator(t, source)
+	
+	metric, err := aggregator.GetBestDay(context.Background(), "Prague", 2, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetBestDay: %v", err)
+	}
+	imperial, err := aggregator.GetBestDay(context.Background(), "Prague", 2, models.QueryOptions{Units: models.UnitsImperial})
+	if err != nil {
+		t.Fatalf("GetBestDay imperial: %v", err)
+	}
+	if imperial.Best.Score != metric.Best.Score || !imperial.Best.Date.Equal(days[1].Date) {
+		t.Errorf("imperial best = %s scoring %.2f, want %s scoring %.2f", imperial.Best.Date.Format("2006-01-02"), imperial.Best.Score, days[1].Date.Format("2006-01-02"), metric.Best.Score)
+	}
+	if !approxEqual(imperial.Best.AvgTemp, 71.6, 1e-9) || imperial.Units != models.UnitsImperial {
+		t.Errorf("imperial best = %.2f in %s, want 71.60 in imperial", imperial.Best.AvgTemp, imperial.Units)
+	}
+}
+
+func TestGetBestDayWithoutForecastDays(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{}
+	aggregator := newTestAggregator(t, source)
+	
+	if _, err := aggregator.GetBestDay(context.Background(), "Prague", 3, models.QueryOptions{}); !errors.Is(err, ErrNoForecastDays) {
+		t.Errorf("err = %v, want ErrNoForecastDays", err)
+	}
+}
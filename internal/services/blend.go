@@ -0,0 +1,51 @@
+package services
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// blendWithForecast returns a copy of weather with temperature, humidity and
+// wind speed moved toward the hourly forecast interpolated at the observation
+// time, the observation keeping nowBlendWeight of the result. The
+// precipitation probability blends 100% or 0%, depending on whether
+// precipitation is observed, with the forecast one. weather is returned
+// unchanged when no hourly forecast covers the observation time.
+func (a *Aggregator) blendWithForecast(city string, weather *models.AggregatedCurrentWeather, opts models.QueryOptions) *models.AggregatedCurrentWeather {
+	a.mu.RLock()
+	data, exists := a.weatherData[dataKey(city, opts)]
+	a.mu.RUnlock()
+	if !exists {
+		return weather
+	}
+	
+	point, err := aggregatePointForecast(data, weather.LastUpdated, a.conditionStrategy)
+	if err != nil {
+		a.logger.Debug("No hourly forecast to blend current weather with",
+			zap.String("city", city),
+			zap.Error(err))
+		return weather
+	}
+	
+	// The forecast is metric, weather may already be converted
+	units := opts.UnitsOrDefault()
+	weight := a.nowBlendWeight
+	blend := func(observed, forecast float64) float64 {
+		return weight*observed + (1-weight)*forecast
+	}
+	
+	observedPop := 0.0
+	if weather.PrecipitationType != models.PrecipitationNone {
+		observedPop = 100
+	}
+	pop := blend(observedPop, point.PrecipitationProbability)
+	
+	blended := *weather
+	blended.Temperature = blend(weather.Temperature, convertTemperature(point.Temperature, units))
+	blended.Humidity = blend(weather.Humidity, point.Humidity)
+	blended.WindSpeed = blend(weather.WindSpeed, convertWindSpeed(point.WindSpeed, units))
+	blended.PrecipitationProbability = &pop
+	blended.Blended = true
+	return &blended
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// newHourlyClient returns a client whose hourly forecast around now disagrees
+// with its current observation of 20°, 50% humidity, 3 m/s and a clear sky
+func newHourlyClient(name string) *fakeClient {
+	now := time.Now().UTC()
+	source := newFakeClient(name, 20)
+	source.forecast = &models.WeatherForecast{
+		Forecast: testDays(2, 10),
+		Hourly: []models.HourlyPoint{
+			{Time: now.Add(-time.Hour), Temperature: 10, Humidity: 90, WindSpeed: 8, PrecipitationProbability: 40, Condition: models.ConditionRain},
+			{Time: now.Add(time.Hour), Temperature: 10, Humidity: 90, WindSpeed: 8, PrecipitationProbability: 40, Condition: models.ConditionRain},
+		},
+	}
+	return source
+}
+
+func TestBlendWithForecast(t *testing.T) {
+	tests := []struct {
+		weight      string
+		temperature float64
+		humidity    float64
+		windSpeed   float64
+		pop         float64
+	}{
+		{"0.7", 17, 62, 4.5, 12},
+		{"1", 20, 50, 3, 0},
+		{"0", 10, 90, 8, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.weight, func(t *testing.T) {
+			t.Setenv("NOW_BLEND_WEIGHT", tt.weight)
+			aggregator := newTestAggregator(t, newHourlyClient("hourly"))
+			
+			weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{Blend: true})
+			if err != nil {
+				t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+			}
+			if !weather.Blended {
+				t.Fatal("Blended = false, want true")
+			}
+			if !approxEqual(weather.Temperature, tt.temperature, 1e-9) || !approxEqual(weather.Humidity, tt.humidity, 1e-9) || !approxEqual(weather.WindSpeed, tt.windSpeed, 1e-9) {
+				t.Errorf("blended = %.2f°, %.2f%%, %.2f m/s, want %.2f°, %.2f%%, %.2f m/s",
+					weather.Temperature, weather.Humidity, weather.WindSpeed, tt.temperature, tt.humidity, tt.windSpeed)
+			}
+			if weather.PrecipitationProbability == nil || !approxEqual(*weather.PrecipitationProbability, tt.pop, 1e-9) {
+				t.Errorf("PrecipitationProbability = %v, want %.2f", weather.PrecipitationProbability, tt.pop)
+			}
+		})
+	}
+}
+
+func TestBlendIsOptIn(t *testing.T) {
+	aggregator := newTestAggregator(t, newHourlyClient("hourly"))
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.Blended || weather.Temperature != 20 {
+		t.Errorf("without blend: %.2f°, blended %v, want the 20° observation", weather.Temperature, weather.Blended)
+	}
+	
+	// The blended copy must not leak into the cached observation
+	aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{Blend: true})
+	weather, _ = aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if weather.Blended || weather.Temperature != 20 {
+		t.Errorf("after a blended request: %.2f°, blended %v, want the 20° observation", weather.Temperature, weather.Blended)
+	}
+}
+
+func TestBlendWithoutHourlyForecast(t *testing.T) {
+	source := newFakeClient("daily", 20)
+	source.forecast = &models.WeatherForecast{Forecast: testDays(2, 10)}
+	aggregator := newTestAggregator(t, source)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{Blend: true})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.Blended || weather.Temperature != 20 {
+		t.Errorf("no hourly forecast: %.2f°, blended %v, want the 20° observation unchanged", weather.Temperature, weather.Blended)
+	}
+}
+
+func TestBlendConvertsForecastToRequestedUnits(t *testing.T) {
+	t.Setenv("NOW_BLEND_WEIGHT", "0.5")
+	aggregator := newTestAggregator(t, newHourlyClient("hourly"))
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{Units: models.UnitsImperial, Blend: true})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	// halfway between 68°F and 50°F
+	if !approxEqual(weather.Temperature, 59, 1e-9) {
+		t.Errorf("Temperature = %.2f°F, want 59.00°F", weather.Temperature)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// withBreakdown returns a copy of weather with the reading of every provider
+// that answered, in the units requested by opts or, with RawUnits, in each
+// provider's native units
+func (a *Aggregator) withBreakdown(city string, weather *models.AggregatedCurrentWeather, opts models.QueryOptions) *models.AggregatedCurrentWeather {
+	a.mu.RLock()
+	data, exists := a.weatherData[dataKey(city, opts)]
+	a.mu.RUnlock()
+	if !exists {
+		return weather
+	}
+	
+	breakdown := make(map[string]models.SourceReading, len(data.Current))
+	for source, current := range data.Current {
+		if opts.RawUnits {
+			breakdown[source] = a.nativeReading(source, current)
+		} else {
+			breakdown[source] = sourceReading(current, opts.UnitsOrDefault(), opts.PressureUnitOrDefault())
+		}
+	}
+	
+	withBreakdown := *weather
+	withBreakdown.Breakdown = breakdown
+	return &withBreakdown
+}
+
+// sourceReading converts a normalized (metric, hPa) reading to units and pressure
+func sourceReading(current *models.CurrentWeather, units models.UnitSystem, pressure models.PressureUnit) models.SourceReading {
+	return models.SourceReading{
+		Temperature: convertTemperature(current.Temperature, units),
+		FeelsLike:   convertTemperature(current.FeelsLike, units),
+		Humidity:    current.Humidity,
+		Pressure:    convertPressure(current.Pressure, pressure),
+		WindSpeed:   convertWindSpeed(current.WindSpeed, units),
+		WindGust:    convertWindSpeed(current.WindGust, units),
+		CloudCover:  current.CloudCover,
+		Visibility:  current.Visibility,
+		Condition:   current.Condition,
+		Description: current.Description,
+		Timestamp:   current.Timestamp,
+		UnitLabels:  currentUnitLabels(units, pressure),
+	}
+}
+
+// nativeReading undoes the normalization of the provider's client, leaving
+// the reading in the units the provider sent
+func (a *Aggregator) nativeReading(source string, current *models.CurrentWeather) models.SourceReading {
+	reading := sourceReading(current, models.UnitsMetric, models.PressureHPa)
+	
+	var native map[string]models.NativeUnit
+	for _, client := range a.clients {
+		if nativeClient, ok := client.(NativeUnitsClient); ok && client.Name() == source {
+			native = nativeClient.NativeUnits()
+		}
+	}
+	
+	for measurement, unit := range native {
+		switch measurement {
+		case "pressure":
+			reading.Pressure *= unit.Factor
+		case "wind_speed":
+			reading.WindSpeed *= unit.Factor
+			reading.WindGust *= unit.Factor
+		case "visibility":
+			if reading.Visibility != nil {
+				visibility := *reading.Visibility * unit.Factor
+				reading.Visibility = &visibility
+			}
+		default:
+			continue
+		}
+		reading.UnitLabels[measurement] = unit.Label
+	}
+	return reading
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// nativeClient reports wind in km/h and visibility in km
+type nativeClient struct {
+	*fakeClient
+}
+
+func (c *nativeClient) NativeUnits() map[string]models.NativeUnit {
+	return map[string]models.NativeUnit{
+		"wind_speed": {Label: "km/h", Factor: 3.6},
+		"visibility": {Label: "km", Factor: 0.001},
+	}
+}
+
+// newBreakdownAggregator aggregates a provider with native units and one without
+func newBreakdownAggregator(t *testing.T) *Aggregator {
+	t.Helper()
+	
+	visibility := 10000.0
+	native := &nativeClient{fakeClient: newFakeClient("native", 20)}
+	native.current.WindSpeed = 5
+	native.current.WindGust = 10
+	native.current.Visibility = &visibility
+	return newTestAggregator(t, native, newFakeClient("plain", 10))
+}
+
+func TestBreakdownIsOptIn(t *testing.T) {
+	aggregator := newBreakdownAggregator(t)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.Breakdown != nil {
+		t.Errorf("Breakdown = %v, want none without breakdown", weather.Breakdown)
+	}
+}
+
+func TestBreakdownInRequestedUnits(t *testing.T) {
+	aggregator := newBreakdownAggregator(t)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{Units: models.UnitsImperial, Breakdown: true})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if len(weather.Breakdown) != 2 {
+		t.Fatalf("Breakdown = %v, want both sources", weather.Breakdown)
+	}
+	native, plain := weather.Breakdown["native"], weather.Breakdown["plain"]
+	if native.Temperature != 68 || plain.Temperature != 50 || native.UnitLabels["temperature"] != "°F" {
+		t.Errorf("temperatures = %v%s and %v, want 68 and 50 °F", native.Temperature, native.UnitLabels["temperature"], plain.Temperature)
+	}
+	if native.UnitLabels["wind_speed"] != "mph" || *native.Visibility != 10000 {
+		t.Errorf("native wind in %s, visibility %v, want mph and the 10000 m unconverted", native.UnitLabels["wind_speed"], *native.Visibility)
+	}
+}
+
+func TestBreakdownInNativeUnits(t *testing.T) {
+	aggregator := newBreakdownAggregator(t)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{Units: models.UnitsImperial, Breakdown: true, RawUnits: true})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	native, plain := weather.Breakdown["native"], weather.Breakdown["plain"]
+	
+	if math.Abs(native.WindSpeed-18) > 1e-9 || math.Abs(native.WindGust-36) > 1e-9 || native.UnitLabels["wind_speed"] != "km/h" {
+		t.Errorf("native wind = %v gusting %v %s, want 18 gusting 36 km/h", native.WindSpeed, native.WindGust, native.UnitLabels["wind_speed"])
+	}
+	if math.Abs(*native.Visibility-10) > 1e-9 || native.UnitLabels["visibility"] != "km" {
+		t.Errorf("native visibility = %v %s, want 10 km", *native.Visibility, native.UnitLabels["visibility"])
+	}
+	// Measurements without a native unit stay normalized, ignoring units
+	if native.Temperature != 20 || native.UnitLabels["temperature"] != "°C" || native.Pressure != 1013 {
+		t.Errorf("native temperature %v%s pressure %v, want 20°C and 1013 hPa", native.Temperature, native.UnitLabels["temperature"], native.Pressure)
+	}
+	if plain.WindSpeed != 3 || plain.UnitLabels["wind_speed"] != "m/s" {
+		t.Errorf("plain wind = %v %s, want the normalized 3 m/s", plain.WindSpeed, plain.UnitLabels["wind_speed"])
+	}
+	
+	// The aggregate itself is still in the requested units
+	if weather.Temperature != 59 || weather.UnitLabels["temperature"] != "°F" {
+		t.Errorf("aggregate = %v%s, want 59°F", weather.Temperature, weather.UnitLabels["temperature"])
+	}
+}
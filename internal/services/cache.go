@@ -1,38 +1,72 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
 	"go.uber.org/zap"
 )
 
 type CacheItem struct {
 	Data       interface{}
+	StoredAt   time.Time
 	ExpiresAt  time.Time
 }
 
+// Entry types reported by Dump
+const (
+	cacheTypeCurrent  = "current"
+	cacheTypeForecast = "forecast"
+)
+
 type WeatherCache struct {
 	mu               sync.RWMutex
 	currentWeather   map[string]CacheItem
-	forecast         map[string]map[int]CacheItem // city -> days -> cache item
+	forecast         map[string]CacheItem // full horizon, shorter ones are sliced from it
 	logger           *zap.Logger
 	defaultDuration  time.Duration
 	maxSize          int
 	cleanupInterval  time.Duration
 	stopCleanup      chan bool
+	stopOnce         sync.Once
+	cleanupDone      chan struct{} // closed when the cleanup goroutine has returned
+	cleanupHooks     []func() // run after every cleanup tick
+	hits             atomic.Int64
+	misses           atomic.Int64
+	remote           remoteCache // optional shared second tier
+	namespace        string      // prepended to every key in both tiers, "<CACHE_PREFIX>:" or empty
+	remoteHits       atomic.Int64
 }
 
-func NewWeatherCache(defaultDuration time.Duration, maxSize int, logger *zap.Logger) *WeatherCache {
+// remoteTimeout bounds each call to the second tier so a slow Redis degrades
+// to cache misses instead of slowing down requests
+const remoteTimeout = 500 * time.Millisecond
+
+// NewWeatherCache creates an in-memory cache. A non-empty prefix namespaces
+// its keys, so environments sharing a remote tier do not collide.
+func NewWeatherCache(defaultDuration time.Duration, maxSize int, prefix string, logger *zap.Logger) *WeatherCache {
+	var namespace string
+	if prefix != "" {
+		namespace = prefix + ":"
+	}
+	
 	cache := &WeatherCache{
 		currentWeather:  make(map[string]CacheItem),
-		forecast:        make(map[string]map[int]CacheItem),
+		forecast:        make(map[string]CacheItem),
 		logger:          logger,
 		defaultDuration: defaultDuration,
 		maxSize:         maxSize,
 		cleanupInterval: time.Minute,
 		stopCleanup:     make(chan bool),
+		cleanupDone:     make(chan struct{}),
+		namespace:       namespace,
 	}
 	
 	go cache.startCleanup()
@@ -40,7 +74,28 @@ func NewWeatherCache(defaultDuration time.Duration, maxSize int, logger *zap.Log
 	return cache
 }
 
+// NewTieredWeatherCache backs the in-memory cache with a shared remote tier.
+// Lookups fall back to the remote tier on a local miss and promote what they
+// find; writes go to both tiers with the same TTL.
+func NewTieredWeatherCache(defaultDuration time.Duration, maxSize int, prefix string, remote remoteCache, logger *zap.Logger) *WeatherCache {
+	cache := NewWeatherCache(defaultDuration, maxSize, prefix, logger)
+	cache.remote = remote
+	return cache
+}
+
 func (c *WeatherCache) SetCurrentWeather(city string, weather *models.AggregatedCurrentWeather) {
+	c.setCurrentWeatherUntil(city, weather, time.Now().Add(c.defaultDuration))
+}
+
+// setCurrentWeatherUntil caches weather in both tiers until expiresAt, for
+// variants derived from an entry that must expire together with it
+func (c *WeatherCache) setCurrentWeatherUntil(city string, weather *models.AggregatedCurrentWeather, expiresAt time.Time) {
+	key := c.namespace + city
+	c.setCurrentLocal(key, weather, expiresAt)
+	c.setRemote(remoteCurrentKey(c.namespace, city), weather, time.Until(expiresAt))
+}
+
+func (c *WeatherCache) setCurrentLocal(city string, weather *models.AggregatedCurrentWeather, expiresAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
@@ -51,87 +106,241 @@ func (c *WeatherCache) SetCurrentWeather(city string, weather *models.Aggregated
 	
 	c.currentWeather[city] = CacheItem{
 		Data:      weather,
-		ExpiresAt: time.Now().Add(c.defaultDuration),
+		StoredAt:  time.Now(),
+		ExpiresAt: expiresAt,
 	}
 	
 	c.logger.Debug("Current weather cached",
 		zap.String("city", city),
-		zap.Time("expires_at", time.Now().Add(c.defaultDuration)))
+		zap.Time("expires_at", expiresAt))
+}
+
+// GetCurrentWeather looks up current weather, counting the lookup as a hit or
+// miss. Lookups that belong to a request already counted use peekCurrentWeather.
+func (c *WeatherCache) GetCurrentWeather(city string) (*models.AggregatedCurrentWeather, time.Time, bool) {
+	weather, expiresAt, ok := c.peekCurrentWeather(city)
+	c.recordLookup(ok)
+	return weather, expiresAt, ok
 }
 
-func (c *WeatherCache) GetCurrentWeather(city string) (*models.AggregatedCurrentWeather, bool) {
+// peekCurrentWeather looks up current weather without counting the lookup
+func (c *WeatherCache) peekCurrentWeather(city string) (*models.AggregatedCurrentWeather, time.Time, bool) {
+	key := c.namespace + city
+	
 	c.mu.RLock()
-	item, exists := c.currentWeather[city]
+	item, exists := c.currentWeather[key]
 	c.mu.RUnlock()
 	
-	if !exists {
-		return nil, false
-	}
-	
-	if time.Now().After(item.ExpiresAt) {
+	if exists && time.Now().After(item.ExpiresAt) {
 		c.mu.Lock()
-		delete(c.currentWeather, city)
+		delete(c.currentWeather, key)
 		c.mu.Unlock()
-		return nil, false
+		exists = false
+	}
+	
+	if !exists {
+		var weather models.AggregatedCurrentWeather
+		ttl, found := c.getRemote(remoteCurrentKey(c.namespace, city), &weather)
+		if !found {
+			return nil, time.Time{}, false
+		}
+		
+		expiresAt := time.Now().Add(ttl)
+		c.setCurrentLocal(key, &weather, expiresAt)
+		c.remoteHits.Add(1)
+		return &weather, expiresAt, true
 	}
 	
 	weather, ok := item.Data.(*models.AggregatedCurrentWeather)
-	return weather, ok
+	return weather, item.ExpiresAt, ok
+}
+
+func (c *WeatherCache) SetForecast(city string, forecast *models.AggregatedForecast) {
+	c.setForecastUntil(city, forecast, time.Now().Add(c.defaultDuration))
+}
+
+// setForecastUntil caches forecast in both tiers until expiresAt, see
+// setCurrentWeatherUntil
+func (c *WeatherCache) setForecastUntil(city string, forecast *models.AggregatedForecast, expiresAt time.Time) {
+	key := c.namespace + city
+	c.setForecastLocal(key, forecast, expiresAt)
+	c.setRemote(remoteForecastKey(c.namespace, city), forecast, time.Until(expiresAt))
 }
 
-func (c *WeatherCache) SetForecast(city string, days int, forecast *models.AggregatedForecast) {
+func (c *WeatherCache) setForecastLocal(city string, forecast *models.AggregatedForecast, expiresAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	if _, exists := c.forecast[city]; !exists {
-		c.forecast[city] = make(map[int]CacheItem)
-	}
-	
 	// Check total cache size
-	totalItems := len(c.currentWeather)
-	for _, cityForecasts := range c.forecast {
-		totalItems += len(cityForecasts)
-	}
-	
-	if totalItems >= c.maxSize {
+	if len(c.currentWeather)+len(c.forecast) >= c.maxSize {
 		c.evictOldestForecast()
 	}
 	
-	c.forecast[city][days] = CacheItem{
+	c.forecast[city] = CacheItem{
 		Data:      forecast,
-		ExpiresAt: time.Now().Add(c.defaultDuration),
+		StoredAt:  time.Now(),
+		ExpiresAt: expiresAt,
 	}
 	
 	c.logger.Debug("Forecast cached",
 		zap.String("city", city),
-		zap.Int("days", days),
-		zap.Time("expires_at", time.Now().Add(c.defaultDuration)))
+		zap.Int("days", len(forecast.Days)),
+		zap.Time("expires_at", expiresAt))
 }
 
-func (c *WeatherCache) GetForecast(city string, days int) (*models.AggregatedForecast, bool) {
-	c.mu.RLock()
-	cityForecasts, cityExists := c.forecast[city]
-	if !cityExists {
-		c.mu.RUnlock()
-		return nil, false
-	}
+// GetForecast looks up a forecast, counting the lookup, see GetCurrentWeather
+func (c *WeatherCache) GetForecast(city string) (*models.AggregatedForecast, time.Time, bool) {
+	forecast, expiresAt, ok := c.peekForecast(city)
+	c.recordLookup(ok)
+	return forecast, expiresAt, ok
+}
+
+// peekForecast looks up a forecast without counting the lookup
+func (c *WeatherCache) peekForecast(city string) (*models.AggregatedForecast, time.Time, bool) {
+	key := c.namespace + city
 	
-	item, exists := cityForecasts[days]
+	c.mu.RLock()
+	item, exists := c.forecast[key]
 	c.mu.RUnlock()
 	
-	if !exists {
-		return nil, false
-	}
-	
-	if time.Now().After(item.ExpiresAt) {
+	if exists && time.Now().After(item.ExpiresAt) {
 		c.mu.Lock()
-		delete(c.forecast[city], days)
+		delete(c.forecast, key)
 		c.mu.Unlock()
-		return nil, false
+		exists = false
+	}
+	
+	if !exists {
+		var forecast models.AggregatedForecast
+		ttl, found := c.getRemote(remoteForecastKey(c.namespace, city), &forecast)
+		if !found {
+			return nil, time.Time{}, false
+		}
+		
+		expiresAt := time.Now().Add(ttl)
+		c.setForecastLocal(key, &forecast, expiresAt)
+		c.remoteHits.Add(1)
+		return &forecast, expiresAt, true
 	}
 	
 	forecast, ok := item.Data.(*models.AggregatedForecast)
-	return forecast, ok
+	return forecast, item.ExpiresAt, ok
+}
+
+// setRemote writes an entry through to the second tier; failures only cost
+// other instances a cache hit, so they are logged and ignored
+func (c *WeatherCache) setRemote(key string, value interface{}, ttl time.Duration) {
+	if c.remote == nil || ttl <= 0 {
+		return
+	}
+	
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("Failed to encode cache entry", zap.String("key", key), zap.Error(err))
+		return
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	
+	if err := c.remote.Set(ctx, key, data, ttl); err != nil {
+		c.logger.Warn("Failed to write remote cache entry", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// getRemote decodes a second-tier entry into value and returns its remaining TTL
+func (c *WeatherCache) getRemote(key string, value interface{}) (time.Duration, bool) {
+	if c.remote == nil {
+		return 0, false
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	
+	data, ttl, found, err := c.remote.Get(ctx, key)
+	if err != nil {
+		c.logger.Warn("Failed to read remote cache entry", zap.String("key", key), zap.Error(err))
+		return 0, false
+	}
+	if !found {
+		return 0, false
+	}
+	
+	if err := json.Unmarshal(data, value); err != nil {
+		c.logger.Warn("Failed to decode remote cache entry", zap.String("key", key), zap.Error(err))
+		return 0, false
+	}
+	return ttl, true
+}
+
+func (c *WeatherCache) deleteRemote(patterns []string) {
+	if c.remote == nil {
+		return
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	
+	if err := c.remote.DeleteMatching(ctx, patterns...); err != nil {
+		c.logger.Warn("Failed to delete remote cache entries",
+			zap.Strings("patterns", patterns),
+			zap.Error(err))
+	}
+}
+
+// unqualified strips the namespace from a stored key
+func (c *WeatherCache) unqualified(key string) string {
+	return strings.TrimPrefix(key, c.namespace)
+}
+
+func (c *WeatherCache) recordLookup(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// Delete removes every entry cached for a city, across all option variants
+// and both tiers
+func (c *WeatherCache) Delete(city string) {
+	c.mu.Lock()
+	for key := range c.currentWeather {
+		if matchesCity(c.unqualified(key), city) {
+			delete(c.currentWeather, key)
+		}
+	}
+	for key := range c.forecast {
+		if matchesCity(c.unqualified(key), city) {
+			delete(c.forecast, key)
+		}
+	}
+	c.mu.Unlock()
+	
+	c.deleteRemote(remoteCityPatterns(c.namespace, utils.NormalizeCity(city)))
+	
+	c.logger.Debug("Cache entries deleted", zap.String("city", city))
+}
+
+// DeleteDerived removes the presentation variants cached from the data entry
+// baseKey, so they are rebuilt from fresh data on the next request
+func (c *WeatherCache) DeleteDerived(baseKey string) {
+	qualified := c.namespace + baseKey
+	
+	c.mu.Lock()
+	for key := range c.currentWeather {
+		if isDerivedFrom(key, qualified) {
+			delete(c.currentWeather, key)
+		}
+	}
+	for key := range c.forecast {
+		if isDerivedFrom(key, qualified) {
+			delete(c.forecast, key)
+		}
+	}
+	c.mu.Unlock()
+	
+	c.deleteRemote(remoteDerivedPatterns(c.namespace, baseKey))
 }
 
 func (c *WeatherCache) evictOldestCurrent() {
@@ -153,29 +362,26 @@ func (c *WeatherCache) evictOldestCurrent() {
 }
 
 func (c *WeatherCache) evictOldestForecast() {
-	var oldestCity string
-	var oldestDays int
+	var oldestKey string
 	var oldestTime time.Time
 	
-	for city, forecasts := range c.forecast {
-		for days, item := range forecasts {
-			if oldestCity == "" || item.ExpiresAt.Before(oldestTime) {
-				oldestCity = city
-				oldestDays = days
-				oldestTime = item.ExpiresAt
-			}
+	for key, item := range c.forecast {
+		if oldestKey == "" || item.ExpiresAt.Before(oldestTime) {
+			oldestKey = key
+			oldestTime = item.ExpiresAt
 		}
 	}
 	
-	if oldestCity != "" {
-		delete(c.forecast[oldestCity], oldestDays)
+	if oldestKey != "" {
+		delete(c.forecast, oldestKey)
 		c.logger.Debug("Evicted oldest forecast from cache",
-			zap.String("city", oldestCity),
-			zap.Int("days", oldestDays))
+			zap.String("city", oldestKey))
 	}
 }
 
 func (c *WeatherCache) startCleanup() {
+	defer close(c.cleanupDone)
+	
 	ticker := time.NewTicker(c.cleanupInterval)
 	defer ticker.Stop()
 	
@@ -183,12 +389,68 @@ func (c *WeatherCache) startCleanup() {
 		select {
 		case <-ticker.C:
 			c.cleanup()
+			
+			c.mu.RLock()
+			hooks := c.cleanupHooks
+			c.mu.RUnlock()
+			for _, hook := range hooks {
+				hook()
+			}
 		case <-c.stopCleanup:
 			return
 		}
 	}
 }
 
+// Dump describes every unexpired local entry, without payloads, sorted by key
+// and type. Entries only held by the remote tier are not listed.
+func (c *WeatherCache) Dump() []models.CacheEntryInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
+	now := time.Now()
+	entries := make([]models.CacheEntryInfo, 0, len(c.currentWeather)+len(c.forecast))
+	add := func(key, entryType string, item CacheItem, lastUpdated time.Time) {
+		if now.After(item.ExpiresAt) {
+			return
+		}
+		entries = append(entries, models.CacheEntryInfo{
+			Key:         key,
+			City:        cityFromKey(c.unqualified(key)),
+			Type:        entryType,
+			Derived:     strings.Contains(key, derivedKeySeparator),
+			StoredAt:    item.StoredAt,
+			Age:         int64(now.Sub(item.StoredAt).Seconds()),
+			ExpiresAt:   item.ExpiresAt,
+			ExpiresIn:   int64(item.ExpiresAt.Sub(now).Seconds()),
+			LastUpdated: lastUpdated,
+		})
+	}
+	
+	for key, item := range c.currentWeather {
+		var lastUpdated time.Time
+		if weather, ok := item.Data.(*models.AggregatedCurrentWeather); ok {
+			lastUpdated = weather.LastUpdated
+		}
+		add(key, cacheTypeCurrent, item, lastUpdated)
+	}
+	for key, item := range c.forecast {
+		var lastUpdated time.Time
+		if forecast, ok := item.Data.(*models.AggregatedForecast); ok {
+			lastUpdated = forecast.LastUpdated
+		}
+		add(key, cacheTypeForecast, item, lastUpdated)
+	}
+	
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].Type < entries[j].Type
+	})
+	return entries
+}
+
 func (c *WeatherCache) cleanup() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -205,16 +467,10 @@ func (c *WeatherCache) cleanup() {
 	}
 	
 	// Clean forecast
-	for city, forecasts := range c.forecast {
-		for days, item := range forecasts {
-			if now.After(item.ExpiresAt) {
-				delete(forecasts, days)
-				expiredCount++
-			}
-		}
-		
-		if len(forecasts) == 0 {
+	for city, item := range c.forecast {
+		if now.After(item.ExpiresAt) {
 			delete(c.forecast, city)
+			expiredCount++
 		}
 	}
 	
@@ -224,18 +480,74 @@ func (c *WeatherCache) cleanup() {
 	}
 }
 
+// OnCleanup registers fn to run after every cleanup tick, after the functions
+// registered before it. fn runs on the cleanup goroutine and should hand long
+// work off.
+func (c *WeatherCache) OnCleanup(fn func()) {
+	c.mu.Lock()
+	c.cleanupHooks = append(c.cleanupHooks, fn)
+	c.mu.Unlock()
+}
+
+// ExpiresWithin reports whether the current weather cached under key expires
+// in less than window. Missing and already expired entries report false.
+func (c *WeatherCache) ExpiresWithin(key string, window time.Duration) bool {
+	c.mu.RLock()
+	item, exists := c.currentWeather[c.namespace+key]
+	c.mu.RUnlock()
+	
+	if !exists {
+		return false
+	}
+	remaining := time.Until(item.ExpiresAt)
+	return remaining > 0 && remaining < window
+}
+
+// Stop ends the cleanup goroutine and waits for a running cleanup, including
+// the cleanup hook, to finish. It is safe to call more than once.
 func (c *WeatherCache) Stop() {
-	close(c.stopCleanup)
+	c.stopOnce.Do(func() {
+		close(c.stopCleanup)
+	})
+	<-c.cleanupDone
+}
+
+// Close stops the cache and closes the connection to the remote tier
+func (c *WeatherCache) Close() error {
+	c.Stop()
+	
+	if c.remote == nil {
+		return nil
+	}
+	return c.remote.Close()
 }
 
 func (c *WeatherCache) GetStats() map[string]interface{} {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	
+	hits := c.hits.Load()
+	misses := c.misses.Load()
+	
+	hitRatio := 0.0
+	if total := hits + misses; total > 0 {
+		hitRatio = float64(hits) / float64(total)
+	}
+	
+	backend := "memory"
+	if c.remote != nil {
+		backend = "tiered"
+	}
+	
 	return map[string]interface{}{
+		"backend":               backend,
+		"remote_hits":           c.remoteHits.Load(),
 		"current_weather_items": len(c.currentWeather),
 		"forecast_items":        len(c.forecast),
 		"max_size":              c.maxSize,
 		"default_duration":      c.defaultDuration.String(),
+		"hits":                  hits,
+		"misses":                misses,
+		"hit_ratio":             hitRatio,
 	}
 }
\ No newline at end of file
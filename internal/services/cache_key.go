@@ -0,0 +1,83 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+)
+
+const (
+	// separates options that change what is fetched from the providers
+	cacheKeySeparator = "|"
+	// separates presentation options, whose entries are derived from the data entry
+	derivedKeySeparator = "#"
+)
+
+// dataKey builds the key weather data is fetched and aggregated under. Default
+// options map to the bare normalized city name so scheduled fetches and plain
+// requests share entries, whatever capitalization they use.
+func dataKey(city string, opts models.QueryOptions) string {
+	key := utils.NormalizeCity(city)
+	if opts.Country != "" {
+		key += cacheKeySeparator + "country=" + opts.Country
+	}
+	if lang := opts.LangOrDefault(); lang != models.DefaultLang {
+		key += cacheKeySeparator + "lang=" + lang
+	}
+	if len(opts.Sources) > 0 {
+		key += cacheKeySeparator + "sources=" + strings.Join(opts.Sources, ",")
+	}
+	return key
+}
+
+// cacheKey builds the key a response is cached under: the data key plus any
+// presentation options such as units
+func cacheKey(city string, opts models.QueryOptions) string {
+	key := dataKey(city, opts)
+	if units := opts.UnitsOrDefault(); units != models.UnitsMetric {
+		key += derivedKeySeparator + "units=" + string(units)
+	}
+	if unit := opts.PressureUnitOrDefault(); unit != models.PressureHPa {
+		key += derivedKeySeparator + "pressure=" + string(unit)
+	}
+	if unit := opts.PrecipitationUnitOrDefault(); unit != models.PrecipitationMM {
+		key += derivedKeySeparator + "precip=" + string(unit)
+	}
+	if opts.Smooth {
+		key += derivedKeySeparator + "smooth"
+	}
+	return key
+}
+
+// forecastCacheKey is cacheKey for forecasts. They report no pressure, so the
+// pressure unit must not split their entries.
+func forecastCacheKey(city string, opts models.QueryOptions) string {
+	opts.PressureUnit = ""
+	return cacheKey(city, opts)
+}
+
+// horizonKey builds the key a shorter forecast horizon of the data entry key
+// is cached under, a presentation variant dropped with the other ones
+func horizonKey(key string, days int) string {
+	return key + derivedKeySeparator + "days=" + strconv.Itoa(days)
+}
+
+// matchesCity reports whether key belongs to city
+func matchesCity(key, city string) bool {
+	return cityFromKey(key) == utils.NormalizeCity(city)
+}
+
+// isDerivedFrom reports whether key holds a presentation variant of the data entry baseKey
+func isDerivedFrom(key, baseKey string) bool {
+	return strings.HasPrefix(key, baseKey+derivedKeySeparator)
+}
+
+// cityFromKey returns the normalized city part of a cache key
+func cityFromKey(key string) string {
+	if i := strings.IndexAny(key, cacheKeySeparator+derivedKeySeparator); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
\ No newline at end of file
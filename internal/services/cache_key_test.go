@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestDataKeySeparatesLanguages(t *testing.T) {
+	if got, want := dataKey("Prague", models.QueryOptions{Lang: "en"}), dataKey("prague", models.QueryOptions{}); got != want {
+		t.Errorf("the default language keys %q, want the bare %q", got, want)
+	}
+	if dataKey("Prague", models.QueryOptions{Lang: "de"}) == dataKey("Prague", models.QueryOptions{}) {
+		t.Error("German descriptions share the key of the English ones")
+	}
+}
+
+func TestPressureUnitSplitsCurrentButNotForecastKeys(t *testing.T) {
+	hPa := models.QueryOptions{}
+	inHg := models.QueryOptions{PressureUnit: models.PressureInHg}
+	
+	if cacheKey("Prague", inHg) == cacheKey("Prague", hPa) {
+		t.Error("current weather in inHg shares the key of hPa")
+	}
+	if cacheKey("Prague", models.QueryOptions{PressureUnit: models.PressureHPa}) != cacheKey("Prague", hPa) {
+		t.Error("the explicit default pressure unit has a key of its own")
+	}
+	if forecastCacheKey("Prague", inHg) != forecastCacheKey("Prague", hPa) {
+		t.Error("forecasts, which report no pressure, are split by pressure unit")
+	}
+}
+
+func TestCountrySplitsCacheKeys(t *testing.T) {
+	canada := models.QueryOptions{Country: "CA"}
+	britain := models.QueryOptions{Country: "GB"}
+	
+	if cacheKey("London", canada) == cacheKey("London", britain) {
+		t.Error("London,CA shares the current weather key of London,GB")
+	}
+	if cacheKey("London", canada) == cacheKey("London", models.QueryOptions{}) {
+		t.Error("London,CA shares the current weather key of London without a country")
+	}
+	if forecastCacheKey("London", canada) == forecastCacheKey("London", britain) {
+		t.Error("London,CA shares the forecast key of London,GB")
+	}
+}
+
+func TestPrecipitationUnitSplitsForecastKeys(t *testing.T) {
+	inches := models.QueryOptions{PrecipUnit: models.PrecipitationInches}
+	
+	if forecastCacheKey("Prague", inches) == forecastCacheKey("Prague", models.QueryOptions{}) {
+		t.Error("forecasts in inches share the key of millimeters")
+	}
+	if forecastCacheKey("Prague", models.QueryOptions{PrecipUnit: models.PrecipitationMM}) != forecastCacheKey("Prague", models.QueryOptions{}) {
+		t.Error("the explicit default precipitation unit has a key of its own")
+	}
+}
+
+func TestSourcesSplitDataKeys(t *testing.T) {
+	if dataKey("Prague", models.QueryOptions{Sources: []string{"openmeteo"}}) == dataKey("Prague", models.QueryOptions{}) {
+		t.Error("a single source request shares the key of every source")
+	}
+	if dataKey("Prague", models.QueryOptions{Sources: []string{"openmeteo"}}) == dataKey("Prague", models.QueryOptions{Sources: []string{"openweathermap"}}) {
+		t.Error("requests for different sources share a key")
+	}
+}
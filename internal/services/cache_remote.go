@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// remoteCache is the shared second tier behind the in-memory cache
+type remoteCache interface {
+	// Get returns the value and its remaining TTL, ok is false on a miss
+	Get(ctx context.Context, key string) (value []byte, ttl time.Duration, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeleteMatching removes every key matching one of the glob patterns
+	DeleteMatching(ctx context.Context, patterns ...string) error
+	Close() error
+}
+
+// remoteKeyPrefix follows the CACHE_PREFIX namespace in every remote key, so
+// a namespace owns a whole "<CACHE_PREFIX>:weather:*" keyspace
+const remoteKeyPrefix = "weather:"
+
+type redisCache struct {
+	client *redis.Client
+}
+
+func newRedisCache(url string) (*redisCache, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	return &redisCache{client: redis.NewClient(options)}, nil
+}
+
+func (r *redisCache) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisCache) Get(ctx context.Context, key string) ([]byte, time.Duration, bool, error) {
+	pipe := r.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.PTTL(ctx, key)
+	
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, false, err
+	}
+	
+	value, err := get.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, 0, false, nil
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+	
+	// A key without expiry should not exist, treat it as a miss
+	remaining := ttl.Val()
+	if remaining <= 0 {
+		return nil, 0, false, nil
+	}
+	
+	return value, remaining, true, nil
+}
+
+func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *redisCache) DeleteMatching(ctx context.Context, patterns ...string) error {
+	for _, pattern := range patterns {
+		iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+		
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		
+		if len(keys) > 0 {
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *redisCache) Close() error {
+	return r.client.Close()
+}
+
+func remoteCurrentKey(namespace, key string) string {
+	return namespace + remoteKeyPrefix + "current:" + key
+}
+
+func remoteForecastKey(namespace, key string) string {
+	return namespace + remoteKeyPrefix + "forecast:" + key
+}
+
+// remoteCityPatterns matches every remote entry of a normalized city
+func remoteCityPatterns(namespace, city string) []string {
+	prefix := escapeGlob(namespace + remoteKeyPrefix)
+	escaped := escapeGlob(city)
+	variants := "[" + escapeGlob(cacheKeySeparator+derivedKeySeparator) + "]*"
+	return []string{
+		prefix + "current:" + escaped,
+		prefix + "current:" + escaped + variants,
+		prefix + "forecast:" + escaped,
+		prefix + "forecast:" + escaped + variants,
+	}
+}
+
+// remoteDerivedPatterns matches the remote presentation variants of baseKey
+func remoteDerivedPatterns(namespace, baseKey string) []string {
+	prefix := escapeGlob(namespace + remoteKeyPrefix)
+	escaped := escapeGlob(baseKey + derivedKeySeparator)
+	return []string{
+		prefix + "current:" + escaped + "*",
+		prefix + "forecast:" + escaped + "*",
+	}
+}
+
+// escapeGlob quotes the characters Redis treats as glob syntax
+func escapeGlob(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case '*', '?', '[', ']', '\\', '^', '-':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
\ No newline at end of file
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+func newTestCache(t *testing.T, prefix string) *WeatherCache {
+	t.Helper()
+	
+	cache := NewWeatherCache(time.Minute, 100, prefix, zap.NewNop())
+	t.Cleanup(func() {
+		cache.Close()
+	})
+	return cache
+}
+
+func TestCacheStatsCountHitsAndMisses(t *testing.T) {
+	cache := newTestCache(t, "")
+	if ratio := cache.GetStats()["hit_ratio"]; ratio != 0.0 {
+		t.Errorf("hit_ratio before any lookup = %v, want 0", ratio)
+	}
+	
+	cache.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague"})
+	cache.GetCurrentWeather("prague")
+	cache.GetCurrentWeather("prague")
+	cache.GetCurrentWeather("london")
+	cache.GetForecast("prague")
+	
+	stats := cache.GetStats()
+	if stats["hits"] != int64(2) || stats["misses"] != int64(2) {
+		t.Errorf("hits, misses = %v, %v, want 2, 2", stats["hits"], stats["misses"])
+	}
+	if stats["hit_ratio"] != 0.5 {
+		t.Errorf("hit_ratio = %v, want 0.5", stats["hit_ratio"])
+	}
+}
+
+func TestCacheCountsExpiredEntryAsMiss(t *testing.T) {
+	cache := newTestCache(t, "")
+	cache.setCurrentWeatherUntil("prague", &models.AggregatedCurrentWeather{}, time.Now().Add(-time.Second))
+	
+	if _, _, ok := cache.GetCurrentWeather("prague"); ok {
+		t.Error("expired entry was returned")
+	}
+	if misses := cache.GetStats()["misses"]; misses != int64(1) {
+		t.Errorf("misses = %v, want 1", misses)
+	}
+}
+
+func TestRequestsCountOneLookupEach(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: testDays(3, 20)}
+	aggregator := newTestAggregator(t, source)
+	imperial := models.QueryOptions{Units: models.UnitsImperial}
+	
+	assertStats := func(when string, hits, misses int64) {
+		t.Helper()
+		stats := aggregator.cache.GetStats()
+		if stats["hits"] != hits || stats["misses"] != misses {
+			t.Errorf("%s: hits, misses = %v, %v, want %d, %d", when, stats["hits"], stats["misses"], hits, misses)
+		}
+	}
+	
+	// The re-read after the fetch and the canonical fallback are not counted
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", imperial); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	assertStats("cold current weather", 0, 1)
+	if _, err := aggregator.GetAggregatedForecast(context.Background(), "London", 2, imperial); err != nil {
+		t.Fatalf("GetAggregatedForecast: %v", err)
+	}
+	assertStats("cold forecast", 0, 2)
+	
+	aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", imperial)
+	aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	aggregator.GetAggregatedForecast(context.Background(), "London", 2, imperial)
+	assertStats("warm requests", 3, 2)
+}
+
+// newTestTieredCache returns a cache backed by the shared redis server under prefix
+func newTestTieredCache(t *testing.T, server *miniredis.Miniredis, prefix string) *WeatherCache {
+	t.Helper()
+	
+	remote, err := newRedisCache("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("newRedisCache: %v", err)
+	}
+	cache := NewTieredWeatherCache(time.Minute, 100, prefix, remote, zap.NewNop())
+	t.Cleanup(func() {
+		cache.Close()
+	})
+	return cache
+}
+
+func TestTieredCacheWritesThroughAndPromotes(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newTestTieredCache(t, server, "test")
+	second := newTestTieredCache(t, server, "test")
+	
+	first.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague", Temperature: 21})
+	
+	// write-through with the local TTL
+	remoteKey := remoteCurrentKey("test:", "prague")
+	if !server.Exists(remoteKey) {
+		t.Fatalf("remote tier lacks %s after a write", remoteKey)
+	}
+	if ttl := server.TTL(remoteKey); ttl <= 59*time.Second || ttl > time.Minute {
+		t.Errorf("remote TTL = %v, want the cache duration of 1m", ttl)
+	}
+	
+	// the other instance misses locally and promotes the remote entry
+	weather, expiresAt, ok := second.GetCurrentWeather("prague")
+	if !ok || weather.Temperature != 21 {
+		t.Fatalf("second instance = %v, %v, want the entry from the remote tier", weather, ok)
+	}
+	if remaining := time.Until(expiresAt); remaining <= 58*time.Second || remaining > time.Minute {
+		t.Errorf("promoted entry expires in %v, want the remote TTL", remaining)
+	}
+	
+	// both instances now answer from memory
+	server.FlushAll()
+	for name, cache := range map[string]*WeatherCache{"first": first, "second": second} {
+		if _, _, ok := cache.GetCurrentWeather("prague"); !ok {
+			t.Errorf("%s instance missed with the remote tier empty, want a memory hit", name)
+		}
+	}
+	if hits := second.GetStats()["remote_hits"]; hits != int64(1) {
+		t.Errorf("second instance remote_hits = %v, want the single promotion", hits)
+	}
+}
+
+func TestTieredCacheDeleteClearsBothTiers(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache := newTestTieredCache(t, server, "test")
+	
+	cache.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague"})
+	cache.SetForecast(horizonKey("prague", 3), &models.AggregatedForecast{City: "Prague"})
+	cache.Delete("Prague")
+	
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("remote keys after Delete = %v, want none", keys)
+	}
+	if _, _, ok := cache.GetCurrentWeather("prague"); ok {
+		t.Error("current weather still cached after Delete")
+	}
+}
+
+func TestCachePrefixesKeys(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache := newTestTieredCache(t, server, "staging")
+	
+	cache.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague"})
+	cache.SetForecast(horizonKey("prague", 3), &models.AggregatedForecast{City: "Prague"})
+	
+	for _, key := range server.Keys() {
+		if !strings.HasPrefix(key, "staging:weather:") {
+			t.Errorf("remote key %q, want it under staging:weather:", key)
+		}
+	}
+	if len(server.Keys()) != 2 {
+		t.Errorf("remote keys = %v, want current weather and forecast", server.Keys())
+	}
+	for _, entry := range cache.Dump() {
+		if !strings.HasPrefix(entry.Key, "staging:") || entry.City != "prague" {
+			t.Errorf("local entry %q of %q, want it under staging: for prague", entry.Key, entry.City)
+		}
+	}
+	
+	// without a prefix keys are not namespaced at all
+	bare := newTestTieredCache(t, server, "")
+	bare.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague"})
+	if !server.Exists("weather:current:prague") {
+		t.Errorf("remote keys = %v, want weather:current:prague without a prefix", server.Keys())
+	}
+}
+
+func TestCachePrefixesDoNotCollide(t *testing.T) {
+	server := miniredis.RunT(t)
+	staging := newTestTieredCache(t, server, "staging")
+	prod := newTestTieredCache(t, server, "prod")
+	
+	staging.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague", Temperature: 10})
+	if weather, _, ok := prod.GetCurrentWeather("prague"); ok {
+		t.Fatalf("prod read %+v written by staging", weather)
+	}
+	
+	prod.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague", Temperature: 20})
+	for cache, want := range map[*WeatherCache]float64{staging: 10, prod: 20} {
+		if weather, _, ok := cache.GetCurrentWeather("prague"); !ok || weather.Temperature != want {
+			t.Errorf("%s read %v, %v, want its own %v°", cache.namespace, weather, ok, want)
+		}
+	}
+	
+	// another staging instance promotes staging's entry
+	other := newTestTieredCache(t, server, "staging")
+	if weather, _, ok := other.GetCurrentWeather("prague"); !ok || weather.Temperature != 10 {
+		t.Errorf("second staging instance read %v, %v, want staging's 10°", weather, ok)
+	}
+	
+	staging.Delete("Prague")
+	if !server.Exists(remoteCurrentKey("prod:", "prague")) {
+		t.Error("deleting in staging removed prod's entry")
+	}
+	if server.Exists(remoteCurrentKey("staging:", "prague")) {
+		t.Error("staging's entry survived Delete")
+	}
+}
+
+// newTickingCache returns a cache whose cleanup ticks every interval
+func newTickingCache(t *testing.T, interval time.Duration) *WeatherCache {
+	t.Helper()
+	
+	cache := &WeatherCache{
+		currentWeather:  make(map[string]CacheItem),
+		forecast:        make(map[string]CacheItem),
+		logger:          zap.NewNop(),
+		defaultDuration: time.Minute,
+		maxSize:         100,
+		cleanupInterval: interval,
+		stopCleanup:     make(chan bool),
+		cleanupDone:     make(chan struct{}),
+	}
+	go cache.startCleanup()
+	t.Cleanup(cache.Stop)
+	return cache
+}
+
+// returnsWithin fails the test unless fn returns within a second
+func returnsWithin(t *testing.T, name string, fn func()) {
+	t.Helper()
+	
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("%s did not return", name)
+	}
+}
+
+func TestCacheStopEndsCleanupGoroutine(t *testing.T) {
+	cache := newTickingCache(t, 5*time.Millisecond)
+	var ticks atomic.Int32
+	cache.OnCleanup(func() { ticks.Add(1) })
+	cache.setCurrentWeatherUntil("prague", &models.AggregatedCurrentWeather{}, time.Now().Add(-time.Second))
+	
+	deadline := time.Now().Add(time.Second)
+	for ticks.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("cleanup never ran")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cache.mu.RLock()
+	remaining := len(cache.currentWeather)
+	cache.mu.RUnlock()
+	if remaining != 0 {
+		t.Error("expired entry survived a cleanup tick")
+	}
+	
+	returnsWithin(t, "Stop", cache.Stop)
+	select {
+	case <-cache.cleanupDone:
+	default:
+		t.Fatal("cleanup goroutine still running after Stop")
+	}
+	
+	stopped := ticks.Load()
+	time.Sleep(30 * time.Millisecond)
+	if ticks.Load() != stopped {
+		t.Error("cleanup hook ran after Stop")
+	}
+}
+
+func TestCacheStopIsIdempotent(t *testing.T) {
+	cache := newTickingCache(t, time.Hour)
+	
+	returnsWithin(t, "Stop", cache.Stop)
+	returnsWithin(t, "second Stop", cache.Stop)
+	returnsWithin(t, "Close after Stop", func() {
+		if err := cache.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	
+	concurrent := newTickingCache(t, time.Hour)
+	returnsWithin(t, "concurrent Stop", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				concurrent.Stop()
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestCacheStopWaitsForRunningHook(t *testing.T) {
+	cache := newTickingCache(t, 5*time.Millisecond)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	cache.OnCleanup(func() {
+		once.Do(func() {
+			close(entered)
+			<-release
+		})
+	})
+	
+	<-entered
+	stopped := make(chan struct{})
+	go func() {
+		cache.Stop()
+		close(stopped)
+	}()
+	
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while the cleanup hook was still running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return once the hook finished")
+	}
+}
+
+func TestAggregatorCloseStopsCacheCleanup(t *testing.T) {
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	returnsWithin(t, "Close", func() {
+		if err := aggregator.Close(context.Background()); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	select {
+	case <-aggregator.cache.cleanupDone:
+	default:
+		t.Error("cache cleanup goroutine still running after Close")
+	}
+}
+
+func TestCacheDumpListsUnexpiredEntries(t *testing.T) {
+	cache := newTestCache(t, "dev")
+	updated := time.Now().Add(-time.Minute).UTC()
+	cache.SetCurrentWeather("prague", &models.AggregatedCurrentWeather{City: "Prague", LastUpdated: updated})
+	cache.SetCurrentWeather("prague"+derivedKeySeparator+"imperial", &models.AggregatedCurrentWeather{City: "Prague"})
+	cache.SetForecast("london", &models.AggregatedForecast{City: "London", LastUpdated: updated})
+	cache.setCurrentWeatherUntil("tokyo", &models.AggregatedCurrentWeather{City: "Tokyo"}, time.Now().Add(-time.Second))
+	
+	entries := cache.Dump()
+	if len(entries) != 3 {
+		t.Fatalf("Dump() = %+v, want the 3 unexpired entries", entries)
+	}
+	
+	want := []struct {
+		key, city, entryType string
+		derived              bool
+	}{
+		{"dev:london", "london", cacheTypeForecast, false},
+		{"dev:prague", "prague", cacheTypeCurrent, false},
+		{"dev:prague#imperial", "prague", cacheTypeCurrent, true},
+	}
+	for i, w := range want {
+		entry := entries[i]
+		if entry.Key != w.key || entry.City != w.city || entry.Type != w.entryType || entry.Derived != w.derived {
+			t.Errorf("entries[%d] = %+v, want key %q city %q type %q derived %v", i, entry, w.key, w.city, w.entryType, w.derived)
+		}
+		if entry.ExpiresIn <= 0 || entry.ExpiresIn > 60 || entry.Age != 0 {
+			t.Errorf("entries[%d] age %d expires in %d, want a fresh entry expiring within the minute", i, entry.Age, entry.ExpiresIn)
+		}
+	}
+	if !entries[0].LastUpdated.Equal(updated) || !entries[1].LastUpdated.Equal(updated) {
+		t.Errorf("LastUpdated = %v, %v, want %v", entries[0].LastUpdated, entries[1].LastUpdated, updated)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestCloseIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HISTORY_ENABLED", "true")
+	t.Setenv("HISTORY_DB_PATH", filepath.Join(dir, "history.db"))
+	t.Setenv("STATS_FILE", filepath.Join(dir, "stats.json"))
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	
+	if err := aggregator.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stats.json")); err != nil {
+		t.Errorf("stats not saved on Close: %v", err)
+	}
+	
+	// Closing the history storage or the stats loop twice would fail or panic
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = aggregator.Close(context.Background())
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("repeated Close = %v, want the first call's nil", err)
+		}
+	}
+}
+
+func TestCloseCancelsBackgroundFetchesPastDeadline(t *testing.T) {
+	t.Setenv("CACHE_DURATION", "1m")
+	t.Setenv("CACHE_PREFETCH_WINDOW", "0.2")
+	t.Setenv("REQUEST_FETCH_TIMEOUT", "1m")
+	source := newFakeClient("fake", 20)
+	aggregator := newTestAggregator(t, source)
+	aggregator.SetTrackedCities([]string{"Prague"})
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	
+	// A prefetch that would hang for a minute
+	source.delay = time.Minute
+	expireIn(t, aggregator, "Prague", 5*time.Second)
+	aggregator.prefetchExpiring()
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	err := aggregator.Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want the deadline the background fetch outlived", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Close took %v, want the background fetch cancelled", elapsed)
+	}
+	
+	if again := aggregator.Close(context.Background()); again != err {
+		t.Errorf("second Close = %v, want the first call's %v", again, err)
+	}
+}
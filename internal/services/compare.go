@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// CompareCities fetches the current weather of both cities concurrently. A
+// city that fails is reported in Errors; the diff needs both readings.
+func (a *Aggregator) CompareCities(ctx context.Context, first, second string, opts models.QueryOptions) (*models.WeatherComparison, error) {
+	cities := []string{first, second}
+	results := make([]*models.AggregatedCurrentWeather, len(cities))
+	errs := make([]error, len(cities))
+	
+	var wg sync.WaitGroup
+	for i, city := range cities {
+		wg.Add(1)
+		go func(i int, city string) {
+			defer wg.Done()
+			results[i], errs[i] = a.GetAggregatedCurrentWeather(ctx, city, opts)
+		}(i, city)
+	}
+	wg.Wait()
+	
+	if errs[0] != nil && errs[1] != nil {
+		return nil, fmt.Errorf("failed to fetch weather for both cities: %w", errs[0])
+	}
+	
+	comparison := &models.WeatherComparison{
+		Cities:  cities,
+		Weather: make(map[string]*models.AggregatedCurrentWeather),
+	}
+	
+	for i, city := range cities {
+		if errs[i] != nil {
+			if comparison.Errors == nil {
+				comparison.Errors = make(map[string]string)
+			}
+			comparison.Errors[city] = errs[i].Error()
+			continue
+		}
+		comparison.Weather[city] = results[i]
+	}
+	
+	if errs[0] == nil && errs[1] == nil {
+		comparison.Diff = compareWeather(first, results[0], second, results[1])
+	}
+	
+	return comparison, nil
+}
+
+// compareWeather computes the deltas of first relative to second
+func compareWeather(firstCity string, first *models.AggregatedCurrentWeather, secondCity string, second *models.AggregatedCurrentWeather) *models.WeatherDiff {
+	diff := &models.WeatherDiff{
+		TemperatureDelta: round2(first.Temperature - second.Temperature),
+		HumidityDelta:    round2(first.Humidity - second.Humidity),
+	}
+	
+	switch {
+	case diff.TemperatureDelta > 0:
+		diff.Warmer = firstCity
+	case diff.TemperatureDelta < 0:
+		diff.Warmer = secondCity
+	}
+	
+	return diff
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
\ No newline at end of file
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"go.uber.org/zap"
+)
+
+// dailySummaryStore keeps the latest daily summary per normalized city until
+// the next scheduled run replaces it
+type dailySummaryStore struct {
+	mu        sync.RWMutex
+	summaries map[string]*models.DailySummary
+}
+
+func newDailySummaryStore() *dailySummaryStore {
+	return &dailySummaryStore{summaries: make(map[string]*models.DailySummary)}
+}
+
+func (s *dailySummaryStore) set(city string, summary *models.DailySummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.summaries[utils.NormalizeCity(city)] = summary
+}
+
+func (s *dailySummaryStore) get(city string) (*models.DailySummary, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	summary, ok := s.summaries[utils.NormalizeCity(city)]
+	return summary, ok
+}
+
+// ComputeDailySummaries summarizes today's aggregated forecast of each city,
+// giving every city its own timeout so a slow one cannot starve the rest. A
+// city whose forecast fails keeps its previous summary and is reported in a
+// FetchError.
+func (a *Aggregator) ComputeDailySummaries(ctx context.Context, cities []string, timeout time.Duration) error {
+	var failed []string
+	for _, city := range cities {
+		cityCtx, cancel := context.WithTimeout(ctx, timeout)
+		forecast, err := a.GetAggregatedForecast(cityCtx, city, 1, models.QueryOptions{})
+		cancel()
+		if err != nil {
+			a.logger.Warn("Failed to compute daily summary",
+				zap.String("city", city),
+				zap.Error(err))
+			failed = append(failed, city)
+			continue
+		}
+		
+		today := forecast.Days[0]
+		a.dailySummaries.set(city, &models.DailySummary{
+			City:        forecast.City,
+			Date:        today.Date,
+			High:        today.MaxTemp,
+			Low:         today.MinTemp,
+			Condition:   today.Condition,
+			Description: today.Description,
+			Sources:     forecast.Sources,
+			ComputedAt:  time.Now(),
+		})
+	}
+	
+	if len(failed) > 0 {
+		return &FetchError{Cities: failed}
+	}
+	return nil
+}
+
+// GetDailySummary returns the summary of city computed by the last scheduled
+// run. Only tracked cities are summarized.
+func (a *Aggregator) GetDailySummary(city string) (*models.DailySummary, error) {
+	summary, ok := a.dailySummaries.get(city)
+	if !ok {
+		return nil, fmt.Errorf("daily summary for %s: %w", city, ErrNoData)
+	}
+	return summary, nil
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestComputeDailySummaries(t *testing.T) {
+	days := testDays(3, 10)
+	days[0].MaxTemp = 16
+	days[0].MinTemp = 4
+	days[0].Condition = models.ConditionRain
+	days[0].Description = "light rain"
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: days}
+	aggregator := newTestAggregator(t, source)
+	
+	if err := aggregator.ComputeDailySummaries(context.Background(), []string{"Prague", "London"}, time.Second); err != nil {
+		t.Fatalf("ComputeDailySummaries: %v", err)
+	}
+	
+	summary, err := aggregator.GetDailySummary("  PRAGUE ")
+	if err != nil {
+		t.Fatalf("GetDailySummary: %v", err)
+	}
+	if !summary.Date.Equal(days[0].Date) || summary.High != 16 || summary.Low != 4 {
+		t.Errorf("summary = %s %v/%v, want today at 16/4", summary.Date.Format("2006-01-02"), summary.High, summary.Low)
+	}
+	if summary.Condition != models.ConditionRain || summary.Description != "light rain" || !slices.Equal(summary.Sources, []string{"fake"}) {
+		t.Errorf("summary = %s %q from %v, want light rain from fake", summary.Condition, summary.Description, summary.Sources)
+	}
+	if _, err := aggregator.GetDailySummary("London"); err != nil {
+		t.Errorf("GetDailySummary(London): %v", err)
+	}
+	
+	if _, err := aggregator.GetDailySummary("Tokyo"); !errors.Is(err, ErrNoData) {
+		t.Errorf("untracked city: err = %v, want ErrNoData", err)
+	}
+}
+
+func TestComputeDailySummariesReportsFailedCities(t *testing.T) {
+	source := newFakeClient("fake", 20)
+	source.forecast = &models.WeatherForecast{Forecast: testDays(3, 10)}
+	aggregator := newTestAggregator(t, source)
+	
+	if err := aggregator.ComputeDailySummaries(context.Background(), []string{"Prague"}, time.Second); err != nil {
+		t.Fatalf("ComputeDailySummaries: %v", err)
+	}
+	first, _ := aggregator.GetDailySummary("Prague")
+	
+	// Prague's forecast is cached, London has to be fetched
+	source.err = errors.New("provider down")
+	err := aggregator.ComputeDailySummaries(context.Background(), []string{"Prague", "London"}, time.Second)
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || !slices.Equal(fetchErr.Cities, []string{"London"}) {
+		t.Fatalf("err = %v, want London reported failed", err)
+	}
+	if _, err := aggregator.GetDailySummary("London"); !errors.Is(err, ErrNoData) {
+		t.Errorf("failed city: err = %v, want ErrNoData", err)
+	}
+	if second, _ := aggregator.GetDailySummary("Prague"); second == first {
+		t.Error("Prague's summary was not recomputed")
+	}
+}
+
+func TestConvertDailySummary(t *testing.T) {
+	summary := &models.DailySummary{City: "Prague", High: 20, Low: 10}
+	
+	converted := ConvertDailySummary(summary, models.QueryOptions{Units: models.UnitsImperial})
+	if converted.High != 68 || converted.Low != 50 || converted.Units != models.UnitsImperial || converted.UnitLabels["temperature"] != "°F" {
+		t.Errorf("converted = %v/%v %s %v, want 68/50 °F", converted.High, converted.Low, converted.Units, converted.UnitLabels)
+	}
+	if summary.High != 20 || summary.Units != "" {
+		t.Errorf("stored summary changed to %+v", summary)
+	}
+	
+	if metric := ConvertDailySummary(summary, models.QueryOptions{}); metric.High != 20 || metric.Units != models.UnitsMetric {
+		t.Errorf("metric = %v %s, want 20 metric", metric.High, metric.Units)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHistoryDisabled is returned by trend queries when history storage is off
+var ErrHistoryDisabled = errors.New("history storage is not enabled")
+
+var (
+	ErrUnknownProvider   = errors.New("unknown provider")
+	ErrNoEnabledProvider = errors.New("all weather providers are disabled")
+)
+
+// ErrNoHistoricalProvider is returned when no enabled provider serves past days
+var ErrNoHistoricalProvider = errors.New("no enabled provider offers historical weather")
+
+// ErrNoForecastDays is returned when the providers answered but no forecast
+// day could be assembled from their data
+var ErrNoForecastDays = errors.New("no provider returned usable forecast days")
+
+// ErrNoRequestedSource is returned when none of the providers a request
+// restricted itself to is initialized and enabled
+var ErrNoRequestedSource = errors.New("none of the requested sources is available")
+
+// ErrNoData is returned when a fetch completed but left nothing for the city
+var ErrNoData = errors.New("no weather data available")
+
+// ErrMaintenance is returned instead of fetching while maintenance mode is on
+var ErrMaintenance = errors.New("service is in maintenance mode, only cached data is served")
+
+// UnavailableError is returned when every provider's circuit breaker is open,
+// so a fetch is not even attempted
+type UnavailableError struct {
+	RetryAfter time.Duration
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("all weather providers are unavailable, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// FetchError is returned by a fetch in which some cities failed; the others
+// were fetched and cached as usual
+type FetchError struct {
+	Cities []string
+}
+
+func (e *FetchError) Error() string {
+	return "some cities failed to fetch weather data"
+}
\ No newline at end of file
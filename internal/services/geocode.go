@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// SearchPlaces returns the places matching a free-text query, for clients to
+// offer autocomplete before requesting weather. Repeated queries are answered
+// from the geocoder's search cache.
+func (a *Aggregator) SearchPlaces(ctx context.Context, query string, limit int) ([]models.GeocodeMatch, error) {
+	if a.maintenance.Load() {
+		return nil, ErrMaintenance
+	}
+	
+	searchCtx, cancel := context.WithTimeout(ctx, a.fetchTimeout)
+	defer cancel()
+	
+	return a.geocoder.Search(searchCtx, query, limit)
+}
\ No newline at end of file
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+// GetHistoricalWeather averages the observed weather of a past day over the
+// enabled providers that offer historical data. The result is shaped like a
+// one-day forecast and is not cached.
+func (a *Aggregator) GetHistoricalWeather(ctx context.Context, city string, date time.Time, opts models.QueryOptions) (*models.AggregatedForecast, error) {
+	if err := a.checkAvailability(opts); err != nil {
+		return nil, err
+	}
+	
+	var historical []WeatherClient
+	for _, c := range selectSources(a.enabledClients(), opts.Sources) {
+		if _, ok := c.(HistoricalWeatherClient); ok {
+			historical = append(historical, c)
+		}
+	}
+	if len(historical) == 0 {
+		return nil, ErrNoHistoricalProvider
+	}
+	
+	markFetched(ctx)
+	fetchCtx, cancel := context.WithTimeout(ctx, a.fetchTimeout)
+	defer cancel()
+	
+	data := &models.WeatherData{
+		City:      city,
+		Forecasts: make(map[string]*models.WeatherForecast),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	
+	for _, c := range historical {
+		wg.Add(1)
+		go func(c WeatherClient) {
+			defer wg.Done()
+			
+			day, err := c.(HistoricalWeatherClient).GetHistoricalWeather(fetchCtx, city, date, opts)
+			if err != nil {
+				a.logger.Warn("Failed to fetch historical weather from source",
+					zap.String("source", c.Name()),
+					zap.String("city", city),
+					zap.Error(err))
+				return
+			}
+			
+			mu.Lock()
+			data.Forecasts[c.Name()] = &models.WeatherForecast{
+				City:     city,
+				Forecast: []models.ForecastDay{*day},
+				Source:   c.Name(),
+			}
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+	
+	aggregated := a.aggregateForecast(data, 1)
+	if aggregated == nil {
+		return nil, fmt.Errorf("historical weather for %s on %s: %w", city, date.Format("2006-01-02"), ErrNoData)
+	}
+	return aggregated, nil
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+var ErrTimeOutOfRange = errors.New("requested time is outside the forecast horizon")
+
+// interpolateHourly linearly interpolates an ordered hourly series at t. The
+// condition and description come from the nearer of the two surrounding points.
+func interpolateHourly(points []models.HourlyPoint, t time.Time) (models.HourlyPoint, bool) {
+	if len(points) == 0 || t.Before(points[0].Time) || t.After(points[len(points)-1].Time) {
+		return models.HourlyPoint{}, false
+	}
+	
+	for i := 1; i < len(points); i++ {
+		before, after := points[i-1], points[i]
+		if t.After(after.Time) {
+			continue
+		}
+		
+		span := after.Time.Sub(before.Time)
+		if span <= 0 {
+			return after, true
+		}
+		ratio := float64(t.Sub(before.Time)) / float64(span)
+		
+		nearest := before
+		if ratio > 0.5 {
+			nearest = after
+		}
+		
+		return models.HourlyPoint{
+			Time:        t,
+			Temperature: lerp(before.Temperature, after.Temperature, ratio),
+			Humidity:    lerp(before.Humidity, after.Humidity, ratio),
+			WindSpeed:   lerp(before.WindSpeed, after.WindSpeed, ratio),
+			PrecipitationProbability: lerp(before.PrecipitationProbability, after.PrecipitationProbability, ratio),
+			Condition:   nearest.Condition,
+			Description: nearest.Description,
+		}, true
+	}
+	
+	// t equals the only point
+	return points[0], true
+}
+
+func lerp(a, b, ratio float64) float64 {
+	return a + (b-a)*ratio
+}
+
+// aggregatePointForecast interpolates every source's hourly series at t and
+// averages the sources whose series covers it
+func aggregatePointForecast(data *models.WeatherData, t time.Time, conditionStrategy string) (*models.PointForecast, error) {
+	var totalTemp, totalHumidity, totalWind, totalPop float64
+	var conditions []models.ConditionCode
+	var descriptions []string
+	var sources []string
+	var earliest, latest time.Time
+	
+	for source, forecast := range data.Forecasts {
+		if len(forecast.Hourly) == 0 {
+			continue
+		}
+		
+		first, last := forecast.Hourly[0].Time, forecast.Hourly[len(forecast.Hourly)-1].Time
+		if earliest.IsZero() || first.Before(earliest) {
+			earliest = first
+		}
+		if last.After(latest) {
+			latest = last
+		}
+		
+		point, ok := interpolateHourly(forecast.Hourly, t)
+		if !ok {
+			continue
+		}
+		
+		totalTemp += point.Temperature
+		totalHumidity += point.Humidity
+		totalWind += point.WindSpeed
+		totalPop += point.PrecipitationProbability
+		conditions = append(conditions, point.Condition)
+		descriptions = append(descriptions, point.Description)
+		sources = append(sources, source)
+	}
+	
+	if len(sources) == 0 {
+		if earliest.IsZero() {
+			return nil, fmt.Errorf("no hourly forecast available for %s", data.City)
+		}
+		return nil, fmt.Errorf("%w: forecast covers %s to %s", ErrTimeOutOfRange,
+			earliest.Format(time.RFC3339), latest.Format(time.RFC3339))
+	}
+	
+	count := float64(len(sources))
+	condition, description := aggregateCondition(conditionStrategy, conditions, descriptions)
+	
+	return &models.PointForecast{
+		City:        data.City,
+		Time:        t,
+		Temperature: totalTemp / count,
+		Humidity:    totalHumidity / count,
+		WindSpeed:   totalWind / count,
+		PrecipitationProbability: totalPop / count,
+		Condition:   condition,
+		Description: description,
+		Sources:     sources,
+	}, nil
+}
\ No newline at end of file
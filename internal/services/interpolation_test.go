@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestInterpolateHourly(t *testing.T) {
+	start := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	points := []models.HourlyPoint{
+		{Time: start, Temperature: 10, Humidity: 80, WindSpeed: 2, Condition: models.ConditionClear, Description: "clear sky"},
+		{Time: start.Add(time.Hour), Temperature: 14, Humidity: 60, WindSpeed: 6, Condition: models.ConditionRain, Description: "light rain"},
+	}
+	
+	tests := []struct {
+		at          time.Time
+		temperature float64
+		humidity    float64
+		condition   models.ConditionCode
+	}{
+		{start, 10, 80, models.ConditionClear},
+		{start.Add(15 * time.Minute), 11, 75, models.ConditionClear},
+		{start.Add(45 * time.Minute), 13, 65, models.ConditionRain},
+		{start.Add(time.Hour), 14, 60, models.ConditionRain},
+	}
+	for _, tt := range tests {
+		point, ok := interpolateHourly(points, tt.at)
+		if !ok {
+			t.Errorf("interpolateHourly(%s) not covered", tt.at.Format(time.Kitchen))
+			continue
+		}
+		if !approxEqual(point.Temperature, tt.temperature, 1e-9) || !approxEqual(point.Humidity, tt.humidity, 1e-9) {
+			t.Errorf("interpolateHourly(%s) = %.2f°, %.2f%%, want %.2f°, %.2f%%",
+				tt.at.Format(time.Kitchen), point.Temperature, point.Humidity, tt.temperature, tt.humidity)
+		}
+		if point.Condition != tt.condition {
+			t.Errorf("interpolateHourly(%s) condition = %s, want the nearer point's %s", tt.at.Format(time.Kitchen), point.Condition, tt.condition)
+		}
+	}
+	
+	for _, at := range []time.Time{start.Add(-time.Minute), start.Add(time.Hour + time.Minute)} {
+		if _, ok := interpolateHourly(points, at); ok {
+			t.Errorf("interpolateHourly(%s) covered, want outside the series", at.Format(time.Kitchen))
+		}
+	}
+}
+
+func TestAggregatePointForecastOutOfRange(t *testing.T) {
+	start := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	data := &models.WeatherData{
+		City: "Prague",
+		Forecasts: map[string]*models.WeatherForecast{
+			"a": {Hourly: []models.HourlyPoint{{Time: start, Temperature: 10}, {Time: start.Add(time.Hour), Temperature: 20}}},
+			"b": {Hourly: []models.HourlyPoint{{Time: start, Temperature: 20}, {Time: start.Add(3 * time.Hour), Temperature: 20}}},
+		},
+	}
+	
+	point, err := aggregatePointForecast(data, start.Add(30*time.Minute), "")
+	if err != nil {
+		t.Fatalf("aggregatePointForecast: %v", err)
+	}
+	if point.Temperature != 17.5 || len(point.Sources) != 2 {
+		t.Errorf("point = %.2f° from %v, want 17.50° from both sources", point.Temperature, point.Sources)
+	}
+	
+	// only b covers two hours in
+	point, err = aggregatePointForecast(data, start.Add(2*time.Hour), "")
+	if err != nil || len(point.Sources) != 1 || point.Sources[0] != "b" {
+		t.Errorf("aggregatePointForecast two hours in = %v, %v, want b alone", point, err)
+	}
+	
+	if _, err := aggregatePointForecast(data, start.Add(4*time.Hour), ""); !errors.Is(err, ErrTimeOutOfRange) {
+		t.Errorf("err = %v, want ErrTimeOutOfRange beyond every series", err)
+	}
+}
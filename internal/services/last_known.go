@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// lastKnownEntry is an aggregate and when it was stored
+type lastKnownEntry struct {
+	weather  *models.AggregatedCurrentWeather
+	storedAt time.Time
+}
+
+// lastKnownStore keeps the latest aggregated current weather per data key
+// beyond the cache TTL, to be served stale when a live fetch fails entirely
+type lastKnownStore struct {
+	mu      sync.Mutex
+	entries map[string]lastKnownEntry
+	maxAge  time.Duration // entries stored longer ago than this are not served, 0 disables the store
+	maxSize int           // entries kept at most, the oldest is evicted first
+}
+
+func newLastKnownStore(maxAge time.Duration, maxSize int) *lastKnownStore {
+	return &lastKnownStore{
+		entries: make(map[string]lastKnownEntry),
+		maxAge:  maxAge,
+		maxSize: maxSize,
+	}
+}
+
+func (s *lastKnownStore) set(key string, weather *models.AggregatedCurrentWeather) {
+	if s.maxAge <= 0 {
+		return
+	}
+	
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= s.maxSize {
+		s.evictOldest()
+	}
+	s.entries[key] = lastKnownEntry{weather: weather, storedAt: time.Now()}
+}
+
+// get returns the entry for key unless it was stored longer than maxAge ago, in
+// which case it is dropped. The age is not taken from LastUpdated, which stays
+// zero when no provider reported an observation time.
+func (s *lastKnownStore) get(key string) (*models.AggregatedCurrentWeather, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.storedAt) > s.maxAge {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.weather, true
+}
+
+// sweep drops the entries older than maxAge, which get only does for the keys
+// it is asked for
+func (s *lastKnownStore) sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for key, entry := range s.entries {
+		if time.Since(entry.storedAt) > s.maxAge {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// evictOldest drops the entry stored longest ago, the caller holds s.mu
+func (s *lastKnownStore) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range s.entries {
+		if oldestKey == "" || entry.storedAt.Before(oldest) {
+			oldestKey = key
+			oldest = entry.storedAt
+		}
+	}
+	delete(s.entries, oldestKey)
+}
+
+// deleteCity drops the entries of city
+func (s *lastKnownStore) deleteCity(city string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	for key := range s.entries {
+		if matchesCity(key, city) {
+			delete(s.entries, key)
+		}
+	}
+}
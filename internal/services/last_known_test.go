@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestLastKnownServedStaleWhenEveryProviderFails(t *testing.T) {
+	t.Setenv("CACHE_DURATION", "50ms")
+	source := newFakeClient("fake", 20)
+	aggregator := newTestAggregator(t, source)
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	source.err = errors.New("provider down")
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather after the providers failed: %v", err)
+	}
+	if !weather.Stale || weather.Temperature != 20 {
+		t.Errorf("weather = %v° stale %v, want the last known 20° flagged stale", weather.Temperature, weather.Stale)
+	}
+	
+	imperial, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{Units: models.UnitsImperial})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather in imperial units: %v", err)
+	}
+	if !imperial.Stale || imperial.Temperature != 68 {
+		t.Errorf("imperial weather = %v° stale %v, want the last known 68°F flagged stale", imperial.Temperature, imperial.Stale)
+	}
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "London", models.QueryOptions{}); err == nil {
+		t.Error("a city never fetched succeeded with every provider failing")
+	}
+}
+
+func TestLastKnownServedWithoutObservationTime(t *testing.T) {
+	t.Setenv("CACHE_DURATION", "50ms")
+	source := newFakeClient("fake", 20)
+	source.current.Timestamp = time.Time{}
+	aggregator := newTestAggregator(t, source)
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	source.err = errors.New("provider down")
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather after the providers failed: %v", err)
+	}
+	if !weather.Stale || weather.Temperature != 20 {
+		t.Errorf("weather = %v° stale %v, want the last known 20° flagged stale despite its zero last_updated", weather.Temperature, weather.Stale)
+	}
+}
+
+func TestLastKnownNotServed(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		opts  models.QueryOptions
+		setup func(*Aggregator)
+	}{
+		{name: "store disabled", env: map[string]string{"LAST_KNOWN_MAX_AGE": "0s"}},
+		{name: "older than the request's max age", opts: models.QueryOptions{MaxAge: 10 * time.Millisecond}},
+		{name: "in maintenance", setup: func(a *Aggregator) { a.SetMaintenance(true) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CACHE_DURATION", "50ms")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			source := newFakeClient("fake", 20)
+			aggregator := newTestAggregator(t, source)
+			
+			if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+				t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+			}
+			time.Sleep(100 * time.Millisecond)
+			source.err = errors.New("provider down")
+			if tt.setup != nil {
+				tt.setup(aggregator)
+			}
+			
+			if weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", tt.opts); err == nil {
+				t.Errorf("weather = %v stale %v, want an error", weather.Temperature, weather.Stale)
+			}
+		})
+	}
+}
+
+func TestLastKnownStoreEvictsOldest(t *testing.T) {
+	store := newLastKnownStore(time.Hour, 2)
+	for _, city := range []string{"prague", "london", "tokyo"} {
+		store.set(city, &models.AggregatedCurrentWeather{City: city, LastUpdated: time.Now()})
+		time.Sleep(time.Millisecond)
+	}
+	
+	if _, ok := store.get("prague"); ok {
+		t.Error("oldest entry kept past the size limit")
+	}
+	for _, city := range []string{"london", "tokyo"} {
+		if _, ok := store.get(city); !ok {
+			t.Errorf("%s evicted, want only the oldest entry dropped", city)
+		}
+	}
+}
+
+func TestLastKnownStoreDropsExpiredEntries(t *testing.T) {
+	store := newLastKnownStore(50*time.Millisecond, 10)
+	store.set("prague", &models.AggregatedCurrentWeather{LastUpdated: time.Now()})
+	store.set("london", &models.AggregatedCurrentWeather{LastUpdated: time.Now()})
+	time.Sleep(100 * time.Millisecond)
+	store.set("tokyo", &models.AggregatedCurrentWeather{LastUpdated: time.Now().Add(-2 * time.Hour)})
+	
+	if _, ok := store.get("tokyo"); !ok {
+		t.Error("entry just stored dropped for an old last_updated, want its age taken from when it was stored")
+	}
+	if _, ok := store.get("prague"); ok {
+		t.Error("entry older than the max age served")
+	}
+	store.sweep()
+	if len(store.entries) != 1 {
+		t.Errorf("%d entries after the sweep, want tokyo alone", len(store.entries))
+	}
+	
+	store.set(dataKey("Tokyo", models.QueryOptions{Lang: "de"}), &models.AggregatedCurrentWeather{LastUpdated: time.Now()})
+	store.deleteCity("Tokyo")
+	if len(store.entries) != 0 {
+		t.Errorf("%d entries after deleting the city, want none", len(store.entries))
+	}
+}
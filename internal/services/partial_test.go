@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// newPartialAggregator aggregates a fast provider at 10° and one at 30° that
+// answers after slowDelay, with a 20ms soft deadline
+func newPartialAggregator(t *testing.T, slowDelay time.Duration) (*Aggregator, *fakeClient) {
+	t.Helper()
+	
+	t.Setenv("PARTIAL_RESPONSE_TIMEOUT", "20ms")
+	t.Setenv("REQUEST_FETCH_TIMEOUT", "1m")
+	slow := newFakeClient("slow", 30)
+	slow.delay = slowDelay
+	return newTestAggregator(t, newFakeClient("fast", 10), slow), slow
+}
+
+// storedSources returns the sources of the data fetched for city, sorted, and
+// whether it is still partial
+func storedSources(aggregator *Aggregator, city string) ([]string, bool) {
+	aggregator.mu.RLock()
+	defer aggregator.mu.RUnlock()
+	
+	data, ok := aggregator.weatherData[dataKey(city, models.QueryOptions{})]
+	if !ok {
+		return nil, false
+	}
+	var sources []string
+	for source := range data.Current {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+	return sources, data.Partial
+}
+
+func TestSoftDeadlineReturnsWhatArrived(t *testing.T) {
+	aggregator, slow := newPartialAggregator(t, 300*time.Millisecond)
+	
+	started := time.Now()
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed >= slow.delay {
+		t.Errorf("fetch took %v, want the fast result before the slow provider's %v", elapsed, slow.delay)
+	}
+	if !weather.Partial || !slices.Equal(weather.Sources, []string{"fast"}) || weather.Temperature != 10 {
+		t.Errorf("weather = %.1f° from %v, partial %v, want the fast 10° marked partial", weather.Temperature, weather.Sources, weather.Partial)
+	}
+}
+
+func TestStragglerIsMergedWhenItLands(t *testing.T) {
+	aggregator, _ := newPartialAggregator(t, 50*time.Millisecond)
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	
+	// the cached aggregate is refreshed with the late response
+	deadline := time.Now().Add(time.Second)
+	for {
+		weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+		if err != nil {
+			t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+		}
+		if !weather.Partial {
+			if len(weather.Sources) != 2 || weather.Temperature != 20 {
+				t.Errorf("weather = %.1f° from %v, want 20° from both", weather.Temperature, weather.Sources)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("weather from %v still partial, want the late slow response merged", weather.Sources)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if sources, partial := storedSources(aggregator, "Prague"); partial || len(sources) != 2 {
+		t.Errorf("stored sources %v partial %v, want both", sources, partial)
+	}
+}
+
+func TestFailedStragglerCompletesTheFetch(t *testing.T) {
+	aggregator, slow := newPartialAggregator(t, 50*time.Millisecond)
+	slow.err = errors.New("provider down")
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if !weather.Partial {
+		t.Error("Partial = false before the slow provider answered")
+	}
+	
+	deadline := time.Now().Add(time.Second)
+	for {
+		sources, partial := storedSources(aggregator, "Prague")
+		if !partial {
+			if !slices.Equal(sources, []string{"fast"}) {
+				t.Errorf("sources = %v, want fast alone", sources)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("data still partial after the slow provider failed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSoftDeadlineWaitsForASuccess(t *testing.T) {
+	t.Setenv("PARTIAL_RESPONSE_TIMEOUT", "20ms")
+	failing := newFakeClient("failing", 10)
+	failing.err = errors.New("provider down")
+	slow := newFakeClient("slow", 30)
+	slow.delay = 80 * time.Millisecond
+	aggregator := newTestAggregator(t, failing, slow)
+	
+	started := time.Now()
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < slow.delay {
+		t.Errorf("fetch returned after %v, want it to wait for the only success", elapsed)
+	}
+	if weather.Partial || !slices.Equal(weather.Sources, []string{"slow"}) {
+		t.Errorf("weather from %v, partial %v, want slow and complete", weather.Sources, weather.Partial)
+	}
+}
+
+func TestWithoutSoftDeadlineEveryProviderIsAwaited(t *testing.T) {
+	slow := newFakeClient("slow", 30)
+	slow.delay = 50 * time.Millisecond
+	aggregator := newTestAggregator(t, newFakeClient("fast", 10), slow)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.Partial || len(weather.Sources) != 2 {
+		t.Errorf("weather from %v, partial %v, want both sources", weather.Sources, weather.Partial)
+	}
+}
+
+func TestCloseWaitsForStragglers(t *testing.T) {
+	aggregator, _ := newPartialAggregator(t, 50*time.Millisecond)
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if err := aggregator.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if sources, partial := storedSources(aggregator, "Prague"); partial || len(sources) != 2 {
+		t.Errorf("sources %v partial %v after Close, want the straggler merged first", sources, partial)
+	}
+}
+
+func TestCloseCancelsStragglers(t *testing.T) {
+	aggregator, slow := newPartialAggregator(t, time.Minute)
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if err := aggregator.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want the deadline the straggler outlived", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Close took %v, want the straggler cancelled", elapsed)
+	}
+	deadline := time.Now().Add(time.Second)
+	for slow.active.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the straggler's request is still running after Close, want it cancelled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, partial := storedSources(aggregator, "Prague"); !partial {
+		t.Error("the cancelled straggler was merged")
+	}
+}
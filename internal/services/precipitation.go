@@ -0,0 +1,33 @@
+package services
+
+import "github.com/bobby-s-dev/weather-aggregator/internal/models"
+
+// Between these temperatures rain and snow mix and fall as sleet
+const (
+	sleetMinTemp = 0.0
+	sleetMaxTemp = 2.0
+)
+
+// precipitationType classifies what is falling from the aggregated condition
+// and temperature. Rain below freezing is reported as snow; snow reported
+// above the sleet band is taken as sleet.
+func precipitationType(condition models.ConditionCode, temperature float64) models.PrecipitationType {
+	switch condition {
+	case models.ConditionSnow:
+		if temperature > sleetMaxTemp {
+			return models.PrecipitationSleet
+		}
+		return models.PrecipitationSnow
+	case models.ConditionDrizzle, models.ConditionRain, models.ConditionThunderstorm:
+		switch {
+		case temperature < sleetMinTemp:
+			return models.PrecipitationSnow
+		case temperature <= sleetMaxTemp:
+			return models.PrecipitationSleet
+		default:
+			return models.PrecipitationRain
+		}
+	default:
+		return models.PrecipitationNone
+	}
+}
\ No newline at end of file
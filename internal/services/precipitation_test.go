@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestPrecipitationType(t *testing.T) {
+	tests := []struct {
+		condition   models.ConditionCode
+		temperature float64
+		want        models.PrecipitationType
+	}{
+		{models.ConditionClear, -5, models.PrecipitationNone},
+		{models.ConditionClouds, 10, models.PrecipitationNone},
+		{models.ConditionRain, 10, models.PrecipitationRain},
+		{models.ConditionRain, 2.1, models.PrecipitationRain},
+		{models.ConditionRain, 2, models.PrecipitationSleet},
+		{models.ConditionDrizzle, 0, models.PrecipitationSleet},
+		{models.ConditionRain, -0.1, models.PrecipitationSnow},
+		{models.ConditionThunderstorm, 1, models.PrecipitationSleet},
+		{models.ConditionSnow, -10, models.PrecipitationSnow},
+		{models.ConditionSnow, 2, models.PrecipitationSnow},
+		{models.ConditionSnow, 2.1, models.PrecipitationSleet},
+	}
+	
+	for _, tt := range tests {
+		if got := precipitationType(tt.condition, tt.temperature); got != tt.want {
+			t.Errorf("precipitationType(%s, %v) = %s, want %s", tt.condition, tt.temperature, got, tt.want)
+		}
+	}
+}
+
+func TestCurrentWeatherReportsPrecipitationType(t *testing.T) {
+	source := newFakeClient("fake", 1)
+	source.current.Condition = models.ConditionRain
+	source.current.Description = "light rain"
+	aggregator := newTestAggregator(t, source)
+	
+	weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if weather.PrecipitationType != models.PrecipitationSleet {
+		t.Errorf("PrecipitationType = %s, want %s for rain at 1°C", weather.PrecipitationType, models.PrecipitationSleet)
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+// prefetchExpiring re-fetches tracked cities whose cached current weather is
+// about to expire, so requests keep hitting a warm cache between scheduler
+// runs. At most prefetchMax cities are refreshed per tick and a tick is
+// skipped while the previous prefetch is still running, so slow or rate
+// limited providers are not piled up on.
+func (a *Aggregator) prefetchExpiring() {
+	if a.maintenance.Load() {
+		return
+	}
+	
+	a.mu.RLock()
+	tracked := a.trackedCities
+	a.mu.RUnlock()
+	
+	var cities []string
+	for _, city := range tracked {
+		if a.cache.ExpiresWithin(dataKey(city, models.QueryOptions{}), a.prefetchWindow) {
+			cities = append(cities, city)
+		}
+		if len(cities) == a.prefetchMax {
+			break
+		}
+	}
+	if len(cities) == 0 {
+		return
+	}
+	
+	if !a.prefetching.CompareAndSwap(false, true) {
+		a.logger.Debug("Previous prefetch still running, skipping", zap.Strings("cities", cities))
+		return
+	}
+	
+	a.background.Add(1)
+	go func() {
+		defer a.background.Done()
+		defer a.prefetching.Store(false)
+		
+		ctx, cancel := context.WithTimeout(a.shutdown, a.fetchTimeout)
+		defer cancel()
+		
+		a.logger.Info("Prefetching cache entries close to expiry", zap.Strings("cities", cities))
+		if err := a.fetchWeatherData(ctx, cities, models.QueryOptions{}); err != nil {
+			a.logger.Warn("Prefetch failed", zap.Strings("cities", cities), zap.Error(err))
+		}
+	}()
+}
\ No newline at end of file
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// expireIn moves the cached current weather of city to expire after d, as if
+// the clock had advanced to d before its expiry
+func expireIn(t *testing.T, aggregator *Aggregator, city string, d time.Duration) {
+	t.Helper()
+	
+	key := dataKey(city, models.QueryOptions{})
+	weather, _, ok := aggregator.cache.GetCurrentWeather(key)
+	if !ok {
+		t.Fatalf("%s is not cached", city)
+	}
+	aggregator.cache.setCurrentWeatherUntil(key, weather, time.Now().Add(d))
+}
+
+func TestPrefetchRefreshesEntriesInTheWindow(t *testing.T) {
+	t.Setenv("CACHE_DURATION", "1m")
+	t.Setenv("CACHE_PREFETCH_WINDOW", "0.2")
+	source := newFakeClient("fake", 20)
+	aggregator := newTestAggregator(t, source)
+	aggregator.SetTrackedCities([]string{"Prague"})
+	
+	if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	source.calls.Store(0)
+	
+	aggregator.prefetchExpiring()
+	aggregator.background.Wait()
+	if calls := source.calls.Load(); calls != 0 {
+		t.Fatalf("%d fetches for a fresh entry, want none", calls)
+	}
+	
+	expireIn(t, aggregator, "Prague", 5*time.Second)
+	aggregator.prefetchExpiring()
+	aggregator.background.Wait()
+	if calls := source.calls.Load(); calls != 1 {
+		t.Fatalf("%d fetches for an entry 5s from expiry with a 12s window, want 1", calls)
+	}
+	if aggregator.cache.ExpiresWithin(dataKey("Prague", models.QueryOptions{}), aggregator.prefetchWindow) {
+		t.Error("entry still close to expiry after the prefetch, want it refreshed")
+	}
+}
+
+func TestPrefetchRespectsLimitAndMaintenance(t *testing.T) {
+	t.Setenv("CACHE_DURATION", "1m")
+	t.Setenv("CACHE_PREFETCH_WINDOW", "0.2")
+	t.Setenv("CACHE_PREFETCH_MAX_CITIES", "1")
+	source := newFakeClient("fake", 20)
+	aggregator := newTestAggregator(t, source)
+	aggregator.SetTrackedCities([]string{"Prague", "London"})
+	
+	for _, city := range []string{"Prague", "London"} {
+		if _, err := aggregator.GetAggregatedCurrentWeather(context.Background(), city, models.QueryOptions{}); err != nil {
+			t.Fatalf("GetAggregatedCurrentWeather(%s): %v", city, err)
+		}
+		expireIn(t, aggregator, city, 5*time.Second)
+	}
+	
+	aggregator.SetMaintenance(true)
+	source.calls.Store(0)
+	aggregator.prefetchExpiring()
+	aggregator.background.Wait()
+	if calls := source.calls.Load(); calls != 0 {
+		t.Fatalf("%d fetches in maintenance mode, want none", calls)
+	}
+	
+	aggregator.SetMaintenance(false)
+	aggregator.prefetchExpiring()
+	aggregator.background.Wait()
+	if calls := source.calls.Load(); calls != 1 {
+		t.Errorf("%d fetches with CACHE_PREFETCH_MAX_CITIES=1, want 1", calls)
+	}
+}
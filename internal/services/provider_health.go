@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// healthProbeCity is in the built-in coordinate table, so probing costs
+	// a single provider request and no geocoding
+	healthProbeCity = "London"
+
+	// healthProbeTimeout bounds each provider probe
+	healthProbeTimeout = 5 * time.Second
+)
+
+// providerHealthCache holds the last probe results so the endpoint can be
+// polled freely without multiplying upstream requests
+type providerHealthCache struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	results   []models.ProviderHealth
+}
+
+// CheckProviders probes every enabled provider with a current-weather request
+// for healthProbeCity. Results are reused for minInterval; the returned flag
+// reports whether they came from an earlier check. Providers whose breaker is
+// open are reported down without a request.
+func (a *Aggregator) CheckProviders(ctx context.Context, minInterval time.Duration) ([]models.ProviderHealth, time.Time, bool, error) {
+	if a.maintenance.Load() {
+		return nil, time.Time{}, false, ErrMaintenance
+	}
+	
+	// Held for the whole check so concurrent callers wait for one probe round
+	a.providerHealth.mu.Lock()
+	defer a.providerHealth.mu.Unlock()
+	
+	if !a.providerHealth.checkedAt.IsZero() && time.Since(a.providerHealth.checkedAt) < minInterval {
+		return a.providerHealth.results, a.providerHealth.checkedAt, true, nil
+	}
+	
+	clients := a.enabledClients()
+	results := make([]models.ProviderHealth, len(clients))
+	
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c WeatherClient) {
+			defer wg.Done()
+			results[i] = a.probeProvider(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+	
+	a.providerHealth.checkedAt = time.Now()
+	a.providerHealth.results = results
+	return results, a.providerHealth.checkedAt, false, nil
+}
+
+func (a *Aggregator) probeProvider(ctx context.Context, c WeatherClient) models.ProviderHealth {
+	health := models.ProviderHealth{
+		Name:         c.Name(),
+		Status:       models.ProviderDown,
+		BreakerState: c.BreakerState(),
+	}
+	
+	if retryAfter := c.BreakerRetryAfter(); retryAfter > 0 {
+		health.Error = "circuit breaker is open"
+		return health
+	}
+	
+	probeCtx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+	
+	start := time.Now()
+	_, err := c.GetCurrentWeather(probeCtx, healthProbeCity, models.QueryOptions{})
+	health.LatencyMs = time.Since(start).Milliseconds()
+	health.BreakerState = c.BreakerState()
+	
+	if err != nil {
+		a.logger.Warn("Provider health probe failed",
+			zap.String("source", c.Name()),
+			zap.Error(err))
+		health.Error = err.Error()
+		return health
+	}
+	
+	health.Status = models.ProviderUp
+	return health
+}
\ No newline at end of file
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/config"
+)
+
+// orderClients ranks the clients of a fetch: the providers of
+// PROVIDER_PRIORITY in that order, then the rest in registration order. The
+// latency order puts the providers with the lowest observed latency first and
+// keeps that order for the unmeasured ones, which go last. The first ranked
+// provider is the one the soft deadline waits for, see preferredSource.
+func (a *Aggregator) orderClients(clients []WeatherClient) []WeatherClient {
+	rank := make(map[string]int, len(a.providerPriority))
+	for i, source := range a.providerPriority {
+		rank[source] = i
+	}
+	priority := func(c WeatherClient) int {
+		if i, ok := rank[c.Name()]; ok {
+			return i
+		}
+		return len(a.providerPriority)
+	}
+	
+	ordered := append([]WeatherClient(nil), clients...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return priority(ordered[i]) < priority(ordered[j])
+	})
+	
+	if a.providerOrder != config.ProviderOrderLatency {
+		return ordered
+	}
+	
+	a.mu.RLock()
+	latency := make(map[string]time.Duration, len(ordered))
+	for _, c := range ordered {
+		if stats, ok := a.sourceStats[c.Name()]; ok {
+			latency[c.Name()] = stats.latency
+		}
+	}
+	a.mu.RUnlock()
+	
+	sort.SliceStable(ordered, func(i, j int) bool {
+		li, lj := latency[ordered[i].Name()], latency[ordered[j].Name()]
+		if li == 0 || lj == 0 {
+			return li != 0 && lj == 0
+		}
+		return li < lj
+	})
+	return ordered
+}
+
+// preferredSource returns the provider a fetch past its soft deadline still
+// waits for, the first of ordered when it was ranked explicitly: listed in
+// PROVIDER_PRIORITY, or with the latency order measured. Empty when none is.
+func (a *Aggregator) preferredSource(ordered []WeatherClient) string {
+	if len(ordered) == 0 {
+		return ""
+	}
+	first := ordered[0].Name()
+	
+	if a.providerOrder == config.ProviderOrderLatency {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+		
+		if stats, ok := a.sourceStats[first]; ok && stats.latency > 0 {
+			return first
+		}
+		return ""
+	}
+	
+	for _, source := range a.providerPriority {
+		if source == first {
+			return first
+		}
+	}
+	return ""
+}
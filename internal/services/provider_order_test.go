@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// clientNames lists the names of clients in order
+func clientNames(clients []WeatherClient) []string {
+	names := make([]string, len(clients))
+	for i, c := range clients {
+		names[i] = c.Name()
+	}
+	return names
+}
+
+func newOrderClients() []WeatherClient {
+	return []WeatherClient{newFakeClient("a", 20), newFakeClient("b", 20), newFakeClient("c", 20), newFakeClient("d", 20)}
+}
+
+func TestOrderClientsByPriority(t *testing.T) {
+	tests := []struct {
+		priority  string
+		order     []string
+		preferred string
+	}{
+		{"", []string{"a", "b", "c", "d"}, ""},
+		{"c,a", []string{"c", "a", "b", "d"}, "c"},
+		{"D, missing ,b", []string{"d", "b", "a", "c"}, "d"},
+		{"missing,c", []string{"c", "a", "b", "d"}, "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.priority, func(t *testing.T) {
+			t.Setenv("PROVIDER_PRIORITY", tt.priority)
+			clients := newOrderClients()
+			aggregator := newTestAggregator(t, clients...)
+			
+			ordered := aggregator.orderClients(clients)
+			if got := clientNames(ordered); !slices.Equal(got, tt.order) {
+				t.Errorf("order = %v, want %v", got, tt.order)
+			}
+			if got := aggregator.preferredSource(ordered); got != tt.preferred {
+				t.Errorf("preferredSource = %q, want %q", got, tt.preferred)
+			}
+			if got := clientNames(clients); !slices.Equal(got, []string{"a", "b", "c", "d"}) {
+				t.Errorf("clients reordered in place to %v", got)
+			}
+		})
+	}
+}
+
+func TestOrderClientsByLatency(t *testing.T) {
+	t.Setenv("PROVIDER_ORDER", "latency")
+	t.Setenv("PROVIDER_PRIORITY", "c")
+	clients := newOrderClients()
+	aggregator := newTestAggregator(t, clients...)
+	
+	// unmeasured, ranked by priority
+	ordered := aggregator.orderClients(clients)
+	if got := clientNames(ordered); !slices.Equal(got, []string{"c", "a", "b", "d"}) {
+		t.Errorf("unmeasured order = %v, want the priority order", got)
+	}
+	if got := aggregator.preferredSource(ordered); got != "" {
+		t.Errorf("unmeasured preferredSource = %q, want none", got)
+	}
+	
+	aggregator.recordSourceOutcome("b", true, 40*time.Millisecond)
+	aggregator.recordSourceOutcome("d", true, 10*time.Millisecond)
+	aggregator.recordSourceOutcome("a", false, time.Millisecond)
+	
+	ordered = aggregator.orderClients(clients)
+	if got := clientNames(ordered); !slices.Equal(got, []string{"d", "b", "c", "a"}) {
+		t.Errorf("order = %v, want d and b by latency, then the unmeasured by priority", got)
+	}
+	if got := aggregator.preferredSource(ordered); got != "d" {
+		t.Errorf("preferredSource = %q, want the fastest d", got)
+	}
+}
+
+func TestRecordLatencyDecays(t *testing.T) {
+	stats := newSourceStats()
+	stats.recordLatency(100 * time.Millisecond)
+	if stats.latency != 100*time.Millisecond {
+		t.Errorf("first latency = %v, want 100ms as measured", stats.latency)
+	}
+	
+	stats.recordLatency(200 * time.Millisecond)
+	if stats.latency != 130*time.Millisecond {
+		t.Errorf("decayed latency = %v, want 130ms", stats.latency)
+	}
+}
+
+func TestSoftDeadlineWaitsForPrioritizedProvider(t *testing.T) {
+	for _, tt := range []struct {
+		priority string
+		sources  []string
+		partial  bool
+	}{
+		{"", []string{"fast"}, true},
+		{"slow", []string{"fast", "slow"}, false},
+	} {
+		t.Run(tt.priority, func(t *testing.T) {
+			t.Setenv("PARTIAL_RESPONSE_TIMEOUT", "20ms")
+			t.Setenv("PROVIDER_PRIORITY", tt.priority)
+			slow := newFakeClient("slow", 30)
+			slow.delay = 200 * time.Millisecond
+			aggregator := newTestAggregator(t, newFakeClient("fast", 10), slow)
+			
+			weather, err := aggregator.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+			if err != nil {
+				t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+			}
+			sources := slices.Clone(weather.Sources)
+			slices.Sort(sources)
+			if !slices.Equal(sources, tt.sources) || weather.Partial != tt.partial {
+				t.Errorf("sources %v partial %v, want %v partial %v", weather.Sources, weather.Partial, tt.sources, tt.partial)
+			}
+		})
+	}
+}
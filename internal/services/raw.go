@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/pkg/client"
+)
+
+// rawFetchTimeout bounds the debugging fetch of raw provider responses
+const rawFetchTimeout = 10 * time.Second
+
+// GetRawResponses fetches a city from every enabled provider and returns the
+// responses exactly as received. Nothing is aggregated or cached.
+func (a *Aggregator) GetRawResponses(ctx context.Context, city string) (map[string]*models.RawProviderResponse, error) {
+	if a.maintenance.Load() {
+		return nil, ErrMaintenance
+	}
+	
+	clients := a.enabledClients()
+	if len(clients) == 0 {
+		return nil, ErrNoEnabledProvider
+	}
+	
+	fetchCtx, cancel := context.WithTimeout(ctx, rawFetchTimeout)
+	defer cancel()
+	
+	results := make(map[string]*models.RawProviderResponse, len(clients))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	
+	for _, c := range clients {
+		result := &models.RawProviderResponse{
+			Source:    c.Name(),
+			Responses: []models.RawResponse{},
+		}
+		results[c.Name()] = result
+		
+		wg.Add(1)
+		go func(c WeatherClient, result *models.RawProviderResponse) {
+			defer wg.Done()
+			
+			captureCtx := client.WithResponseCapture(fetchCtx, func(raw client.RawResponse) {
+				mu.Lock()
+				defer mu.Unlock()
+				result.Responses = append(result.Responses, models.RawResponse{
+					URL:    raw.URL,
+					Status: raw.Status,
+					Body:   rawBody(raw.Body),
+				})
+			})
+			
+			if _, _, err := a.getWeather(captureCtx, c, city, a.forecastDays, models.QueryOptions{}); err != nil {
+				mu.Lock()
+				result.Error = err.Error()
+				mu.Unlock()
+			}
+		}(c, result)
+	}
+	wg.Wait()
+	
+	return results, nil
+}
+
+// rawBody embeds JSON bodies as they are and anything else as a JSON string
+func rawBody(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
\ No newline at end of file
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// smoothForecastDays applies a centered moving average of window days to the
+// temperatures and precipitation. Days near the ends average over the
+// neighbours they have, and dates and all other fields are left as they are.
+func smoothForecastDays(days []models.ForecastDay, window int) []models.ForecastDay {
+	if window < 2 || len(days) < 2 {
+		return days
+	}
+	
+	radius := window / 2
+	smoothed := make([]models.ForecastDay, len(days))
+	
+	for i := range days {
+		start, end := i-radius, i+radius
+		if start < 0 {
+			start = 0
+		}
+		if end > len(days)-1 {
+			end = len(days) - 1
+		}
+		
+		var maxTemp, minTemp, avgTemp, precipitation, rain, snowfall, sumsPrecipitation float64
+		var sumsCount int
+		for j := start; j <= end; j++ {
+			maxTemp += days[j].MaxTemp
+			minTemp += days[j].MinTemp
+			avgTemp += days[j].AvgTemp
+			precipitation += days[j].Precipitation
+			if days[j].HasPrecipitationSums {
+				rain += days[j].RainSum
+				snowfall += days[j].SnowfallSum
+				sumsPrecipitation += days[j].Precipitation
+				sumsCount++
+			}
+		}
+		count := float64(end - start + 1)
+		
+		smoothed[i] = days[i]
+		smoothed[i].MaxTemp = maxTemp / count
+		smoothed[i].MinTemp = minTemp / count
+		smoothed[i].AvgTemp = avgTemp / count
+		smoothed[i].Precipitation = precipitation / count
+		if sumsCount > 0 {
+			// Kept to the same days as the sums, as in the aggregation
+			smoothed[i].Precipitation = sumsPrecipitation / float64(sumsCount)
+			smoothed[i].RainSum = rain / float64(sumsCount)
+			smoothed[i].SnowfallSum = snowfall / float64(sumsCount)
+			smoothed[i].HasPrecipitationSums = true
+		}
+	}
+	
+	return smoothed
+}
\ No newline at end of file
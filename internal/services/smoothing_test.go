@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestSmoothForecastDays(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	noisy := []float64{10, 20, 10, 20, 10}
+	days := make([]models.ForecastDay, len(noisy))
+	for i, temp := range noisy {
+		days[i] = models.ForecastDay{
+			Date:          start.AddDate(0, 0, i),
+			AvgTemp:       temp,
+			MaxTemp:       temp + 5,
+			MinTemp:       temp - 5,
+			Precipitation: temp / 10,
+			Humidity:      temp,
+		}
+	}
+	
+	smoothed := smoothForecastDays(days, 3)
+	
+	// the ends average over the two days they have
+	want := []float64{15, 40.0 / 3, 50.0 / 3, 40.0 / 3, 15}
+	for i, day := range smoothed {
+		if !approxEqual(day.AvgTemp, want[i], 1e-9) || !approxEqual(day.MaxTemp, want[i]+5, 1e-9) || !approxEqual(day.Precipitation, want[i]/10, 1e-9) {
+			t.Errorf("day %d = avg %.2f max %.2f precipitation %.2f, want %.2f, %.2f and %.2f",
+				i, day.AvgTemp, day.MaxTemp, day.Precipitation, want[i], want[i]+5, want[i]/10)
+		}
+		if !day.Date.Equal(days[i].Date) || day.Humidity != noisy[i] {
+			t.Errorf("day %d = %s with humidity %v, want the raw date and humidity", i, day.Date.Format(time.DateOnly), day.Humidity)
+		}
+	}
+	if days[0].AvgTemp != 10 {
+		t.Errorf("raw first day = %v after smoothing, want it unchanged", days[0].AvgTemp)
+	}
+	
+	if raw := smoothForecastDays(days, 1); raw[1].AvgTemp != 20 {
+		t.Errorf("window 1 = %v, want the raw series", raw[1].AvgTemp)
+	}
+}
+
+func TestSmoothedPrecipitationCoversRainAndSnowfallSums(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	days := []models.ForecastDay{
+		{Date: start, Precipitation: 1},
+		{Date: start.AddDate(0, 0, 1), Precipitation: 12, RainSum: 8, SnowfallSum: 4, HasPrecipitationSums: true},
+		{Date: start.AddDate(0, 0, 2), Precipitation: 0},
+	}
+	
+	for i, day := range smoothForecastDays(days, 3) {
+		if day.Precipitation < day.RainSum+day.SnowfallSum {
+			t.Errorf("day %d precipitation = %v, want at least rain %v + snowfall %v", i, day.Precipitation, day.RainSum, day.SnowfallSum)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"time"
+)
+
+// recentWindow is the number of most recent fetch outcomes the success rate is computed over
+const recentWindow = 50
+
+const (
+	// deviationDecay is the weight of the newest reading in the decayed
+	// temperature deviation, older readings fade by (1 - deviationDecay) per fetch
+	deviationDecay = 0.3
+	
+	// deviationScale is the deviation in °C at which a source's weight halves
+	deviationScale = 2.0
+	
+	// latencyDecay is the weight of the newest successful fetch in the decayed latency
+	latencyDecay = 0.3
+)
+
+type sourceStats struct {
+	successes   int
+	failures    int
+	lastSuccess time.Time
+	lastFailure time.Time
+	recent      []bool // ring buffer of recent outcomes
+	next        int
+	deviation   float64 // exponentially decayed temperature deviation from the other sources
+	latency     time.Duration // exponentially decayed duration of successful fetches, 0 until the first
+}
+
+func newSourceStats() *sourceStats {
+	return &sourceStats{
+		recent: make([]bool, 0, recentWindow),
+	}
+}
+
+func (s *sourceStats) record(success bool) {
+	if success {
+		s.successes++
+		s.lastSuccess = time.Now()
+	} else {
+		s.failures++
+		s.lastFailure = time.Now()
+	}
+	
+	if len(s.recent) < recentWindow {
+		s.recent = append(s.recent, success)
+		return
+	}
+	s.recent[s.next] = success
+	s.next = (s.next + 1) % recentWindow
+}
+
+// successRate returns the share of successful fetches in the recent window, or
+// 1 if the source hasn't been used yet
+func (s *sourceStats) successRate() float64 {
+	if len(s.recent) == 0 {
+		return 1
+	}
+	
+	successes := 0
+	for _, success := range s.recent {
+		if success {
+			successes++
+		}
+	}
+	return float64(successes) / float64(len(s.recent))
+}
+
+// recordDeviation folds the latest distance from the other sources' temperature
+// into the decayed deviation, so a source recovers once it agrees again
+func (s *sourceStats) recordDeviation(deviation float64) {
+	s.deviation = deviationDecay*deviation + (1-deviationDecay)*s.deviation
+}
+
+// recordLatency folds the duration of a successful fetch into the decayed latency
+func (s *sourceStats) recordLatency(latency time.Duration) {
+	if s.latency == 0 {
+		s.latency = latency
+		return
+	}
+	s.latency = time.Duration(latencyDecay*float64(latency) + (1-latencyDecay)*float64(s.latency))
+}
+
+// adaptiveWeight combines reliability and agreement into a weight in (0, 1]
+func (s *sourceStats) adaptiveWeight() float64 {
+	return s.successRate() / (1 + s.deviation/deviationScale)
+}
+
+func (s *sourceStats) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"successes":    s.successes,
+		"failures":     s.failures,
+		"success_rate": s.successRate(),
+		"last_success": s.lastSuccess,
+		"last_failure": s.lastFailure,
+		"temperature_deviation": s.deviation,
+		"adaptive_weight": s.adaptiveWeight(),
+		"latency_ms":   float64(s.latency) / float64(time.Millisecond),
+	}
+}
\ No newline at end of file
@@ -0,0 +1,46 @@
+package services
+
+import "testing"
+
+func TestAdaptiveWeightShrinksAndRecovers(t *testing.T) {
+	stats := newSourceStats()
+	if weight := stats.adaptiveWeight(); weight != 1 {
+		t.Fatalf("unused source weight = %v, want 1", weight)
+	}
+	
+	for i := 0; i < 5; i++ {
+		stats.record(true)
+	}
+	for i := 0; i < 5; i++ {
+		stats.record(false)
+	}
+	if weight := stats.adaptiveWeight(); weight != 0.5 {
+		t.Errorf("weight after half the fetches failed = %v, want 0.5", weight)
+	}
+	
+	// failures age out of the recent window
+	for i := 0; i < recentWindow; i++ {
+		stats.record(true)
+	}
+	if weight := stats.adaptiveWeight(); weight != 1 {
+		t.Errorf("weight after a window of successes = %v, want 1", weight)
+	}
+	
+	var weights []float64
+	for i := 0; i < 5; i++ {
+		stats.recordDeviation(8)
+		weights = append(weights, stats.adaptiveWeight())
+	}
+	for i := 1; i < len(weights); i++ {
+		if weights[i] >= weights[i-1] {
+			t.Fatalf("weights while disagreeing = %v, want them to keep shrinking", weights)
+		}
+	}
+	
+	for i := 0; i < 30; i++ {
+		stats.recordDeviation(0)
+	}
+	if weight := stats.adaptiveWeight(); weight < 0.99 {
+		t.Errorf("weight after agreeing again = %v, want it recovered to about 1", weight)
+	}
+}
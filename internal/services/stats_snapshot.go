@@ -0,0 +1,163 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// statsSnapshot is the on-disk form of the fetch counters, so reliability
+// tracking survives restarts
+type statsSnapshot struct {
+	SavedAt      time.Time                      `json:"saved_at"`
+	SuccessCount int                            `json:"success_count"`
+	FailureCount int                            `json:"failure_count"`
+	Sources      map[string]sourceStatsSnapshot `json:"sources"`
+}
+
+type sourceStatsSnapshot struct {
+	Successes   int       `json:"successes"`
+	Failures    int       `json:"failures"`
+	LastSuccess time.Time `json:"last_success"`
+	LastFailure time.Time `json:"last_failure"`
+	Recent      []bool    `json:"recent"` // oldest first
+	Deviation   float64   `json:"deviation"`
+	Latency     time.Duration `json:"latency"`
+}
+
+func (s *sourceStats) snapshot() sourceStatsSnapshot {
+	// Unroll the ring buffer so the restored one can start writing at the front
+	recent := make([]bool, 0, len(s.recent))
+	recent = append(recent, s.recent[s.next:]...)
+	recent = append(recent, s.recent[:s.next]...)
+	
+	return sourceStatsSnapshot{
+		Successes:   s.successes,
+		Failures:    s.failures,
+		LastSuccess: s.lastSuccess,
+		LastFailure: s.lastFailure,
+		Recent:      recent,
+		Deviation:   s.deviation,
+		Latency:     s.latency,
+	}
+}
+
+func restoreSourceStats(snapshot sourceStatsSnapshot) *sourceStats {
+	stats := newSourceStats()
+	stats.successes = snapshot.Successes
+	stats.failures = snapshot.Failures
+	stats.lastSuccess = snapshot.LastSuccess
+	stats.lastFailure = snapshot.LastFailure
+	stats.deviation = snapshot.Deviation
+	stats.latency = snapshot.Latency
+	
+	// Keep only the newest outcomes should the window have shrunk
+	recent := snapshot.Recent
+	if len(recent) > recentWindow {
+		recent = recent[len(recent)-recentWindow:]
+	}
+	stats.recent = append(stats.recent, recent...)
+	return stats
+}
+
+func (a *Aggregator) statsSnapshot() statsSnapshot {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	
+	snapshot := statsSnapshot{
+		SavedAt:      time.Now(),
+		SuccessCount: a.successCount,
+		FailureCount: a.failureCount,
+		Sources:      make(map[string]sourceStatsSnapshot, len(a.sourceStats)),
+	}
+	for source, stats := range a.sourceStats {
+		snapshot.Sources[source] = stats.snapshot()
+	}
+	return snapshot
+}
+
+// SaveStats writes the fetch counters to the stats file. The file is replaced
+// atomically so a crash mid-write leaves the previous snapshot intact.
+func (a *Aggregator) SaveStats() error {
+	if a.statsPath == "" {
+		return nil
+	}
+	
+	data, err := json.Marshal(a.statsSnapshot())
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+	
+	tmp, err := os.CreateTemp(filepath.Dir(a.statsPath), filepath.Base(a.statsPath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create stats file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), a.statsPath); err != nil {
+		return fmt.Errorf("failed to replace stats file: %w", err)
+	}
+	return nil
+}
+
+// loadStats restores the counters saved by a previous run. A missing file is
+// a first start and not an error.
+func (a *Aggregator) loadStats() error {
+	data, err := os.ReadFile(a.statsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stats file: %w", err)
+	}
+	
+	var snapshot statsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse stats file: %w", err)
+	}
+	
+	a.mu.Lock()
+	a.successCount = snapshot.SuccessCount
+	a.failureCount = snapshot.FailureCount
+	for source, stats := range snapshot.Sources {
+		a.sourceStats[source] = restoreSourceStats(stats)
+	}
+	a.mu.Unlock()
+	
+	a.logger.Info("Restored fetch stats",
+		zap.String("path", a.statsPath),
+		zap.Time("saved_at", snapshot.SavedAt),
+		zap.Int("sources", len(snapshot.Sources)))
+	return nil
+}
+
+// persistStats saves the stats every interval until Close
+func (a *Aggregator) persistStats(interval time.Duration) {
+	defer close(a.statsDone)
+	
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.SaveStats(); err != nil {
+				a.logger.Warn("Failed to save fetch stats", zap.Error(err))
+			}
+		case <-a.stopStats:
+			return
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestSourceStatsSnapshotRoundTrip(t *testing.T) {
+	stats := newSourceStats()
+	// Past the window, so the ring buffer has wrapped
+	for i := 0; i < recentWindow+10; i++ {
+		stats.record(i%3 != 0)
+	}
+	stats.recordDeviation(1.5)
+	stats.recordLatency(250 * time.Millisecond)
+	
+	saved, err := json.Marshal(stats.snapshot())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var loaded sourceStatsSnapshot
+	if err := json.Unmarshal(saved, &loaded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	restored := restoreSourceStats(loaded)
+	
+	again, _ := json.Marshal(restored.snapshot())
+	if string(again) != string(saved) {
+		t.Errorf("restored snapshot = %s, want %s", again, saved)
+	}
+	if restored.successRate() != stats.successRate() || restored.adaptiveWeight() != stats.adaptiveWeight() {
+		t.Errorf("restored rate %v weight %v, want %v and %v", restored.successRate(), restored.adaptiveWeight(), stats.successRate(), stats.adaptiveWeight())
+	}
+	
+	// The next outcome replaces the oldest one in both
+	stats.record(false)
+	restored.record(false)
+	if restored.successRate() != stats.successRate() {
+		t.Errorf("rate after another failure = %v, want %v", restored.successRate(), stats.successRate())
+	}
+}
+
+func TestRestoreSourceStatsKeepsNewestOutcomes(t *testing.T) {
+	recent := make([]bool, recentWindow+5)
+	for i := recentWindow; i < len(recent); i++ {
+		recent[i] = true
+	}
+	
+	restored := restoreSourceStats(sourceStatsSnapshot{Recent: recent})
+	if len(restored.recent) != recentWindow {
+		t.Fatalf("kept %d outcomes, want %d", len(restored.recent), recentWindow)
+	}
+	if want := 5.0 / recentWindow; restored.successRate() != want {
+		t.Errorf("successRate = %v, want %v from the newest outcomes", restored.successRate(), want)
+	}
+}
+
+func TestStatsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	t.Setenv("STATS_FILE", path)
+	
+	first := newTestAggregator(t, newFakeClient("fake", 20))
+	if _, err := first.GetAggregatedCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err != nil {
+		t.Fatalf("GetAggregatedCurrentWeather: %v", err)
+	}
+	if err := first.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("stats file not written on Close: %v", err)
+	}
+	
+	saved := first.GetStats()
+	restored := newTestAggregator(t, newFakeClient("fake", 20)).GetStats()
+	if restored["success_count"] != saved["success_count"] || restored["failure_count"] != saved["failure_count"] {
+		t.Errorf("restored %v successes %v failures, want %v and %v", restored["success_count"], restored["failure_count"], saved["success_count"], saved["failure_count"])
+	}
+	if sources, _ := restored["source_stats"].(map[string]interface{}); sources["fake"] == nil {
+		t.Errorf("source_stats = %v, want the fake source restored", restored["source_stats"])
+	}
+}
+
+func TestCorruptStatsFileIsAnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	
+	aggregator := newTestAggregator(t, newFakeClient("fake", 20))
+	aggregator.statsPath = path
+	if err := aggregator.loadStats(); err == nil {
+		t.Error("loadStats of a corrupt file succeeded, want an error")
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+)
+
+// summaryPhrases holds the templates and wording of one language
+type summaryPhrases struct {
+	current  string // description, temperature, temperature unit, wind
+	tomorrow string // description, min, max, temperature unit, precipitation
+	wind     [5]string
+	dry      string
+	precip   string // amount, precipitation unit
+}
+
+var summaryLanguages = map[string]summaryPhrases{
+	"en": {
+		current:  "%s, %.0f%s, %s",
+		tomorrow: "tomorrow %s, %.0f to %.0f%s, %s",
+		wind:     [5]string{"calm", "light breeze", "moderate breeze", "strong wind", "gale-force wind"},
+		dry:      "no precipitation expected",
+		precip:   "%s %s of precipitation",
+	},
+	"de": {
+		current:  "%s, %.0f%s, %s",
+		tomorrow: "morgen %s, %.0f bis %.0f%s, %s",
+		wind:     [5]string{"windstill", "leichte Brise", "mäßiger Wind", "starker Wind", "Sturm"},
+		dry:      "kein Niederschlag erwartet",
+		precip:   "%s %s Niederschlag",
+	},
+	"fr": {
+		current:  "%s, %.0f%s, %s",
+		tomorrow: "demain %s, %.0f à %.0f%s, %s",
+		wind:     [5]string{"vent calme", "brise légère", "vent modéré", "vent fort", "tempête"},
+		dry:      "pas de précipitations prévues",
+		precip:   "%s %s de précipitations",
+	},
+	"es": {
+		current:  "%s, %.0f%s, %s",
+		tomorrow: "mañana %s, %.0f a %.0f%s, %s",
+		wind:     [5]string{"calma", "brisa ligera", "viento moderado", "viento fuerte", "temporal"},
+		dry:      "sin precipitaciones previstas",
+		precip:   "%s %s de precipitación",
+	},
+}
+
+// GetSummary describes the current weather and tomorrow's forecast in a
+// sentence, in the units requested by opts. A failing forecast only drops the
+// part about tomorrow.
+func (a *Aggregator) GetSummary(ctx context.Context, city string, opts models.QueryOptions) (*models.WeatherSummary, error) {
+	// Wind and precipitation are classified in metric, converted for display
+	metricOpts := opts
+	metricOpts.Units = ""
+	metricOpts.PrecipUnit = ""
+	
+	current, err := a.GetAggregatedCurrentWeather(ctx, city, metricOpts)
+	if err != nil {
+		return nil, err
+	}
+	
+	var tomorrow *models.ForecastDay
+	if forecast, err := a.GetAggregatedForecast(ctx, city, 2, metricOpts); err == nil && len(forecast.Days) > 1 {
+		tomorrow = &forecast.Days[1]
+	}
+	
+	lang := opts.LangOrDefault()
+	if _, ok := summaryLanguages[lang]; !ok {
+		lang = models.DefaultLang
+	}
+	
+	return &models.WeatherSummary{
+		City:        current.City,
+		Lang:        lang,
+		Summary:     buildSummary(current, tomorrow, lang, opts),
+		LastUpdated: current.LastUpdated,
+	}, nil
+}
+
+// buildSummary phrases the metric current weather and forecast day in the
+// units requested by opts, temperatures in whole degrees rounded like
+// OUTPUT_DECIMALS rounds the other responses
+func buildSummary(current *models.AggregatedCurrentWeather, tomorrow *models.ForecastDay, lang string, opts models.QueryOptions) string {
+	phrases := summaryLanguages[lang]
+	units := opts.UnitsOrDefault()
+	precipUnit := opts.PrecipitationUnitOrDefault()
+	
+	summary := fmt.Sprintf(phrases.current,
+		capitalize(current.Description),
+		utils.RoundHalfUp(convertTemperature(current.Temperature, units), 0),
+		units.TemperatureLabel(),
+		phrases.wind[windClass(current.WindSpeed)])
+	
+	if tomorrow != nil {
+		precipitation := phrases.dry
+		if tomorrow.Precipitation >= 0.1 {
+			precipitation = fmt.Sprintf(phrases.precip,
+				formatPrecipitation(convertPrecipitation(tomorrow.Precipitation, precipUnit), precipUnit),
+				precipUnit.Label())
+		}
+		
+		summary += "; " + fmt.Sprintf(phrases.tomorrow,
+			strings.ToLower(tomorrow.Description),
+			utils.RoundHalfUp(convertTemperature(tomorrow.MinTemp, units), 0),
+			utils.RoundHalfUp(convertTemperature(tomorrow.MaxTemp, units), 0),
+			units.TemperatureLabel(),
+			precipitation)
+	}
+	
+	return summary + "."
+}
+
+// formatPrecipitation keeps a tenth of a millimeter or a hundredth of an inch
+func formatPrecipitation(amount float64, unit models.PrecipitationUnit) string {
+	if unit == models.PrecipitationInches {
+		return strconv.FormatFloat(utils.RoundHalfUp(amount, 2), 'f', 2, 64)
+	}
+	return strconv.FormatFloat(utils.RoundHalfUp(amount, 1), 'f', 1, 64)
+}
+
+// windClass buckets a speed in m/s into calm, light, moderate, strong and gale
+func windClass(speed float64) int {
+	switch {
+	case speed < 0.5:
+		return 0
+	case speed < 3.4:
+		return 1
+	case speed < 8:
+		return 2
+	case speed < 17.2:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func capitalize(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	if first == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToUpper(first)) + text[size:]
+}
\ No newline at end of file
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestBuildSummary(t *testing.T) {
+	current := &models.AggregatedCurrentWeather{Temperature: 18.4, WindSpeed: 2.5, Description: "partly cloudy"}
+	rainy := &models.ForecastDay{MinTemp: 11.6, MaxTemp: 19.5, Precipitation: 2.4, Description: "Light rain"}
+	
+	tests := []struct {
+		name     string
+		current  *models.AggregatedCurrentWeather
+		tomorrow *models.ForecastDay
+		lang     string
+		opts     models.QueryOptions
+		want     string
+	}{
+		{
+			name: "metric", current: current, tomorrow: rainy, lang: "en",
+			want: "Partly cloudy, 18°C, light breeze; tomorrow light rain, 12 to 20°C, 2.4 mm of precipitation.",
+		},
+		{
+			name: "imperial", current: current, tomorrow: rainy, lang: "en",
+			opts: models.QueryOptions{Units: models.UnitsImperial},
+			want: "Partly cloudy, 65°F, light breeze; tomorrow light rain, 53 to 67°F, 0.09 in of precipitation.",
+		},
+		{
+			name: "without forecast", current: current, lang: "en",
+			want: "Partly cloudy, 18°C, light breeze.",
+		},
+		{
+			name:     "german dry day",
+			current:  &models.AggregatedCurrentWeather{Temperature: 3, WindSpeed: 12, Description: "bedeckt"},
+			tomorrow: &models.ForecastDay{MinTemp: -2, MaxTemp: 4, Description: "Sonnig"},
+			lang:     "de",
+			want:     "Bedeckt, 3°C, starker Wind; morgen sonnig, -2 bis 4°C, kein Niederschlag erwartet.",
+		},
+	}
+	for _, tt := range tests {
+		if got := buildSummary(tt.current, tt.tomorrow, tt.lang, tt.opts); got != tt.want {
+			t.Errorf("%s: buildSummary = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWindClass(t *testing.T) {
+	for speed, want := range map[float64]int{0: 0, 0.5: 1, 3.4: 2, 8: 3, 17.2: 4, 30: 4} {
+		if got := windClass(speed); got != want {
+			t.Errorf("windClass(%v) = %d, want %d", speed, got, want)
+		}
+	}
+}
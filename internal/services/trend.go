@@ -0,0 +1,122 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// maxTrendReadings caps the readings kept per city should fetches be far more
+// frequent than the window expects
+const maxTrendReadings = 256
+
+type trendReading struct {
+	at          time.Time
+	temperature float64
+}
+
+// trendTracker keeps the recent aggregated temperatures of each data key in
+// memory and derives the short-term temperature trend from them
+type trendTracker struct {
+	mu        sync.Mutex
+	window    time.Duration
+	threshold float64 // °C per hour below which the temperature counts as steady
+	readings  map[string][]trendReading
+}
+
+func newTrendTracker(window time.Duration, threshold float64) *trendTracker {
+	return &trendTracker{
+		window:    window,
+		threshold: threshold,
+		readings:  make(map[string][]trendReading),
+	}
+}
+
+// record adds a reading for key and returns the trend over the window, nil
+// until there are readings far enough apart to tell
+func (t *trendTracker) record(key string, at time.Time, temperature float64) *models.TemperatureTrend {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	readings := t.readings[key]
+	
+	// Re-aggregating the same observation adds nothing new
+	if n := len(readings); n == 0 || at.After(readings[n-1].at) {
+		readings = append(readings, trendReading{at: at, temperature: temperature})
+	}
+	
+	cutoff := at.Add(-t.window)
+	start := 0
+	for start < len(readings) && readings[start].at.Before(cutoff) {
+		start++
+	}
+	if len(readings)-start > maxTrendReadings {
+		start = len(readings) - maxTrendReadings
+	}
+	readings = append([]trendReading(nil), readings[start:]...)
+	t.readings[key] = readings
+	
+	return classifyTrend(readings, t.threshold)
+}
+
+// trend returns the trend over the readings recorded for key, without adding
+// one
+func (t *trendTracker) trend(key string) *models.TemperatureTrend {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	return classifyTrend(t.readings[key], t.threshold)
+}
+
+// forget drops the readings of every key belonging to city
+func (t *trendTracker) forget(city string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	for key := range t.readings {
+		if matchesCity(key, city) {
+			delete(t.readings, key)
+		}
+	}
+}
+
+// classifyTrend fits a least-squares line through the readings and classifies
+// its slope, in °C per hour
+func classifyTrend(readings []trendReading, threshold float64) *models.TemperatureTrend {
+	if len(readings) < 2 || !readings[len(readings)-1].at.After(readings[0].at) {
+		return nil
+	}
+	
+	origin := readings[0].at
+	var sumX, sumY, sumXY, sumXX float64
+	for _, reading := range readings {
+		x := reading.at.Sub(origin).Hours()
+		sumX += x
+		sumY += reading.temperature
+		sumXY += x * reading.temperature
+		sumXX += x * x
+	}
+	
+	n := float64(len(readings))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return nil
+	}
+	rate := (n*sumXY - sumX*sumY) / denominator
+	
+	direction := models.TrendSteady
+	switch {
+	case math.Abs(rate) < threshold:
+	case rate > 0:
+		direction = models.TrendRising
+	default:
+		direction = models.TrendFalling
+	}
+	
+	return &models.TemperatureTrend{
+		Direction:   direction,
+		RatePerHour: rate,
+	}
+}
\ No newline at end of file
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestTrendClassification(t *testing.T) {
+	start := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+	
+	tests := []struct {
+		name         string
+		temperatures []float64 // one every 30 minutes
+		want         models.TrendDirection
+		rate         float64
+	}{
+		{"rising", []float64{10, 11, 12, 13}, models.TrendRising, 2},
+		{"falling", []float64{15, 14.5, 14, 13.5}, models.TrendFalling, -1},
+		{"steady", []float64{12, 12.1, 11.9, 12.1}, models.TrendSteady, 0.02},
+		{"noisy rise", []float64{10, 12, 11, 13}, models.TrendRising, 1.6},
+	}
+	for _, tt := range tests {
+		tracker := newTrendTracker(3*time.Hour, 0.5)
+		var trend *models.TemperatureTrend
+		for i, temperature := range tt.temperatures {
+			trend = tracker.record("prague", start.Add(time.Duration(i)*30*time.Minute), temperature)
+		}
+		if trend == nil {
+			t.Errorf("%s: trend = nil, want %s", tt.name, tt.want)
+			continue
+		}
+		if trend.Direction != tt.want || math.Abs(trend.RatePerHour-tt.rate) > 1e-9 {
+			t.Errorf("%s: trend = %s at %v/h, want %s at %v/h", tt.name, trend.Direction, trend.RatePerHour, tt.want, tt.rate)
+		}
+	}
+}
+
+func TestTrendNeedsReadingsApart(t *testing.T) {
+	tracker := newTrendTracker(3*time.Hour, 0.5)
+	at := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+	
+	if trend := tracker.record("prague", at, 10); trend != nil {
+		t.Errorf("trend of one reading = %+v, want nil", trend)
+	}
+	if trend := tracker.record("prague", at, 14); trend != nil {
+		t.Errorf("trend of the same observation again = %+v, want nil", trend)
+	}
+	if trend := tracker.trend("london"); trend != nil {
+		t.Errorf("trend of an unknown key = %+v, want nil", trend)
+	}
+}
+
+func TestTrendWindowDropsOldReadings(t *testing.T) {
+	tracker := newTrendTracker(time.Hour, 0.5)
+	start := time.Date(2026, 10, 15, 6, 0, 0, 0, time.UTC)
+	
+	// A fall long ago, then steady within the window
+	tracker.record("prague", start, 20)
+	tracker.record("prague", start.Add(2*time.Hour), 12)
+	trend := tracker.record("prague", start.Add(2*time.Hour+30*time.Minute), 12)
+	if trend == nil || trend.Direction != models.TrendSteady {
+		t.Errorf("trend = %+v, want steady once the old reading left the window", trend)
+	}
+	
+	tracker.forget("Prague")
+	if trend := tracker.trend("prague"); trend != nil {
+		t.Errorf("trend after forget = %+v, want nil", trend)
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// Providers report pressure in hectopascals.
+// 1 inHg = 33.8639 hPa, so 1 hPa = 0.0295300 inHg
+// 1 mmHg = 1.333224 hPa, so 1 hPa = 0.7500617 mmHg
+const (
+	hPaToInHg = 0.0295300
+	hPaToMmHg = 0.7500617
+)
+
+// Precipitation is reported in millimeters and wind in meters per second.
+// 1 in = 25.4 mm, 1 mph = 0.44704 m/s
+const (
+	mmPerInch = 25.4
+	msPerMph  = 0.44704
+)
+
+// kelvinOffset converts °C to K
+const kelvinOffset = 273.15
+
+func convertPressure(hPa float64, unit models.PressureUnit) float64 {
+	switch unit {
+	case models.PressureInHg:
+		return hPa * hPaToInHg
+	case models.PressureMmHg:
+		return hPa * hPaToMmHg
+	default:
+		return hPa
+	}
+}
+
+func convertPrecipitation(mm float64, unit models.PrecipitationUnit) float64 {
+	if unit == models.PrecipitationInches {
+		return mm / mmPerInch
+	}
+	return mm
+}
+
+func convertTemperature(celsius float64, units models.UnitSystem) float64 {
+	switch units {
+	case models.UnitsImperial:
+		return celsius*9/5 + 32
+	case models.UnitsStandard:
+		return celsius + kelvinOffset
+	default:
+		return celsius
+	}
+}
+
+func convertWindSpeed(ms float64, units models.UnitSystem) float64 {
+	if units == models.UnitsImperial {
+		return ms / msPerMph
+	}
+	return ms
+}
+
+// convertCurrentWeather returns a copy of the canonical (metric, hPa) aggregate
+// expressed in the units requested by opts
+func convertCurrentWeather(weather *models.AggregatedCurrentWeather, opts models.QueryOptions) *models.AggregatedCurrentWeather {
+	units := opts.UnitsOrDefault()
+	
+	converted := *weather
+	converted.Temperature = convertTemperature(weather.Temperature, units)
+	converted.FeelsLike = convertTemperature(weather.FeelsLike, units)
+	if weather.HeatIndex != nil {
+		heatIndex := convertTemperature(*weather.HeatIndex, units)
+		converted.HeatIndex = &heatIndex
+	}
+	if weather.WindChill != nil {
+		windChill := convertTemperature(*weather.WindChill, units)
+		converted.WindChill = &windChill
+	}
+	if weather.TemperatureTrend != nil && units == models.UnitsImperial {
+		trend := *weather.TemperatureTrend
+		trend.RatePerHour = weather.TemperatureTrend.RatePerHour * 9 / 5
+		converted.TemperatureTrend = &trend
+	}
+	converted.WindSpeed = convertWindSpeed(weather.WindSpeed, units)
+	converted.WindGust = convertWindSpeed(weather.WindGust, units)
+	converted.Pressure = convertPressure(weather.Pressure, opts.PressureUnitOrDefault())
+	converted.Units = units
+	converted.UnitLabels = currentUnitLabels(units, opts.PressureUnitOrDefault())
+	return &converted
+}
+
+// convertForecast returns a copy of the canonical forecast in the units requested by opts
+func convertForecast(forecast *models.AggregatedForecast, opts models.QueryOptions) *models.AggregatedForecast {
+	units := opts.UnitsOrDefault()
+	precipUnit := opts.PrecipitationUnitOrDefault()
+	
+	converted := *forecast
+	converted.Days = make([]models.ForecastDay, len(forecast.Days))
+	for i, day := range forecast.Days {
+		day.MaxTemp = convertTemperature(day.MaxTemp, units)
+		day.MinTemp = convertTemperature(day.MinTemp, units)
+		day.AvgTemp = convertTemperature(day.AvgTemp, units)
+		day.WindGust = convertWindSpeed(day.WindGust, units)
+		day.Precipitation = convertPrecipitation(day.Precipitation, precipUnit)
+		day.RainSum = convertPrecipitation(day.RainSum, precipUnit)
+		day.SnowfallSum = convertPrecipitation(day.SnowfallSum, precipUnit)
+		converted.Days[i] = day
+	}
+	converted.Units = units
+	converted.UnitLabels = forecastUnitLabels(units, precipUnit)
+	return &converted
+}
+
+// convertPointForecast returns a copy of the canonical point forecast in the
+// units requested by opts
+func convertPointForecast(point *models.PointForecast, opts models.QueryOptions) *models.PointForecast {
+	units := opts.UnitsOrDefault()
+	
+	converted := *point
+	converted.Temperature = convertTemperature(point.Temperature, units)
+	converted.WindSpeed = convertWindSpeed(point.WindSpeed, units)
+	converted.Units = units
+	converted.UnitLabels = map[string]string{
+		"temperature":               units.TemperatureLabel(),
+		"wind_speed":                units.WindSpeedLabel(),
+		"humidity":                  "%",
+		"precipitation_probability": "%",
+	}
+	return &converted
+}
+
+// ConvertDailySummary returns summary in the units opts asks for, summaries
+// are stored in metric
+func ConvertDailySummary(summary *models.DailySummary, opts models.QueryOptions) *models.DailySummary {
+	units := opts.UnitsOrDefault()
+	
+	converted := *summary
+	converted.High = convertTemperature(summary.High, units)
+	converted.Low = convertTemperature(summary.Low, units)
+	converted.Units = units
+	converted.UnitLabels = map[string]string{
+		"temperature": units.TemperatureLabel(),
+	}
+	return &converted
+}
+
+// currentUnitLabels names the unit of each measurement in current weather
+func currentUnitLabels(units models.UnitSystem, pressure models.PressureUnit) map[string]string {
+	return map[string]string{
+		"temperature": units.TemperatureLabel(),
+		"wind_speed":  units.WindSpeedLabel(),
+		"pressure":    pressure.Label(),
+		"humidity":    "%",
+		"cloud_cover": "%",
+		"visibility":  "m",
+	}
+}
+
+// forecastUnitLabels names the unit of each measurement in a forecast
+func forecastUnitLabels(units models.UnitSystem, precipitation models.PrecipitationUnit) map[string]string {
+	return map[string]string{
+		"temperature":   units.TemperatureLabel(),
+		"wind_speed":    units.WindSpeedLabel(),
+		"precipitation": precipitation.Label(),
+		"humidity":      "%",
+	}
+}
\ No newline at end of file
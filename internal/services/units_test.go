@@ -0,0 +1,70 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// approxEqual reports whether a and b differ by less than tolerance
+func approxEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) < tolerance
+}
+
+func TestConvertPressure(t *testing.T) {
+	tests := []struct {
+		unit models.PressureUnit
+		want float64
+	}{
+		{models.PressureHPa, 1013.25},
+		{"", 1013.25},
+		{models.PressureInHg, 29.92},
+		{models.PressureMmHg, 760},
+	}
+	for _, tt := range tests {
+		if got := convertPressure(1013.25, tt.unit); !approxEqual(got, tt.want, 0.01) {
+			t.Errorf("convertPressure(1013.25, %q) = %v, want %v", tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestConvertCurrentWeatherPressureUnit(t *testing.T) {
+	weather := &models.AggregatedCurrentWeather{Pressure: 1013.25}
+	
+	converted := convertCurrentWeather(weather, models.QueryOptions{PressureUnit: models.PressureMmHg})
+	if !approxEqual(converted.Pressure, 760, 0.01) || converted.UnitLabels["pressure"] != models.PressureMmHg.Label() {
+		t.Errorf("pressure = %v %s, want 760 %s", converted.Pressure, converted.UnitLabels["pressure"], models.PressureMmHg.Label())
+	}
+	if weather.Pressure != 1013.25 {
+		t.Errorf("canonical pressure changed to %v", weather.Pressure)
+	}
+}
+
+func TestConvertForecastPrecipitation(t *testing.T) {
+	forecast := &models.AggregatedForecast{Days: []models.ForecastDay{{Precipitation: 25.4, RainSum: 12.7, SnowfallSum: 12.7}}}
+	
+	tests := []struct {
+		name  string
+		opts  models.QueryOptions
+		want  float64 // of Precipitation, the sums are half of it
+		label string
+	}{
+		{"metric", models.QueryOptions{}, 25.4, "mm"},
+		{"imperial", models.QueryOptions{Units: models.UnitsImperial}, 1, "in"},
+		{"override", models.QueryOptions{PrecipUnit: models.PrecipitationInches}, 1, "in"},
+		{"imperial in mm", models.QueryOptions{Units: models.UnitsImperial, PrecipUnit: models.PrecipitationMM}, 25.4, "mm"},
+	}
+	for _, tt := range tests {
+		day := convertForecast(forecast, tt.opts).Days[0]
+		if !approxEqual(day.Precipitation, tt.want, 1e-9) || !approxEqual(day.RainSum, tt.want/2, 1e-9) || !approxEqual(day.SnowfallSum, tt.want/2, 1e-9) {
+			t.Errorf("%s: precipitation %v rain %v snow %v, want %v, %v and %v", tt.name, day.Precipitation, day.RainSum, day.SnowfallSum, tt.want, tt.want/2, tt.want/2)
+		}
+		if label := convertForecast(forecast, tt.opts).UnitLabels["precipitation"]; label != tt.label {
+			t.Errorf("%s: precipitation label = %q, want %q", tt.name, label, tt.label)
+		}
+	}
+	if forecast.Days[0].Precipitation != 25.4 {
+		t.Errorf("canonical precipitation changed to %v", forecast.Days[0].Precipitation)
+	}
+}
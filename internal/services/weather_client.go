@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+type WeatherClient interface {
+	GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error)
+	GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error)
+	
+	// Name is the source name used in aggregated responses
+	Name() string
+	RequiresAPIKey() bool
+	BreakerState() string
+	BreakerRetryAfter() time.Duration
+	
+	// Usage returns the upstream calls made in the current UTC hour and day
+	Usage() (hour, day models.UsageWindow)
+}
+
+// CombinedWeatherClient is implemented by clients that can fetch current
+// conditions and the forecast in one request
+type CombinedWeatherClient interface {
+	GetWeather(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.CurrentWeather, *models.WeatherForecast, error)
+}
+
+// NativeUnitsClient is implemented by clients whose provider reports some
+// measurements in other units than the normalized ones
+type NativeUnitsClient interface {
+	NativeUnits() map[string]models.NativeUnit // measurement -> unit, keyed like unit_labels
+}
+
+// KeyRotatingClient is implemented by clients that rotate several API keys
+type KeyRotatingClient interface {
+	KeyUsage() []models.APIKeyUsage
+}
+
+// HistoricalWeatherClient is implemented by clients that can report the
+// observed weather of past days
+type HistoricalWeatherClient interface {
+	GetHistoricalWeather(ctx context.Context, city string, date time.Time, opts models.QueryOptions) (*models.ForecastDay, error)
+}
\ No newline at end of file
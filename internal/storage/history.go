@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS current_snapshots (
+	city         TEXT    NOT NULL COLLATE NOCASE,
+	recorded_at  INTEGER NOT NULL,
+	temperature  REAL    NOT NULL,
+	feels_like   REAL    NOT NULL,
+	humidity     REAL    NOT NULL,
+	pressure     REAL    NOT NULL,
+	wind_speed   REAL    NOT NULL,
+	confidence   REAL    NOT NULL,
+	source_count INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_current_snapshots_city_time ON current_snapshots (city, recorded_at);
+`
+
+// HistoryStore keeps a time series of aggregated current-weather snapshots
+type HistoryStore struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewHistoryStore(path string, logger *zap.Logger) (*HistoryStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	
+	// SQLite allows a single writer, serialize access instead of hitting SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history schema: %w", err)
+	}
+	
+	logger.Info("History storage initialized", zap.String("path", path))
+	
+	return &HistoryStore{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// SaveSnapshot stores one aggregated reading, keyed by its last update time
+func (s *HistoryStore) SaveSnapshot(ctx context.Context, weather *models.AggregatedCurrentWeather) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO current_snapshots
+			(city, recorded_at, temperature, feels_like, humidity, pressure, wind_speed, confidence, source_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		weather.City,
+		weather.LastUpdated.Unix(),
+		weather.Temperature,
+		weather.FeelsLike,
+		weather.Humidity,
+		weather.Pressure,
+		weather.WindSpeed,
+		weather.Confidence,
+		weather.SourceCount,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot for %s: %w", weather.City, err)
+	}
+	return nil
+}
+
+// QueryRange returns the snapshots of a city recorded within [from, to], oldest first
+func (s *HistoryStore) QueryRange(ctx context.Context, city string, from, to time.Time) ([]models.TrendPoint, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT recorded_at, temperature, feels_like, humidity, pressure, wind_speed, confidence, source_count
+		FROM current_snapshots
+		WHERE city = ? AND recorded_at BETWEEN ? AND ?
+		ORDER BY recorded_at`,
+		city, from.Unix(), to.Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history for %s: %w", city, err)
+	}
+	defer rows.Close()
+	
+	points := make([]models.TrendPoint, 0)
+	for rows.Next() {
+		var point models.TrendPoint
+		var recordedAt int64
+		
+		if err := rows.Scan(
+			&recordedAt,
+			&point.Temperature,
+			&point.FeelsLike,
+			&point.Humidity,
+			&point.Pressure,
+			&point.WindSpeed,
+			&point.Confidence,
+			&point.SourceCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read history row: %w", err)
+		}
+		
+		point.Timestamp = time.Unix(recordedAt, 0).UTC()
+		points = append(points, point)
+	}
+	
+	return points, rows.Err()
+}
+
+func (s *HistoryStore) Close() error {
+	return s.db.Close()
+}
\ No newline at end of file
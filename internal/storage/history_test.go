@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+func newTestHistoryStore(t *testing.T) *HistoryStore {
+	t.Helper()
+	
+	store, err := NewHistoryStore(filepath.Join(t.TempDir(), "history.db"), zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewHistoryStore: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return store
+}
+
+func TestHistoryStoreQueriesRange(t *testing.T) {
+	store := newTestHistoryStore(t)
+	ctx := context.Background()
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	
+	snapshots := []*models.AggregatedCurrentWeather{
+		{City: "Prague", Temperature: 18, LastUpdated: start, SourceCount: 2},
+		{City: "Prague", Temperature: 20, LastUpdated: start.Add(time.Hour), SourceCount: 2},
+		{City: "Prague", Temperature: 22, LastUpdated: start.Add(2 * time.Hour), SourceCount: 1},
+		{City: "London", Temperature: 15, LastUpdated: start.Add(time.Hour), SourceCount: 2},
+	}
+	// stored out of order, the query sorts them
+	for _, i := range []int{2, 0, 3, 1} {
+		if err := store.SaveSnapshot(ctx, snapshots[i]); err != nil {
+			t.Fatalf("SaveSnapshot: %v", err)
+		}
+	}
+	
+	points, err := store.QueryRange(ctx, "prague", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("QueryRange: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("QueryRange returned %d points, want the 2 Prague ones in range", len(points))
+	}
+	for i, want := range []float64{18, 20} {
+		if points[i].Temperature != want || !points[i].Timestamp.Equal(start.Add(time.Duration(i)*time.Hour)) {
+			t.Errorf("points[%d] = %.0f° at %s, want %.0f° oldest first", i, points[i].Temperature, points[i].Timestamp, want)
+		}
+	}
+	
+	points, err = store.QueryRange(ctx, "Tokyo", start, start.Add(time.Hour))
+	if err != nil || len(points) != 0 {
+		t.Errorf("QueryRange for an unrecorded city = %v, %v, want no points", points, err)
+	}
+}
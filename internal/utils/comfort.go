@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"math"
+)
+
+const msToMph = 2.236936
+
+// HeatIndex returns the NWS heat index in °C for a temperature in °C and a
+// relative humidity in percent. It is only defined from 80°F (26.7°C) up,
+// otherwise ok is false.
+// See https://www.wpc.ncep.noaa.gov/html/heatindex_equation.shtml
+func HeatIndex(tempC, humidity float64) (float64, bool) {
+	t := celsiusToFahrenheit(tempC)
+	if t < 80 {
+		return 0, false
+	}
+	rh := humidity
+	
+	// Rothfusz regression
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+		0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+	
+	switch {
+	case rh < 13 && t <= 112:
+		hi -= ((13 - rh) / 4) * math.Sqrt((17-math.Abs(t-95))/17)
+	case rh > 85 && t <= 87:
+		hi += ((rh - 85) / 10) * ((87 - t) / 5)
+	}
+	
+	return fahrenheitToCelsius(hi), true
+}
+
+// WindChill returns the NWS wind chill in °C for a temperature in °C and a wind
+// speed in m/s. It is only defined at or below 50°F (10°C) with wind above
+// 3 mph (1.34 m/s), otherwise ok is false.
+// See https://www.weather.gov/media/epz/wxcalc/windChill.pdf
+func WindChill(tempC, windSpeed float64) (float64, bool) {
+	t := celsiusToFahrenheit(tempC)
+	v := windSpeed * msToMph
+	if t > 50 || v <= 3 {
+		return 0, false
+	}
+	
+	vPow := math.Pow(v, 0.16)
+	wc := 35.74 + 0.6215*t - 35.75*vPow + 0.4275*t*vPow
+	
+	return fahrenheitToCelsius(wc), true
+}
+
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+func fahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
\ No newline at end of file
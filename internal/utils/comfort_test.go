@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+// Reference values from the NWS heat index and wind chill charts, in °F
+func TestHeatIndex(t *testing.T) {
+	tests := []struct {
+		tempF, humidity, want float64
+	}{
+		{80, 90, 86},
+		{86, 40, 85},
+		{90, 70, 106},
+		{96, 65, 121},
+		{100, 40, 109},
+	}
+	for _, tt := range tests {
+		index, ok := HeatIndex(fahrenheitToCelsius(tt.tempF), tt.humidity)
+		if got := celsiusToFahrenheit(index); !ok || math.Abs(got-tt.want) > 1 {
+			t.Errorf("HeatIndex(%v°F, %v%%) = %.1f°F, %v, want %v°F", tt.tempF, tt.humidity, got, ok, tt.want)
+		}
+	}
+	
+	if _, ok := HeatIndex(25, 90); ok {
+		t.Error("HeatIndex at 25°C is defined, want it only from 26.7°C up")
+	}
+}
+
+func TestWindChill(t *testing.T) {
+	tests := []struct {
+		tempF, windMph, want float64
+	}{
+		{40, 5, 36},
+		{30, 10, 21},
+		{20, 25, 3},
+		{0, 15, -19},
+		{-10, 30, -39},
+	}
+	for _, tt := range tests {
+		chill, ok := WindChill(fahrenheitToCelsius(tt.tempF), tt.windMph/msToMph)
+		if got := celsiusToFahrenheit(chill); !ok || math.Abs(got-tt.want) > 1 {
+			t.Errorf("WindChill(%v°F, %v mph) = %.1f°F, %v, want %v°F", tt.tempF, tt.windMph, got, ok, tt.want)
+		}
+	}
+	
+	if _, ok := WindChill(12, 10); ok {
+		t.Error("WindChill at 12°C is defined, want it only at or below 10°C")
+	}
+	if _, ok := WindChill(0, 1); ok {
+		t.Error("WindChill in 1 m/s of wind is defined, want it only above 3 mph")
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance in kilometers between two points
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	
+	return earthRadiusKm * c
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// Clamp limits value to the range [min, max]
+func Clamp(value, min, max float64) float64 {
+	return math.Max(min, math.Min(max, value))
+}
+
+// IsFinite reports whether value is neither NaN nor infinite
+func IsFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// WeightedMean returns the mean of values weighted by weights, or the plain
+// mean when the weights sum to zero. Non-finite values and weights are
+// skipped, and 0 is returned when nothing is left.
+func WeightedMean(values, weights []float64) float64 {
+	var sum, weightSum, plainSum float64
+	count := 0
+	for i, value := range values {
+		if !IsFinite(value) || !IsFinite(weights[i]) {
+			continue
+		}
+		sum += value * weights[i]
+		weightSum += weights[i]
+		plainSum += value
+		count++
+	}
+	
+	if count == 0 {
+		return 0
+	}
+	if weightSum == 0 {
+		return plainSum / float64(count)
+	}
+	return sum / weightSum
+}
+
+// NormalizeCity case-folds a city name and collapses its whitespace, so that
+// "london", " London " and "LONDON" compare equal
+func NormalizeCity(city string) string {
+	return strings.ToLower(strings.Join(strings.Fields(city), " "))
+}
+
+// RoundHalfUp rounds value to the given number of decimals, with halves
+// rounded away from zero, so -0.5 becomes -1 like 0.5 becomes 1. The scaled
+// value is first cut to a few more digits so that representation error, as
+// in 1.005*100 = 100.49999..., does not round down.
+func RoundHalfUp(value float64, decimals int) float64 {
+	if !IsFinite(value) {
+		return value
+	}
+	
+	pow := math.Pow(10, float64(decimals))
+	scaled, err := strconv.ParseFloat(strconv.FormatFloat(value*pow, 'f', 6, 64), 64)
+	if err != nil {
+		return value
+	}
+	rounded := math.Round(scaled) / pow
+	if rounded == 0 {
+		return 0 // not -0, e.g. for -0.04 at one decimal
+	}
+	return rounded
+}
\ No newline at end of file
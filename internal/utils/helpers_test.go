@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineKm(t *testing.T) {
+	// London to Paris is about 344 km
+	if km := HaversineKm(51.5074, -0.1278, 48.8566, 2.3522); math.Abs(km-343.6) > 1 {
+		t.Errorf("HaversineKm(London, Paris) = %v, want about 343.6", km)
+	}
+	if km := HaversineKm(50, 14, 50, 14); km != 0 {
+		t.Errorf("HaversineKm of a point to itself = %v, want 0", km)
+	}
+}
+
+func TestNormalizeCity(t *testing.T) {
+	for _, city := range []string{"New York", "new york", "NEW YORK", "  New   York ", "New\tYork"} {
+		if got := NormalizeCity(city); got != "new york" {
+			t.Errorf("NormalizeCity(%q) = %q, want %q", city, got, "new york")
+		}
+	}
+}
+
+func TestRoundHalfUp(t *testing.T) {
+	tests := []struct {
+		value    float64
+		decimals int
+		want     float64
+	}{
+		{18.333333333, 1, 18.3},
+		{18.35, 1, 18.4},
+		{2.25, 1, 2.3},
+		{1.005, 2, 1.01},
+		{0.5, 0, 1},
+		{-0.5, 0, -1},
+		{-2.25, 1, -2.3},
+		{1013.26, 0, 1013},
+		{12.3456, 3, 12.346},
+	}
+	for _, tt := range tests {
+		if got := RoundHalfUp(tt.value, tt.decimals); got != tt.want {
+			t.Errorf("RoundHalfUp(%v, %d) = %v, want %v", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+	
+	if got := RoundHalfUp(-0.04, 1); got != 0 || math.Signbit(got) {
+		t.Errorf("RoundHalfUp(-0.04, 1) = %v, want 0 without a sign", got)
+	}
+	if got := RoundHalfUp(math.NaN(), 1); !math.IsNaN(got) {
+		t.Errorf("RoundHalfUp(NaN, 1) = %v, want NaN", got)
+	}
+	if got := RoundHalfUp(math.Inf(1), 1); !math.IsInf(got, 1) {
+		t.Errorf("RoundHalfUp(+Inf, 1) = %v, want +Inf", got)
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"math"
+	"time"
+)
+
+const synodicMonthDays = 29.530588853
+
+// Reference new moon of 2000-01-06 18:14 UTC
+var referenceNewMoon = time.Date(2000, time.January, 6, 18, 14, 0, 0, time.UTC)
+
+var moonPhaseNames = [8]string{
+	"New Moon",
+	"Waxing Crescent",
+	"First Quarter",
+	"Waxing Gibbous",
+	"Full Moon",
+	"Waning Gibbous",
+	"Last Quarter",
+	"Waning Crescent",
+}
+
+// MoonPhase returns the fraction of the lunar cycle at t (0 new moon, 0.5 full
+// moon) and its name. It counts mean synodic months from a reference new moon,
+// which is accurate to within about a day.
+func MoonPhase(t time.Time) (float64, string) {
+	days := t.Sub(referenceNewMoon).Hours() / 24
+	phase := math.Mod(days/synodicMonthDays, 1)
+	if phase < 0 {
+		phase++
+	}
+	
+	// Each name covers an eighth of the cycle centered on its phase
+	name := moonPhaseNames[int(phase*8+0.5)%8]
+	
+	return math.Round(phase*1000) / 1000, name
+}
\ No newline at end of file
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMoonPhaseAtReferenceDates(t *testing.T) {
+	tests := []struct {
+		at    time.Time
+		phase float64
+		name  string
+	}{
+		{time.Date(2023, time.December, 12, 23, 32, 0, 0, time.UTC), 0, "New Moon"},
+		{time.Date(2024, time.January, 25, 17, 54, 0, 0, time.UTC), 0.5, "Full Moon"},
+		{time.Date(2024, time.April, 8, 18, 21, 0, 0, time.UTC), 0, "New Moon"},
+		{time.Date(2024, time.April, 15, 19, 13, 0, 0, time.UTC), 0.25, "First Quarter"},
+		{time.Date(2024, time.April, 23, 23, 49, 0, 0, time.UTC), 0.5, "Full Moon"},
+		{time.Date(2024, time.May, 1, 11, 27, 0, 0, time.UTC), 0.75, "Last Quarter"},
+		{time.Date(1999, time.December, 22, 17, 31, 0, 0, time.UTC), 0.5, "Full Moon"},
+	}
+	for _, tt := range tests {
+		phase, name := MoonPhase(tt.at)
+		if phase < 0 || phase >= 1 {
+			t.Errorf("MoonPhase(%s) = %v, want within [0, 1)", tt.at.Format(time.DateOnly), phase)
+		}
+		// the phase wraps around, 0.99 is as close to a new moon as 0.01
+		distance := math.Abs(phase - tt.phase)
+		if distance > 0.5 {
+			distance = 1 - distance
+		}
+		// about a day of a mean synodic month
+		if distance > 0.04 {
+			t.Errorf("MoonPhase(%s) = %v, want about %v", tt.at.Format(time.DateOnly), phase, tt.phase)
+		}
+		if name != tt.name {
+			t.Errorf("MoonPhase(%s) name = %q, want %q", tt.at.Format(time.DateOnly), name, tt.name)
+		}
+	}
+}
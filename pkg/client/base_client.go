@@ -2,12 +2,15 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
 	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
 )
@@ -23,6 +26,54 @@ type BaseClient struct {
 	maxRetries    int
 	retryDelay    time.Duration
 	multiplier    float64
+	maxDelay      time.Duration // cap on each backoff delay, 0 for none
+	breakerTimeout time.Duration
+	logBodies     bool
+	mu            sync.Mutex
+	openedAt      time.Time // when the breaker last tripped
+	usage         usageCounter // calls that reached the provider
+}
+
+// StatusError is a provider response with a non-2xx status code
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+type noRateLimitRetryKey struct{}
+
+// withoutRateLimitRetry returns a context under which a 429 ends the request
+// right away instead of being retried, for callers with a fallback of their own
+func withoutRateLimitRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRateLimitRetryKey{}, true)
+}
+
+func rateLimitRetryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRateLimitRetryKey{}).(bool)
+	return disabled
+}
+
+type keyRejectionsExcusedKey struct{}
+
+// withKeyRejectionsExcused returns a context under which a 401 or 429 is
+// reported to the caller without counting as a provider failure, for callers
+// that rotate API keys and can answer it with another key
+func withKeyRejectionsExcused(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keyRejectionsExcusedKey{}, true)
+}
+
+// isExcusedKeyRejection reports whether err is a 401 or 429 under a context
+// from withKeyRejectionsExcused
+func isExcusedKeyRejection(ctx context.Context, err error) bool {
+	if excused, _ := ctx.Value(keyRejectionsExcusedKey{}).(bool); !excused {
+		return false
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusTooManyRequests)
 }
 
 type ClientConfig struct {
@@ -30,89 +81,145 @@ type ClientConfig struct {
 	MaxRetries    int
 	RetryDelay    time.Duration
 	Multiplier    float64
-	Threshold     int
+	MaxDelay      time.Duration // cap on each backoff delay, 0 for none
+	Threshold     int     // consecutive failures that trip the breaker, 0 disables
+	MinRequests   int     // requests before FailureRatio is considered
+	FailureRatio  float64 // failed share of requests that trips the breaker, 0 disables
+	SuccessThreshold int  // consecutive half-open probe successes that close the breaker
 	BreakerTimeout time.Duration
+	LogBodies     bool // log truncated response bodies at debug level
+	Transport     http.RoundTripper // shared between clients, http.DefaultTransport when nil
 }
 
 func NewBaseClient(name string, config ClientConfig, logger *zap.Logger) *BaseClient {
 	httpClient := &http.Client{
-		Timeout: config.Timeout,
+		Timeout:   config.Timeout,
+		Transport: config.Transport,
+	}
+	
+	// gobreaker closes a half-open breaker after MaxRequests consecutive
+	// successes and lets no more than that many probes through meanwhile
+	probes := config.SuccessThreshold
+	if probes < 1 {
+		probes = 1
+	}
+	
+	// gobreaker falls back to 60s when no timeout is configured
+	breakerTimeout := config.BreakerTimeout
+	if breakerTimeout <= 0 {
+		breakerTimeout = 60 * time.Second
+	}
+	
+	baseClient := &BaseClient{
+		client:        httpClient,
+		logger:        logger,
+		maxRetries:    config.MaxRetries,
+		retryDelay:    config.RetryDelay,
+		multiplier:    config.Multiplier,
+		maxDelay:      config.MaxDelay,
+		breakerTimeout: breakerTimeout,
+		logBodies:     config.LogBodies,
 	}
 	
 	// Circuit breaker settings
 	breakerSettings := gobreaker.Settings{
 		Name:        name,
-		MaxRequests: 1,
+		MaxRequests: uint32(probes),
 		Interval:    0,
-		Timeout:     config.BreakerTimeout,
-		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return counts.Requests >= 3 && failureRatio >= 0.6
-		},
+		Timeout:     breakerTimeout,
+		ReadyToTrip: readyToTrip(config),
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			logger.Info("Circuit breaker state changed",
 				zap.String("client", name),
 				zap.String("from", from.String()),
 				zap.String("to", to.String()))
+			
+			if to == gobreaker.StateOpen {
+				baseClient.mu.Lock()
+				baseClient.openedAt = time.Now()
+				baseClient.mu.Unlock()
+			}
 		},
 	}
 	
-	return &BaseClient{
-		client:        httpClient,
-		logger:        logger,
-		circuitBreaker: gobreaker.NewCircuitBreaker(breakerSettings),
-		maxRetries:    config.MaxRetries,
-		retryDelay:    config.RetryDelay,
-		multiplier:    config.Multiplier,
+	baseClient.circuitBreaker = gobreaker.NewCircuitBreaker(breakerSettings)
+	
+	return baseClient
+}
+
+// readyToTrip trips the breaker after Threshold consecutive failures, or once
+// MinRequests were made and at least FailureRatio of them failed
+func readyToTrip(config ClientConfig) func(gobreaker.Counts) bool {
+	return func(counts gobreaker.Counts) bool {
+		if config.Threshold > 0 && counts.ConsecutiveFailures >= uint32(config.Threshold) {
+			return true
+		}
+		if config.FailureRatio <= 0 || counts.Requests < uint32(config.MinRequests) {
+			return false
+		}
+		failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+		return failureRatio >= config.FailureRatio
 	}
 }
 
-func (c *BaseClient) GetWithRetry(ctx context.Context, url string) ([]byte, error) {
-	var response []byte
+// GetWithRetry fetches url, retrying transport errors, 5xx and 429 responses.
+// A successful response must carry a JSON body.
+func (c *BaseClient) GetWithRetry(ctx context.Context, url string) (JSONBody, error) {
+	var response JSONBody
 	var err error
 	
-	// Execute with circuit breaker
+	// Execute with circuit breaker, calls it rejects never reach the provider
 	_, execErr := c.circuitBreaker.Execute(func() (interface{}, error) {
+		c.usage.record(time.Now())
 		response, err = c.doGetWithRetry(ctx, url)
+		if isExcusedKeyRejection(ctx, err) {
+			return nil, nil
+		}
 		return response, err
 	})
 	
 	if execErr != nil {
-		return nil, execErr
+		return JSONBody{}, execErr
 	}
 	
 	return response, err
 }
 
-func (c *BaseClient) doGetWithRetry(ctx context.Context, url string) ([]byte, error) {
+func (c *BaseClient) doGetWithRetry(ctx context.Context, url string) (JSONBody, error) {
 	var lastErr error
+	loggedURL := redactURL(url)
+	capture := captureFrom(ctx)
 	
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		if attempt > 0 {
-			// Calculate exponential backoff delay
-			delay := time.Duration(float64(c.retryDelay) * math.Pow(c.multiplier, float64(attempt-1)))
+			delay := c.backoff(attempt)
 			c.logger.Debug("Retrying request",
-				zap.String("url", url),
+				zap.String("url", loggedURL),
 				zap.Int("attempt", attempt),
 				zap.Duration("delay", delay))
 			
 			select {
 			case <-ctx.Done():
-				return nil, ctx.Err()
+				return JSONBody{}, ctx.Err()
 			case <-time.After(delay):
 			}
 		}
 		
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
-			return nil, fmt.Errorf("creating request failed: %w", err)
+			return JSONBody{}, fmt.Errorf("creating request failed: %w", err)
 		}
 		
+		c.logger.Debug("Sending request",
+			zap.String("url", loggedURL),
+			zap.Int("attempt", attempt))
+		
 		resp, err := c.client.Do(req)
 		if err != nil {
+			err = redactError(err)
 			lastErr = err
 			c.logger.Warn("HTTP request failed",
-				zap.String("url", url),
+				zap.String("url", loggedURL),
 				zap.Int("attempt", attempt),
 				zap.Error(err))
 			continue
@@ -128,21 +235,95 @@ func (c *BaseClient) doGetWithRetry(ctx context.Context, url string) ([]byte, er
 			}
 			
 			c.logger.Debug("Request successful",
-				zap.String("url", url),
+				zap.String("url", loggedURL),
 				zap.Int("status", resp.StatusCode),
 				zap.Int("body_size", len(body)))
+			c.logBody(loggedURL, resp.StatusCode, body)
+			if capture != nil {
+				capture(RawResponse{URL: loggedURL, Status: resp.StatusCode, Body: body})
+			}
 			
-			return body, nil
+			// An HTML page or plain-text notice behind a 200 is a failure, not
+			// a response to decode into zero values. The provider answered, so
+			// asking again would only get the same page.
+			checked, err := checkJSONBody(resp.Header.Get("Content-Type"), body)
+			if err != nil {
+				c.logger.Warn("Response body is not JSON",
+					zap.String("url", loggedURL),
+					zap.Int("attempt", attempt),
+					zap.Error(err))
+				return JSONBody{}, err
+			}
+			
+			return checked, nil
 		}
 		
+		if c.logBodies || capture != nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxCapturedBody))
+			c.logBody(loggedURL, resp.StatusCode, body)
+			if capture != nil {
+				capture(RawResponse{URL: loggedURL, Status: resp.StatusCode, Body: body})
+			}
+		}
 		resp.Body.Close()
-		lastErr = fmt.Errorf("HTTP %d", resp.StatusCode)
+		lastErr = &StatusError{StatusCode: resp.StatusCode}
 		
 		// Don't retry on client errors (4xx) except 429 (rate limiting)
 		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != 429 {
 			break
 		}
+		if resp.StatusCode == 429 && rateLimitRetryDisabled(ctx) {
+			break
+		}
 	}
 	
-	return nil, fmt.Errorf("max retries exceeded, last error: %w", lastErr)
+	return JSONBody{}, fmt.Errorf("max retries exceeded, last error: %w", lastErr)
+}
+
+// backoff returns the delay before the given retry attempt, growing
+// exponentially up to maxDelay
+func (c *BaseClient) backoff(attempt int) time.Duration {
+	delay := float64(c.retryDelay) * math.Pow(c.multiplier, float64(attempt-1))
+	
+	// Compared as float, a large power overflows the Duration conversion
+	if c.maxDelay > 0 && delay > float64(c.maxDelay) {
+		return c.maxDelay
+	}
+	return time.Duration(delay)
+}
+
+func (c *BaseClient) logBody(loggedURL string, status int, body []byte) {
+	if !c.logBodies {
+		return
+	}
+	c.logger.Debug("Response body",
+		zap.String("url", loggedURL),
+		zap.Int("status", status),
+		zap.String("body", truncateBody(body)))
+}
+
+func (c *BaseClient) BreakerState() string {
+	return c.circuitBreaker.State().String()
+}
+
+// Usage returns the calls made in the current UTC hour and day
+func (c *BaseClient) Usage() (hour, day models.UsageWindow) {
+	return c.usage.snapshot(time.Now())
+}
+
+// BreakerRetryAfter returns how long until an open breaker lets a probe request
+// through, or zero if the breaker is not open
+func (c *BaseClient) BreakerRetryAfter() time.Duration {
+	if c.circuitBreaker.State() != gobreaker.StateOpen {
+		return 0
+	}
+	
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	remaining := c.breakerTimeout - time.Since(c.openedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
 }
\ No newline at end of file
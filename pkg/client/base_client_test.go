@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+func TestBackoffIsCapped(t *testing.T) {
+	config := testClientConfig()
+	config.RetryDelay = 100 * time.Millisecond
+	config.Multiplier = 100
+	config.MaxDelay = time.Second
+	client := NewBaseClient("test", config, zap.NewNop())
+	
+	if delay := client.backoff(1); delay != 100*time.Millisecond {
+		t.Errorf("backoff(1) = %v, want the 100ms base delay", delay)
+	}
+	for _, attempt := range []int{2, 5, 50, 1000} {
+		if delay := client.backoff(attempt); delay != time.Second {
+			t.Errorf("backoff(%d) = %v, want the 1s cap", attempt, delay)
+		}
+	}
+}
+
+func TestBackoffWithoutCap(t *testing.T) {
+	config := testClientConfig()
+	config.RetryDelay = time.Second
+	config.Multiplier = 2
+	client := NewBaseClient("test", config, zap.NewNop())
+	
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second} {
+		if delay := client.backoff(attempt); delay != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, delay, want)
+		}
+	}
+}
+
+func TestRetriesWaitNoLongerThanCap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+	
+	config := testClientConfig()
+	config.MaxRetries = 3
+	config.RetryDelay = 10 * time.Millisecond
+	config.Multiplier = 1000
+	config.MaxDelay = 20 * time.Millisecond
+	client := NewBaseClient("test", config, zap.NewNop())
+	
+	started := time.Now()
+	if _, err := client.GetWithRetry(context.Background(), server.URL); err == nil {
+		t.Fatal("GetWithRetry succeeded against a failing server")
+	}
+	// Uncapped, the third retry alone would wait 10ms * 1000^2
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("3 retries took %v, want each delay capped at 20ms", elapsed)
+	}
+}
+
+func TestReadyToTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ClientConfig
+		counts gobreaker.Counts
+		want   bool
+	}{
+		{"below consecutive threshold", ClientConfig{Threshold: 5}, gobreaker.Counts{Requests: 4, TotalFailures: 4, ConsecutiveFailures: 4}, false},
+		{"at consecutive threshold", ClientConfig{Threshold: 5}, gobreaker.Counts{Requests: 5, TotalFailures: 5, ConsecutiveFailures: 5}, true},
+		{"ratio before min requests", ClientConfig{MinRequests: 10, FailureRatio: 0.5}, gobreaker.Counts{Requests: 9, TotalFailures: 9}, false},
+		{"ratio below threshold", ClientConfig{MinRequests: 10, FailureRatio: 0.5}, gobreaker.Counts{Requests: 10, TotalFailures: 4}, false},
+		{"ratio at threshold", ClientConfig{MinRequests: 10, FailureRatio: 0.5}, gobreaker.Counts{Requests: 10, TotalFailures: 5}, true},
+		{"consecutive disabled", ClientConfig{MinRequests: 10, FailureRatio: 0.5}, gobreaker.Counts{Requests: 3, TotalFailures: 3, ConsecutiveFailures: 3}, false},
+		{"ratio disabled", ClientConfig{Threshold: 5, MinRequests: 1}, gobreaker.Counts{Requests: 10, TotalFailures: 9, ConsecutiveFailures: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readyToTrip(tt.config)(tt.counts); got != tt.want {
+				t.Errorf("readyToTrip(%+v) = %v, want %v", tt.counts, got, tt.want)
+			}
+		})
+	}
+}
+
+// newSwitchableServer answers 200 with a JSON body, or 503 while failing is set
+func newSwitchableServer(t *testing.T, failing *atomic.Bool) string {
+	t.Helper()
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestBreakerTripsAtConfiguredThreshold(t *testing.T) {
+	var failing atomic.Bool
+	url := newSwitchableServer(t, &failing)
+	
+	config := testClientConfig()
+	config.MaxRetries = 0
+	config.Threshold = 4
+	config.MinRequests = 1
+	client := NewBaseClient("test", config, zap.NewNop())
+	
+	// Successes in between reset the consecutive count
+	failing.Store(true)
+	for i := 0; i < 3; i++ {
+		client.GetWithRetry(context.Background(), url)
+	}
+	failing.Store(false)
+	client.GetWithRetry(context.Background(), url)
+	failing.Store(true)
+	for i := 0; i < 3; i++ {
+		client.GetWithRetry(context.Background(), url)
+	}
+	if state := client.BreakerState(); state != "closed" {
+		t.Fatalf("state after 3 consecutive failures = %q, want closed", state)
+	}
+	
+	client.GetWithRetry(context.Background(), url)
+	if state := client.BreakerState(); state != "open" {
+		t.Errorf("state after 4 consecutive failures = %q, want open", state)
+	}
+}
+
+func TestBreakerTripsAtConfiguredFailureRatio(t *testing.T) {
+	var failing atomic.Bool
+	url := newSwitchableServer(t, &failing)
+	
+	config := testClientConfig()
+	config.MaxRetries = 0
+	config.MinRequests = 4
+	config.FailureRatio = 0.5
+	client := NewBaseClient("test", config, zap.NewNop())
+	
+	for _, fail := range []bool{false, true, false} {
+		failing.Store(fail)
+		client.GetWithRetry(context.Background(), url)
+	}
+	if state := client.BreakerState(); state != "closed" {
+		t.Fatalf("state after 3 requests = %q, want closed below MinRequests", state)
+	}
+	
+	// gobreaker only checks the criteria when a request fails
+	failing.Store(true)
+	client.GetWithRetry(context.Background(), url)
+	if state := client.BreakerState(); state != "open" {
+		t.Errorf("state with 2 of 4 requests failed = %q, want open", state)
+	}
+}
+
+func TestBreakerClosesAfterSuccessThreshold(t *testing.T) {
+	var failing atomic.Bool
+	url := newSwitchableServer(t, &failing)
+	
+	config := testClientConfig()
+	config.MaxRetries = 0
+	config.Threshold = 1
+	config.MinRequests = 1
+	config.SuccessThreshold = 3
+	config.BreakerTimeout = 20 * time.Millisecond
+	client := NewBaseClient("test", config, zap.NewNop())
+	
+	trip := func() {
+		failing.Store(true)
+		client.GetWithRetry(context.Background(), url)
+		if state := client.BreakerState(); state != "open" {
+			t.Fatalf("state after a failure = %q, want open", state)
+		}
+		time.Sleep(30 * time.Millisecond)
+		failing.Store(false)
+	}
+	
+	trip()
+	for i := 1; i < 3; i++ {
+		if _, err := client.GetWithRetry(context.Background(), url); err != nil {
+			t.Fatalf("probe %d: %v", i, err)
+		}
+		if state := client.BreakerState(); state != "half-open" {
+			t.Errorf("state after %d successful probes = %q, want half-open", i, state)
+		}
+	}
+	client.GetWithRetry(context.Background(), url)
+	if state := client.BreakerState(); state != "closed" {
+		t.Errorf("state after 3 successful probes = %q, want closed", state)
+	}
+	
+	// A failed probe reopens the breaker and the count starts over
+	trip()
+	client.GetWithRetry(context.Background(), url)
+	failing.Store(true)
+	client.GetWithRetry(context.Background(), url)
+	if state := client.BreakerState(); state != "open" {
+		t.Errorf("state after a failed probe = %q, want open", state)
+	}
+}
@@ -0,0 +1,27 @@
+package client
+
+import "context"
+
+// maxCapturedBody bounds how much of an error response is kept for capture
+const maxCapturedBody = 64 * 1024
+
+// RawResponse is an upstream response exactly as the provider returned it
+type RawResponse struct {
+	URL    string // credentials redacted
+	Status int
+	Body   []byte
+}
+
+type captureKey struct{}
+
+// WithResponseCapture returns a context under which every upstream response
+// is also passed to fn. It is a debugging aid and fn must be safe for
+// concurrent use when a client issues requests in parallel.
+func WithResponseCapture(ctx context.Context, fn func(RawResponse)) context.Context {
+	return context.WithValue(ctx, captureKey{}, fn)
+}
+
+func captureFrom(ctx context.Context) func(RawResponse) {
+	fn, _ := ctx.Value(captureKey{}).(func(RawResponse))
+	return fn
+}
\ No newline at end of file
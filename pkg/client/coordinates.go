@@ -0,0 +1,45 @@
+package client
+
+import (
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+)
+
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Note: Open-Meteo requires coordinates, not city names
+// For simplicity, we'll use hardcoded coordinates for major cities.
+// These are also the city centers resolved points are measured against.
+var cityCoordinates = map[string]Coordinates{
+	"Prague":  {Latitude: 50.0755, Longitude: 14.4378},
+	"London":  {Latitude: 51.5074, Longitude: -0.1278},
+	"NewYork": {Latitude: 40.7128, Longitude: -74.0060},
+	"Tokyo":   {Latitude: 35.6762, Longitude: 139.6503},
+	"Sydney":  {Latitude: -33.8688, Longitude: 151.2093},
+}
+
+// cityNames maps normalized city names to their spelling in cityCoordinates
+var cityNames = func() map[string]string {
+	names := make(map[string]string, len(cityCoordinates))
+	for name := range cityCoordinates {
+		names[utils.NormalizeCity(name)] = name
+	}
+	return names
+}()
+
+// LookupCoordinates finds a city regardless of case and surrounding whitespace
+func LookupCoordinates(city string) (Coordinates, bool) {
+	name, ok := CanonicalCityName(city)
+	if !ok {
+		return Coordinates{}, false
+	}
+	return cityCoordinates[name], true
+}
+
+// CanonicalCityName returns the table's spelling of a known city
+func CanonicalCityName(city string) (string, bool) {
+	name, ok := cityNames[utils.NormalizeCity(city)]
+	return name, ok
+}
\ No newline at end of file
@@ -0,0 +1,19 @@
+package client
+
+import "testing"
+
+func TestLookupCoordinatesIgnoresCaseAndWhitespace(t *testing.T) {
+	for _, city := range []string{"London", "london", "LONDON", " London ", "\tlOnDoN\n"} {
+		coords, ok := LookupCoordinates(city)
+		if !ok || coords != cityCoordinates["London"] {
+			t.Errorf("LookupCoordinates(%q) = %v, %v, want London's coordinates", city, coords, ok)
+		}
+		if name, _ := CanonicalCityName(city); name != "London" {
+			t.Errorf("CanonicalCityName(%q) = %q, want London", city, name)
+		}
+	}
+	
+	if _, ok := LookupCoordinates("Atlantis"); ok {
+		t.Error("LookupCoordinates(Atlantis) found coordinates, want none")
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"go.uber.org/zap"
+)
+
+// maxGeocodedCities bounds the in-memory geocoding cache; it is simply reset
+// when full since resolved coordinates are cheap to look up again
+const maxGeocodedCities = 1000
+
+// Geocoder resolves city names to coordinates. Known cities without a country
+// come from the built-in table, everything else from the Open-Meteo
+// geocoding API, with results kept in memory.
+type Geocoder struct {
+	*BaseClient
+	baseURL string
+	mu      sync.RWMutex
+	cache   map[string]Coordinates
+	searches map[string]searchEntry // normalized query and limit -> matches
+}
+
+type geocodingResponse struct {
+	Results []struct {
+		Name        string  `json:"name"`
+		Latitude    float64 `json:"latitude"`
+		Longitude   float64 `json:"longitude"`
+		CountryCode string  `json:"country_code"`
+		Country     string  `json:"country"`
+		Admin1      string  `json:"admin1"`
+		Population  int     `json:"population"`
+	} `json:"results"`
+}
+
+func NewGeocoder(config ClientConfig, logger *zap.Logger) *Geocoder {
+	return &Geocoder{
+		BaseClient: NewBaseClient("geocoder", config, logger),
+		baseURL:    "https://geocoding-api.open-meteo.com/v1",
+		cache:      make(map[string]Coordinates),
+		searches:   make(map[string]searchEntry),
+	}
+}
+
+// Resolve returns the coordinates of city, optionally restricted to an
+// ISO 3166-1 alpha-2 country code such as "GB" or "CA"
+func (g *Geocoder) Resolve(ctx context.Context, city, country string) (Coordinates, error) {
+	if coords, ok := g.Cached(city, country); ok {
+		return coords, nil
+	}
+	
+	requestURL := fmt.Sprintf("%s/search?name=%s&count=1&format=json", g.baseURL, url.QueryEscape(strings.TrimSpace(city)))
+	if country != "" {
+		requestURL += "&countryCode=" + url.QueryEscape(country)
+	}
+	
+	body, err := g.GetWithRetry(ctx, requestURL)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("failed to geocode %s: %w", city, err)
+	}
+	
+	var response geocodingResponse
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return Coordinates{}, fmt.Errorf("failed to parse geocoding response: %w", err)
+	}
+	
+	if len(response.Results) == 0 {
+		return Coordinates{}, fmt.Errorf("coordinates not found for city: %s", city)
+	}
+	
+	coords := Coordinates{
+		Latitude:  response.Results[0].Latitude,
+		Longitude: response.Results[0].Longitude,
+	}
+	
+	g.mu.Lock()
+	if len(g.cache) >= maxGeocodedCities {
+		g.cache = make(map[string]Coordinates)
+	}
+	g.cache[geocodeKey(city, country)] = coords
+	g.mu.Unlock()
+	
+	g.logger.Debug("City geocoded",
+		zap.String("city", city),
+		zap.String("country", country),
+		zap.String("resolved", response.Results[0].Name),
+		zap.String("resolved_country", response.Results[0].CountryCode))
+	
+	return coords, nil
+}
+
+// Cached returns coordinates that are known without calling the API
+func (g *Geocoder) Cached(city, country string) (Coordinates, bool) {
+	if country == "" {
+		if coords, ok := LookupCoordinates(city); ok {
+			return coords, true
+		}
+	}
+	
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	
+	coords, ok := g.cache[geocodeKey(city, country)]
+	return coords, ok
+}
+
+func geocodeKey(city, country string) string {
+	return utils.NormalizeCity(city) + "," + country
+}
\ No newline at end of file
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// searchTTL is how long search results are reused; autocomplete sends the
+	// same prefixes over and over while place names rarely change
+	searchTTL = time.Hour
+
+	// maxCachedSearches bounds the search cache, which is reset when full
+	maxCachedSearches = 1000
+)
+
+type searchEntry struct {
+	matches   []models.GeocodeMatch
+	expiresAt time.Time
+}
+
+// Search returns up to limit places whose name matches the free-text query,
+// ranked with name prefix matches first and then by population. Places that
+// share a name, region and country are reported once.
+func (g *Geocoder) Search(ctx context.Context, query string, limit int) ([]models.GeocodeMatch, error) {
+	query = strings.TrimSpace(query)
+	key := fmt.Sprintf("%s,%d", utils.NormalizeCity(query), limit)
+	
+	g.mu.RLock()
+	entry, ok := g.searches[key]
+	g.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.matches, nil
+	}
+	
+	requestURL := fmt.Sprintf("%s/search?name=%s&count=%d&format=json", g.baseURL, url.QueryEscape(query), limit)
+	
+	body, err := g.GetWithRetry(ctx, requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search places for %s: %w", query, err)
+	}
+	
+	var response geocodingResponse
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse geocoding response: %w", err)
+	}
+	
+	matches := make([]models.GeocodeMatch, 0, len(response.Results))
+	seen := make(map[string]bool)
+	for _, result := range response.Results {
+		dedupeKey := strings.ToLower(result.Name + "," + result.Admin1 + "," + result.CountryCode)
+		if seen[dedupeKey] {
+			continue
+		}
+		seen[dedupeKey] = true
+		
+		matches = append(matches, models.GeocodeMatch{
+			Name:        result.Name,
+			Region:      result.Admin1,
+			Country:     result.Country,
+			CountryCode: result.CountryCode,
+			Latitude:    result.Latitude,
+			Longitude:   result.Longitude,
+			Population:  result.Population,
+		})
+	}
+	rankMatches(matches, query)
+	
+	g.mu.Lock()
+	if len(g.searches) >= maxCachedSearches {
+		g.searches = make(map[string]searchEntry)
+	}
+	g.searches[key] = searchEntry{matches: matches, expiresAt: time.Now().Add(searchTTL)}
+	g.mu.Unlock()
+	
+	g.logger.Debug("Places searched",
+		zap.String("query", query),
+		zap.Int("matches", len(matches)))
+	
+	return matches, nil
+}
+
+// rankMatches orders names starting with the query first, the geocoder's own
+// order breaking ties between equally populated places
+func rankMatches(matches []models.GeocodeMatch, query string) {
+	prefix := strings.ToLower(query)
+	sort.SliceStable(matches, func(i, j int) bool {
+		iPrefix := strings.HasPrefix(strings.ToLower(matches[i].Name), prefix)
+		jPrefix := strings.HasPrefix(strings.ToLower(matches[j].Name), prefix)
+		if iPrefix != jPrefix {
+			return iPrefix
+		}
+		return matches[i].Population > matches[j].Population
+	})
+}
\ No newline at end of file
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGeocoderResolvesCountriesSeparately(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Query().Get("countryCode") {
+		case "CA":
+			respondJSON(`{"results":[{"name":"London","latitude":42.98,"longitude":-81.23,"country_code":"CA"}]}`)(w, r)
+		case "GB":
+			respondJSON(`{"results":[{"name":"London","latitude":51.51,"longitude":-0.13,"country_code":"GB"}]}`)(w, r)
+		default:
+			respondJSON(`{"results":[]}`)(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	
+	geocoder := NewGeocoder(testClientConfig(), zap.NewNop())
+	geocoder.baseURL = server.URL
+	
+	canada, err := geocoder.Resolve(context.Background(), "London", "CA")
+	if err != nil {
+		t.Fatalf("Resolve CA: %v", err)
+	}
+	britain, err := geocoder.Resolve(context.Background(), "London", "GB")
+	if err != nil {
+		t.Fatalf("Resolve GB: %v", err)
+	}
+	if canada.Latitude != 42.98 || britain.Latitude != 51.51 {
+		t.Errorf("London,CA at %v and London,GB at %v, want 42.98 and 51.51", canada.Latitude, britain.Latitude)
+	}
+	
+	if _, err := geocoder.Resolve(context.Background(), "london", "CA"); err != nil {
+		t.Fatalf("Resolve again: %v", err)
+	}
+	if _, err := geocoder.Resolve(context.Background(), "London", ""); err != nil {
+		t.Fatalf("Resolve without country: %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("%d geocoding requests, want 2 with repeats and the built-in city served locally", got)
+	}
+}
+
+func TestGeocoderSearchRanksAndDeduplicates(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		respondJSON(`{"results":[
+			{"name":"Le Parc","latitude":45.1,"longitude":5.2,"country_code":"FR","country":"France","admin1":"Isère","population":900000},
+			{"name":"Parma","latitude":44.8,"longitude":10.33,"country_code":"IT","country":"Italy","admin1":"Emilia-Romagna","population":200000},
+			{"name":"Paris","latitude":33.66,"longitude":-95.56,"country_code":"US","country":"United States","admin1":"Texas","population":25000},
+			{"name":"Paris","latitude":48.85,"longitude":2.35,"country_code":"FR","country":"France","admin1":"Île-de-France","population":2100000},
+			{"name":"Paris","latitude":48.86,"longitude":2.34,"country_code":"FR","country":"France","admin1":"Île-de-France","population":2100000}
+		]}`)(w, r)
+	}))
+	t.Cleanup(server.Close)
+	
+	geocoder := NewGeocoder(testClientConfig(), zap.NewNop())
+	geocoder.baseURL = server.URL
+	
+	matches, err := geocoder.Search(context.Background(), "Par", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	
+	var got []string
+	for _, match := range matches {
+		got = append(got, match.Name+","+match.CountryCode)
+	}
+	want := []string{"Paris,FR", "Parma,IT", "Paris,US", "Le Parc,FR"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("matches = %v, want %v", got, want)
+	}
+	if first := matches[0]; first.Latitude != 48.85 || first.Population != 2100000 || first.Region != "Île-de-France" {
+		t.Errorf("first match = %+v, want Paris with its coordinates and population", first)
+	}
+	
+	if _, err := geocoder.Search(context.Background(), " par ", 10); err != nil {
+		t.Fatalf("Search again: %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("%d geocoding requests, want the repeated query served from the cache", got)
+	}
+	if _, err := geocoder.Search(context.Background(), "Par", 5); err != nil {
+		t.Fatalf("Search with another limit: %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("%d geocoding requests, want another limit requested anew", got)
+	}
+}
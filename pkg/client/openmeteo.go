@@ -2,17 +2,36 @@ package client
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
-	"weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
 	"go.uber.org/zap"
 )
 
+// Snowfall is reported as centimeters of fresh snow, 7 cm melt to about 10 mm
+// of water
+const openMeteoSnowCmToMm = 10.0 / 7.0
+
+// Variables requested from the forecast endpoint for each block
+const (
+	openMeteoCurrentFields = "temperature_2m,relative_humidity_2m,pressure_msl,wind_speed_10m,wind_direction_10m,wind_gusts_10m,cloud_cover,visibility,weather_code"
+	openMeteoDailyFields   = "temperature_2m_max,temperature_2m_min,precipitation_sum,rain_sum,snowfall_sum,wind_gusts_10m_max,weather_code"
+	openMeteoHourlyFields  = "temperature_2m,relative_humidity_2m,wind_speed_10m,precipitation_probability,weather_code"
+)
+
+// Fields a response cannot do without, see requireFields. Single null days
+// are tolerated, a whole missing series is not.
+var (
+	openMeteoCurrentRequired  = []string{"current.temperature_2m", "current.relative_humidity_2m"}
+	openMeteoForecastRequired = []string{"daily.time", "daily.temperature_2m_max", "daily.temperature_2m_min"}
+)
+
 type OpenMeteoClient struct {
 	*BaseClient
-	baseURL string
+	baseURL  string
+	geocoder *Geocoder
 }
 
 type OpenMeteoCurrentResponse struct {
@@ -24,8 +43,11 @@ type OpenMeteoCurrentResponse struct {
 		Temperature2M float64 `json:"temperature_2m"`
 		WindSpeed10M  float64 `json:"wind_speed_10m"`
 		WindDirection float64 `json:"wind_direction_10m"`
+		WindGusts10M  float64 `json:"wind_gusts_10m"`
 		RelativeHumidity2M int `json:"relative_humidity_2m"`
 		PressureMSL    float64 `json:"pressure_msl"`
+		CloudCover    float64 `json:"cloud_cover"`
+		Visibility    *float64 `json:"visibility"` // meters, not available for every model
 		WeatherCode   int     `json:"weather_code"`
 	} `json:"current"`
 	CurrentUnits struct {
@@ -43,6 +65,9 @@ type OpenMeteoForecastResponse struct {
 		Temperature2MMax []float64 `json:"temperature_2m_max"`
 		Temperature2MMin []float64 `json:"temperature_2m_min"`
 		PrecipitationSum []float64 `json:"precipitation_sum"`
+		RainSum          []float64 `json:"rain_sum"`
+		SnowfallSum      []float64 `json:"snowfall_sum"` // centimeters
+		WindGusts10MMax  []float64 `json:"wind_gusts_10m_max"`
 		WeatherCode      []int     `json:"weather_code"`
 	} `json:"daily"`
 	DailyUnits struct {
@@ -50,47 +75,100 @@ type OpenMeteoForecastResponse struct {
 		Temperature2MMax string `json:"temperature_2m_max"`
 		Temperature2MMin string `json:"temperature_2m_min"`
 	} `json:"daily_units"`
+	Hourly struct {
+		Time                     []string  `json:"time"`
+		Temperature2M            []float64 `json:"temperature_2m"`
+		RelativeHumidity2M       []float64 `json:"relative_humidity_2m"`
+		WindSpeed10M             []float64 `json:"wind_speed_10m"`
+		PrecipitationProbability []float64 `json:"precipitation_probability"`
+		WeatherCode              []int     `json:"weather_code"`
+	} `json:"hourly"`
 }
 
-func NewOpenMeteoClient(config ClientConfig, logger *zap.Logger) *OpenMeteoClient {
+func NewOpenMeteoClient(geocoder *Geocoder, config ClientConfig, logger *zap.Logger) *OpenMeteoClient {
 	baseClient := NewBaseClient("openmeteo", config, logger)
 	return &OpenMeteoClient{
 		BaseClient: baseClient,
 		baseURL:    "https://api.open-meteo.com/v1",
+		geocoder:   geocoder,
 	}
 }
 
-func (c *OpenMeteoClient) GetCurrentWeather(ctx context.Context, city string) (*models.CurrentWeather, error) {
-	// Note: Open-Meteo requires coordinates, not city names
-	// For simplicity, we'll use hardcoded coordinates for major cities
-	coordinates := map[string]string{
-		"Prague":  "50.0755,14.4378",
-		"London":  "51.5074,-0.1278",
-		"NewYork": "40.7128,-74.0060",
-		"Tokyo":   "35.6762,139.6503",
-		"Sydney":  "-33.8688,151.2093",
-	}
-	
-	coords, ok := coordinates[city]
-	if !ok {
-		return nil, fmt.Errorf("coordinates not found for city: %s", city)
+func (c *OpenMeteoClient) Name() string {
+	return "open-meteo"
+}
+
+func (c *OpenMeteoClient) RequiresAPIKey() bool {
+	return false
+}
+
+func (c *OpenMeteoClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	coords, err := c.geocoder.Resolve(ctx, city, opts.Country)
+	if err != nil {
+		return nil, err
 	}
 	
-	url := fmt.Sprintf("%s/forecast?latitude=%s&longitude=%s&current=temperature_2m,relative_humidity_2m,pressure_msl,wind_speed_10m,wind_direction_10m,weather_code", 
-		c.baseURL, coords, coords[len(coords)/2:])
+	url := fmt.Sprintf("%s/forecast?latitude=%.4f&longitude=%.4f&current=%s&timezone=GMT&wind_speed_unit=ms", 
+		c.baseURL, coords.Latitude, coords.Longitude, openMeteoCurrentFields)
 	
-	data, err := c.GetWithRetry(ctx, url)
+	body, err := c.GetWithRetry(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch current weather: %w", err)
 	}
 	
 	var response OpenMeteoCurrentResponse
-	if err := json.Unmarshal(data, &response); err != nil {
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if err := requireFields(body.Value, openMeteoCurrentRequired...); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 	
-	currentTime, _ := time.Parse(time.RFC3339, response.Current.Time)
-	weatherDesc := c.weatherCodeToDescription(response.Current.WeatherCode)
+	return c.parseCurrent(city, coords, response, opts), nil
+}
+
+// GetWeather fetches current conditions and the forecast in a single request,
+// the forecast endpoint serves both blocks at once
+func (c *OpenMeteoClient) GetWeather(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.CurrentWeather, *models.WeatherForecast, error) {
+	coords, err := c.geocoder.Resolve(ctx, city, opts.Country)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	url := fmt.Sprintf("%s/forecast?latitude=%.4f&longitude=%.4f&current=%s&daily=%s&hourly=%s&timezone=GMT&forecast_days=%d&wind_speed_unit=ms",
+		c.baseURL, coords.Latitude, coords.Longitude, openMeteoCurrentFields, openMeteoDailyFields, openMeteoHourlyFields, days)
+	
+	body, err := c.GetWithRetry(ctx, url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch weather: %w", err)
+	}
+	
+	// Both response types read their own blocks from the same payload
+	var currentResponse OpenMeteoCurrentResponse
+	if err := decodeJSON(body.Data, &currentResponse); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	
+	var forecastResponse OpenMeteoForecastResponse
+	if err := decodeJSON(body.Data, &forecastResponse); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse forecast response: %w", err)
+	}
+	
+	if err := requireFields(body.Value, append(openMeteoCurrentRequired, openMeteoForecastRequired...)...); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	
+	return c.parseCurrent(city, coords, currentResponse, opts), c.parseForecast(city, days, forecastResponse, opts), nil
+}
+
+func (c *OpenMeteoClient) parseCurrent(city string, coords Coordinates, response OpenMeteoCurrentResponse, opts models.QueryOptions) *models.CurrentWeather {
+	if name, ok := CanonicalCityName(city); ok {
+		city = name
+	}
+	
+	// Open-Meteo times carry no seconds or offset, requested in GMT they are UTC
+	currentTime, _ := time.Parse("2006-01-02T15:04", response.Current.Time)
+	weatherDesc := c.weatherCodeToDescription(response.Current.WeatherCode, opts.LangOrDefault())
 	
 	weather := &models.CurrentWeather{
 		City:        city,
@@ -100,69 +178,157 @@ func (c *OpenMeteoClient) GetCurrentWeather(ctx context.Context, city string) (*
 		Pressure:    response.Current.PressureMSL,
 		WindSpeed:   response.Current.WindSpeed10M,
 		WindDegree:  response.Current.WindDirection,
+		WindGust:    response.Current.WindGusts10M,
+		CloudCover:  utils.Clamp(response.Current.CloudCover, 0, 100),
+		Visibility:  response.Current.Visibility,
+		Condition:   wmoConditionCode(response.Current.WeatherCode),
 		Description: weatherDesc,
 		Icon:        c.weatherCodeToIcon(response.Current.WeatherCode),
 		Timestamp:   currentTime,
-		Source:      "open-meteo",
+		Source:      c.Name(),
+		ResolvedLatitude:  response.Latitude,
+		ResolvedLongitude: response.Longitude,
+		DistanceKm:  utils.HaversineKm(coords.Latitude, coords.Longitude, response.Latitude, response.Longitude),
 	}
 	
-	return weather, nil
+	return weather
 }
 
-func (c *OpenMeteoClient) GetForecast(ctx context.Context, city string, days int) (*models.WeatherForecast, error) {
-	coordinates := map[string]string{
-		"Prague":  "50.0755,14.4378",
-		"London":  "51.5074,-0.1278",
-		"NewYork": "40.7128,-74.0060",
-		"Tokyo":   "35.6762,139.6503",
-		"Sydney":  "-33.8688,151.2093",
-	}
-	
-	coords, ok := coordinates[city]
-	if !ok {
-		return nil, fmt.Errorf("coordinates not found for city: %s", city)
+func (c *OpenMeteoClient) GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error) {
+	coords, err := c.geocoder.Resolve(ctx, city, opts.Country)
+	if err != nil {
+		return nil, err
 	}
 	
-	url := fmt.Sprintf("%s/forecast?latitude=%s&longitude=%s&daily=temperature_2m_max,temperature_2m_min,precipitation_sum,weather_code&forecast_days=%d",
-		c.baseURL, coords, coords[len(coords)/2:], days)
+	url := fmt.Sprintf("%s/forecast?latitude=%.4f&longitude=%.4f&daily=%s&hourly=%s&timezone=GMT&forecast_days=%d&wind_speed_unit=ms",
+		c.baseURL, coords.Latitude, coords.Longitude, openMeteoDailyFields, openMeteoHourlyFields, days)
 	
-	data, err := c.GetWithRetry(ctx, url)
+	body, err := c.GetWithRetry(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch forecast: %w", err)
 	}
 	
 	var response OpenMeteoForecastResponse
-	if err := json.Unmarshal(data, &response); err != nil {
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse forecast response: %w", err)
+	}
+	if err := requireFields(body.Value, openMeteoForecastRequired...); err != nil {
 		return nil, fmt.Errorf("failed to parse forecast response: %w", err)
 	}
 	
+	return c.parseForecast(city, days, response, opts), nil
+}
+
+func (c *OpenMeteoClient) parseForecast(city string, days int, response OpenMeteoForecastResponse, opts models.QueryOptions) *models.WeatherForecast {
+	if name, ok := CanonicalCityName(city); ok {
+		city = name
+	}
+	
 	forecast := &models.WeatherForecast{
 		City:     city,
 		Forecast: make([]models.ForecastDay, 0, days),
-		Source:   "open-meteo",
+		Source:   c.Name(),
+	}
+	
+	// The daily arrays are not guaranteed to be equally long, so only index
+	// as far as every one of them reaches
+	available := minLength(
+		len(response.Daily.Time),
+		len(response.Daily.Temperature2MMax),
+		len(response.Daily.Temperature2MMin),
+		len(response.Daily.PrecipitationSum),
+		len(response.Daily.WeatherCode),
+	)
+	if available < len(response.Daily.Time) {
+		c.logger.Warn("Open-Meteo daily arrays have mismatched lengths, skipping incomplete days",
+			zap.String("city", city),
+			zap.Int("days", len(response.Daily.Time)),
+			zap.Int("complete_days", available))
 	}
 	
-	for i := 0; i < days && i < len(response.Daily.Time); i++ {
+	for i := 0; i < days && i < available; i++ {
 		date, _ := time.Parse("2006-01-02", response.Daily.Time[i])
-		weatherDesc := c.weatherCodeToDescription(response.Daily.WeatherCode[i])
+		weatherDesc := c.weatherCodeToDescription(response.Daily.WeatherCode[i], opts.LangOrDefault())
 		
 		dayForecast := models.ForecastDay{
 			Date:         date,
 			MaxTemp:      response.Daily.Temperature2MMax[i],
 			MinTemp:      response.Daily.Temperature2MMin[i],
 			AvgTemp:      (response.Daily.Temperature2MMax[i] + response.Daily.Temperature2MMin[i]) / 2,
+			Condition:    wmoConditionCode(response.Daily.WeatherCode[i]),
 			Description:  weatherDesc,
 			Icon:         c.weatherCodeToIcon(response.Daily.WeatherCode[i]),
 			Precipitation: response.Daily.PrecipitationSum[i],
 		}
 		
+		// Gusts and the rain/snow split are optional, a missing value leaves the day at zero
+		if i < len(response.Daily.WindGusts10MMax) {
+			dayForecast.WindGust = response.Daily.WindGusts10MMax[i]
+		}
+		if i < len(response.Daily.RainSum) && i < len(response.Daily.SnowfallSum) {
+			dayForecast.RainSum = response.Daily.RainSum[i]
+			dayForecast.SnowfallSum = response.Daily.SnowfallSum[i] * openMeteoSnowCmToMm
+			dayForecast.HasPrecipitationSums = true
+		}
+		
 		forecast.Forecast = append(forecast.Forecast, dayForecast)
 	}
 	
-	return forecast, nil
+	forecast.Hourly = c.parseHourly(response, opts)
+	
+	return forecast
+}
+
+func (c *OpenMeteoClient) parseHourly(response OpenMeteoForecastResponse, opts models.QueryOptions) []models.HourlyPoint {
+	hourly := response.Hourly
+	available := minLength(
+		len(hourly.Time),
+		len(hourly.Temperature2M),
+		len(hourly.RelativeHumidity2M),
+		len(hourly.WindSpeed10M),
+		len(hourly.PrecipitationProbability),
+		len(hourly.WeatherCode),
+	)
+	
+	points := make([]models.HourlyPoint, 0, available)
+	for i := 0; i < available; i++ {
+		// Hourly times come without an offset, we request them in GMT
+		pointTime, err := time.Parse("2006-01-02T15:04", hourly.Time[i])
+		if err != nil {
+			continue
+		}
+		
+		points = append(points, models.HourlyPoint{
+			Time:        pointTime,
+			Temperature: hourly.Temperature2M[i],
+			Humidity:    hourly.RelativeHumidity2M[i],
+			WindSpeed:   hourly.WindSpeed10M[i],
+			PrecipitationProbability: hourly.PrecipitationProbability[i],
+			Condition:   wmoConditionCode(hourly.WeatherCode[i]),
+			Description: c.weatherCodeToDescription(hourly.WeatherCode[i], opts.LangOrDefault()),
+		})
+	}
+	
+	return points
+}
+
+func minLength(lengths ...int) int {
+	shortest := lengths[0]
+	for _, length := range lengths[1:] {
+		if length < shortest {
+			shortest = length
+		}
+	}
+	return shortest
 }
 
-func (c *OpenMeteoClient) weatherCodeToDescription(code int) string {
+func (c *OpenMeteoClient) weatherCodeToDescription(code int, lang string) string {
+	if localized, ok := localizedWeatherCodes[lang]; ok {
+		if desc, ok := localized[code]; ok {
+			return desc
+		}
+	}
+	
 	// WMO Weather interpretation codes
 	weatherCodes := map[int]string{
 		0: "Clear sky",
@@ -201,6 +367,28 @@ func (c *OpenMeteoClient) weatherCodeToDescription(code int) string {
 	return "Unknown"
 }
 
+// wmoConditionCode maps a WMO weather interpretation code to the normalized condition
+func wmoConditionCode(code int) models.ConditionCode {
+	switch {
+	case code <= 1:
+		return models.ConditionClear
+	case code <= 3:
+		return models.ConditionClouds
+	case code == 45 || code == 48:
+		return models.ConditionFog
+	case code >= 51 && code <= 57:
+		return models.ConditionDrizzle
+	case code >= 61 && code <= 67, code >= 80 && code <= 82:
+		return models.ConditionRain
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		return models.ConditionSnow
+	case code >= 95 && code <= 99:
+		return models.ConditionThunderstorm
+	default:
+		return models.ConditionUnknown
+	}
+}
+
 func (c *OpenMeteoClient) weatherCodeToIcon(code int) string {
 	// Map weather codes to icon names
 	if code == 0 {
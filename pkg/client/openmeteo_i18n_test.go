@@ -0,0 +1,24 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestWeatherCodeToDescriptionLocalized(t *testing.T) {
+	client := &OpenMeteoClient{}
+	tests := []struct {
+		code int
+		lang string
+		want string
+	}{
+		{0, "de", "Klarer Himmel"},
+		{0, "en", "Clear sky"},
+		{0, "xx", "Clear sky"}, // unsupported languages fall back to English
+		{999, "de", "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := client.weatherCodeToDescription(tt.code, tt.lang); got != tt.want {
+			t.Errorf("weatherCodeToDescription(%d, %q) = %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+// newTestOpenMeteoClient returns a client whose requests are served by handler
+func newTestOpenMeteoClient(t *testing.T, handler http.HandlerFunc) *OpenMeteoClient {
+	t.Helper()
+	
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	
+	client := NewOpenMeteoClient(NewGeocoder(testClientConfig(), zap.NewNop()), testClientConfig(), zap.NewNop())
+	client.baseURL = server.URL
+	return client
+}
+
+func TestOpenMeteoForecastFewerDaysThanRequested(t *testing.T) {
+	client := newTestOpenMeteoClient(t, respondJSON(`{"daily":{
+		"time":["2026-10-15","2026-10-16","2026-10-17"],
+		"temperature_2m_max":[20,21,22],
+		"temperature_2m_min":[10,11,12],
+		"precipitation_sum":[0,1,2],
+		"weather_code":[0,61]
+	}}`))
+	
+	forecast, err := client.GetForecast(context.Background(), "Prague", 7, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+	if len(forecast.Forecast) != 2 {
+		t.Fatalf("got %d days, want the 2 every daily array covers", len(forecast.Forecast))
+	}
+	if day := forecast.Forecast[1]; day.MaxTemp != 21 || day.Condition != models.ConditionRain || day.WindGust != 0 {
+		t.Errorf("second day = %+v, want 21°C max with rain and no gusts", day)
+	}
+}
+
+func TestOpenMeteoForecastStopsAtRequestedDays(t *testing.T) {
+	client := newTestOpenMeteoClient(t, respondJSON(`{"daily":{
+		"time":["2026-10-15","2026-10-16","2026-10-17"],
+		"temperature_2m_max":[20,21,22],
+		"temperature_2m_min":[10,11,12],
+		"precipitation_sum":[0,1,2],
+		"weather_code":[0,61,3]
+	}}`))
+	
+	forecast, err := client.GetForecast(context.Background(), "Prague", 1, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+	if len(forecast.Forecast) != 1 {
+		t.Errorf("got %d days, want 1", len(forecast.Forecast))
+	}
+}
+
+func TestWMOConditionCode(t *testing.T) {
+	tests := map[int]models.ConditionCode{
+		0:  models.ConditionClear,
+		2:  models.ConditionClouds,
+		45: models.ConditionFog,
+		53: models.ConditionDrizzle,
+		63: models.ConditionRain,
+		81: models.ConditionRain,
+		75: models.ConditionSnow,
+		86: models.ConditionSnow,
+		95: models.ConditionThunderstorm,
+		42: models.ConditionUnknown,
+	}
+	for code, want := range tests {
+		if got := wmoConditionCode(code); got != want {
+			t.Errorf("wmoConditionCode(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestOpenMeteoCurrentClampsCloudCover(t *testing.T) {
+	client := newTestOpenMeteoClient(t, respondJSON(
+		`{"current":{"temperature_2m":20,"relative_humidity_2m":50,"cloud_cover":120}}`))
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if weather.CloudCover != 100 {
+		t.Errorf("CloudCover = %v, want 120 clamped to 100", weather.CloudCover)
+	}
+}
+
+func TestOpenMeteoCurrentVisibility(t *testing.T) {
+	for body, want := range map[string]*float64{
+		`{"current":{"temperature_2m":20,"relative_humidity_2m":50,"visibility":24140}}`:  func() *float64 { v := 24140.0; return &v }(),
+		`{"current":{"temperature_2m":20,"relative_humidity_2m":50,"visibility":null}}`:   nil,
+	} {
+		client := newTestOpenMeteoClient(t, respondJSON(body))
+		weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+		if err != nil {
+			t.Fatalf("GetCurrentWeather: %v", err)
+		}
+		if (weather.Visibility == nil) != (want == nil) || (want != nil && *weather.Visibility != *want) {
+			t.Errorf("Visibility from %s = %v, want %v", body, weather.Visibility, want)
+		}
+	}
+}
+
+func TestOpenMeteoGetWeatherIssuesOneRequest(t *testing.T) {
+	var requests atomic.Int32
+	payload := respondJSON(`{
+		"current":{"time":"2026-10-15T12:00","temperature_2m":18.5,"relative_humidity_2m":60,"weather_code":3},
+		"daily":{
+			"time":["2026-10-15","2026-10-16"],
+			"temperature_2m_max":[20,21],
+			"temperature_2m_min":[10,11],
+			"precipitation_sum":[0,1],
+			"weather_code":[3,61]
+		}
+	}`)
+	client := newTestOpenMeteoClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		payload(w, r)
+	})
+	
+	current, forecast, err := client.GetWeather(context.Background(), "Prague", 2, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetWeather: %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("GetWeather made %d requests, want 1", requests.Load())
+	}
+	if current.Temperature != 18.5 || current.Condition != models.ConditionClouds {
+		t.Errorf("current = %.1f° %s, want 18.5° clouds", current.Temperature, current.Condition)
+	}
+	if want := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC); !current.Timestamp.Equal(want) {
+		t.Errorf("current timestamp = %v, want %v", current.Timestamp, want)
+	}
+	if len(forecast.Forecast) != 2 || forecast.Forecast[1].Condition != models.ConditionRain {
+		t.Errorf("forecast = %+v, want 2 days ending in rain", forecast.Forecast)
+	}
+}
+
+func TestOpenMeteoCurrentTimeIsRequestedAndParsedInUTC(t *testing.T) {
+	var timezone string
+	payload := respondJSON(`{"current":{"time":"2026-10-15T07:45","temperature_2m":18.5,"relative_humidity_2m":60}}`)
+	client := newTestOpenMeteoClient(t, func(w http.ResponseWriter, r *http.Request) {
+		timezone = r.URL.Query().Get("timezone")
+		payload(w, r)
+	})
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if timezone != "GMT" {
+		t.Errorf("timezone = %q, want GMT", timezone)
+	}
+	if want := time.Date(2026, 10, 15, 7, 45, 0, 0, time.UTC); !weather.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", weather.Timestamp, want)
+	}
+}
+
+func TestOpenMeteoMissingTemperatureIsAnError(t *testing.T) {
+	client := newTestOpenMeteoClient(t, respondJSON(`{"current":{"relative_humidity_2m":50}}`))
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if weather != nil {
+		t.Errorf("weather = %+v, want none instead of a 0° reading", weather)
+	}
+	assertMissingField(t, err, "current.temperature_2m")
+	
+	client = newTestOpenMeteoClient(t, respondJSON(`{"daily":{"time":["2026-10-15"],"temperature_2m_max":[20]}}`))
+	_, err = client.GetForecast(context.Background(), "Prague", 1, models.QueryOptions{})
+	assertMissingField(t, err, "daily.temperature_2m_min")
+}
@@ -2,18 +2,22 @@ package client
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/url"
+	"sort"
 	"time"
 
-	"weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
 	"go.uber.org/zap"
 )
 
 type OpenWeatherClient struct {
 	*BaseClient
-	apiKey string
+	keys    *apiKeyRing // rotated per request
 	baseURL string
+	oneCall bool // One Call 3.0 needs its own subscription
+	geocoder *Geocoder
 }
 
 type OpenWeatherCurrentResponse struct {
@@ -38,10 +42,12 @@ type OpenWeatherCurrentResponse struct {
 	Wind struct {
 		Speed float64 `json:"speed"`
 		Deg   float64 `json:"deg"`
+		Gust  float64 `json:"gust"`
 	} `json:"wind"`
 	Clouds struct {
 		All int `json:"all"`
 	} `json:"clouds"`
+	Visibility *float64 `json:"visibility"` // meters, omitted when unknown
 	Dt  int64  `json:"dt"`
 	Sys struct {
 		Country string `json:"country"`
@@ -51,47 +57,15 @@ type OpenWeatherCurrentResponse struct {
 	Timezone int    `json:"timezone"`
 	ID       int    `json:"id"`
 	Name     string `json:"name"`
-	Cod      int    `json:"cod"`
+	Cod      openWeatherCode    `json:"cod"`
+	Message  openWeatherMessage `json:"message"`
 }
 
 type OpenWeatherForecastResponse struct {
-	Cod     string `json:"cod"`
-	Message int    `json:"message"`
+	Cod     openWeatherCode    `json:"cod"`
+	Message openWeatherMessage `json:"message"`
 	Cnt     int    `json:"cnt"`
-	List    []struct {
-		Dt   int64 `json:"dt"`
-		Main struct {
-			Temp      float64 `json:"temp"`
-			FeelsLike float64 `json:"feels_like"`
-			TempMin   float64 `json:"temp_min"`
-			TempMax   float64 `json:"temp_max"`
-			Pressure  float64 `json:"pressure"`
-			SeaLevel  int     `json:"sea_level"`
-			GrndLevel int     `json:"grnd_level"`
-			Humidity  int     `json:"humidity"`
-			TempKf    float64 `json:"temp_kf"`
-		} `json:"main"`
-		Weather []struct {
-			ID          int    `json:"id"`
-			Main        string `json:"main"`
-			Description string `json:"description"`
-			Icon        string `json:"icon"`
-		} `json:"weather"`
-		Clouds struct {
-			All int `json:"all"`
-		} `json:"clouds"`
-		Wind struct {
-			Speed float64 `json:"speed"`
-			Deg   float64 `json:"deg"`
-			Gust  float64 `json:"gust"`
-		} `json:"wind"`
-		Visibility int     `json:"visibility"`
-		Pop        float64 `json:"pop"`
-		Sys        struct {
-			Pod string `json:"pod"`
-		} `json:"sys"`
-		DtTxt string `json:"dt_txt"`
-	} `json:"list"`
+	List    []OpenWeatherForecastItem `json:"list"`
 	City struct {
 		ID    int    `json:"id"`
 		Name  string `json:"name"`
@@ -107,30 +81,82 @@ type OpenWeatherForecastResponse struct {
 	} `json:"city"`
 }
 
-func NewOpenWeatherClient(apiKey string, config ClientConfig, logger *zap.Logger) *OpenWeatherClient {
+// OpenWeatherForecastItem is one 3-hour step of the forecast
+type OpenWeatherForecastItem struct {
+	Dt   int64 `json:"dt"`
+	Main struct {
+		Temp      float64 `json:"temp"`
+		FeelsLike float64 `json:"feels_like"`
+		TempMin   float64 `json:"temp_min"`
+		TempMax   float64 `json:"temp_max"`
+		Pressure  float64 `json:"pressure"`
+		SeaLevel  int     `json:"sea_level"`
+		GrndLevel int     `json:"grnd_level"`
+		Humidity  int     `json:"humidity"`
+		TempKf    float64 `json:"temp_kf"`
+	} `json:"main"`
+	Weather []struct {
+		ID          int    `json:"id"`
+		Main        string `json:"main"`
+		Description string `json:"description"`
+		Icon        string `json:"icon"`
+	} `json:"weather"`
+	Clouds struct {
+		All int `json:"all"`
+	} `json:"clouds"`
+	Wind struct {
+		Speed float64 `json:"speed"`
+		Deg   float64 `json:"deg"`
+		Gust  float64 `json:"gust"`
+	} `json:"wind"`
+	Visibility int     `json:"visibility"`
+	Pop        float64 `json:"pop"`
+	Sys        struct {
+		Pod string `json:"pod"`
+	} `json:"sys"`
+	DtTxt string `json:"dt_txt"`
+}
+
+// NewOpenWeatherClient creates a client that rotates through apiKeys, several
+// keys multiply the quota of free plans
+func NewOpenWeatherClient(apiKeys []string, oneCall bool, geocoder *Geocoder, config ClientConfig, logger *zap.Logger) *OpenWeatherClient {
 	baseClient := NewBaseClient("openweather", config, logger)
 	return &OpenWeatherClient{
 		BaseClient: baseClient,
-		apiKey:     apiKey,
+		keys:       newAPIKeyRing(apiKeys),
 		baseURL:    "https://api.openweathermap.org/data/2.5",
+		oneCall:    oneCall,
+		geocoder:   geocoder,
 	}
 }
 
-func (c *OpenWeatherClient) GetCurrentWeather(ctx context.Context, city string) (*models.CurrentWeather, error) {
-	url := fmt.Sprintf("%s/weather?q=%s&appid=%s&units=metric", c.baseURL, city, c.apiKey)
-	
-	data, err := c.GetWithRetry(ctx, url)
+func (c *OpenWeatherClient) Name() string {
+	return "openweathermap"
+}
+
+func (c *OpenWeatherClient) RequiresAPIKey() bool {
+	return true
+}
+
+func (c *OpenWeatherClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	body, err := c.get(ctx, func(key string) string {
+		return fmt.Sprintf("%s/weather?q=%s&appid=%s&units=metric&lang=%s", c.baseURL, openWeatherQuery(city, opts), key, opts.LangOrDefault())
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch current weather: %w", err)
 	}
 	
 	var response OpenWeatherCurrentResponse
-	if err := json.Unmarshal(data, &response); err != nil {
+	if err := decodeJSON(body.Data, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 	
 	if response.Cod != 200 {
-		return nil, fmt.Errorf("API error: %d", response.Cod)
+		return nil, openWeatherAPIError(response.Cod, response.Message)
+	}
+	
+	if err := requireFields(body.Value, "main.temp", "main.humidity"); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 	
 	weather := &models.CurrentWeather{
@@ -141,57 +167,121 @@ func (c *OpenWeatherClient) GetCurrentWeather(ctx context.Context, city string)
 		Pressure:    float64(response.Main.Pressure),
 		WindSpeed:   response.Wind.Speed,
 		WindDegree:  response.Wind.Deg,
-		Description: response.Weather[0].Description,
-		Icon:        response.Weather[0].Icon,
+		WindGust:    response.Wind.Gust, // omitted by the API in calm conditions
+		CloudCover:  utils.Clamp(float64(response.Clouds.All), 0, 100),
+		Visibility:  response.Visibility,
 		Timestamp:   time.Unix(response.Dt, 0),
-		Source:      "openweathermap",
+		Source:      c.Name(),
+		ResolvedLatitude:  response.Coord.Lat,
+		ResolvedLongitude: response.Coord.Lon,
+	}
+	
+	// A sparse response may come without weather conditions; keep the readings
+	// and leave description and icon empty rather than failing the whole call
+	if len(response.Weather) > 0 {
+		weather.Condition = openWeatherConditionCode(response.Weather[0].ID)
+		weather.Description = response.Weather[0].Description
+		weather.Icon = response.Weather[0].Icon
+	} else {
+		weather.Condition = models.ConditionUnknown
+		c.logger.Warn("OpenWeatherMap response has no weather conditions",
+			zap.String("city", city))
+	}
+	
+	// OpenWeatherMap resolves city names to its own station list
+	if coords, ok := c.geocoder.Cached(city, opts.Country); ok {
+		weather.DistanceKm = utils.HaversineKm(coords.Latitude, coords.Longitude, response.Coord.Lat, response.Coord.Lon)
 	}
 	
 	return weather, nil
 }
 
-func (c *OpenWeatherClient) GetForecast(ctx context.Context, city string, days int) (*models.WeatherForecast, error) {
-	// OpenWeatherMap provides forecast for 5 days with 3-hour intervals
-	url := fmt.Sprintf("%s/forecast?q=%s&appid=%s&units=metric&cnt=%d", c.baseURL, city, c.apiKey, days*8)
-	
-	data, err := c.GetWithRetry(ctx, url)
+// openWeatherQuery builds the escaped q parameter, "city,country" when a
+// country is given
+func openWeatherQuery(city string, opts models.QueryOptions) string {
+	if opts.Country != "" {
+		city += "," + opts.Country
+	}
+	return url.QueryEscape(city)
+}
+
+// openWeatherMaxSlots is the number of 3-hour slots the forecast endpoint returns at most
+const openWeatherMaxSlots = 40
+
+func (c *OpenWeatherClient) GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error) {
+	// OpenWeatherMap provides forecast for 5 days with 3-hour intervals,
+	// longer horizons get every available slot
+	slots := days * 8
+	if slots > openWeatherMaxSlots {
+		slots = openWeatherMaxSlots
+	}
+	body, err := c.get(ctx, func(key string) string {
+		return fmt.Sprintf("%s/forecast?q=%s&appid=%s&units=metric&cnt=%d&lang=%s", c.baseURL, openWeatherQuery(city, opts), key, slots, opts.LangOrDefault())
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch forecast: %w", err)
 	}
 	
 	var response OpenWeatherForecastResponse
-	if err := json.Unmarshal(data, &response); err != nil {
+	if err := decodeJSON(body.Data, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse forecast response: %w", err)
 	}
 	
-	if response.Cod != "200" {
-		return nil, fmt.Errorf("API error: %s", response.Cod)
+	if response.Cod != 200 {
+		return nil, openWeatherAPIError(response.Cod, response.Message)
 	}
 	
-	// Group forecast by day
-	forecastByDay := make(map[string][]OpenWeatherForecastResponse.List)
+	if err := requireFields(body.Value, "list[].main.temp"); err != nil {
+		return nil, fmt.Errorf("failed to parse forecast response: %w", err)
+	}
+	
+	// Group forecast by day, keeping the dates in order as map iteration does not
+	forecastByDay := make(map[string][]OpenWeatherForecastItem)
+	var dates []string
 	for _, item := range response.List {
 		date := time.Unix(item.Dt, 0).Format("2006-01-02")
+		if _, ok := forecastByDay[date]; !ok {
+			dates = append(dates, date)
+		}
 		forecastByDay[date] = append(forecastByDay[date], item)
 	}
+	sort.Strings(dates)
 	
 	forecast := &models.WeatherForecast{
 		City:     response.City.Name,
 		Forecast: make([]models.ForecastDay, 0, days),
-		Source:   "openweathermap",
+		Hourly:   make([]models.HourlyPoint, 0, len(response.List)),
+		Source:   c.Name(),
+	}
+	
+	// The 3-hour slots double as the hourly series
+	for _, item := range response.List {
+		point := models.HourlyPoint{
+			Time:        time.Unix(item.Dt, 0).UTC(),
+			Temperature: item.Main.Temp,
+			Humidity:    float64(item.Main.Humidity),
+			WindSpeed:   item.Wind.Speed,
+			PrecipitationProbability: item.Pop * 100,
+		}
+		if len(item.Weather) > 0 {
+			point.Condition = openWeatherConditionCode(item.Weather[0].ID)
+			point.Description = item.Weather[0].Description
+		}
+		forecast.Hourly = append(forecast.Hourly, point)
 	}
 	
 	// Calculate daily aggregates
-	for dateStr, items := range forecastByDay {
+	for _, dateStr := range dates {
 		if len(forecast.Forecast) >= days {
 			break
 		}
 		
+		items := forecastByDay[dateStr]
 		date, _ := time.Parse("2006-01-02", dateStr)
 		var dayForecast models.ForecastDay
 		dayForecast.Date = date
 		
-		var totalTemp, maxTemp, minTemp, totalHumidity float64
+		var totalTemp, maxTemp, minTemp, totalHumidity, maxGust float64
 		maxTemp = -100
 		minTemp = 100
 		
@@ -200,6 +290,10 @@ func (c *OpenWeatherClient) GetForecast(ctx context.Context, city string, days i
 			totalTemp += temp
 			totalHumidity += float64(item.Main.Humidity)
 			
+			if item.Wind.Gust > maxGust {
+				maxGust = item.Wind.Gust
+			}
+			
 			if temp > maxTemp {
 				maxTemp = temp
 			}
@@ -212,15 +306,43 @@ func (c *OpenWeatherClient) GetForecast(ctx context.Context, city string, days i
 		dayForecast.MaxTemp = maxTemp
 		dayForecast.MinTemp = minTemp
 		dayForecast.Humidity = totalHumidity / float64(len(items))
+		dayForecast.WindGust = maxGust
 		
-		// Use the most common weather description for the day
-		if len(items) > 0 && len(items[0].Weather) > 0 {
-			dayForecast.Description = items[0].Weather[0].Description
-			dayForecast.Icon = items[0].Weather[0].Icon
+		// Use the first slot of the day that carries weather conditions
+		for _, item := range items {
+			if len(item.Weather) > 0 {
+				dayForecast.Condition = openWeatherConditionCode(item.Weather[0].ID)
+				dayForecast.Description = item.Weather[0].Description
+				dayForecast.Icon = item.Weather[0].Icon
+				break
+			}
 		}
 		
 		forecast.Forecast = append(forecast.Forecast, dayForecast)
 	}
 	
 	return forecast, nil
+}
+
+// openWeatherConditionCode maps an OpenWeatherMap condition ID to the normalized condition
+// See https://openweathermap.org/weather-conditions
+func openWeatherConditionCode(id int) models.ConditionCode {
+	switch {
+	case id >= 200 && id < 300:
+		return models.ConditionThunderstorm
+	case id >= 300 && id < 400:
+		return models.ConditionDrizzle
+	case id >= 500 && id < 600:
+		return models.ConditionRain
+	case id >= 600 && id < 700:
+		return models.ConditionSnow
+	case id >= 700 && id < 800:
+		return models.ConditionFog
+	case id == 800:
+		return models.ConditionClear
+	case id > 800 && id < 900:
+		return models.ConditionClouds
+	default:
+		return models.ConditionUnknown
+	}
 }
\ No newline at end of file
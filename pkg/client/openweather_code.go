@@ -0,0 +1,51 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// openWeatherCode is the "cod" status field, which OpenWeatherMap sends as a
+// number on some endpoints and as a string on others and in error payloads
+type openWeatherCode int
+
+func (c *openWeatherCode) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*c = 0
+		return nil
+	}
+	
+	code, err := strconv.Atoi(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid cod value %s", data)
+	}
+	*c = openWeatherCode(code)
+	return nil
+}
+
+// openWeatherMessage is the "message" field, an error text on failures but a
+// number on successful forecast responses
+type openWeatherMessage string
+
+func (m *openWeatherMessage) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = openWeatherMessage(text)
+		return nil
+	}
+	
+	// Not an error text, keep the raw number
+	*m = openWeatherMessage(data)
+	return nil
+}
+
+// openWeatherAPIError formats a non-200 cod together with the API's message
+func openWeatherAPIError(code openWeatherCode, message openWeatherMessage) error {
+	if message == "" {
+		return fmt.Errorf("API error: %d", code)
+	}
+	return fmt.Errorf("API error: %d: %s", code, message)
+}
\ No newline at end of file
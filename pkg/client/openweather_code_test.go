@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestOpenWeatherCodeUnmarshal(t *testing.T) {
+	tests := map[string]openWeatherCode{
+		`200`:   200,
+		`"200"`: 200,
+		`"404"`: 404,
+		`null`:  0,
+	}
+	for input, want := range tests {
+		var code openWeatherCode
+		if err := json.Unmarshal([]byte(input), &code); err != nil || code != want {
+			t.Errorf("unmarshal %s = %d, %v, want %d", input, code, err, want)
+		}
+	}
+	
+	var code openWeatherCode
+	if err := json.Unmarshal([]byte(`"ok"`), &code); err == nil {
+		t.Error(`unmarshal "ok" succeeded, want an error`)
+	}
+}
+
+func TestOpenWeatherMessageUnmarshal(t *testing.T) {
+	tests := map[string]openWeatherMessage{
+		`"city not found"`: "city not found",
+		`0`:                "0",
+	}
+	for input, want := range tests {
+		var message openWeatherMessage
+		if err := json.Unmarshal([]byte(input), &message); err != nil || message != want {
+			t.Errorf("unmarshal %s = %q, %v, want %q", input, message, err, want)
+		}
+	}
+}
+
+func TestOpenWeatherStringCodeErrorSurfacesMessage(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"key"}, respondJSON(`{"cod":"404","message":"city not found"}`))
+	
+	_, err := client.GetCurrentWeather(context.Background(), "Atlantis", models.QueryOptions{})
+	if err == nil || !strings.Contains(err.Error(), "404: city not found") {
+		t.Errorf("err = %v, want the API's code and message", err)
+	}
+}
+
+func TestOpenWeatherNumericCodeSucceeds(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"key"}, respondJSON(
+		`{"cod":200,"name":"Prague","main":{"temp":21.5,"humidity":40},"weather":[{"id":800,"description":"clear sky"}],"dt":1700000000}`))
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if weather.Temperature != 21.5 {
+		t.Errorf("temperature = %v, want 21.5", weather.Temperature)
+	}
+}
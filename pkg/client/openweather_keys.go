@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+// keyCooldown is how long a key rejected as invalid or rate limited is skipped
+const keyCooldown = time.Minute
+
+// apiKey is one of the configured keys and its usage
+type apiKey struct {
+	value      string
+	requests   int64     // requests sent with the key
+	rejections int64     // of those answered with 401 or 429
+	skipUntil  time.Time // zero while the key is usable
+}
+
+// apiKeyRing hands out keys round-robin, skipping keys that were recently
+// rejected
+type apiKeyRing struct {
+	mu   sync.Mutex
+	keys []apiKey
+	next int // index the next rotation starts at
+}
+
+func newAPIKeyRing(keys []string) *apiKeyRing {
+	ring := &apiKeyRing{keys: make([]apiKey, len(keys))}
+	for i, key := range keys {
+		ring.keys[i].value = key
+	}
+	return ring
+}
+
+// order returns the indexes of the keys to try for one request, in rotation
+// order and without the keys cooling down. When every key is cooling down all
+// of them are returned, the cooldown is only a guess at the provider's window.
+func (r *apiKeyRing) order(now time.Time) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	start := r.next
+	r.next = (r.next + 1) % len(r.keys)
+	
+	all := make([]int, 0, len(r.keys))
+	usable := make([]int, 0, len(r.keys))
+	for n := 0; n < len(r.keys); n++ {
+		i := (start + n) % len(r.keys)
+		all = append(all, i)
+		if !now.Before(r.keys[i].skipUntil) {
+			usable = append(usable, i)
+		}
+	}
+	
+	if len(usable) == 0 {
+		return all
+	}
+	return usable
+}
+
+// use returns the key at index i and counts a request made with it
+func (r *apiKeyRing) use(i int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	r.keys[i].requests++
+	return r.keys[i].value
+}
+
+// reject skips the key at index i for keyCooldown and returns its request and
+// rejection counts
+func (r *apiKeyRing) reject(i int, now time.Time) (requests, rejections int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	key := &r.keys[i]
+	key.rejections++
+	key.skipUntil = now.Add(keyCooldown)
+	return key.requests, key.rejections
+}
+
+// usage reports the counts of every key and whether it is cooling down
+func (r *apiKeyRing) usage(now time.Time) []models.APIKeyUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	usage := make([]models.APIKeyUsage, len(r.keys))
+	for i, key := range r.keys {
+		usage[i] = models.APIKeyUsage{Key: i + 1, Requests: key.requests, Rejections: key.rejections}
+		if now.Before(key.skipUntil) {
+			skipUntil := key.skipUntil
+			usage[i].SkippedUntil = &skipUntil
+		}
+	}
+	return usage
+}
+
+// KeyUsage returns the requests and rejections of each configured API key,
+// nil with a single key whose counts match the provider's own usage
+func (c *OpenWeatherClient) KeyUsage() []models.APIKeyUsage {
+	if len(c.keys.keys) < 2 {
+		return nil
+	}
+	return c.keys.usage(time.Now())
+}
+
+// get fetches the URL built for one key at a time, moving on to the next key
+// when one is rejected as invalid (401) or rate limited (429). Only the last
+// key tried retries a 429 after backing off. A rejection concerns the key
+// rather than the provider, so it does not count against the circuit breaker.
+func (c *OpenWeatherClient) get(ctx context.Context, buildURL func(key string) string) (JSONBody, error) {
+	order := c.keys.order(time.Now())
+	ctx = withKeyRejectionsExcused(ctx)
+	
+	var lastErr error
+	for n, i := range order {
+		reqCtx := ctx
+		if n < len(order)-1 {
+			reqCtx = withoutRateLimitRetry(ctx)
+		}
+		
+		body, err := c.GetWithRetry(reqCtx, buildURL(c.keys.use(i)))
+		
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) ||
+			(statusErr.StatusCode != http.StatusUnauthorized && statusErr.StatusCode != http.StatusTooManyRequests) {
+			return body, err
+		}
+		
+		requests, rejections := c.keys.reject(i, time.Now())
+		c.logger.Warn("OpenWeatherMap API key rejected, skipping it",
+			zap.Int("key", i+1),
+			zap.Int("status", statusErr.StatusCode),
+			zap.Int64("requests", requests),
+			zap.Int64("rejections", rejections),
+			zap.Duration("cooldown", keyCooldown))
+		lastErr = err
+	}
+	
+	return JSONBody{}, lastErr
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+const openWeatherCurrentBody = `{"cod":200,"main":{"temp":12,"humidity":70},"weather":[{"id":800,"description":"clear sky"}]}`
+
+// keyServer answers with openWeatherCurrentBody and records the appid of every
+// request, keys in rejected are answered with their status instead
+type keyServer struct {
+	mu       sync.Mutex
+	keys     []string
+	rejected map[string]int
+}
+
+func (s *keyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("appid")
+	s.mu.Lock()
+	s.keys = append(s.keys, key)
+	status := s.rejected[key]
+	s.mu.Unlock()
+	
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	respondJSON(openWeatherCurrentBody)(w, r)
+}
+
+func (s *keyServer) used() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	used := s.keys
+	s.keys = nil
+	return used
+}
+
+func TestOpenWeatherRotatesKeys(t *testing.T) {
+	server := &keyServer{}
+	client := newTestOpenWeatherClient(t, []string{"a", "b", "c"}, server.ServeHTTP)
+	
+	for i := 0; i < 6; i++ {
+		if _, err := client.GetCurrentWeather(context.Background(), "London", models.QueryOptions{}); err != nil {
+			t.Fatalf("GetCurrentWeather: %v", err)
+		}
+	}
+	if used := server.used(); !slices.Equal(used, []string{"a", "b", "c", "a", "b", "c"}) {
+		t.Errorf("keys used = %v, want a round-robin over a, b and c", used)
+	}
+	
+	for _, usage := range client.KeyUsage() {
+		if usage.Requests != 2 || usage.Rejections != 0 || usage.SkippedUntil != nil {
+			t.Errorf("key %d usage = %+v, want 2 requests and no rejection", usage.Key, usage)
+		}
+	}
+}
+
+func TestOpenWeatherFailsOverOnRejectedKey(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusUnauthorized} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := &keyServer{rejected: map[string]int{"a": status}}
+			client := newTestOpenWeatherClient(t, []string{"a", "b"}, server.ServeHTTP)
+			
+			if _, err := client.GetCurrentWeather(context.Background(), "London", models.QueryOptions{}); err != nil {
+				t.Fatalf("GetCurrentWeather: %v", err)
+			}
+			if used := server.used(); !slices.Equal(used, []string{"a", "b"}) {
+				t.Errorf("keys used = %v, want a rejected once and b taking over", used)
+			}
+			
+			// The rejected key cools down, every request goes to b meanwhile
+			for i := 0; i < 3; i++ {
+				if _, err := client.GetCurrentWeather(context.Background(), "London", models.QueryOptions{}); err != nil {
+					t.Fatalf("GetCurrentWeather: %v", err)
+				}
+			}
+			if used := server.used(); !slices.Equal(used, []string{"b", "b", "b"}) {
+				t.Errorf("keys used = %v during the cooldown, want only b", used)
+			}
+			
+			usage := client.KeyUsage()
+			if usage[0].Rejections != 1 || usage[0].SkippedUntil == nil {
+				t.Errorf("key 1 usage = %+v, want a rejection and a cooldown", usage[0])
+			}
+			if usage[1].Requests != 4 || usage[1].Rejections != 0 {
+				t.Errorf("key 2 usage = %+v, want 4 requests and no rejection", usage[1])
+			}
+		})
+	}
+}
+
+func TestRejectedKeysDoNotTripBreaker(t *testing.T) {
+	backend := httptest.NewServer(&keyServer{rejected: map[string]int{"a": http.StatusUnauthorized}})
+	t.Cleanup(backend.Close)
+	
+	config := testClientConfig()
+	config.Threshold = 2
+	client := NewOpenWeatherClient([]string{"a", "b"}, false, NewGeocoder(testClientConfig(), zap.NewNop()), config, zap.NewNop())
+	client.baseURL = backend.URL
+	
+	for i := 0; i < 5; i++ {
+		client.keys.keys[0].skipUntil = time.Time{} // ask the rejecting key every time
+		if _, err := client.GetCurrentWeather(context.Background(), "London", models.QueryOptions{}); err != nil {
+			t.Fatalf("GetCurrentWeather: %v", err)
+		}
+	}
+	if state := client.BreakerState(); state != "closed" {
+		t.Errorf("breaker %s after rejections of one key, want closed", state)
+	}
+}
+
+func TestAPIKeyRingWithEveryKeyCoolingDown(t *testing.T) {
+	ring := newAPIKeyRing([]string{"a", "b"})
+	now := time.Now()
+	ring.reject(0, now)
+	ring.reject(1, now)
+	
+	if order := ring.order(now); len(order) != 2 {
+		t.Errorf("order = %v with every key cooling down, want all keys", order)
+	}
+	if order := ring.order(now.Add(keyCooldown)); len(order) != 2 {
+		t.Errorf("order = %v after the cooldown, want both keys back", order)
+	}
+}
+
+func TestKeyUsageWithSingleKey(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"a"}, respondJSON(openWeatherCurrentBody))
+	if usage := client.KeyUsage(); usage != nil {
+		t.Errorf("KeyUsage = %v with a single key, want nil", usage)
+	}
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"go.uber.org/zap"
+)
+
+const oneCallURL = "https://api.openweathermap.org/data/3.0/onecall"
+
+type openWeatherCondition struct {
+	ID          int    `json:"id"`
+	Main        string `json:"main"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+}
+
+// OpenWeatherOneCallResponse is the One Call API 3.0 payload
+type OpenWeatherOneCallResponse struct {
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Current struct {
+		Dt         int64    `json:"dt"`
+		Temp       float64  `json:"temp"`
+		FeelsLike  float64  `json:"feels_like"`
+		Pressure   float64  `json:"pressure"`
+		Humidity   float64  `json:"humidity"`
+		Clouds     float64  `json:"clouds"`
+		Visibility *float64 `json:"visibility"`
+		WindSpeed  float64  `json:"wind_speed"`
+		WindDeg    float64  `json:"wind_deg"`
+		WindGust   float64  `json:"wind_gust"`
+		Weather    []openWeatherCondition `json:"weather"`
+	} `json:"current"`
+	Hourly []struct {
+		Dt        int64   `json:"dt"`
+		Temp      float64 `json:"temp"`
+		Humidity  float64 `json:"humidity"`
+		WindSpeed float64 `json:"wind_speed"`
+		Pop       float64 `json:"pop"`
+		Weather   []openWeatherCondition `json:"weather"`
+	} `json:"hourly"`
+	Daily []struct {
+		Dt   int64 `json:"dt"`
+		Temp struct {
+			Day float64 `json:"day"`
+			Min float64 `json:"min"`
+			Max float64 `json:"max"`
+		} `json:"temp"`
+		Humidity float64 `json:"humidity"`
+		WindGust float64 `json:"wind_gust"`
+		Rain     float64 `json:"rain"` // mm, omitted when dry
+		Snow     float64 `json:"snow"`
+		Weather  []openWeatherCondition `json:"weather"`
+	} `json:"daily"`
+}
+
+// GetWeather uses One Call to get current conditions and the forecast in one
+// request. Without One Call, or for cities that cannot be geocoded, it falls
+// back to the separate current and forecast endpoints.
+func (c *OpenWeatherClient) GetWeather(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.CurrentWeather, *models.WeatherForecast, error) {
+	if !c.oneCall {
+		return c.getWeatherSeparately(ctx, city, days, opts)
+	}
+	
+	coords, err := c.geocoder.Resolve(ctx, city, opts.Country)
+	if err != nil {
+		c.logger.Debug("Geocoding failed, using the city name endpoints",
+			zap.String("city", city),
+			zap.Error(err))
+		return c.getWeatherSeparately(ctx, city, days, opts)
+	}
+	
+	body, err := c.get(ctx, func(key string) string {
+		return fmt.Sprintf("%s?lat=%.4f&lon=%.4f&exclude=minutely,alerts&appid=%s&units=metric&lang=%s",
+			oneCallURL, coords.Latitude, coords.Longitude, key, opts.LangOrDefault())
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch one call weather: %w", err)
+	}
+	
+	var response OpenWeatherOneCallResponse
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse one call response: %w", err)
+	}
+	
+	if err := requireFields(body.Value, "current.temp", "current.humidity", "daily[].temp.min", "daily[].temp.max"); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse one call response: %w", err)
+	}
+	
+	current := &models.CurrentWeather{
+		City:        city,
+		Temperature: response.Current.Temp,
+		FeelsLike:   response.Current.FeelsLike,
+		Humidity:    response.Current.Humidity,
+		Pressure:    response.Current.Pressure,
+		WindSpeed:   response.Current.WindSpeed,
+		WindDegree:  response.Current.WindDeg,
+		WindGust:    response.Current.WindGust,
+		CloudCover:  utils.Clamp(response.Current.Clouds, 0, 100),
+		Visibility:  response.Current.Visibility,
+		Condition:   models.ConditionUnknown,
+		Timestamp:   time.Unix(response.Current.Dt, 0),
+		Source:      c.Name(),
+		ResolvedLatitude:  response.Lat,
+		ResolvedLongitude: response.Lon,
+		DistanceKm:  utils.HaversineKm(coords.Latitude, coords.Longitude, response.Lat, response.Lon),
+	}
+	if len(response.Current.Weather) > 0 {
+		current.Condition = openWeatherConditionCode(response.Current.Weather[0].ID)
+		current.Description = response.Current.Weather[0].Description
+		current.Icon = response.Current.Weather[0].Icon
+	}
+	
+	forecast := &models.WeatherForecast{
+		City:     city,
+		Forecast: make([]models.ForecastDay, 0, days),
+		Hourly:   make([]models.HourlyPoint, 0, len(response.Hourly)),
+		Source:   c.Name(),
+	}
+	
+	for _, item := range response.Hourly {
+		point := models.HourlyPoint{
+			Time:        time.Unix(item.Dt, 0).UTC(),
+			Temperature: item.Temp,
+			Humidity:    item.Humidity,
+			WindSpeed:   item.WindSpeed,
+			PrecipitationProbability: item.Pop * 100,
+		}
+		if len(item.Weather) > 0 {
+			point.Condition = openWeatherConditionCode(item.Weather[0].ID)
+			point.Description = item.Weather[0].Description
+		}
+		forecast.Hourly = append(forecast.Hourly, point)
+	}
+	
+	for i := 0; i < days && i < len(response.Daily); i++ {
+		item := response.Daily[i]
+		dateStr := time.Unix(item.Dt, 0).UTC().Format("2006-01-02")
+		date, _ := time.Parse("2006-01-02", dateStr)
+		
+		dayForecast := models.ForecastDay{
+			Date:          date,
+			MaxTemp:       item.Temp.Max,
+			MinTemp:       item.Temp.Min,
+			AvgTemp:       item.Temp.Day,
+			Humidity:      item.Humidity,
+			Condition:     models.ConditionUnknown,
+			Precipitation: item.Rain + item.Snow,
+			RainSum:       item.Rain,
+			SnowfallSum:   item.Snow,
+			HasPrecipitationSums: true,
+			WindGust:      item.WindGust,
+		}
+		if len(item.Weather) > 0 {
+			dayForecast.Condition = openWeatherConditionCode(item.Weather[0].ID)
+			dayForecast.Description = item.Weather[0].Description
+			dayForecast.Icon = item.Weather[0].Icon
+		}
+		
+		forecast.Forecast = append(forecast.Forecast, dayForecast)
+	}
+	
+	return current, forecast, nil
+}
+
+func (c *OpenWeatherClient) getWeatherSeparately(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.CurrentWeather, *models.WeatherForecast, error) {
+	current, currentErr := c.GetCurrentWeather(ctx, city, opts)
+	if currentErr != nil {
+		c.logger.Warn("Failed to fetch current weather",
+			zap.String("city", city),
+			zap.Error(currentErr))
+	}
+	
+	forecast, forecastErr := c.GetForecast(ctx, city, days, opts)
+	if forecastErr != nil {
+		c.logger.Warn("Failed to fetch forecast",
+			zap.String("city", city),
+			zap.Error(forecastErr))
+	}
+	
+	if currentErr != nil {
+		return current, forecast, currentErr
+	}
+	return current, forecast, forecastErr
+}
\ No newline at end of file
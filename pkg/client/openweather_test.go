@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+// testClientConfig retries right away and never trips the breaker
+func testClientConfig() ClientConfig {
+	return ClientConfig{
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+		Multiplier: 2,
+	}
+}
+
+// newTestOpenWeatherClient returns a client whose requests are served by handler
+func newTestOpenWeatherClient(t *testing.T, keys []string, handler http.HandlerFunc) *OpenWeatherClient {
+	t.Helper()
+	
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	
+	geocoder := NewGeocoder(testClientConfig(), zap.NewNop())
+	client := NewOpenWeatherClient(keys, false, geocoder, testClientConfig(), zap.NewNop())
+	client.baseURL = server.URL
+	return client
+}
+
+// respondJSON returns a handler answering every request with body
+func respondJSON(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func TestOpenWeatherCurrentWithoutWeatherConditions(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"key"}, respondJSON(
+		`{"cod":200,"name":"Prague","main":{"temp":21.5,"humidity":40},"weather":[],"dt":1700000000}`))
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if weather.Temperature != 21.5 || weather.Humidity != 40 {
+		t.Errorf("readings = %v°C %v%%, want 21.5°C 40%%", weather.Temperature, weather.Humidity)
+	}
+	if weather.Condition != models.ConditionUnknown || weather.Description != "" || weather.Icon != "" {
+		t.Errorf("condition %q description %q icon %q, want unknown and empty", weather.Condition, weather.Description, weather.Icon)
+	}
+}
+
+func TestOpenWeatherForecastDaySkipsSlotsWithoutConditions(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"key"}, respondJSON(`{"cod":"200","list":[
+		{"dt":1700049600,"main":{"temp":10,"humidity":50},"weather":[]},
+		{"dt":1700056800,"main":{"temp":12,"humidity":50},"weather":[{"id":500,"description":"light rain","icon":"10d"}]}
+	]}`))
+	
+	forecast, err := client.GetForecast(context.Background(), "Prague", 1, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+	if len(forecast.Forecast) != 1 {
+		t.Fatalf("got %d forecast days, want 1", len(forecast.Forecast))
+	}
+	day := forecast.Forecast[0]
+	if day.Condition != models.ConditionRain || day.Description != "light rain" || day.Icon != "10d" {
+		t.Errorf("day condition %q %q %q, want the second slot's rain", day.Condition, day.Description, day.Icon)
+	}
+	if day.MinTemp != 10 || day.MaxTemp != 12 {
+		t.Errorf("day temperatures %v to %v, want 10 to 12 from every slot", day.MinTemp, day.MaxTemp)
+	}
+}
+
+func TestOpenWeatherForecastDaysAreInDateOrder(t *testing.T) {
+	// Noon UTC on five consecutive days starting 2023-11-15
+	var items []string
+	for i := 0; i < 5; i++ {
+		items = append(items, fmt.Sprintf(`{"dt":%d,"main":{"temp":%d,"humidity":50},"weather":[{"id":800}]}`, 1700049600+i*86400, 10+i))
+	}
+	client := newTestOpenWeatherClient(t, []string{"key"}, respondJSON(`{"cod":"200","list":[`+strings.Join(items, ",")+`]}`))
+	
+	for attempt := 0; attempt < 20; attempt++ {
+		forecast, err := client.GetForecast(context.Background(), "Prague", 3, models.QueryOptions{})
+		if err != nil {
+			t.Fatalf("GetForecast: %v", err)
+		}
+		if len(forecast.Forecast) != 3 {
+			t.Fatalf("got %d forecast days, want 3", len(forecast.Forecast))
+		}
+		for i, day := range forecast.Forecast {
+			if want := time.Unix(1700049600+int64(i)*86400, 0).Format("2006-01-02"); day.Date.Format("2006-01-02") != want {
+				t.Fatalf("day %d = %s, want %s, the days in order from the first date", i, day.Date.Format("2006-01-02"), want)
+			}
+		}
+	}
+}
+
+func TestOpenWeatherRequestsDescriptionsInLang(t *testing.T) {
+	var lang string
+	client := newTestOpenWeatherClient(t, []string{"key"}, func(w http.ResponseWriter, r *http.Request) {
+		lang = r.URL.Query().Get("lang")
+		respondJSON(`{"cod":200,"main":{"temp":20,"humidity":40},"weather":[{"id":800,"description":"klarer Himmel"}]}`)(w, r)
+	})
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{Lang: "de"})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if lang != "de" || weather.Description != "klarer Himmel" {
+		t.Errorf("requested lang %q got description %q, want de and the provider's German text", lang, weather.Description)
+	}
+}
+
+func TestOpenWeatherConditionCode(t *testing.T) {
+	tests := map[int]models.ConditionCode{
+		211: models.ConditionThunderstorm,
+		301: models.ConditionDrizzle,
+		502: models.ConditionRain,
+		601: models.ConditionSnow,
+		741: models.ConditionFog,
+		800: models.ConditionClear,
+		804: models.ConditionClouds,
+		100: models.ConditionUnknown,
+	}
+	for id, want := range tests {
+		if got := openWeatherConditionCode(id); got != want {
+			t.Errorf("openWeatherConditionCode(%d) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestOpenWeatherWindGusts(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"key"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/weather" {
+			respondJSON(`{"cod":200,"main":{"temp":20,"humidity":40},"wind":{"speed":5,"gust":9.5}}`)(w, r)
+			return
+		}
+		respondJSON(`{"cod":"200","list":[
+			{"dt":1700049600,"main":{"temp":10,"humidity":50},"wind":{"gust":7}},
+			{"dt":1700056800,"main":{"temp":12,"humidity":50},"wind":{"gust":11}}
+		]}`)(w, r)
+	})
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if weather.WindGust != 9.5 {
+		t.Errorf("current WindGust = %v, want 9.5", weather.WindGust)
+	}
+	
+	forecast, err := client.GetForecast(context.Background(), "Prague", 1, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+	if gust := forecast.Forecast[0].WindGust; gust != 11 {
+		t.Errorf("forecast day WindGust = %v, want the strongest slot's 11", gust)
+	}
+}
+
+func TestOpenWeatherCloudCover(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"key"}, respondJSON(
+		`{"cod":200,"main":{"temp":20,"humidity":40},"clouds":{"all":75}}`))
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if weather.CloudCover != 75 {
+		t.Errorf("CloudCover = %v, want 75", weather.CloudCover)
+	}
+}
+
+func TestOpenWeatherQualifiesCityWithCountry(t *testing.T) {
+	var query string
+	client := newTestOpenWeatherClient(t, []string{"key"}, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+		respondJSON(`{"cod":200,"main":{"temp":12,"humidity":70},"weather":[{"id":800,"description":"clear sky"}]}`)(w, r)
+	})
+	
+	if _, err := client.GetCurrentWeather(context.Background(), "London", models.QueryOptions{Country: "CA"}); err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if query != "London,CA" {
+		t.Errorf("q = %q, want London,CA", query)
+	}
+}
+
+func TestOpenWeatherEscapesCity(t *testing.T) {
+	var query url.Values
+	client := newTestOpenWeatherClient(t, []string{"key"}, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		respondJSON(`{"cod":200,"main":{"temp":12,"humidity":70},"weather":[{"id":800,"description":"clear sky"}]}`)(w, r)
+	})
+	
+	for _, city := range []string{"New York", "St. John's", "Saint-Étienne", "Prague&units=imperial", "Prague#fragment"} {
+		if _, err := client.GetCurrentWeather(context.Background(), city, models.QueryOptions{}); err != nil {
+			t.Fatalf("GetCurrentWeather(%q): %v", city, err)
+		}
+		if q := query.Get("q"); q != city {
+			t.Errorf("q = %q, want %q", q, city)
+		}
+		if units := query.Get("units"); units != "metric" {
+			t.Errorf("%q: units = %q, want the client's metric", city, units)
+		}
+	}
+}
+
+func TestOpenWeatherMissingTemperatureIsAnError(t *testing.T) {
+	client := newTestOpenWeatherClient(t, []string{"key"}, respondJSON(
+		`{"cod":200,"name":"Prague","main":{"humidity":40},"weather":[],"dt":1700000000}`))
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if weather != nil {
+		t.Errorf("weather = %+v, want none instead of a 0° reading", weather)
+	}
+	assertMissingField(t, err, "main.temp")
+	
+	client = newTestOpenWeatherClient(t, []string{"key"}, respondJSON(`{"cod":"200","list":[
+		{"dt":1700049600,"main":{"temp":10,"humidity":50},"weather":[]},
+		{"dt":1700056800,"main":{"humidity":50},"weather":[]}
+	]}`))
+	_, err = client.GetForecast(context.Background(), "Prague", 1, models.QueryOptions{})
+	assertMissingField(t, err, "list[].main.temp")
+}
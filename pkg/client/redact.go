@@ -0,0 +1,55 @@
+package client
+
+import (
+	"net/url"
+	"strings"
+)
+
+// secretParams are query parameters that carry credentials
+var secretParams = []string{"appid", "key", "apikey", "api_key", "token"}
+
+const (
+	redactedValue  = "REDACTED"
+	maxLoggedBody  = 512
+)
+
+// redactURL masks credentials in a request URL before it is logged
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "<unparseable url>"
+	}
+	
+	query := parsed.Query()
+	changed := false
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Set(name, redactedValue)
+				changed = true
+			}
+		}
+	}
+	
+	if changed {
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String()
+}
+
+// redactError masks credentials in the URL net/http embeds in its errors
+func redactError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		redacted := *urlErr
+		redacted.URL = redactURL(urlErr.URL)
+		return &redacted
+	}
+	return err
+}
+
+func truncateBody(body []byte) string {
+	if len(body) <= maxLoggedBody {
+		return string(body)
+	}
+	return string(body[:maxLoggedBody]) + "...(truncated)"
+}
\ No newline at end of file
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com/weather?q=Prague&appid=secret": "https://api.example.com/weather?appid=REDACTED&q=Prague",
+		"https://api.example.com/weather?KEY=secret&q=Prague":   "https://api.example.com/weather?KEY=REDACTED&q=Prague",
+		"https://api.example.com/weather?q=Prague":              "https://api.example.com/weather?q=Prague",
+	}
+	for raw, want := range tests {
+		if got := redactURL(raw); got != want {
+			t.Errorf("redactURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestRequestLoggingMasksAPIKey(t *testing.T) {
+	server := httptest.NewServer(respondJSON(`{"cod":200,"name":"Prague"}`))
+	t.Cleanup(server.Close)
+	
+	for _, logBodies := range []bool{false, true} {
+		core, logs := observer.New(zapcore.DebugLevel)
+		config := testClientConfig()
+		config.LogBodies = logBodies
+		client := NewBaseClient("test", config, zap.New(core))
+		
+		if _, err := client.GetWithRetry(context.Background(), server.URL+"/weather?q=Prague&appid=secret123"); err != nil {
+			t.Fatalf("GetWithRetry: %v", err)
+		}
+		
+		var loggedBody bool
+		for _, entry := range logs.All() {
+			for key, value := range entry.ContextMap() {
+				if text, ok := value.(string); ok && strings.Contains(text, "secret123") {
+					t.Errorf("%q logged %s = %q, want the key masked", entry.Message, key, text)
+				}
+				if key == "url" && !strings.Contains(value.(string), "appid=REDACTED") {
+					t.Errorf("%q logged url %q, want appid=REDACTED", entry.Message, value)
+				}
+				if key == "body" {
+					loggedBody = true
+				}
+			}
+		}
+		if loggedBody != logBodies {
+			t.Errorf("LogBodies %v: body logged = %v", logBodies, loggedBody)
+		}
+	}
+}
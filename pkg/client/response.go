@@ -0,0 +1,130 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// maxErrorSnippet bounds how much of an unexpected body goes into an error
+const maxErrorSnippet = 200
+
+// secretInBody matches credentials echoed back in a body, such as the request
+// URL quoted on a provider's error page
+var secretInBody = regexp.MustCompile(`(?i)\b(` + strings.Join(secretParams, "|") + `)=[^&"'\s<>]+`)
+
+// BodyError is returned for a successful response whose body is not the JSON
+// the client expected, typically an HTML error page served by a proxy or a
+// plain-text rate limit notice
+type BodyError struct {
+	ContentType string
+	Snippet     string // truncated, credentials redacted
+	Err         error  // decoding error, nil when the content type alone ruled the body out
+}
+
+func (e *BodyError) Error() string {
+	contentType := e.ContentType
+	if contentType == "" {
+		contentType = "none"
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("unexpected response body (content type %s): %v: %q", contentType, e.Err, e.Snippet)
+	}
+	return fmt.Sprintf("unexpected response body (content type %s): %q", contentType, e.Snippet)
+}
+
+func (e *BodyError) Unwrap() error {
+	return e.Err
+}
+
+// JSONBody is a successful response body that parsed as JSON
+type JSONBody struct {
+	Data  []byte
+	Value interface{} // Data decoded generically when it was checked, see requireFields
+}
+
+// checkJSONBody rejects bodies that cannot be what a JSON API meant to send:
+// anything served as HTML, and anything that does not parse as JSON
+func checkJSONBody(contentType string, body []byte) (JSONBody, error) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/html" {
+		return JSONBody{}, &BodyError{ContentType: contentType, Snippet: bodySnippet(body)}
+	}
+	
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return JSONBody{}, &BodyError{ContentType: contentType, Snippet: bodySnippet(body), Err: err}
+	}
+	return JSONBody{Data: body, Value: value}, nil
+}
+
+// decodeJSON unmarshals a response body into v, quoting the start of the body
+// when it does not have the expected shape
+func decodeJSON(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %q", err, bodySnippet(data))
+	}
+	return nil
+}
+
+// MissingFieldError is returned for a successful response that lacks a field
+// the reading cannot do without. Decoded as is, the field would read as zero,
+// e.g. a bogus 0°C temperature.
+type MissingFieldError struct {
+	Field string // path in the body, see requireFields
+}
+
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("response lacks required field %s", e.Field)
+}
+
+// requireFields checks that every path is present and not null in a decoded
+// JSON body. Paths are dot-separated keys, a key ending in [] requires the rest
+// of the path in every element of that array, e.g. "list[].main.temp".
+func requireFields(body interface{}, paths ...string) error {
+	for _, path := range paths {
+		if !hasField(body, strings.Split(path, ".")) {
+			return &MissingFieldError{Field: path}
+		}
+	}
+	return nil
+}
+
+func hasField(value interface{}, keys []string) bool {
+	if len(keys) == 0 {
+		return value != nil
+	}
+	
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	
+	key := strings.TrimSuffix(keys[0], "[]")
+	child := object[key]
+	if key == keys[0] {
+		return hasField(child, keys[1:])
+	}
+	
+	items, ok := child.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if !hasField(item, keys[1:]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bodySnippet returns the start of body on one line with credentials masked
+func bodySnippet(body []byte) string {
+	text := strings.Join(strings.Fields(string(body)), " ")
+	text = secretInBody.ReplaceAllString(text, "${1}="+redactedValue)
+	if len(text) > maxErrorSnippet {
+		text = strings.ToValidUTF8(text[:maxErrorSnippet], "") + "...(truncated)"
+	}
+	return text
+}
\ No newline at end of file
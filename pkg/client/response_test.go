@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+func TestCheckJSONBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		valid       bool
+	}{
+		{"json", "application/json", `{"main":{"temp":12}}`, true},
+		{"json without content type", "", `{"main":{"temp":12}}`, true},
+		{"json served as text", "text/plain", `[1,2]`, true},
+		{"html page", "text/html; charset=utf-8", `<html><body>Bad gateway</body></html>`, false},
+		{"json served as html", "text/html", `{"main":{"temp":12}}`, false},
+		{"plain text notice", "text/plain", "Rate limit exceeded, try again later", false},
+		{"empty body", "application/json", "", false},
+	}
+	for _, tt := range tests {
+		checked, err := checkJSONBody(tt.contentType, []byte(tt.body))
+		if tt.valid {
+			if err != nil || string(checked.Data) != tt.body {
+				t.Errorf("%s: checkJSONBody = %q, %v, want the body", tt.name, checked.Data, err)
+			}
+			continue
+		}
+		
+		var bodyErr *BodyError
+		if !errors.As(err, &bodyErr) {
+			t.Errorf("%s: checkJSONBody error = %v, want a BodyError", tt.name, err)
+			continue
+		}
+		if bodyErr.ContentType != tt.contentType {
+			t.Errorf("%s: content type = %q, want %q", tt.name, bodyErr.ContentType, tt.contentType)
+		}
+	}
+}
+
+func TestBodySnippet(t *testing.T) {
+	if got := bodySnippet([]byte("<html>\n  <title>Error</title>\n</html>")); got != "<html> <title>Error</title> </html>" {
+		t.Errorf("bodySnippet = %q, want it on one line", got)
+	}
+	if got := bodySnippet([]byte(`<a href="/data?q=London&appid=s3cret&units=metric">retry</a>`)); strings.Contains(got, "s3cret") || !strings.Contains(got, "appid=REDACTED") {
+		t.Errorf("bodySnippet = %q, want the key redacted", got)
+	}
+	
+	long := bodySnippet([]byte(strings.Repeat("x", 1000)))
+	if !strings.HasSuffix(long, "...(truncated)") || len(long) != maxErrorSnippet+len("...(truncated)") {
+		t.Errorf("bodySnippet of 1000 bytes has %d bytes, want it cut at %d", len(long), maxErrorSnippet)
+	}
+	if cut := bodySnippet([]byte(strings.Repeat("é", 150))); !strings.HasSuffix(cut, "...(truncated)") || !utf8.ValidString(cut) {
+		t.Errorf("bodySnippet = %q, want a multi-byte body cut on a rune boundary", cut)
+	}
+}
+
+func TestHTMLBodyIsAnInformativeError(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestOpenWeatherClient(t, []string{"key"}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body><h1>502 Bad Gateway</h1></body></html>"))
+	})
+	
+	_, err := client.GetCurrentWeather(context.Background(), "London", models.QueryOptions{})
+	var bodyErr *BodyError
+	if !errors.As(err, &bodyErr) {
+		t.Fatalf("GetCurrentWeather error = %v, want a BodyError", err)
+	}
+	if !strings.Contains(err.Error(), "text/html") || !strings.Contains(err.Error(), "502 Bad Gateway") {
+		t.Errorf("error = %q, want the content type and the start of the page", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want the page not to be asked for again", n)
+	}
+}
+
+func TestRequireFields(t *testing.T) {
+	tests := []struct {
+		body    string
+		path    string
+		missing bool
+	}{
+		{`{"main":{"temp":0}}`, "main.temp", false},
+		{`{"main":{"humidity":40}}`, "main.temp", true},
+		{`{"main":{"temp":null}}`, "main.temp", true},
+		{`{"main":[]}`, "main.temp", true},
+		{`{"list":[{"main":{"temp":1}},{"main":{"temp":2}}]}`, "list[].main.temp", false},
+		{`{"list":[{"main":{"temp":1}},{"main":{}}]}`, "list[].main.temp", true},
+		{`{"list":[]}`, "list[].main.temp", false},
+		{`{"list":{"main":{"temp":1}}}`, "list[].main.temp", true},
+	}
+	for _, tt := range tests {
+		body, err := checkJSONBody("application/json", []byte(tt.body))
+		if err != nil {
+			t.Fatalf("checkJSONBody(%s): %v", tt.body, err)
+		}
+		err = requireFields(body.Value, tt.path)
+		var missing *MissingFieldError
+		if got := errors.As(err, &missing); got != tt.missing {
+			t.Errorf("requireFields(%s, %s) = %v, want missing %v", tt.body, tt.path, err, tt.missing)
+		} else if got && missing.Field != tt.path {
+			t.Errorf("requireFields(%s, %s) reported %s", tt.body, tt.path, missing.Field)
+		}
+	}
+	
+	body, _ := checkJSONBody("application/json", []byte(`{"a":1,"b":2}`))
+	if err := requireFields(body.Value, "a", "c", "b"); err == nil || !strings.Contains(err.Error(), "c") {
+		t.Errorf("requireFields = %v, want c reported", err)
+	}
+}
+
+// assertMissingField checks that err reports field missing from a response
+func assertMissingField(t *testing.T, err error, field string) {
+	t.Helper()
+	
+	var missing *MissingFieldError
+	if !errors.As(err, &missing) || missing.Field != field {
+		t.Errorf("err = %v, want %s reported missing", err, field)
+	}
+}
@@ -0,0 +1,326 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"go.uber.org/zap"
+)
+
+type TomorrowIOClient struct {
+	*BaseClient
+	apiKey   string
+	baseURL  string
+	geocoder *Geocoder
+}
+
+type TomorrowIORealtimeResponse struct {
+	Data struct {
+		Time   string `json:"time"`
+		Values struct {
+			Temperature          float64  `json:"temperature"`
+			TemperatureApparent  float64  `json:"temperatureApparent"`
+			Humidity             float64  `json:"humidity"`
+			PressureSeaLevel     float64  `json:"pressureSeaLevel"`
+			PressureSurfaceLevel float64  `json:"pressureSurfaceLevel"`
+			WindSpeed            float64  `json:"windSpeed"`
+			WindDirection        float64  `json:"windDirection"`
+			WindGust             float64  `json:"windGust"`
+			CloudCover           float64  `json:"cloudCover"`
+			Visibility           *float64 `json:"visibility"` // kilometers
+			WeatherCode          int      `json:"weatherCode"`
+		} `json:"values"`
+	} `json:"data"`
+	Location struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	} `json:"location"`
+}
+
+type TomorrowIOForecastResponse struct {
+	Timelines struct {
+		Daily []struct {
+			Time   string `json:"time"`
+			Values struct {
+				TemperatureMax         float64 `json:"temperatureMax"`
+				TemperatureMin         float64 `json:"temperatureMin"`
+				TemperatureAvg         float64 `json:"temperatureAvg"`
+				HumidityAvg            float64 `json:"humidityAvg"`
+				RainAccumulationSum    float64 `json:"rainAccumulationSum"`
+				SnowAccumulationLweSum float64 `json:"snowAccumulationLweSum"` // liquid water equivalent
+				WindGustMax            float64 `json:"windGustMax"`
+				WeatherCodeMax         int     `json:"weatherCodeMax"`
+			} `json:"values"`
+		} `json:"daily"`
+		Hourly []struct {
+			Time   string `json:"time"`
+			Values struct {
+				Temperature              float64 `json:"temperature"`
+				Humidity                 float64 `json:"humidity"`
+				WindSpeed                float64 `json:"windSpeed"`
+				PrecipitationProbability float64 `json:"precipitationProbability"`
+				WeatherCode              int     `json:"weatherCode"`
+			} `json:"values"`
+		} `json:"hourly"`
+	} `json:"timelines"`
+}
+
+func NewTomorrowIOClient(apiKey string, geocoder *Geocoder, config ClientConfig, logger *zap.Logger) *TomorrowIOClient {
+	baseClient := NewBaseClient("tomorrow.io", config, logger)
+	return &TomorrowIOClient{
+		BaseClient: baseClient,
+		apiKey:     apiKey,
+		baseURL:    "https://api.tomorrow.io/v4",
+		geocoder:   geocoder,
+	}
+}
+
+func (c *TomorrowIOClient) Name() string {
+	return "tomorrow.io"
+}
+
+func (c *TomorrowIOClient) RequiresAPIKey() bool {
+	return true
+}
+
+// NativeUnits reports the kilometer visibility of Tomorrow.io's metric units
+func (c *TomorrowIOClient) NativeUnits() map[string]models.NativeUnit {
+	return map[string]models.NativeUnit{
+		"visibility": {Label: "km", Factor: 0.001},
+	}
+}
+
+func (c *TomorrowIOClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	coords, err := c.geocoder.Resolve(ctx, city, opts.Country)
+	if err != nil {
+		return nil, err
+	}
+	
+	url := fmt.Sprintf("%s/weather/realtime?location=%.4f,%.4f&units=metric&apikey=%s",
+		c.baseURL, coords.Latitude, coords.Longitude, c.apiKey)
+	
+	body, err := c.GetWithRetry(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch current weather: %w", err)
+	}
+	
+	var response TomorrowIORealtimeResponse
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if err := requireFields(body.Value, "data.values.temperature", "data.values.humidity"); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	
+	return c.parseRealtime(city, coords, response), nil
+}
+
+func (c *TomorrowIOClient) parseRealtime(city string, coords Coordinates, response TomorrowIORealtimeResponse) *models.CurrentWeather {
+	if name, ok := CanonicalCityName(city); ok {
+		city = name
+	}
+	
+	values := response.Data.Values
+	timestamp, _ := time.Parse(time.RFC3339, response.Data.Time)
+	
+	// Sea level pressure matches the other providers, it is not available everywhere
+	pressure := values.PressureSeaLevel
+	if pressure == 0 {
+		pressure = values.PressureSurfaceLevel
+	}
+	
+	weather := &models.CurrentWeather{
+		City:        city,
+		Temperature: values.Temperature,
+		FeelsLike:   values.TemperatureApparent,
+		Humidity:    values.Humidity,
+		Pressure:    pressure,
+		WindSpeed:   values.WindSpeed,
+		WindDegree:  values.WindDirection,
+		WindGust:    values.WindGust,
+		CloudCover:  utils.Clamp(values.CloudCover, 0, 100),
+		Condition:   tomorrowIOConditionCode(values.WeatherCode),
+		Description: tomorrowIODescription(values.WeatherCode),
+		Icon:        tomorrowIOIcon(values.WeatherCode),
+		Timestamp:   timestamp,
+		Source:      c.Name(),
+		ResolvedLatitude:  response.Location.Lat,
+		ResolvedLongitude: response.Location.Lon,
+		DistanceKm:  utils.HaversineKm(coords.Latitude, coords.Longitude, response.Location.Lat, response.Location.Lon),
+	}
+	
+	if values.Visibility != nil {
+		meters := *values.Visibility * 1000
+		weather.Visibility = &meters
+	}
+	
+	return weather
+}
+
+func (c *TomorrowIOClient) GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error) {
+	coords, err := c.geocoder.Resolve(ctx, city, opts.Country)
+	if err != nil {
+		return nil, err
+	}
+	
+	url := fmt.Sprintf("%s/weather/forecast?location=%.4f,%.4f&timesteps=1d,1h&units=metric&apikey=%s",
+		c.baseURL, coords.Latitude, coords.Longitude, c.apiKey)
+	
+	body, err := c.GetWithRetry(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forecast: %w", err)
+	}
+	
+	var response TomorrowIOForecastResponse
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse forecast response: %w", err)
+	}
+	if err := requireFields(body.Value, "timelines.daily[].values.temperatureMax", "timelines.daily[].values.temperatureMin"); err != nil {
+		return nil, fmt.Errorf("failed to parse forecast response: %w", err)
+	}
+	
+	return c.parseForecast(city, days, response), nil
+}
+
+func (c *TomorrowIOClient) parseForecast(city string, days int, response TomorrowIOForecastResponse) *models.WeatherForecast {
+	if name, ok := CanonicalCityName(city); ok {
+		city = name
+	}
+	
+	forecast := &models.WeatherForecast{
+		City:     city,
+		Forecast: make([]models.ForecastDay, 0, days),
+		Hourly:   make([]models.HourlyPoint, 0, len(response.Timelines.Hourly)),
+		Source:   c.Name(),
+	}
+	
+	for _, day := range response.Timelines.Daily {
+		if len(forecast.Forecast) >= days {
+			break
+		}
+		
+		// Daily entries start at local midnight, keep just the date like the other providers
+		dayTime, err := time.Parse(time.RFC3339, day.Time)
+		if err != nil {
+			continue
+		}
+		
+		values := day.Values
+		forecast.Forecast = append(forecast.Forecast, models.ForecastDay{
+			Date:          time.Date(dayTime.Year(), dayTime.Month(), dayTime.Day(), 0, 0, 0, 0, time.UTC),
+			MaxTemp:       values.TemperatureMax,
+			MinTemp:       values.TemperatureMin,
+			AvgTemp:       values.TemperatureAvg,
+			Humidity:      values.HumidityAvg,
+			Condition:     tomorrowIOConditionCode(values.WeatherCodeMax),
+			Description:   tomorrowIODescription(values.WeatherCodeMax),
+			Icon:          tomorrowIOIcon(values.WeatherCodeMax),
+			Precipitation: values.RainAccumulationSum + values.SnowAccumulationLweSum,
+			RainSum:       values.RainAccumulationSum,
+			SnowfallSum:   values.SnowAccumulationLweSum,
+			HasPrecipitationSums: true,
+			WindGust:      values.WindGustMax,
+		})
+	}
+	
+	for _, hour := range response.Timelines.Hourly {
+		pointTime, err := time.Parse(time.RFC3339, hour.Time)
+		if err != nil {
+			continue
+		}
+		
+		values := hour.Values
+		forecast.Hourly = append(forecast.Hourly, models.HourlyPoint{
+			Time:        pointTime.UTC(),
+			Temperature: values.Temperature,
+			Humidity:    values.Humidity,
+			WindSpeed:   values.WindSpeed,
+			PrecipitationProbability: values.PrecipitationProbability,
+			Condition:   tomorrowIOConditionCode(values.WeatherCode),
+			Description: tomorrowIODescription(values.WeatherCode),
+		})
+	}
+	
+	return forecast
+}
+
+// tomorrowIOWeatherCodes describes Tomorrow.io's weather codes
+var tomorrowIOWeatherCodes = map[int]string{
+	1000: "Clear",
+	1100: "Mostly clear",
+	1101: "Partly cloudy",
+	1102: "Mostly cloudy",
+	1001: "Cloudy",
+	2000: "Fog",
+	2100: "Light fog",
+	4000: "Drizzle",
+	4001: "Rain",
+	4200: "Light rain",
+	4201: "Heavy rain",
+	5000: "Snow",
+	5001: "Flurries",
+	5100: "Light snow",
+	5101: "Heavy snow",
+	6000: "Freezing drizzle",
+	6001: "Freezing rain",
+	6200: "Light freezing rain",
+	6201: "Heavy freezing rain",
+	7000: "Ice pellets",
+	7101: "Heavy ice pellets",
+	7102: "Light ice pellets",
+	8000: "Thunderstorm",
+}
+
+func tomorrowIODescription(code int) string {
+	if desc, ok := tomorrowIOWeatherCodes[code]; ok {
+		return desc
+	}
+	return "Unknown"
+}
+
+// tomorrowIOConditionCode maps Tomorrow.io's weather codes, grouped by thousands
+func tomorrowIOConditionCode(code int) models.ConditionCode {
+	switch {
+	case code == 1000 || code == 1100:
+		return models.ConditionClear
+	case code == 1001 || code == 1101 || code == 1102:
+		return models.ConditionClouds
+	case code >= 2000 && code < 3000:
+		return models.ConditionFog
+	case code == 4000 || code == 6000:
+		return models.ConditionDrizzle
+	case code >= 4000 && code < 5000, code >= 6000 && code < 7000:
+		return models.ConditionRain
+	case code >= 5000 && code < 6000, code >= 7000 && code < 8000:
+		return models.ConditionSnow
+	case code == 8000:
+		return models.ConditionThunderstorm
+	default:
+		return models.ConditionUnknown
+	}
+}
+
+// tomorrowIOIcon picks the OpenWeatherMap style icon used by the other clients
+func tomorrowIOIcon(code int) string {
+	switch tomorrowIOConditionCode(code) {
+	case models.ConditionClear:
+		return "01d"
+	case models.ConditionClouds:
+		return "02d"
+	case models.ConditionFog:
+		return "50d"
+	case models.ConditionDrizzle:
+		return "09d"
+	case models.ConditionRain:
+		return "10d"
+	case models.ConditionSnow:
+		return "13d"
+	case models.ConditionThunderstorm:
+		return "11d"
+	default:
+		return ""
+	}
+}
\ No newline at end of file
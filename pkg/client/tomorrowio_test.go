@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+// newTestTomorrowIOClient returns a client whose requests are served by handler
+func newTestTomorrowIOClient(t *testing.T, handler http.HandlerFunc) *TomorrowIOClient {
+	t.Helper()
+	
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	
+	client := NewTomorrowIOClient("key", NewGeocoder(testClientConfig(), zap.NewNop()), testClientConfig(), zap.NewNop())
+	client.baseURL = server.URL
+	return client
+}
+
+func TestTomorrowIOCurrentWeather(t *testing.T) {
+	var path string
+	client := newTestTomorrowIOClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		respondJSON(`{
+			"data":{"time":"2026-10-15T12:00:00Z","values":{
+				"temperature":14.2,"temperatureApparent":13.1,"humidity":71,
+				"pressureSurfaceLevel":990.5,"windSpeed":4.2,"windDirection":250,"windGust":9.1,
+				"cloudCover":104,"visibility":16,"weatherCode":4200}},
+			"location":{"lat":50.0755,"lon":14.4378}
+		}`)(w, r)
+	})
+	
+	weather, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if path != "/weather/realtime" {
+		t.Errorf("requested %s, want /weather/realtime", path)
+	}
+	if weather.Source != "tomorrow.io" || weather.Temperature != 14.2 || weather.FeelsLike != 13.1 || weather.Humidity != 71 {
+		t.Errorf("weather = %+v, want 14.2°C feeling 13.1°C at 71%% from tomorrow.io", weather)
+	}
+	if weather.Pressure != 990.5 {
+		t.Errorf("Pressure = %v, want the surface level 990.5 without a sea level value", weather.Pressure)
+	}
+	if weather.WindGust != 9.1 || weather.WindDegree != 250 || weather.CloudCover != 100 {
+		t.Errorf("gust %v from %v° under %v%% cloud, want 9.1 from 250° under 100%%", weather.WindGust, weather.WindDegree, weather.CloudCover)
+	}
+	if weather.Visibility == nil || *weather.Visibility != 16000 {
+		t.Errorf("Visibility = %v, want 16000 m", weather.Visibility)
+	}
+	if weather.Condition != models.ConditionRain || weather.Description != "Light rain" {
+		t.Errorf("condition %s %q, want rain and Light rain", weather.Condition, weather.Description)
+	}
+	if !weather.Timestamp.Equal(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v, want the observation time", weather.Timestamp)
+	}
+}
+
+func TestTomorrowIOForecast(t *testing.T) {
+	client := newTestTomorrowIOClient(t, respondJSON(`{"timelines":{
+		"daily":[
+			{"time":"2026-10-15T05:00:00Z","values":{"temperatureMax":18,"temperatureMin":8,"temperatureAvg":13,"humidityAvg":60,
+				"rainAccumulationSum":1.5,"snowAccumulationLweSum":0.5,"windGustMax":12,"weatherCodeMax":5100}},
+			{"time":"2026-10-16T05:00:00Z","values":{"temperatureMax":20,"temperatureMin":10,"temperatureAvg":15,"humidityAvg":55,"weatherCodeMax":1000}},
+			{"time":"2026-10-17T05:00:00Z","values":{"temperatureMax":21,"temperatureMin":11,"temperatureAvg":16,"humidityAvg":50,"weatherCodeMax":1001}}
+		],
+		"hourly":[
+			{"time":"2026-10-15T12:00:00Z","values":{"temperature":16,"humidity":58,"windSpeed":3,"precipitationProbability":40,"weatherCode":4001}}
+		]
+	}}`))
+	
+	forecast, err := client.GetForecast(context.Background(), "Prague", 2, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+	if len(forecast.Forecast) != 2 {
+		t.Fatalf("got %d days, want the 2 requested", len(forecast.Forecast))
+	}
+	
+	day := forecast.Forecast[0]
+	if !day.Date.Equal(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, want 2026-10-15 at midnight", day.Date)
+	}
+	if day.MaxTemp != 18 || day.MinTemp != 8 || day.AvgTemp != 13 || day.WindGust != 12 {
+		t.Errorf("day = %+v, want 18/8/13°C with 12 m/s gusts", day)
+	}
+	if day.Precipitation != 2 || day.RainSum != 1.5 || day.SnowfallSum != 0.5 || !day.HasPrecipitationSums {
+		t.Errorf("precipitation %v (rain %v snow %v), want 2 split into 1.5 and 0.5", day.Precipitation, day.RainSum, day.SnowfallSum)
+	}
+	if day.Condition != models.ConditionSnow {
+		t.Errorf("Condition = %s, want snow", day.Condition)
+	}
+	
+	if len(forecast.Hourly) != 1 || forecast.Hourly[0].PrecipitationProbability != 40 || forecast.Hourly[0].Condition != models.ConditionRain {
+		t.Errorf("Hourly = %+v, want one rainy point at 40%%", forecast.Hourly)
+	}
+}
+
+func TestTomorrowIOMissingTemperatureIsAnError(t *testing.T) {
+	client := newTestTomorrowIOClient(t, respondJSON(`{"data":{"time":"2026-10-15T12:00:00Z","values":{"humidity":71}}}`))
+	
+	if _, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{}); err == nil {
+		t.Error("GetCurrentWeather succeeded without a temperature, want an error")
+	}
+}
+
+func TestTomorrowIOForecastMissingTemperatureIsAnError(t *testing.T) {
+	client := newTestTomorrowIOClient(t, respondJSON(`{"timelines":{"daily":[
+		{"time":"2026-10-15T05:00:00Z","values":{"temperatureMax":18,"temperatureMin":8}},
+		{"time":"2026-10-16T05:00:00Z","values":{"temperatureMax":20}}
+	]}}`))
+	
+	_, err := client.GetForecast(context.Background(), "Prague", 2, models.QueryOptions{})
+	assertMissingField(t, err, "timelines.daily[].values.temperatureMin")
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// countingTransport counts the requests it passes on to the default transport
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientsUseConfiguredTransport(t *testing.T) {
+	server := httptest.NewServer(respondJSON(`{"ok":true}`))
+	t.Cleanup(server.Close)
+	
+	transport := &countingTransport{}
+	config := testClientConfig()
+	config.Transport = transport
+	
+	// one transport shared by two clients
+	for _, name := range []string{"first", "second"} {
+		if _, err := NewBaseClient(name, config, zap.NewNop()).GetWithRetry(context.Background(), server.URL); err != nil {
+			t.Fatalf("GetWithRetry from %s: %v", name, err)
+		}
+	}
+	if requests := transport.requests.Load(); requests != 2 {
+		t.Errorf("transport carried %d requests, want both clients' 2", requests)
+	}
+}
+
+func TestNewTransportAppliesPoolSettings(t *testing.T) {
+	transport := NewTransport(TransportConfig{
+		MaxIdleConns:        50,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     45 * time.Second,
+	})
+	
+	if transport.MaxIdleConns != 50 || transport.MaxIdleConnsPerHost != 5 || transport.IdleConnTimeout != 45*time.Second {
+		t.Errorf("pool = %d idle, %d per host, %v timeout, want 50, 5 and 45s",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+	if transport.Proxy == nil {
+		t.Error("transport ignores HTTP_PROXY, want the proxy taken from the environment")
+	}
+}
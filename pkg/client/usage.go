@@ -0,0 +1,45 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+)
+
+// usageCounter tallies upstream calls in the current UTC hour and day
+type usageCounter struct {
+	mu   sync.Mutex
+	hour models.UsageWindow
+	day  models.UsageWindow
+}
+
+// record counts a call made at now
+func (u *usageCounter) record(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	
+	u.roll(now)
+	u.hour.Calls++
+	u.day.Calls++
+}
+
+// snapshot returns the windows now falls into
+func (u *usageCounter) snapshot(now time.Time) (hour, day models.UsageWindow) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	
+	u.roll(now)
+	return u.hour, u.day
+}
+
+// roll starts a new window with no calls once now has left the current one
+func (u *usageCounter) roll(now time.Time) {
+	now = now.UTC()
+	if hourStart := now.Truncate(time.Hour); !hourStart.Equal(u.hour.Start) {
+		u.hour = models.UsageWindow{Start: hourStart}
+	}
+	if dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC); !dayStart.Equal(u.day.Start) {
+		u.day = models.UsageWindow{Start: dayStart}
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestUsageCounterWindows(t *testing.T) {
+	var counter usageCounter
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+	
+	counter.record(at(10, 10, 15))
+	counter.record(at(10, 10, 45))
+	hour, day := counter.snapshot(at(10, 10, 59))
+	if hour.Calls != 2 || day.Calls != 2 || !hour.Start.Equal(at(10, 10, 0)) || !day.Start.Equal(at(10, 0, 0)) {
+		t.Errorf("windows = %+v %+v, want 2 calls in the 10:00 hour and the day", hour, day)
+	}
+	
+	// A new hour starts empty, the day keeps counting
+	hour, day = counter.snapshot(at(10, 11, 5))
+	if hour.Calls != 0 || !hour.Start.Equal(at(10, 11, 0)) || day.Calls != 2 {
+		t.Errorf("windows = %+v %+v in the next hour, want an empty hour and 2 calls in the day", hour, day)
+	}
+	counter.record(at(10, 11, 10))
+	if hour, day = counter.snapshot(at(10, 11, 10)); hour.Calls != 1 || day.Calls != 3 {
+		t.Errorf("windows = %+v %+v, want 1 call in the hour and 3 in the day", hour, day)
+	}
+	
+	// Windows are UTC whatever the caller's zone
+	prague := time.FixedZone("CET", 3600)
+	counter.record(time.Date(2024, 3, 11, 0, 30, 0, 0, prague))
+	if hour, day = counter.snapshot(at(10, 23, 40)); hour.Calls != 1 || day.Calls != 4 {
+		t.Errorf("windows = %+v %+v, want 00:30 CET counted in 23:00 UTC of the same day", hour, day)
+	}
+	
+	if hour, day = counter.snapshot(at(11, 0, 5)); hour.Calls != 0 || day.Calls != 0 || !day.Start.Equal(at(11, 0, 0)) {
+		t.Errorf("windows = %+v %+v on the next day, want both empty", hour, day)
+	}
+}
+
+func TestGetWithRetryCountsUpstreamCalls(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		respondJSON(`{}`)(w, r)
+	}))
+	t.Cleanup(server.Close)
+	
+	config := testClientConfig()
+	config.Threshold = 1
+	config.BreakerTimeout = time.Hour
+	client := NewBaseClient("test", config, zap.NewNop())
+	
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetWithRetry(context.Background(), server.URL); err != nil {
+			t.Fatalf("GetWithRetry: %v", err)
+		}
+	}
+	if hour, day := client.Usage(); hour.Calls != 3 || day.Calls != 3 {
+		t.Errorf("usage = %d this hour and %d today, want 3", hour.Calls, day.Calls)
+	}
+	
+	// Calls the open breaker rejects never reach the provider
+	failing.Store(true)
+	for i := 0; i < 3; i++ {
+		client.GetWithRetry(context.Background(), server.URL)
+	}
+	if hour, _ := client.Usage(); hour.Calls != 4 {
+		t.Errorf("usage = %d this hour, want the failing call counted and the rejected ones not", hour.Calls)
+	}
+}
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"github.com/bobby-s-dev/weather-aggregator/internal/utils"
+	"go.uber.org/zap"
+)
+
+// Metric units report wind in km/h, the models use m/s
+const kmhToMs = 1 / 3.6
+
+type VisualCrossingClient struct {
+	*BaseClient
+	apiKey   string
+	baseURL  string
+	geocoder *Geocoder
+}
+
+type visualCrossingConditions struct {
+	Datetime      string   `json:"datetime"`
+	DatetimeEpoch int64    `json:"datetimeEpoch"`
+	Temp          float64  `json:"temp"`
+	FeelsLike     float64  `json:"feelslike"`
+	Humidity      float64  `json:"humidity"`
+	Pressure      float64  `json:"pressure"`
+	WindSpeed     float64  `json:"windspeed"` // km/h
+	WindGust      float64  `json:"windgust"`  // km/h
+	WindDir       float64  `json:"winddir"`
+	CloudCover    float64  `json:"cloudcover"`
+	Visibility    *float64 `json:"visibility"` // kilometers
+	PrecipProb    float64  `json:"precipprob"`
+	Conditions    string   `json:"conditions"`
+	Icon          string   `json:"icon"`
+}
+
+type VisualCrossingTimelineResponse struct {
+	Latitude          float64 `json:"latitude"`
+	Longitude         float64 `json:"longitude"`
+	ResolvedAddress   string  `json:"resolvedAddress"`
+	Days              []struct {
+		visualCrossingConditions
+		TempMax float64                    `json:"tempmax"`
+		TempMin float64                    `json:"tempmin"`
+		Precip  float64                    `json:"precip"`
+		Hours   []visualCrossingConditions `json:"hours"`
+	} `json:"days"`
+	CurrentConditions *visualCrossingConditions `json:"currentConditions"`
+}
+
+func NewVisualCrossingClient(apiKey string, geocoder *Geocoder, config ClientConfig, logger *zap.Logger) *VisualCrossingClient {
+	baseClient := NewBaseClient("visualcrossing", config, logger)
+	return &VisualCrossingClient{
+		BaseClient: baseClient,
+		apiKey:     apiKey,
+		baseURL:    "https://weather.visualcrossing.com/VisualCrossingWebServices/rest/services/timeline",
+		geocoder:   geocoder,
+	}
+}
+
+func (c *VisualCrossingClient) Name() string {
+	return "visualcrossing"
+}
+
+func (c *VisualCrossingClient) RequiresAPIKey() bool {
+	return true
+}
+
+// NativeUnits reports the km/h wind and kilometer visibility of Visual
+// Crossing's metric units
+func (c *VisualCrossingClient) NativeUnits() map[string]models.NativeUnit {
+	return map[string]models.NativeUnit{
+		"wind_speed": {Label: "km/h", Factor: 1 / kmhToMs},
+		"visibility": {Label: "km", Factor: 0.001},
+	}
+}
+
+func (c *VisualCrossingClient) GetCurrentWeather(ctx context.Context, city string, opts models.QueryOptions) (*models.CurrentWeather, error) {
+	current, _, err := c.GetWeather(ctx, city, 1, opts)
+	return current, err
+}
+
+func (c *VisualCrossingClient) GetForecast(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.WeatherForecast, error) {
+	_, forecast, err := c.GetWeather(ctx, city, days, opts)
+	return forecast, err
+}
+
+// GetWeather fetches current conditions and the forecast in a single request,
+// the timeline endpoint serves both for a date range starting today
+func (c *VisualCrossingClient) GetWeather(ctx context.Context, city string, days int, opts models.QueryOptions) (*models.CurrentWeather, *models.WeatherForecast, error) {
+	today := time.Now().UTC()
+	response, err := c.fetchTimeline(ctx, city, today, today.AddDate(0, 0, days-1), "current,days,hours", opts,
+		"currentConditions.temp", "currentConditions.humidity", "days[].tempmax", "days[].tempmin")
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	return c.parseCurrent(city, response, opts), c.parseForecast(city, days, response), nil
+}
+
+// GetHistoricalWeather returns the observed weather of a past day
+func (c *VisualCrossingClient) GetHistoricalWeather(ctx context.Context, city string, date time.Time, opts models.QueryOptions) (*models.ForecastDay, error) {
+	response, err := c.fetchTimeline(ctx, city, date, date, "days", opts, "days[].tempmax", "days[].tempmin")
+	if err != nil {
+		return nil, err
+	}
+	
+	forecast := c.parseForecast(city, 1, response)
+	if len(forecast.Forecast) == 0 {
+		return nil, fmt.Errorf("no historical data for %s on %s", city, date.Format("2006-01-02"))
+	}
+	return &forecast.Forecast[0], nil
+}
+
+// fetchTimeline fetches the timeline between from and to, failing when a path
+// of required is missing from the response
+func (c *VisualCrossingClient) fetchTimeline(ctx context.Context, city string, from, to time.Time, include string, opts models.QueryOptions, required ...string) (*VisualCrossingTimelineResponse, error) {
+	location := strings.TrimSpace(city)
+	if opts.Country != "" {
+		location += "," + opts.Country
+	}
+	
+	requestURL := fmt.Sprintf("%s/%s/%s/%s?unitGroup=metric&include=%s&lang=%s&key=%s&contentType=json",
+		c.baseURL, url.PathEscape(location), from.Format("2006-01-02"), to.Format("2006-01-02"),
+		include, opts.LangOrDefault(), c.apiKey)
+	
+	body, err := c.GetWithRetry(ctx, requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch timeline: %w", err)
+	}
+	
+	var response VisualCrossingTimelineResponse
+	if err := decodeJSON(body.Data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse timeline response: %w", err)
+	}
+	if err := requireFields(body.Value, required...); err != nil {
+		return nil, fmt.Errorf("failed to parse timeline response: %w", err)
+	}
+	
+	return &response, nil
+}
+
+func (c *VisualCrossingClient) parseCurrent(city string, response *VisualCrossingTimelineResponse, opts models.QueryOptions) *models.CurrentWeather {
+	if name, ok := CanonicalCityName(city); ok {
+		city = name
+	}
+	
+	current := response.CurrentConditions
+	weather := &models.CurrentWeather{
+		City:        city,
+		Temperature: current.Temp,
+		FeelsLike:   current.FeelsLike,
+		Humidity:    current.Humidity,
+		Pressure:    current.Pressure,
+		WindSpeed:   current.WindSpeed * kmhToMs,
+		WindDegree:  current.WindDir,
+		WindGust:    current.WindGust * kmhToMs,
+		CloudCover:  utils.Clamp(current.CloudCover, 0, 100),
+		Condition:   visualCrossingConditionCode(current.Icon),
+		Description: current.Conditions,
+		Icon:        visualCrossingIcon(current.Icon),
+		Timestamp:   time.Unix(current.DatetimeEpoch, 0),
+		Source:      c.Name(),
+		ResolvedLatitude:  response.Latitude,
+		ResolvedLongitude: response.Longitude,
+	}
+	
+	if current.Visibility != nil {
+		meters := *current.Visibility * 1000
+		weather.Visibility = &meters
+	}
+	
+	// Visual Crossing resolves addresses itself, only known coordinates give a distance
+	if coords, ok := c.geocoder.Cached(city, opts.Country); ok {
+		weather.DistanceKm = utils.HaversineKm(coords.Latitude, coords.Longitude, response.Latitude, response.Longitude)
+	}
+	
+	return weather
+}
+
+func (c *VisualCrossingClient) parseForecast(city string, days int, response *VisualCrossingTimelineResponse) *models.WeatherForecast {
+	if name, ok := CanonicalCityName(city); ok {
+		city = name
+	}
+	
+	forecast := &models.WeatherForecast{
+		City:     city,
+		Forecast: make([]models.ForecastDay, 0, days),
+		Source:   c.Name(),
+	}
+	
+	for _, day := range response.Days {
+		if len(forecast.Forecast) >= days {
+			break
+		}
+		
+		date, err := time.Parse("2006-01-02", day.Datetime)
+		if err != nil {
+			continue
+		}
+		
+		forecast.Forecast = append(forecast.Forecast, models.ForecastDay{
+			Date:          date,
+			MaxTemp:       day.TempMax,
+			MinTemp:       day.TempMin,
+			AvgTemp:       day.Temp,
+			Humidity:      day.Humidity,
+			Condition:     visualCrossingConditionCode(day.Icon),
+			Description:   day.Conditions,
+			Icon:          visualCrossingIcon(day.Icon),
+			Precipitation: day.Precip,
+			WindGust:      day.WindGust * kmhToMs,
+		})
+		
+		for _, hour := range day.Hours {
+			forecast.Hourly = append(forecast.Hourly, models.HourlyPoint{
+				Time:        time.Unix(hour.DatetimeEpoch, 0).UTC(),
+				Temperature: hour.Temp,
+				Humidity:    hour.Humidity,
+				WindSpeed:   hour.WindSpeed * kmhToMs,
+				PrecipitationProbability: hour.PrecipProb,
+				Condition:   visualCrossingConditionCode(hour.Icon),
+				Description: hour.Conditions,
+			})
+		}
+	}
+	
+	return forecast
+}
+
+// visualCrossingConditionCode maps the icon set, which is the only
+// language-independent condition field in the response
+func visualCrossingConditionCode(icon string) models.ConditionCode {
+	switch {
+	case strings.HasPrefix(icon, "clear"):
+		return models.ConditionClear
+	case icon == "cloudy", strings.HasPrefix(icon, "partly-cloudy"), icon == "wind":
+		return models.ConditionClouds
+	case icon == "fog":
+		return models.ConditionFog
+	case strings.HasPrefix(icon, "thunder"):
+		return models.ConditionThunderstorm
+	case strings.HasPrefix(icon, "snow"):
+		return models.ConditionSnow
+	case icon == "rain", strings.HasPrefix(icon, "showers"):
+		return models.ConditionRain
+	default:
+		return models.ConditionUnknown
+	}
+}
+
+// visualCrossingIcon picks the OpenWeatherMap style icon used by the other clients
+func visualCrossingIcon(icon string) string {
+	switch visualCrossingConditionCode(icon) {
+	case models.ConditionClear:
+		if strings.HasSuffix(icon, "-night") {
+			return "01n"
+		}
+		return "01d"
+	case models.ConditionClouds:
+		if strings.HasSuffix(icon, "-night") {
+			return "02n"
+		}
+		return "02d"
+	case models.ConditionFog:
+		return "50d"
+	case models.ConditionRain:
+		return "10d"
+	case models.ConditionSnow:
+		return "13d"
+	case models.ConditionThunderstorm:
+		return "11d"
+	default:
+		return ""
+	}
+}
\ No newline at end of file
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bobby-s-dev/weather-aggregator/internal/models"
+	"go.uber.org/zap"
+)
+
+// newTestVisualCrossingClient returns a client whose requests are served by handler
+func newTestVisualCrossingClient(t *testing.T, handler http.HandlerFunc) *VisualCrossingClient {
+	t.Helper()
+	
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	
+	client := NewVisualCrossingClient("key", NewGeocoder(testClientConfig(), zap.NewNop()), testClientConfig(), zap.NewNop())
+	client.baseURL = server.URL
+	return client
+}
+
+const visualCrossingTimeline = `{
+	"latitude":50.0755,"longitude":14.4378,"resolvedAddress":"Praha, Česko",
+	"currentConditions":{"datetimeEpoch":1760529600,"temp":14.5,"feelslike":13,"humidity":65,"pressure":1015,
+		"windspeed":18,"windgust":36,"winddir":270,"cloudcover":40,"visibility":20,"conditions":"Partially cloudy","icon":"partly-cloudy-day"},
+	"days":[
+		{"datetime":"2026-10-15","temp":13,"tempmax":17,"tempmin":9,"humidity":70,"precip":1.2,"windgust":45,"conditions":"Rain","icon":"rain",
+			"hours":[{"datetimeEpoch":1760529600,"temp":14,"humidity":66,"windspeed":18,"precipprob":30,"conditions":"Rain","icon":"rain"}]},
+		{"datetime":"2026-10-16","temp":15,"tempmax":19,"tempmin":11,"humidity":60,"conditions":"Clear","icon":"clear-day"}
+	]
+}`
+
+func TestVisualCrossingGetWeatherIssuesOneRequest(t *testing.T) {
+	var requests atomic.Int32
+	var include string
+	client := newTestVisualCrossingClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		include = r.URL.Query().Get("include")
+		respondJSON(visualCrossingTimeline)(w, r)
+	})
+	
+	current, forecast, err := client.GetWeather(context.Background(), "Prague", 2, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetWeather: %v", err)
+	}
+	if requests.Load() != 1 || include != "current,days,hours" {
+		t.Errorf("%d requests including %q, want one for current,days,hours", requests.Load(), include)
+	}
+	
+	if current.Source != "visualcrossing" || current.Temperature != 14.5 || current.Humidity != 65 || current.Pressure != 1015 {
+		t.Errorf("current = %+v, want 14.5°C at 65%% and 1015 hPa", current)
+	}
+	if math.Abs(current.WindSpeed-5) > 1e-9 || math.Abs(current.WindGust-10) > 1e-9 {
+		t.Errorf("wind %v gusting %v m/s, want 18 and 36 km/h as 5 and 10", current.WindSpeed, current.WindGust)
+	}
+	if current.Visibility == nil || *current.Visibility != 20000 || current.Condition != models.ConditionClouds || current.Icon != "02d" {
+		t.Errorf("visibility %v condition %s icon %s, want 20000 m of clouds as 02d", current.Visibility, current.Condition, current.Icon)
+	}
+	
+	if len(forecast.Forecast) != 2 {
+		t.Fatalf("got %d days, want 2", len(forecast.Forecast))
+	}
+	day := forecast.Forecast[0]
+	if !day.Date.Equal(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)) || day.MaxTemp != 17 || day.MinTemp != 9 || day.Precipitation != 1.2 {
+		t.Errorf("first day = %+v, want 2026-10-15 at 17/9°C with 1.2 mm", day)
+	}
+	if day.Condition != models.ConditionRain || math.Abs(day.WindGust-12.5) > 1e-9 {
+		t.Errorf("first day %s gusting %v m/s, want rain gusting 12.5", day.Condition, day.WindGust)
+	}
+	if len(forecast.Hourly) != 1 || forecast.Hourly[0].PrecipitationProbability != 30 {
+		t.Errorf("Hourly = %+v, want the one hour at 30%%", forecast.Hourly)
+	}
+}
+
+func TestVisualCrossingCurrentAndForecast(t *testing.T) {
+	client := newTestVisualCrossingClient(t, respondJSON(visualCrossingTimeline))
+	
+	current, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	if current.Temperature != 14.5 {
+		t.Errorf("Temperature = %v, want 14.5", current.Temperature)
+	}
+	
+	forecast, err := client.GetForecast(context.Background(), "Prague", 1, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+	if len(forecast.Forecast) != 1 || forecast.Forecast[0].AvgTemp != 13 {
+		t.Errorf("Forecast = %+v, want the first day at 13°C", forecast.Forecast)
+	}
+}
+
+func TestVisualCrossingHistoricalWeather(t *testing.T) {
+	var path, include string
+	client := newTestVisualCrossingClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path, include = r.URL.Path, r.URL.Query().Get("include")
+		respondJSON(`{"days":[{"datetime":"2020-02-29","temp":4,"tempmax":7,"tempmin":1,"humidity":80,"precip":3.5,"conditions":"Snow","icon":"snow"}]}`)(w, r)
+	})
+	
+	date := time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)
+	day, err := client.GetHistoricalWeather(context.Background(), "Prague", date, models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetHistoricalWeather: %v", err)
+	}
+	if !strings.HasSuffix(path, "/Prague/2020-02-29/2020-02-29") || include != "days" {
+		t.Errorf("requested %s including %q, want the single past day", path, include)
+	}
+	if !day.Date.Equal(date) || day.MaxTemp != 7 || day.MinTemp != 1 || day.Precipitation != 3.5 || day.Condition != models.ConditionSnow {
+		t.Errorf("day = %+v, want 2020-02-29 at 7/1°C with 3.5 mm of snow", day)
+	}
+	
+	empty := newTestVisualCrossingClient(t, respondJSON(`{"days":[]}`))
+	if _, err := empty.GetHistoricalWeather(context.Background(), "Prague", date, models.QueryOptions{}); err == nil {
+		t.Error("GetHistoricalWeather succeeded without days, want an error")
+	}
+}
+
+func TestVisualCrossingNativeUnitsUndoNormalization(t *testing.T) {
+	client := newTestVisualCrossingClient(t, respondJSON(visualCrossingTimeline))
+	
+	current, err := client.GetCurrentWeather(context.Background(), "Prague", models.QueryOptions{})
+	if err != nil {
+		t.Fatalf("GetCurrentWeather: %v", err)
+	}
+	native := client.NativeUnits()
+	if wind := current.WindSpeed * native["wind_speed"].Factor; math.Abs(wind-18) > 1e-9 || native["wind_speed"].Label != "km/h" {
+		t.Errorf("native wind = %v %s, want the 18 km/h sent", wind, native["wind_speed"].Label)
+	}
+	if visibility := *current.Visibility * native["visibility"].Factor; math.Abs(visibility-20) > 1e-9 || native["visibility"].Label != "km" {
+		t.Errorf("native visibility = %v %s, want the 20 km sent", visibility, native["visibility"].Label)
+	}
+}
+
+func TestVisualCrossingMissingTemperatureIsAnError(t *testing.T) {
+	client := newTestVisualCrossingClient(t, respondJSON(
+		`{"currentConditions":{"humidity":65},"days":[{"datetime":"2026-10-15","tempmax":17,"tempmin":9}]}`))
+	
+	current, _, err := client.GetWeather(context.Background(), "Prague", 1, models.QueryOptions{})
+	if current != nil {
+		t.Errorf("current = %+v, want none instead of a 0° reading", current)
+	}
+	assertMissingField(t, err, "currentConditions.temp")
+}